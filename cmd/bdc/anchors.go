@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/anchor"
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+)
+
+// gitOutput runs git with args in the current directory and returns its
+// trimmed stdout.
+func gitOutput(args ...string) (string, error) {
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// repoRelativePath converts a path given on the command line (relative to
+// CWD) into a slash-separated path relative to the repository root.
+func repoRelativePath(path string) (string, error) {
+	top, err := gitOutput("rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", fmt.Errorf("code anchors require a git repository")
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	// Resolve symlinks on both sides so /tmp vs /private/tmp style
+	// differences don't produce "../" paths.
+	if resolved, err := filepath.EvalSymlinks(top); err == nil {
+		top = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(filepath.Dir(abs)); err == nil {
+		abs = filepath.Join(resolved, filepath.Base(abs))
+	}
+	rel, err := filepath.Rel(top, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the repository", path)
+	}
+	if rel == "." {
+		return "", nil
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// resolveAnchorSpec turns "path:40-72" (lines as currently on disk) into an
+// anchor pinned to HEAD. Uncommitted edits to the file are accounted for by
+// mapping the working-tree lines back to their HEAD positions.
+func resolveAnchorSpec(spec string) (types.CodeAnchor, error) {
+	path, start, end, err := anchor.Parse(spec)
+	if err != nil {
+		return types.CodeAnchor{}, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return types.CodeAnchor{}, fmt.Errorf("invalid anchor %q: %w", spec, err)
+	}
+	if info.IsDir() {
+		return types.CodeAnchor{}, fmt.Errorf("invalid anchor %q: is a directory", spec)
+	}
+	rel, err := repoRelativePath(path)
+	if err != nil {
+		return types.CodeAnchor{}, err
+	}
+
+	a := types.CodeAnchor{Path: rel, StartLine: start, EndLine: end}
+
+	head, err := gitOutput("rev-parse", "HEAD")
+	if err != nil {
+		return a, nil // No commits yet
+	}
+	if _, err := gitOutput("cat-file", "-e", head+":"+rel); err != nil {
+		return a, nil // Untracked or new file: nothing to re-map against
+	}
+	a.Commit = head
+
+	if start > 0 {
+		diff, err := gitOutput("diff", "-U0", "--no-color", "--no-ext-diff", head, "--", rel)
+		if err != nil {
+			return types.CodeAnchor{}, fmt.Errorf("failed to diff %s: %w", rel, err)
+		}
+		for _, fd := range anchor.ParseDiff([]byte(diff)) {
+			s, e, status := anchor.Remap(start, end, anchor.Invert(fd.Hunks))
+			if status == anchor.StatusDeleted {
+				// The lines only exist in the working tree; keep them unpinned.
+				a.Commit = ""
+				return a, nil
+			}
+			a.StartLine, a.EndLine = s, e
+		}
+	}
+	return a, nil
+}
+
+// anchorRemapper maps anchors to their current location in the working tree,
+// caching one `git diff` per anchor commit.
+type anchorRemapper struct {
+	diffs map[string]map[string]anchor.FileDiff // commit -> old path -> diff
+}
+
+func newAnchorRemapper() *anchorRemapper {
+	return &anchorRemapper{diffs: make(map[string]map[string]anchor.FileDiff)}
+}
+
+// current returns where a now points and what happened to it since its
+// commit. Anchors without a commit (or whose commit is gone) are returned
+// as recorded.
+func (r *anchorRemapper) current(a types.CodeAnchor) (types.CodeAnchor, anchor.Status) {
+	if a.Commit == "" {
+		return a, anchor.StatusUnchanged
+	}
+	byPath, ok := r.diffs[a.Commit]
+	if !ok {
+		byPath = make(map[string]anchor.FileDiff)
+		if out, err := gitOutput("diff", "-U0", "-M", "--no-color", "--no-ext-diff", a.Commit); err == nil {
+			for _, fd := range anchor.ParseDiff([]byte(out)) {
+				byPath[fd.OldPath] = fd
+			}
+		}
+		r.diffs[a.Commit] = byPath
+	}
+
+	fd, ok := byPath[a.Path]
+	if !ok {
+		return a, anchor.StatusUnchanged
+	}
+	if fd.NewPath == "" {
+		return a, anchor.StatusDeleted
+	}
+
+	cur := a
+	cur.Path = fd.NewPath
+	s, e, status := anchor.Remap(a.StartLine, a.EndLine, fd.Hunks)
+	if status == anchor.StatusDeleted {
+		return a, status
+	}
+	cur.StartLine, cur.EndLine = s, e
+	if status == anchor.StatusUnchanged && cur.Path != a.Path {
+		status = anchor.StatusMoved
+	}
+	return cur, status
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/answer"
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+	"github.com/spf13/cobra"
+)
+
+// askSearchDepth is how many search hits an answer is built from.
+const askSearchDepth = 20
+
+var (
+	askLimit  int
+	askThread string
+)
+
+var askCmd = &cobra.Command{
+	Use:   "ask <question>",
+	Short: "Answer a question from recorded insights",
+	Long: `Answer a question such as "why did we choose exponential backoff?" by
+quoting the recorded insights that answer it, with citations.
+
+The answer is extractive and built offline, with no language model:
+  1. The question's words (minus "why", "did", "choose", ...) are searched
+     for in insight content and summaries with the full-text index, and
+     the matches scored by BM25 (see 'bdc related').
+  2. Links widen the search by one hop: the insight that supersedes a
+     match, and insights that build on, are built on by or contradict one.
+  3. Decisions and pivots rank above discoveries and feedback, and those
+     above hypotheses and questions. Superseded insights rank below their
+     replacement and are marked. Merged duplicates count for the insight
+     they were merged into.
+  4. The best sentence of each top insight is quoted with its [n], and the
+     sources list each insight's thread and current understanding.
+
+With --json the answer, citations, reasons and thread context are printed
+for agents and hooks.
+
+Examples:
+  bdc ask "why did we choose exponential backoff?"
+  bdc ask "how are sessions stored" --limit 5
+  bdc ask "redis timeouts" --thread thr-9e1b
+  bdc ask "why not kafka?" --json`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		question := strings.Join(args, " ")
+		query := answer.Query(question)
+		if query == "" {
+			return fmt.Errorf("the question has no words to search for")
+		}
+
+		s, err := getReadOnlyStore()
+		if err != nil {
+			return err
+		}
+		defer closeStore()
+
+		threadID := ""
+		if askThread != "" {
+			threadID = redirectThreadID(s, askThread)
+		}
+		ranked, err := searchInsights(s, query, threadID, askSearchDepth)
+		if err != nil {
+			return err
+		}
+		hits := make([]answer.Hit, len(ranked))
+		for i, r := range ranked {
+			hits[i] = answer.Hit{Insight: r.Insight, Score: r.Score}
+		}
+
+		corpus := answer.Corpus{
+			Insights: make(map[string]*types.Insight),
+			Threads:  make(map[string]*types.InsightThread),
+		}
+		insights, err := s.ListInsights("", "", time.Time{}, "")
+		if err != nil {
+			return fmt.Errorf("failed to list insights: %w", err)
+		}
+		for _, ins := range insights {
+			corpus.Insights[ins.ID] = ins
+		}
+		threads, err := s.ListThreads("")
+		if err != nil {
+			return fmt.Errorf("failed to list threads: %w", err)
+		}
+		for _, t := range threads {
+			corpus.Threads[t.ID] = t
+		}
+		if corpus.Deps, err = s.ListAllDependencies(); err != nil {
+			return fmt.Errorf("failed to list dependencies: %w", err)
+		}
+
+		a := answer.Ask(question, hits, corpus, askLimit)
+
+		if jsonOutput {
+			out, err := json.MarshalIndent(a, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal JSON: %w", err)
+			}
+			fmt.Println(string(out))
+			return nil
+		}
+
+		if len(a.Citations) == 0 {
+			fmt.Println("No insights found about that. Try other words, or 'bdc list'.")
+			return nil
+		}
+		fmt.Println(a.Answer)
+		fmt.Println()
+		fmt.Println("Sources:")
+		for _, c := range a.Citations {
+			fmt.Printf("  [%d] %s %s %s (%s)\n", c.N, c.ID, c.Type, c.Timestamp.Format("2006-01-02"), strings.Join(c.Reasons, "; "))
+			if c.Thread != nil {
+				line := fmt.Sprintf("Thread %s %q", c.Thread.ID, c.Thread.Title)
+				if c.Thread.Understanding != "" {
+					line += ": " + truncate(c.Thread.Understanding, 80)
+				}
+				fmt.Printf("      %s\n", line)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(askCmd)
+
+	askCmd.Flags().IntVar(&askLimit, "limit", answer.DefaultLimit, "maximum number of insights to cite")
+	askCmd.Flags().StringVar(&askThread, "thread", "", "only search this thread's insights")
+}
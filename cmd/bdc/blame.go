@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/anchor"
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+	"github.com/spf13/cobra"
+)
+
+var blameCmd = &cobra.Command{
+	Use:   "blame <path>[:<line>[-<line>]]",
+	Short: "List insights anchored to a file or line range",
+	Long: `List the insights anchored to a file (or directory), optionally narrowed to
+a line or line range.
+
+Anchors are recorded against a commit with 'bdc capture --at path:40-72'.
+Their line ranges are re-mapped to the current working tree using git diff
+hunks, so an anchor follows its code when lines are added above it, when the
+file is renamed, or when the anchored lines themselves are edited.
+
+Examples:
+  bdc blame src/auth/jwt.go
+  bdc blame src/auth/jwt.go:57
+  bdc blame src/auth/jwt.go:40-72 --json
+  bdc blame src/auth/`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBlame,
+}
+
+// blameEntry is one anchored insight with its re-mapped location.
+type blameEntry struct {
+	Insight *types.Insight   `json:"insight"`
+	Anchor  types.CodeAnchor `json:"anchor"`  // As recorded
+	Current types.CodeAnchor `json:"current"` // In the working tree
+	Status  anchor.Status    `json:"status"`
+}
+
+func runBlame(cmd *cobra.Command, args []string) error {
+	path, start, end, err := anchor.Parse(args[0])
+	if err != nil {
+		return err
+	}
+	rel, err := repoRelativePath(path)
+	if err != nil {
+		return err
+	}
+	isDir := rel == ""
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		isDir = true
+	}
+
+	s, err := getReadOnlyStore()
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	insights, err := s.ListInsights("", "", time.Time{}, "")
+	if err != nil {
+		return fmt.Errorf("failed to list insights: %w", err)
+	}
+
+	remapper := newAnchorRemapper()
+	entries := []blameEntry{}
+	for _, ins := range insights {
+		for _, a := range ins.Anchors {
+			cur, status := remapper.current(a)
+			if !blamePathMatches(cur.Path, rel, isDir) {
+				continue
+			}
+			if start > 0 && (status == anchor.StatusDeleted || !anchor.Overlaps(cur, start, end)) {
+				continue
+			}
+			entries = append(entries, blameEntry{Insight: ins, Anchor: a, Current: cur, Status: status})
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Current.Path != b.Current.Path {
+			return a.Current.Path < b.Current.Path
+		}
+		if a.Current.StartLine != b.Current.StartLine {
+			return a.Current.StartLine < b.Current.StartLine
+		}
+		return a.Insight.Timestamp.Before(b.Insight.Timestamp)
+	})
+
+	if jsonOutput {
+		out, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	if len(entries) == 0 {
+		fmt.Printf("No insights anchored to %s\n", args[0])
+		return nil
+	}
+
+	lastPath := ""
+	for _, e := range entries {
+		if e.Current.Path != lastPath {
+			if lastPath != "" {
+				fmt.Println()
+			}
+			fmt.Println(e.Current.Path)
+			lastPath = e.Current.Path
+		}
+		lines := strings.TrimPrefix(anchor.FormatLines(e.Current), ":")
+		if lines == "" {
+			lines = "(file)"
+		}
+		fmt.Printf("  %-10s %s %s  %s  %s%s\n",
+			lines,
+			getInsightSymbol(e.Insight.Type),
+			e.Insight.ID,
+			e.Insight.Timestamp.Format("2006-01-02"),
+			truncate(e.Insight.Content, 60),
+			blameNote(e))
+	}
+	return nil
+}
+
+// blamePathMatches reports whether an anchored path falls under the query.
+func blamePathMatches(anchored, query string, isDir bool) bool {
+	if !isDir {
+		return anchored == query
+	}
+	return query == "" || strings.HasPrefix(anchored, strings.TrimSuffix(query, "/")+"/")
+}
+
+// blameNote explains how an anchor drifted since it was recorded.
+func blameNote(e blameEntry) string {
+	switch e.Status {
+	case anchor.StatusMoved:
+		return fmt.Sprintf("  (was %s)", anchor.Format(e.Anchor))
+	case anchor.StatusChanged:
+		return fmt.Sprintf("  (code changed since %s)", shortSHA(e.Anchor.Commit))
+	case anchor.StatusDeleted:
+		return fmt.Sprintf("  (deleted since %s)", shortSHA(e.Anchor.Commit))
+	default:
+		return ""
+	}
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+func init() {
+	rootCmd.AddCommand(blameCmd)
+}
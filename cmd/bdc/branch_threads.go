@@ -0,0 +1,287 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/beads"
+	"github.com/brianevanmiller/beadcrumbs/internal/store"
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+	"github.com/spf13/cobra"
+)
+
+// branchRefSystem is the external_ref_mappings system for branch threads.
+const branchRefSystem = "git-branch"
+
+// autoBranchConfigKey enables branch-scoped automatic threads.
+const autoBranchConfigKey = "thread.auto_branch"
+
+// threadConfigKeys are the keys bdc thread config accepts, without the
+// "thread." prefix. The keys are described in threadConfigCmd's help.
+var threadConfigKeys = []string{"auto_branch"}
+
+var threadConfigCmd = &cobra.Command{
+	Use:   "config <key> [value]",
+	Short: "Get or set thread config",
+	Long: `Get or set thread configuration.
+
+Keys:
+  auto_branch   Attach 'bdc capture' without --thread to a thread for the
+                current git branch, creating it on first use (true/false,
+                default: false). main, master and origin's default branch
+                are never given a thread.
+
+Branch threads are linked to their branch as git-branch:<name>. Conclude them
+with 'bdc thread close --branch' once the branch is merged or deleted.
+'bdc capture --no-git-context' records no branch, so it skips the branch
+thread too.
+
+Config is per-repository.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := getStore()
+		if err != nil {
+			return err
+		}
+		defer closeStore()
+
+		known := false
+		for _, k := range threadConfigKeys {
+			if k == args[0] {
+				known = true
+			}
+		}
+		if !known {
+			return fmt.Errorf("unknown thread config key %q (keys: %s)", args[0], strings.Join(threadConfigKeys, ", "))
+		}
+		key := "thread." + args[0]
+
+		if len(args) == 1 {
+			// Get
+			value, err := s.GetConfig(key)
+			if err != nil {
+				return err
+			}
+			if value == "" {
+				fmt.Printf("%s: (not set)\n", args[0])
+			} else {
+				fmt.Printf("%s: %s\n", args[0], value)
+			}
+		} else {
+			// Set
+			value, err := validateThreadConfigValue(args[0], args[1])
+			if err != nil {
+				return err
+			}
+			if err := s.SetConfig(key, value); err != nil {
+				return err
+			}
+			fmt.Printf("Set %s = %s\n", args[0], value)
+		}
+
+		return nil
+	},
+}
+
+// validateThreadConfigValue rejects values the key can't use and returns
+// the value to store. Booleans are stored as true or false, the form
+// autoBranchThread reads.
+func validateThreadConfigValue(key, value string) (string, error) {
+	switch key {
+	case "auto_branch":
+		on, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("auto_branch must be true or false")
+		}
+		return strconv.FormatBool(on), nil
+	}
+	return value, nil
+}
+
+// branchThreadRef returns the external ref that links a thread to branch.
+func branchThreadRef(branch string) string {
+	return branchRefSystem + ":" + branch
+}
+
+// autoBranchThread returns the thread for branch when branch threads are
+// enabled, creating and linking it on first use. It returns "" when the
+// mode is off, HEAD is detached or branch is a default branch.
+func autoBranchThread(branch string) (string, error) {
+	if branch == "" || isDefaultBranch(branch) {
+		return "", nil
+	}
+	s, err := getStore()
+	if err != nil {
+		return "", err
+	}
+	if enabled, _ := s.GetConfig(autoBranchConfigKey); enabled != "true" {
+		return "", nil
+	}
+
+	ref := branchThreadRef(branch)
+	mapping, err := s.GetExternalRefMappingByRef(ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up external ref: %w", err)
+	}
+	if mapping != nil {
+		return redirectThreadID(s, mapping.ThreadID), nil
+	}
+	extRef := &beads.ExternalRef{System: branchRefSystem, ID: branch, Raw: ref}
+	return createThreadForExternalRef(s, extRef, branch)
+}
+
+// isDefaultBranch reports whether branch is the repository's mainline, which
+// outlives any one piece of work and so never gets a branch thread.
+func isDefaultBranch(branch string) bool {
+	if branch == "main" || branch == "master" {
+		return true
+	}
+	head, err := gitOutput("symbolic-ref", "--quiet", "--short", "refs/remotes/origin/HEAD")
+	return err == nil && strings.TrimPrefix(head, "origin/") == branch
+}
+
+// branchThreadMappings returns the git-branch mappings of active threads,
+// or only the one for branch when it is set.
+func branchThreadMappings(s store.Storage, branch string) ([]*store.ExternalRefMapping, error) {
+	if branch != "" {
+		m, err := s.GetExternalRefMappingByRef(branchThreadRef(branch))
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up external ref: %w", err)
+		}
+		if m == nil {
+			return nil, fmt.Errorf("no thread for branch %s", branch)
+		}
+		return []*store.ExternalRefMapping{m}, nil
+	}
+
+	threads, err := s.ListThreads(types.ThreadActive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list threads: %w", err)
+	}
+	var mappings []*store.ExternalRefMapping
+	for _, t := range threads {
+		refs, err := s.GetExternalRefMappingsByThread(t.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get external refs: %w", err)
+		}
+		for _, m := range refs {
+			if m.System == branchRefSystem {
+				mappings = append(mappings, m)
+			}
+		}
+	}
+	return mappings, nil
+}
+
+// branchFinished reports why a branch's work is over: "merged" when it has
+// commits of its own and they are in HEAD (as-is or rebased), "upstream
+// gone" when the remote branch it tracked was deleted (as after a squash
+// merge), "deleted" when the local branch is gone, "" otherwise. The
+// checked-out branch is never finished.
+func branchFinished(branch string) string {
+	if current, err := gitOutput("symbolic-ref", "--quiet", "--short", "HEAD"); err == nil && current == branch {
+		return ""
+	}
+	ref := "refs/heads/" + branch
+	tip, err := gitOutput("rev-parse", "--verify", "--quiet", ref)
+	if err != nil {
+		return "deleted"
+	}
+	if track, err := gitOutput("for-each-ref", "--format=%(upstream:track)", ref); err == nil && track == "[gone]" {
+		return "upstream gone"
+	}
+	// A branch still at its starting point is trivially "in HEAD"; the oldest
+	// reflog entry tells where it started.
+	if log, err := gitOutput("reflog", "show", "--format=%H", ref); err == nil && log != "" {
+		lines := strings.Split(log, "\n")
+		if lines[len(lines)-1] == tip {
+			return ""
+		}
+	}
+	if _, err := gitOutput("merge-base", "--is-ancestor", ref, "HEAD"); err == nil {
+		return "merged"
+	}
+	// Rebased or cherry-picked commits differ in hash but not in patch:
+	// git cherry marks those already in HEAD with "-".
+	cherry, err := gitOutput("cherry", "HEAD", ref)
+	if err != nil || cherry == "" {
+		return ""
+	}
+	for _, line := range strings.Split(cherry, "\n") {
+		if !strings.HasPrefix(line, "-") {
+			return ""
+		}
+	}
+	return "merged"
+}
+
+// closeBranchThreads implements 'bdc thread close --branch [name]': it
+// concludes the named branch's thread, or every active branch thread whose
+// branch has been merged into HEAD or deleted.
+func closeBranchThreads(s store.Storage, branch string, status types.ThreadStatus) error {
+	mappings, err := branchThreadMappings(s, branch)
+	if err != nil {
+		return err
+	}
+
+	var closed []*types.InsightThread
+	for _, m := range mappings {
+		reason := "closed"
+		if branch == "" {
+			if reason = branchFinished(m.ExternalID); reason == "" {
+				continue
+			}
+		}
+
+		thread, err := s.GetThread(redirectThreadID(s, m.ThreadID))
+		if err != nil || thread == nil {
+			return fmt.Errorf("thread not found: %s", m.ThreadID)
+		}
+		if thread.Status != types.ThreadActive {
+			continue
+		}
+		if err := closeThread(s, thread, status); err != nil {
+			return err
+		}
+		closed = append(closed, thread)
+		if !jsonOutput {
+			fmt.Printf("Thread %s closed with status: %s (branch %s %s)\n", thread.ID, status, m.ExternalID, reason)
+		}
+	}
+
+	if jsonOutput {
+		if closed == nil {
+			closed = []*types.InsightThread{}
+		}
+		out, err := json.MarshalIndent(closed, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+	if len(closed) == 0 {
+		fmt.Println("No branch threads to close")
+	}
+	return nil
+}
+
+// branchThreadName returns the branch a thread is linked to, or "".
+func branchThreadName(s store.Storage, threadID string) string {
+	mappings, err := s.GetExternalRefMappingsByThread(threadID)
+	if err != nil {
+		return ""
+	}
+	for _, m := range mappings {
+		if m.System == branchRefSystem {
+			return m.ExternalID
+		}
+	}
+	return ""
+}
+
+func init() {
+	threadCmd.AddCommand(threadConfigCmd)
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/brianevanmiller/beadcrumbs/internal/anchor"
 	"github.com/brianevanmiller/beadcrumbs/internal/beads"
 	gh "github.com/brianevanmiller/beadcrumbs/internal/github"
 	"github.com/brianevanmiller/beadcrumbs/internal/linear"
@@ -28,6 +29,10 @@ var (
 	captureAuthor     string
 	captureEndorsedBy []string
 	captureOrigin     string
+	captureAt         []string
+	captureNoGit      bool
+	captureLabels     []string
+	captureSuggest    bool
 )
 
 var captureCmd = &cobra.Command{
@@ -41,11 +46,27 @@ context. It accepts:
   - Bead ID: bd-xxx or bead-xxx (auto-creates/links thread)
   - External ref: linear:TASK-123, github:owner/repo#42
 
+Without --thread, the insight joins the current git branch's thread when
+'bdc thread config auto_branch true' is set (the thread is created on first
+use; --no-git-context turns this off for one capture).
+
+If the insight reads like an earlier one of the same type, a warning names
+it; 'bdc dedupe' merges the two.
+
+--suggest-links offers links to the most related earlier insights of the
+same thread (see 'bdc related'): a pivot supersedes the direction it turns
+away from (as 'bdc lint' suggests), contradicts when the new insight
+disagrees ("not", "instead", ...), builds-on otherwise. Nothing
+is linked until you run the suggested 'bdc link' commands; with --json
+the suggestions are in "suggested_links".
+
 Examples:
   bdc capture --thread bd-a1b2 --decision "We'll use Redis for caching"
   bdc capture --thread linear:ENG-456 --pivot "Need to rethink the data model"
   bdc capture --hypothesis "The bug might be in the auth middleware" --author cc:opus-4.6
-  bdc capture --origin claude:sess_abc123 --discovery "Found root cause" --thread thr-xxx`,
+  bdc capture --origin claude:sess_abc123 --discovery "Found root cause" --thread thr-xxx
+  bdc capture --at src/auth/jwt.go:40-72 --discovery "The bug is in JWT validation"
+  bdc capture --thread thr-xxx --discovery "Redis timeouts come from the pool size" --suggest-links`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		content := args[0]
@@ -87,6 +108,34 @@ Examples:
 			insight.EndorsedBy = captureEndorsedBy
 		}
 
+		// Labels (mapped to paths for bdc context in .beadcrumbs/context.yaml)
+		if len(captureLabels) > 0 {
+			insight.Tags = captureLabels
+		}
+
+		// Record where in the repository this was learned
+		if !captureNoGit {
+			insight.Source.Git = collectGitContext()
+		}
+
+		// Attach to the current branch's thread when branch threads are on
+		if insight.ThreadID == "" && insight.Source.Git != nil {
+			threadID, err := autoBranchThread(insight.Source.Git.Branch)
+			if err != nil {
+				return fmt.Errorf("failed to resolve branch thread: %w", err)
+			}
+			insight.ThreadID = threadID
+		}
+
+		// Anchor to code (repeatable)
+		for _, spec := range captureAt {
+			a, err := resolveAnchorSpec(spec)
+			if err != nil {
+				return err
+			}
+			insight.Anchors = append(insight.Anchors, a)
+		}
+
 		// Save to store
 		s, err := getStore()
 		if err != nil {
@@ -113,9 +162,26 @@ Examples:
 		if err := s.CreateInsight(insight); err != nil {
 			return fmt.Errorf("failed to save insight: %w", err)
 		}
+		warnNearDuplicates(s, insight)
+
+		var suggested []*store.ScoredInsight
+		var links []types.Dependency
+		if captureSuggest {
+			suggested, links, err = suggestLinks(s, insight, 3)
+			if err != nil {
+				return fmt.Errorf("failed to suggest links: %w", err)
+			}
+		}
 
 		if jsonOutput {
-			out, err := json.MarshalIndent(insight, "", "  ")
+			var result interface{} = insight
+			if captureSuggest {
+				if links == nil {
+					links = []types.Dependency{}
+				}
+				result = captureResult{Insight: insight, SuggestedLinks: links}
+			}
+			out, err := json.MarshalIndent(result, "", "  ")
 			if err != nil {
 				return fmt.Errorf("failed to marshal JSON: %w", err)
 			}
@@ -133,9 +199,21 @@ Examples:
 		if len(insight.EndorsedBy) > 0 {
 			fmt.Printf("  Endorsed by: %s\n", strings.Join(insight.EndorsedBy, ", "))
 		}
+		if len(insight.Tags) > 0 {
+			fmt.Printf("  Labels: %s\n", strings.Join(insight.Tags, ", "))
+		}
 		if insight.Source.Ref != "" {
 			fmt.Printf("  Origin: %s (%s)\n", insight.Source.Ref, insight.Source.Type)
 		}
+		for _, a := range insight.Anchors {
+			fmt.Printf("  Anchor: %s\n", anchor.Format(a))
+		}
+		if insight.Source.Git != nil && insight.Source.Git.Branch != "" {
+			fmt.Printf("  Branch: %s\n", insight.Source.Git.Branch)
+		}
+		if captureSuggest {
+			printSuggestedLinks(insight, suggested, links)
+		}
 		return nil
 	},
 }
@@ -169,6 +247,12 @@ func determineInsightType() (types.InsightType, error) {
 		count++
 		selectedType = types.InsightDecision
 	}
+	for t, set := range captureCustomFlags {
+		if *set {
+			count++
+			selectedType = t
+		}
+	}
 
 	// If --type flag is set, use it
 	if captureType != "" {
@@ -177,7 +261,11 @@ func determineInsightType() (types.InsightType, error) {
 		}
 		t := types.InsightType(captureType)
 		if !t.IsValid() {
-			return "", fmt.Errorf("invalid insight type: %s. Valid types: hypothesis, discovery, question, feedback, pivot, decision", captureType)
+			valid := make([]string, 0, len(types.ValidInsightTypes()))
+			for _, vt := range types.ValidInsightTypes() {
+				valid = append(valid, string(vt))
+			}
+			return "", fmt.Errorf("invalid insight type: %s. Valid types: %s", captureType, strings.Join(valid, ", "))
 		}
 		return t, nil
 	}
@@ -263,9 +351,13 @@ func parseTimestamp(ts string) (time.Time, error) {
 // resolveThreadRef resolves a thread reference to a thread ID.
 // Accepts: thr-xxx (thread ID), bd-xxx (bead ID), or external:ref format.
 func resolveThreadRef(ref string) (string, error) {
-	// Direct thread ID
+	// Direct thread ID (following merge redirects)
 	if strings.HasPrefix(ref, "thr-") {
-		return ref, nil
+		s, err := getStore()
+		if err != nil {
+			return "", err
+		}
+		return redirectThreadID(s, ref), nil
 	}
 
 	// External ref (e.g., "linear:ENG-456")
@@ -397,7 +489,7 @@ func createThreadForExternalRef(s store.Storage, extRef *beads.ExternalRef, titl
 func init() {
 	rootCmd.AddCommand(captureCmd)
 
-	captureCmd.Flags().StringVar(&captureType, "type", "", "insight type (hypothesis|discovery|question|feedback|pivot|decision)")
+	captureCmd.Flags().StringVar(&captureType, "type", "", "insight type (hypothesis|discovery|question|feedback|pivot|decision, or a custom type)")
 	captureCmd.Flags().StringVar(&captureThreadID, "thread", "", "thread/bead/external ref to associate with")
 	captureCmd.Flags().BoolVar(&captureHypothesis, "hypothesis", false, "mark as hypothesis (speculation before evidence)")
 	captureCmd.Flags().BoolVar(&captureDiscovery, "discovery", false, "mark as discovery (evidence-based finding)")
@@ -408,9 +500,36 @@ func init() {
 	captureCmd.Flags().StringVar(&captureTimestamp, "timestamp", "", "when the insight occurred (RFC3339, date, or relative like '2h ago')")
 	captureCmd.Flags().StringVar(&captureAuthor, "author", "", "who captured this insight (e.g., 'brian', 'cc:opus-4.6')")
 	captureCmd.Flags().StringSliceVar(&captureEndorsedBy, "endorsed-by", nil, "who endorsed this insight (repeatable)")
+	captureCmd.Flags().BoolVar(&captureNoGit, "no-git-context", false, "don't record branch, commit and worktree, or join the branch thread")
+	captureCmd.Flags().StringSliceVar(&captureAt, "at", nil, "anchor to code: path[:start[-end]] (repeatable)")
+	captureCmd.Flags().StringSliceVar(&captureLabels, "label", nil, "label the insight, e.g. auth (repeatable)")
+	captureCmd.Flags().BoolVar(&captureSuggest, "suggest-links", false, "suggest builds-on/contradicts/supersedes links to related insights in the thread")
 	captureCmd.Flags().StringVar(&captureOrigin, "origin", "", "origin identifier (e.g., 'claude:sess_abc123', 'notion:page-id')")
 }
 
+// captureResult is the --json output of bdc capture --suggest-links.
+type captureResult struct {
+	*types.Insight
+	SuggestedLinks []types.Dependency `json:"suggested_links"`
+}
+
+// printSuggestedLinks prints the bdc link commands for the links
+// suggestLinks found.
+func printSuggestedLinks(insight *types.Insight, targets []*store.ScoredInsight, links []types.Dependency) {
+	switch {
+	case insight.ThreadID == "":
+		fmt.Println("No link suggestions: the insight has no thread.")
+		return
+	case len(targets) == 0:
+		fmt.Println("No link suggestions: nothing related in the thread.")
+		return
+	}
+	fmt.Println("Suggested links:")
+	for i, t := range targets {
+		fmt.Printf("  bdc link %s --%s %s   # %.2f %s\n", insight.ID, links[i].Type, t.ID, t.Score, truncate(t.Content, 50))
+	}
+}
+
 // resolveOrigin resolves the origin value from flag > env > file.
 // If --origin was explicitly passed (even as empty string), that takes precedence.
 func resolveOrigin(cmd *cobra.Command) string {
@@ -428,6 +547,7 @@ func resolveOrigin(cmd *cobra.Command) string {
 func inferSourceType(origin, author string) string {
 	aiPrefixes := []string{
 		"claude:", "cursor:", "codex:", "warp:", "gemini:", "zed:", "opencode:",
+		"aider:", "windsurf:",
 	}
 	for _, prefix := range aiPrefixes {
 		if strings.HasPrefix(origin, prefix) {
@@ -0,0 +1,391 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/classify"
+	"github.com/brianevanmiller/beadcrumbs/internal/store"
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	classifyExplain bool
+	classifySource  string
+
+	// Training
+	classifyMinExamples  int
+	classifyTestFraction float64
+)
+
+// minTrainingExamples is the fewest labeled insights bdc classify train
+// accepts by default.
+const minTrainingExamples = 20
+
+var classifyCmd = &cobra.Command{
+	Use:   "classify <text>",
+	Short: "Show the insight type and confidence bdc would assign to text",
+	Long: `Classify text the way imports do and print the insight type and
+confidence. Use --explain to see which rules fired, which were negated and
+how each type scored, when tuning .beadcrumbs/classify.yaml.
+
+Each rule adds its weight to its type's score when its pattern matches,
+unless a negation ("not", "no longer", ...) appears in the three words
+before the match. The highest score wins; below the threshold the default
+type is used.
+
+  version: 1
+  threshold: 0.5            # lowest winning score (default 0.5)
+  default_type: hypothesis  # when nothing reaches the threshold
+  default_confidence: 0.3
+  negations: ["not", "no longer", "never"]
+  no_builtin: false         # true drops the built-in rules
+  rules:
+    - type: decision
+      match: "we agreed"    # case-insensitive substring
+      weight: 1.5           # default 1
+    - type: risk            # custom types from types.yaml work too
+      regex: '\bmight (break|fail)\b'
+      negations: ["unlikely to"]
+  sources:                  # per source type: slack, ai-session, csv-import, ...
+    slack:
+      weights: {question: 0.5}
+      rules:
+        - type: decision
+          match: ":white_check_mark:"
+  model_weight: 2           # how much a trained model counts (default 2)
+  no_model: false           # true ignores the trained model
+
+After "bdc classify train" a model learned from your captured insights adds
+its probability for each type, times model_weight, to the rule scores.
+
+Examples:
+  bdc classify "Turns out the cache is per tenant"
+  bdc classify "We're not going with Redis" --explain
+  bdc classify "Ship it?" --source slack --json
+  bdc classify train
+  bdc classify eval`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		text := strings.Join(args, " ")
+		res := classify.Default().Classify(text, classifySource)
+
+		if jsonOutput {
+			data, err := json.MarshalIndent(res, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal JSON: %w", err)
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+
+		fmt.Printf("%s %s (confidence %.2f)\n", getInsightSymbol(res.Type), res.Type, res.Confidence)
+		if !classifyExplain {
+			return nil
+		}
+
+		fmt.Println("\nRules:")
+		if len(res.Hits) == 0 {
+			fmt.Println("  (none matched)")
+		}
+		for _, h := range res.Hits {
+			mark, note := "+", fmt.Sprintf("+%.2f", h.Rule.Weight)
+			if h.NegatedBy != "" {
+				mark, note = "x", fmt.Sprintf("negated by %q", h.NegatedBy)
+			}
+			name := h.Rule.Pattern()
+			if h.Rule.Name != "" {
+				name = h.Rule.Name + " " + name
+			}
+			fmt.Printf("  %s %-12s %-30s %-18s [%s]\n", mark, h.Rule.Type, name, note, h.Source)
+		}
+
+		if len(res.Scores) > 0 {
+			fmt.Println("\nScores:")
+			for _, t := range res.SortedScores() {
+				fmt.Printf("  %-12s %.2f\n", t, res.Scores[t])
+			}
+		}
+
+		cfg := classify.Default()
+		if res.Fallback {
+			fmt.Printf("\nNo type reached the threshold %.2f; using the default type.\n", cfg.Threshold)
+		}
+		if _, err := os.Stat(classifyConfigPath()); err == nil {
+			fmt.Printf("\nConfig: %s\n", classifyConfigPath())
+		} else {
+			fmt.Printf("\nConfig: built-in rules (no %s)\n", classifyConfigPath())
+		}
+		if cfg.Model != nil && !cfg.NoModel {
+			fmt.Printf("Model: %s (%d examples, weight %.2f)\n", classifyModelPath(), cfg.Model.Examples, cfg.ModelWeight)
+		}
+		return nil
+	},
+}
+
+var classifyTrainCmd = &cobra.Command{
+	Use:   "train",
+	Short: "Train the type model from captured insights",
+	Long: `Train a naive Bayes model over the words and word pairs of the
+insights people captured with an explicit type, and save it to
+.beadcrumbs/classify-model.json. Imported, extracted and agent-captured
+insights are left out, since their types were guessed. Once the model
+exists, imports and "bdc classify" combine it with the rules (see
+model_weight).
+
+Retrain after capturing more insights; delete the file to go back to the
+rules alone.
+
+Examples:
+  bdc classify train
+  bdc classify train --min-examples 50`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := getReadOnlyStore()
+		if err != nil {
+			return err
+		}
+		defer closeStore()
+
+		examples, err := labeledExamples(s)
+		if err != nil {
+			return err
+		}
+		if len(examples) < classifyMinExamples {
+			return fmt.Errorf("need at least %d captured insights to train, found %d (see --min-examples)", classifyMinExamples, len(examples))
+		}
+
+		m := classify.Train(examples)
+		if err := m.Save(classifyModelPath()); err != nil {
+			return err
+		}
+
+		if jsonOutput {
+			counts := make(map[types.InsightType]int)
+			for t, c := range m.Classes {
+				counts[t] = c.Docs
+			}
+			data, err := json.MarshalIndent(map[string]interface{}{
+				"path":     classifyModelPath(),
+				"examples": m.Examples,
+				"vocab":    m.Vocab,
+				"types":    counts,
+			}, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal JSON: %w", err)
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+
+		fmt.Printf("Trained on %d insights (%d distinct terms):\n", m.Examples, m.Vocab)
+		for _, t := range m.Types() {
+			fmt.Printf("  %s %-12s %d\n", getInsightSymbol(t), t, m.Classes[t].Docs)
+		}
+		fmt.Printf("Saved %s\n", classifyModelPath())
+		return nil
+	},
+}
+
+var classifyEvalCmd = &cobra.Command{
+	Use:   "eval",
+	Short: "Measure type detection on a held-out share of captured insights",
+	Long: `Hold out a share of the captured insights, train a model on the
+rest and classify the held-out insights three ways: with the rules alone,
+with the model alone and with both, as imports do. Prints the accuracy of
+each and a confusion matrix for rules plus model. The saved model is not
+changed.
+
+The split depends only on insight IDs, so repeated runs over the same
+insights give the same numbers.
+
+Examples:
+  bdc classify eval
+  bdc classify eval --test-fraction 0.3 --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if classifyTestFraction <= 0 || classifyTestFraction >= 1 {
+			return fmt.Errorf("--test-fraction must be between 0 and 1")
+		}
+
+		s, err := getReadOnlyStore()
+		if err != nil {
+			return err
+		}
+		defer closeStore()
+
+		insights, err := labeledInsights(s)
+		if err != nil {
+			return err
+		}
+		var train, test []classify.Example
+		for _, ins := range insights {
+			ex := classify.Example{Text: ins.Content, Type: ins.Type}
+			if heldOut(ins.ID, classifyTestFraction) {
+				test = append(test, ex)
+			} else {
+				train = append(train, ex)
+			}
+		}
+		if len(train) == 0 || len(test) == 0 {
+			return fmt.Errorf("not enough captured insights to evaluate (found %d)", len(insights))
+		}
+
+		m := classify.Train(train)
+		rules := *classify.Default()
+		rules.Model = nil
+		combined := rules
+		combined.Model, combined.NoModel = m, false
+
+		rulesEval := classify.Evaluate(test, func(text string) types.InsightType {
+			return rules.Classify(text, "").Type
+		})
+		modelEval := classify.Evaluate(test, func(text string) types.InsightType {
+			t, _ := m.Best(text)
+			return t
+		})
+		combinedEval := classify.Evaluate(test, func(text string) types.InsightType {
+			return combined.Classify(text, "").Type
+		})
+
+		if jsonOutput {
+			data, err := json.MarshalIndent(map[string]interface{}{
+				"train":    len(train),
+				"test":     len(test),
+				"rules":    rulesEval,
+				"model":    modelEval,
+				"combined": combinedEval,
+			}, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal JSON: %w", err)
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+
+		fmt.Printf("Trained on %d insights, tested on %d held out.\n\n", len(train), len(test))
+		fmt.Println("Accuracy:")
+		fmt.Printf("  rules         %5.1f%%\n", 100*rulesEval.Accuracy)
+		fmt.Printf("  model         %5.1f%%\n", 100*modelEval.Accuracy)
+		fmt.Printf("  rules + model %5.1f%%\n", 100*combinedEval.Accuracy)
+
+		fmt.Println("\nConfusion matrix, rules + model (rows: actual, columns: predicted):")
+		printConfusionMatrix(combinedEval)
+
+		fmt.Println("\nPer type:")
+		for _, t := range combinedEval.Types {
+			fmt.Printf("  %-12s precision %.2f  recall %.2f\n", t, combinedEval.Precision(t), combinedEval.Recall(t))
+		}
+		return nil
+	},
+}
+
+func init() {
+	classifyCmd.Flags().BoolVar(&classifyExplain, "explain", false, "show the rules that fired and the score of each type")
+	classifyCmd.Flags().StringVar(&classifySource, "source", "", "classify as if imported from this source type (e.g. slack)")
+	classifyTrainCmd.Flags().IntVar(&classifyMinExamples, "min-examples", minTrainingExamples, "fewest captured insights to train on")
+	classifyEvalCmd.Flags().Float64Var(&classifyTestFraction, "test-fraction", 0.2, "share of captured insights to hold out for testing")
+	classifyCmd.AddCommand(classifyTrainCmd)
+	classifyCmd.AddCommand(classifyEvalCmd)
+	rootCmd.AddCommand(classifyCmd)
+}
+
+// labeledInsights returns the insights a person captured with an explicit
+// type, oldest first. Imported and extracted insights carry a guessed type,
+// so they are left out whatever their confidence.
+func labeledInsights(s store.Storage) ([]*types.Insight, error) {
+	all, err := s.ListInsights("", "", time.Time{}, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list insights: %w", err)
+	}
+	var labeled []*types.Insight
+	for i := len(all) - 1; i >= 0; i-- {
+		ins := all[i]
+		if capturedByHand(ins) && ins.Type.IsValid() && strings.TrimSpace(ins.Content) != "" {
+			labeled = append(labeled, ins)
+		}
+	}
+	return labeled, nil
+}
+
+// capturedByHand reports whether ins came from bdc capture by a person:
+// capture records a "human" source (agents get "ai-session"), while
+// importers record their own source type or the conversation participants.
+func capturedByHand(ins *types.Insight) bool {
+	return ins.Source.Type == "human" && len(ins.Source.Participants) == 0
+}
+
+// labeledExamples returns labeledInsights as training examples.
+func labeledExamples(s store.Storage) ([]classify.Example, error) {
+	insights, err := labeledInsights(s)
+	if err != nil {
+		return nil, err
+	}
+	examples := make([]classify.Example, len(insights))
+	for i, ins := range insights {
+		examples[i] = classify.Example{Text: ins.Content, Type: ins.Type}
+	}
+	return examples, nil
+}
+
+// heldOut reports whether the insight with this ID is in the test split.
+func heldOut(id string, fraction float64) bool {
+	h := fnv.New32a()
+	h.Write([]byte(id))
+	return float64(h.Sum32()%1000) < fraction*1000
+}
+
+// printConfusionMatrix prints ev.Matrix as a table.
+func printConfusionMatrix(ev *classify.Evaluation) {
+	width := 5
+	for _, t := range ev.Types {
+		if len(t) > width {
+			width = len(t)
+		}
+	}
+	fmt.Printf("  %-*s", width, "")
+	for _, t := range ev.Types {
+		fmt.Printf(" %*s", width, t)
+	}
+	fmt.Println()
+	for _, actual := range ev.Types {
+		fmt.Printf("  %-*s", width, actual)
+		for _, predicted := range ev.Types {
+			fmt.Printf(" %*d", width, ev.Matrix[actual][predicted])
+		}
+		fmt.Println()
+	}
+}
+
+// classifyConfigPath returns the classifier config next to the database.
+func classifyConfigPath() string {
+	return filepath.Join(filepath.Dir(dbPath), classify.ConfigFileName)
+}
+
+// classifyModelPath returns the trained model file next to the database.
+func classifyModelPath() string {
+	return filepath.Join(filepath.Dir(dbPath), classify.ModelFileName)
+}
+
+// loadClassifier registers the classifier config for the resolved
+// database, with the trained model if there is one. Custom types must be
+// loaded first so rules can name them. A model that can't be read is
+// skipped with a warning so bdc classify train can replace it.
+func loadClassifier() error {
+	if err := classify.LoadAndApply(classifyConfigPath()); err != nil {
+		return fmt.Errorf("invalid %s: %w", classifyConfigPath(), err)
+	}
+	m, err := classify.LoadModel(classifyModelPath())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring %s: %v\n", classifyModelPath(), err)
+		return nil
+	}
+	classify.Default().Model = m
+	return nil
+}
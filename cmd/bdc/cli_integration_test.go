@@ -1,12 +1,20 @@
 package main
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
 )
 
 var testBinary string
@@ -339,294 +347,1958 @@ func TestCLI_TraceBeadID(t *testing.T) {
 	}
 }
 
-// ============================================================================
-// Import Operations (PR #6)
-// ============================================================================
-
-func TestCLI_ImportCSV_DryRun(t *testing.T) {
+func TestCLI_ThreadHierarchy(t *testing.T) {
 	dir := setupTestEnv(t)
 
-	// Create CSV file
-	csvContent := "content,type\nTest CSV insight,hypothesis\nAnother insight,decision\n"
-	csvPath := filepath.Join(dir, "test.csv")
-	if err := os.WriteFile(csvPath, []byte(csvContent), 0644); err != nil {
-		t.Fatal(err)
-	}
+	pOut, _, _ := bdcRun(t, dir, "thread", "new", "Parent Investigation")
+	parentID := extractThreadID(t, pOut)
 
-	stdout, _, err := bdcRun(t, dir, "import", "--dry-run", csvPath)
+	cOut, _, err := bdcRun(t, dir, "thread", "new", "Child Investigation", "--parent", parentID)
 	if err != nil {
-		t.Fatalf("import --dry-run failed: %v", err)
+		t.Fatalf("thread new --parent failed: %v", err)
 	}
+	childID := extractThreadID(t, cOut)
 
-	if !strings.Contains(stdout, "Test CSV insight") && !strings.Contains(stdout, "hypothesis") {
-		t.Errorf("dry-run output should show preview, got: %q", stdout)
+	bdcRun(t, dir, "capture", "--thread", parentID, "--discovery", "parent finding")
+	bdcRun(t, dir, "capture", "--thread", childID, "--decision", "child decision")
+
+	// List nests the child under the parent
+	stdout, _, _ := bdcRun(t, dir, "thread", "list")
+	if !strings.Contains(stdout, "└── "+childID) {
+		t.Errorf("thread list should nest child under parent, got: %q", stdout)
 	}
-}
 
-func TestCLI_ImportJSONL_DryRun(t *testing.T) {
-	dir := setupTestEnv(t)
+	// Show lists sub-threads; --rollup includes their insights
+	stdout, _, _ = bdcRun(t, dir, "thread", "show", parentID)
+	if !strings.Contains(stdout, "Sub-threads (1)") || strings.Contains(stdout, "child decision") {
+		t.Errorf("thread show should list sub-thread but not its insights, got: %q", stdout)
+	}
+	stdout, _, _ = bdcRun(t, dir, "thread", "show", parentID, "--rollup")
+	if !strings.Contains(stdout, "child decision") {
+		t.Errorf("thread show --rollup should include child insights, got: %q", stdout)
+	}
 
-	// Create JSONL file
-	jsonlContent := `{"content":"JSONL test insight","type":"hypothesis"}
-{"content":"Another JSONL insight","type":"decision"}
-`
-	jsonlPath := filepath.Join(dir, "test.jsonl")
-	if err := os.WriteFile(jsonlPath, []byte(jsonlContent), 0644); err != nil {
-		t.Fatal(err)
+	stdout, _, _ = bdcRun(t, dir, "timeline", parentID, "--rollup")
+	if !strings.Contains(stdout, "child decision") || !strings.Contains(stdout, "parent finding") {
+		t.Errorf("timeline --rollup should include both threads, got: %q", stdout)
 	}
 
-	stdout, _, err := bdcRun(t, dir, "import", "--dry-run", jsonlPath)
+	// Closing the parent warns about the active child but still succeeds
+	_, stderr, err := bdcRun(t, dir, "thread", "close", parentID)
 	if err != nil {
-		t.Fatalf("import --dry-run JSONL failed: %v", err)
+		t.Fatalf("thread close failed: %v", err)
 	}
-
-	if !strings.Contains(stdout, "JSONL test insight") {
-		t.Errorf("dry-run output should show preview, got: %q", stdout)
+	if !strings.Contains(stderr, "active sub-thread") || !strings.Contains(stderr, childID) {
+		t.Errorf("expected active sub-thread warning, got stderr: %q", stderr)
 	}
-}
-
-func TestCLI_ImportNonexistentFile(t *testing.T) {
-	dir := setupTestEnv(t)
 
-	_, _, err := bdcRun(t, dir, "import", "nonexistent.csv")
+	// Unknown parent is rejected
+	_, _, err = bdcRun(t, dir, "thread", "new", "Orphan", "--parent", "thr-none")
 	if err == nil {
-		t.Error("expected error importing non-existent file")
+		t.Error("expected error for unknown parent")
 	}
 }
 
-func TestCLI_ImportAutoJSONL(t *testing.T) {
+func TestCLI_ThreadMergeSplitMove(t *testing.T) {
 	dir := setupTestEnv(t)
 
-	// First, create some data via the CLI and export it
-	tOut, _, _ := bdcRun(t, dir, "thread", "new", "Auto-import Source Thread")
-	thrID := extractThreadID(t, tOut)
-	bdcRun(t, dir, "capture", "--thread", thrID, "--decision", "auto import content")
-	bdcRun(t, dir, "export", "--quiet")
+	// Two threads for the same topic: one from a bead ref, one created directly
+	c1, _, _ := bdcRun(t, dir, "capture", "--thread", "bd-dup1", "--hypothesis", "maybe a race")
+	ins1 := extractInsightID(t, c1)
+	srcID := extractThreadID(t, c1)
+	tOut, _, _ := bdcRun(t, dir, "thread", "new", "Race condition")
+	dstID := extractThreadID(t, tOut)
+	c2, _, _ := bdcRun(t, dir, "capture", "--thread", dstID, "--discovery", "lock is missing")
+	ins2 := extractInsightID(t, c2)
+	bdcRun(t, dir, "link", ins2, "--builds-on="+ins1)
 
-	// Read the exported JSONL to verify it exists
-	threadsData, err := os.ReadFile(filepath.Join(dir, ".beadcrumbs", "threads.jsonl"))
-	if err != nil {
-		t.Fatalf("failed to read exported threads.jsonl: %v", err)
+	// Merge
+	if _, _, err := bdcRun(t, dir, "thread", "merge", srcID, dstID); err != nil {
+		t.Fatalf("thread merge failed: %v", err)
 	}
-	if len(threadsData) == 0 {
-		t.Fatal("exported threads.jsonl is empty")
+
+	// The old thread ID and the bead ref both resolve to the destination
+	stdout, _, _ := bdcRun(t, dir, "thread", "show", srcID)
+	if !strings.Contains(stdout, "Thread: "+dstID) || !strings.Contains(stdout, "maybe a race") {
+		t.Errorf("old thread ID should redirect to %s, got: %q", dstID, stdout)
+	}
+	c3, _, _ := bdcRun(t, dir, "capture", "--thread", "bd-dup1", "--decision", "add a mutex")
+	if strings.Contains(c3, "Created thread") || !strings.Contains(c3, dstID) {
+		t.Errorf("bead ref should map to merged thread %s, got: %q", dstID, c3)
 	}
+	ins3 := extractInsightID(t, c3)
 
-	// Create a fresh environment and copy the JSONL files
-	dir2 := t.TempDir()
-	gitCmd := exec.Command("git", "init")
-	gitCmd.Dir = dir2
-	gitCmd.Run()
-	bdcRun(t, dir2, "init", "--quiet")
+	// Merged thread is hidden from the default list
+	stdout, _, _ = bdcRun(t, dir, "thread", "list")
+	if strings.Contains(stdout, srcID) {
+		t.Errorf("merged thread should not be listed, got: %q", stdout)
+	}
 
-	// Copy threads.jsonl from first env
-	if err := os.WriteFile(filepath.Join(dir2, ".beadcrumbs", "threads.jsonl"), threadsData, 0644); err != nil {
-		t.Fatal(err)
+	// Dependency survives
+	stdout, _, _ = bdcRun(t, dir, "show", ins2)
+	if !strings.Contains(stdout, ins1) {
+		t.Errorf("builds-on edge should survive merge, got: %q", stdout)
 	}
 
-	// Run auto import
-	_, _, err = bdcRun(t, dir2, "import", "--auto", "--quiet")
+	// Split from the decision onwards
+	stdout, _, err := bdcRun(t, dir, "thread", "split", dstID, "--from", ins3, "--title", "Fix")
 	if err != nil {
-		t.Fatalf("import --auto failed: %v", err)
+		t.Fatalf("thread split failed: %v", err)
+	}
+	splitID := extractThreadID(t, stdout)
+	stdout, _, _ = bdcRun(t, dir, "thread", "show", splitID)
+	if !strings.Contains(stdout, "add a mutex") || strings.Contains(stdout, "lock is missing") {
+		t.Errorf("split thread should hold only the later insight, got: %q", stdout)
 	}
 
-	// Verify thread was imported (must specify --status since default list requires it)
-	stdout, _, _ := bdcRun(t, dir2, "thread", "list", "--status=active")
-	if !strings.Contains(stdout, "Auto-import Source Thread") {
-		t.Errorf("auto import should have imported thread, thread list: %q", stdout)
+	// Move one insight back
+	if _, _, err := bdcRun(t, dir, "mv", ins3, "--thread", dstID); err != nil {
+		t.Fatalf("mv failed: %v", err)
+	}
+	stdout, _, _ = bdcRun(t, dir, "thread", "show", dstID)
+	if !strings.Contains(stdout, "add a mutex") {
+		t.Errorf("mv should move insight back, got: %q", stdout)
 	}
 }
 
-// ============================================================================
-// Prime (PRs #1, #8)
-// ============================================================================
-
-func TestCLI_PrimeOutput(t *testing.T) {
+func TestCLI_ThreadUnderstand(t *testing.T) {
 	dir := setupTestEnv(t)
 
-	stdout, _, _ := bdcRun(t, dir, "prime")
-	if !strings.Contains(stdout, "Beadcrumbs") {
-		t.Errorf("prime output should contain 'Beadcrumbs', got: %q", stdout)
+	tOut, _, _ := bdcRun(t, dir, "thread", "new", "Understand Test")
+	thrID := extractThreadID(t, tOut)
+	bdcRun(t, dir, "capture", "--thread", thrID, "--decision", "Use Redis")
+	bdcRun(t, dir, "capture", "--thread", thrID, "--pivot", "Drop the in-memory cache")
+
+	// --suggest prints a deterministic draft without saving
+	stdout, _, err := bdcRun(t, dir, "thread", "understand", thrID, "--suggest")
+	if err != nil {
+		t.Fatalf("understand --suggest failed: %v", err)
+	}
+	want := "Current direction: Use Redis.\nChanged course: Drop the in-memory cache.\n"
+	if stdout != want {
+		t.Errorf("draft = %q, want %q", stdout, want)
 	}
-}
 
-func TestCLI_PrimeSilentWhenAbsent(t *testing.T) {
-	dir := t.TempDir() // No .beadcrumbs/
+	// Set directly
+	if _, _, err := bdcRun(t, dir, "thread", "understand", thrID, "Redis it is", "--author", "alice"); err != nil {
+		t.Fatalf("understand failed: %v", err)
+	}
 
-	stdout, _, _ := bdcRun(t, dir, "prime")
-	if stdout != "" {
-		t.Errorf("prime should produce no stdout when .beadcrumbs/ absent, got: %q", stdout)
+	// Edit via $EDITOR: the script extends the first line in place and adds
+	// a Markdown heading, which must survive
+	editor := filepath.Join(t.TempDir(), "editor.sh")
+	os.WriteFile(editor, []byte("#!/bin/sh\nsed -i.bak '1s/$/ with a 5m TTL/' \"$1\"\n"+
+		"{ echo '# Cache plan'; cat \"$1\"; } > \"$1.new\" && mv \"$1.new\" \"$1\"\n"), 0755)
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", editor)
+	if _, stderr, err := bdcRun(t, dir, "thread", "understand", thrID, "--author", "bob"); err != nil {
+		t.Fatalf("understand via editor failed: %v (%s)", err, stderr)
+	}
+
+	stdout, _, _ = bdcRun(t, dir, "thread", "show", thrID)
+	if !strings.Contains(stdout, "Redis it is with a 5m TTL") || !strings.Contains(stdout, "by bob") {
+		t.Errorf("thread show should include edited understanding, got: %q", stdout)
+	}
+	if !strings.Contains(stdout, "# Cache plan") || strings.Contains(stdout, "everything below") {
+		t.Errorf("only the text below the scissors line should be dropped, got: %q", stdout)
+	}
+
+	stdout, _, _ = bdcRun(t, dir, "thread", "understand", thrID, "--history")
+	if !strings.Contains(stdout, "#2") || !strings.Contains(stdout, "alice") || !strings.Contains(stdout, "bob") {
+		t.Errorf("history should list both revisions, got: %q", stdout)
 	}
 }
 
-func TestCLI_PrimeCustomOverride(t *testing.T) {
+func TestCLI_CustomInsightTypes(t *testing.T) {
 	dir := setupTestEnv(t)
-
-	// Create custom PRIME.md
-	primePath := filepath.Join(dir, ".beadcrumbs", "PRIME.md")
-	if err := os.WriteFile(primePath, []byte("CUSTOM PRIME CONTENT"), 0644); err != nil {
+	typesYAML := "version: 1\ntypes:\n  - name: risk\n    symbol: \"⚠\"\n    section: Open Risks\n"
+	if err := os.WriteFile(filepath.Join(dir, ".beadcrumbs", "types.yaml"), []byte(typesYAML), 0644); err != nil {
 		t.Fatal(err)
 	}
 
-	stdout, _, _ := bdcRun(t, dir, "prime")
-	if !strings.Contains(stdout, "CUSTOM PRIME CONTENT") {
-		t.Errorf("prime should use PRIME.md override, got: %q", stdout)
+	// The flag alias and --type both accept the custom type
+	tOut, _, _ := bdcRun(t, dir, "thread", "new", "Risky")
+	thrID := extractThreadID(t, tOut)
+	if _, stderr, err := bdcRun(t, dir, "capture", "--thread", thrID, "--risk", "Eviction could drop sessions"); err != nil {
+		t.Fatalf("capture --risk failed: %v\n%s", err, stderr)
 	}
-	if strings.Contains(stdout, "Beadcrumbs Insight Tracker Active") {
-		t.Error("prime should NOT show default content when PRIME.md exists")
+	if _, stderr, err := bdcRun(t, dir, "capture", "--thread", thrID, "--type", "risk", "Cold cache on deploy"); err != nil {
+		t.Fatalf("capture --type risk failed: %v\n%s", err, stderr)
 	}
-}
 
-func TestCLI_PrimeExportFlag(t *testing.T) {
-	dir := setupTestEnv(t)
+	stdout, _, err := bdcRun(t, dir, "timeline", thrID)
+	if err != nil {
+		t.Fatalf("timeline failed: %v", err)
+	}
+	if !strings.Contains(stdout, "⚠") {
+		t.Errorf("timeline should use the custom symbol, got:\n%s", stdout)
+	}
 
-	// Create custom PRIME.md
-	primePath := filepath.Join(dir, ".beadcrumbs", "PRIME.md")
-	if err := os.WriteFile(primePath, []byte("CUSTOM"), 0644); err != nil {
+	_, stderr, err := bdcRun(t, dir, "capture", "--type", "constraint", "Must run on ARM")
+	if err == nil || !strings.Contains(stderr, "risk") {
+		t.Errorf("unknown --type should fail and list custom types, got err=%v stderr=%q", err, stderr)
+	}
+
+	// Unknown types arriving via JSONL are kept, with a warning
+	line := `{"id":"ins-c0ffee","timestamp":"2026-01-01T00:00:00Z","content":"Must run on ARM","summary":"","type":"constraint","confidence":1,"source":{"type":"human"},"created_at":"2026-01-01T00:00:00Z"}` + "\n"
+	f, err := os.OpenFile(filepath.Join(dir, ".beadcrumbs", "insights.jsonl"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
 		t.Fatal(err)
 	}
+	f.WriteString(line)
+	f.Close()
 
-	stdout, _, _ := bdcRun(t, dir, "prime", "--export")
-	if strings.Contains(stdout, "CUSTOM") {
-		t.Error("prime --export should ignore PRIME.md")
+	_, stderr, err = bdcRun(t, dir, "import", "--auto", "--quiet")
+	if err != nil {
+		t.Fatalf("import --auto failed: %v\n%s", err, stderr)
 	}
-	if !strings.Contains(stdout, "Beadcrumbs") {
-		t.Errorf("prime --export should show default content, got: %q", stdout)
+	if !strings.Contains(stderr, "unknown type(s): constraint") {
+		t.Errorf("expected unknown type warning, got stderr=%q", stderr)
+	}
+	stdout, _, _ = bdcRun(t, dir, "show", "ins-c0ffee")
+	if !strings.Contains(stdout, "Must run on ARM") {
+		t.Errorf("insight with unknown type should still be imported, got:\n%s", stdout)
 	}
-}
-
-// ============================================================================
-// Timeline + Filtering (PR #9)
-// ============================================================================
 
-func TestCLI_TimelineOriginFilter(t *testing.T) {
-	dir := setupTestEnv(t)
+	// A broken config stops captures and is only a warning elsewhere
+	os.WriteFile(filepath.Join(dir, ".beadcrumbs", "types.yaml"), []byte("version: 7\n"), 0644)
+	if _, stderr, err := bdcRun(t, dir, "capture", "--discovery", "x"); err == nil || !strings.Contains(stderr, "unsupported types config version") {
+		t.Errorf("expected config error, got err=%v stderr=%q", err, stderr)
+	}
+	if _, stderr, err := bdcRun(t, dir, "list"); err != nil || !strings.Contains(stderr, "Warning: invalid") {
+		t.Errorf("list should warn and continue, got err=%v stderr=%q", err, stderr)
+	}
 
-	tOut, _, _ := bdcRun(t, dir, "thread", "new", "Timeline Filter Test")
-	thrID := extractThreadID(t, tOut)
+	// Flags capture already has can't be claimed
+	os.WriteFile(filepath.Join(dir, ".beadcrumbs", "types.yaml"), []byte("version: 1\ntypes:\n  - name: risk\n    symbol: x\n    flag: suggest-links\n"), 0644)
+	if _, stderr, err := bdcRun(t, dir, "capture", "--discovery", "y"); err == nil || !strings.Contains(stderr, "--suggest-links") {
+		t.Errorf("expected a flag clash error, got err=%v stderr=%q", err, stderr)
+	}
+}
 
-	// Two insights from different origins
-	bdcRun(t, dir, "capture", "--thread", thrID, "--origin", "claude:sess_x", "--decision", "from claude")
-	bdcRun(t, dir, "capture", "--thread", thrID, "--origin", "cursor:ws_y", "--hypothesis", "from cursor")
+func TestCLI_Classify(t *testing.T) {
+	dir := setupTestEnv(t)
 
-	// Filter by claude origin
-	stdout, _, err := bdcRun(t, dir, "timeline", thrID, "--origin", "claude:sess_x")
+	stdout, stderr, err := bdcRun(t, dir, "classify", "We are not going with Redis", "--explain")
 	if err != nil {
-		t.Fatalf("timeline with origin filter failed: %v", err)
+		t.Fatalf("classify failed: %v\n%s", err, stderr)
+	}
+	if !strings.Contains(stdout, "hypothesis") || !strings.Contains(stdout, `negated by "not"`) {
+		t.Errorf("expected a negated decision rule, got:\n%s", stdout)
 	}
 
-	if !strings.Contains(stdout, "from claude") {
-		t.Errorf("timeline should contain 'from claude', got: %q", stdout)
+	cfg := `version: 1
+rules:
+  - name: agreement
+    type: decision
+    match: "we agreed"
+sources:
+  slack:
+    weights: {decision: 0}
+`
+	if err := os.WriteFile(filepath.Join(dir, ".beadcrumbs", "classify.yaml"), []byte(cfg), 0644); err != nil {
+		t.Fatal(err)
 	}
-	if strings.Contains(stdout, "from cursor") {
-		t.Errorf("timeline should NOT contain 'from cursor' with claude filter, got: %q", stdout)
+	stdout, _, _ = bdcRun(t, dir, "classify", "We agreed on monthly partitions", "--explain")
+	if !strings.Contains(stdout, "decision (confidence 0.67)") || !strings.Contains(stdout, "agreement") || !strings.Contains(stdout, "[config]") {
+		t.Errorf("expected the config rule to fire, got:\n%s", stdout)
 	}
-}
 
-// ============================================================================
-// Slack Error Paths (PR #6)
-// ============================================================================
+	stdout, _, _ = bdcRun(t, dir, "classify", "We agreed on monthly partitions", "--source", "slack", "--json")
+	var res struct {
+		Type     string `json:"type"`
+		Fallback bool   `json:"fallback"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &res); err != nil {
+		t.Fatalf("classify --json: %v\n%s", err, stdout)
+	}
+	if res.Type != "hypothesis" || !res.Fallback {
+		t.Errorf("slack weight 0 should drop decisions, got %+v", res)
+	}
 
-func TestCLI_SlackFetchNoAuth(t *testing.T) {
-	dir := setupTestEnv(t)
+	// Imports use the configured rules
+	path := filepath.Join(dir, "notes.txt")
+	os.WriteFile(path, []byte("We agreed on monthly partitions for the events table.\n"), 0644)
+	stdout, _, _ = bdcRun(t, dir, "import", path, "--dry-run")
+	if !strings.Contains(stdout, "[DECISION]") {
+		t.Errorf("import should classify with classify.yaml, got:\n%s", stdout)
+	}
 
-	_, stderr, err := bdcRun(t, dir, "slack", "fetch", "general")
-	if err == nil {
-		t.Error("expected error for slack fetch without auth")
+	os.WriteFile(filepath.Join(dir, ".beadcrumbs", "classify.yaml"), []byte("version: 1\nrules:\n  - type: nope\n    match: x\n"), 0644)
+	if _, _, err := bdcRun(t, dir, "classify", "anything"); err == nil {
+		t.Error("expected an invalid classify.yaml to fail")
 	}
-	combined := stderr
-	if !strings.Contains(combined, "token") && !strings.Contains(combined, "auth") && !strings.Contains(combined, "configured") {
-		t.Errorf("expected helpful auth error, got: %q", combined)
+	for _, args := range [][]string{{"list"}, {"prime"}, {"import", "--auto"}} {
+		if _, stderr, err := bdcRun(t, dir, args...); err != nil || !strings.Contains(stderr, "using the default classifier") {
+			t.Errorf("%v should warn and continue, got err=%v stderr=%q", args, err, stderr)
+		}
 	}
 }
 
-func TestCLI_ImportCSV_CustomMapping(t *testing.T) {
+func TestCLI_ClassifyTrainEval(t *testing.T) {
 	dir := setupTestEnv(t)
 
-	// Create CSV with custom column names
-	csvContent := "body,category,who\nMy insight text,hypothesis,brian\n"
-	csvPath := filepath.Join(dir, "custom.csv")
-	if err := os.WriteFile(csvPath, []byte(csvContent), 0644); err != nil {
-		t.Fatal(err)
+	if _, _, err := bdcRun(t, dir, "classify", "train"); err == nil {
+		t.Error("expected train to fail without enough captured insights")
 	}
 
-	stdout, _, err := bdcRun(t, dir, "import", "--dry-run", "--csv", "--map-content=body", "--map-type=category", "--map-author=who", csvPath)
-	if err != nil {
-		t.Fatalf("import with custom mapping failed: %v", err)
+	subjects := []string{"billing", "search", "sessions", "exports", "webhooks", "reports", "uploads", "alerts"}
+	for _, subj := range subjects {
+		for flag, content := range map[string]string{
+			"--decision":   "we will keep postgres for " + subj,
+			"--discovery":  "the " + subj + " job leaks memory under load",
+			"--hypothesis": "maybe " + subj + " retries cause the duplicate rows",
+		} {
+			if _, stderr, err := bdcRun(t, dir, "capture", flag, content); err != nil {
+				t.Fatalf("capture failed: %v\n%s", err, stderr)
+			}
+		}
 	}
 
-	if !strings.Contains(stdout, "My insight text") {
-		t.Errorf("import should show mapped content, got: %q", stdout)
+	// Imported and agent-captured insights are not training data
+	jsonlPath := filepath.Join(dir, "typed.jsonl")
+	os.WriteFile(jsonlPath, []byte(`{"content":"we will keep redis for queues","type":"decision","confidence":1}`+"\n"), 0644)
+	if _, stderr, err := bdcRun(t, dir, "import", jsonlPath); err != nil {
+		t.Fatalf("import failed: %v\n%s", err, stderr)
+	}
+	if _, stderr, err := bdcRun(t, dir, "capture", "--decision", "--author", "cc:opus-4.6", "we will keep redis for locks"); err != nil {
+		t.Fatalf("capture failed: %v\n%s", err, stderr)
 	}
-}
 
-// ============================================================================
-// Version & Upgrade (PR #15)
-// ============================================================================
+	stdout, stderr, err := bdcRun(t, dir, "classify", "train")
+	if err != nil {
+		t.Fatalf("classify train failed: %v\n%s", err, stderr)
+	}
+	if !strings.Contains(stdout, "Trained on 24 insights") {
+		t.Errorf("unexpected train output:\n%s", stdout)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".beadcrumbs", "classify-model.json")); err != nil {
+		t.Fatalf("model file not written: %v", err)
+	}
 
-func TestCLI_VersionOutput(t *testing.T) {
-	// version should work without any .beadcrumbs directory
-	dir := t.TempDir()
+	stdout, _, _ = bdcRun(t, dir, "classify", "the payments job leaks memory", "--explain")
+	if !strings.Contains(stdout, "discovery") || !strings.Contains(stdout, "[model]") {
+		t.Errorf("expected the model to type the text, got:\n%s", stdout)
+	}
 
-	stdout, _, err := bdcRun(t, dir, "version")
+	stdout, stderr, err = bdcRun(t, dir, "classify", "eval", "--test-fraction", "0.3")
 	if err != nil {
-		t.Fatalf("bdc version failed: %v", err)
+		t.Fatalf("classify eval failed: %v\n%s", err, stderr)
 	}
-
-	if !strings.Contains(stdout, "bdc version") {
-		t.Errorf("expected 'bdc version' in output, got: %q", stdout)
+	for _, want := range []string{"rules + model", "Confusion matrix", "precision"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("eval output missing %q:\n%s", want, stdout)
+		}
 	}
-	if !strings.Contains(stdout, "0.9.0") {
-		t.Errorf("expected version '0.9.0' in output, got: %q", stdout)
+
+	os.WriteFile(filepath.Join(dir, ".beadcrumbs", "classify-model.json"), []byte("{"), 0644)
+	if _, stderr, err := bdcRun(t, dir, "classify", "anything"); err != nil || !strings.Contains(stderr, "Warning") {
+		t.Errorf("a broken model should be skipped with a warning, got err=%v stderr=%q", err, stderr)
 	}
 }
 
-func TestCLI_VersionNoDBRequired(t *testing.T) {
-	// version must not require a database or git repo
-	dir := t.TempDir()
+// setGitIdentity lets tests commit without a global git config.
+func setGitIdentity(t *testing.T) {
+	t.Helper()
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+}
 
-	_, stderr, err := bdcRun(t, dir, "version")
+// gitIn runs git in dir and fails the test on error.
+func gitIn(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
 	if err != nil {
-		t.Fatalf("bdc version should succeed without .beadcrumbs, got err: %v stderr: %q", err, stderr)
+		t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, out)
 	}
+	return strings.TrimSpace(string(out))
 }
 
-func TestCLI_UpgradeNoDBRequired(t *testing.T) {
-	// upgrade must not require a database or git repo
-	dir := t.TempDir()
+func TestCLI_CodeAnchorsAndBlame(t *testing.T) {
+	setGitIdentity(t)
+	dir := setupTestEnv(t)
 
-	stdout, _, err := bdcRun(t, dir, "upgrade")
+	src := filepath.Join(dir, "src")
+	os.MkdirAll(src, 0755)
+	lines := []string{"package auth", "", "func Validate() {", "\tcheckSig()", "\tcheckExp()", "}", ""}
+	for i := 0; i < 20; i++ {
+		lines = append(lines, fmt.Sprintf("func helper%d() {}", i))
+	}
+	os.WriteFile(filepath.Join(src, "jwt.go"), []byte(strings.Join(lines, "\n")), 0644)
+	gitIn(t, dir, "add", "src")
+	gitIn(t, dir, "commit", "-q", "--no-verify", "-m", "add jwt")
+
+	stdout, stderr, err := bdcRun(t, dir, "capture", "--at", "src/jwt.go:4-5", "--discovery", "Expiry is checked after the signature")
 	if err != nil {
-		t.Fatalf("bdc upgrade should succeed without .beadcrumbs, got err: %v", err)
+		t.Fatalf("capture --at failed: %v\n%s", err, stderr)
+	}
+	insID := extractInsightID(t, stdout)
+	if !strings.Contains(stdout, "Anchor: src/jwt.go:4-5") {
+		t.Errorf("capture should echo the anchor, got: %q", stdout)
 	}
 
-	// When built locally, it should show the "could not detect" fallback
-	if !strings.Contains(stdout, "upgrade") && !strings.Contains(stdout, "npm") {
-		t.Errorf("expected upgrade instructions in output, got: %q", stdout)
+	// Add two lines above the anchor and rename the file
+	lines = append([]string{"// Package auth validates tokens.", "// Tokens are JWTs."}, lines...)
+	os.WriteFile(filepath.Join(src, "jwt.go"), []byte(strings.Join(lines, "\n")), 0644)
+	gitIn(t, dir, "mv", "src/jwt.go", "src/token.go")
+	gitIn(t, dir, "commit", "-q", "--no-verify", "-am", "document and rename")
+
+	stdout, _, err = bdcRun(t, dir, "blame", "src/token.go:6")
+	if err != nil {
+		t.Fatalf("blame failed: %v", err)
+	}
+	if !strings.Contains(stdout, insID) || !strings.Contains(stdout, "6-7") || !strings.Contains(stdout, "was src/jwt.go:4-5") {
+		t.Errorf("blame should follow the anchor to token.go:6-7, got:\n%s", stdout)
+	}
+
+	stdout, _, _ = bdcRun(t, dir, "blame", "src/token.go:1")
+	if strings.Contains(stdout, insID) {
+		t.Errorf("line 1 is outside the anchor, got:\n%s", stdout)
+	}
+
+	// Directory queries match everything underneath
+	stdout, _, _ = bdcRun(t, dir, "blame", "src", "--json")
+	var entries []map[string]interface{}
+	if err := json.Unmarshal([]byte(stdout), &entries); err != nil || len(entries) != 1 {
+		t.Fatalf("expected one JSON entry, got %q (%v)", stdout, err)
+	}
+	if entries[0]["status"] != "moved" {
+		t.Errorf("status = %v, want moved", entries[0]["status"])
+	}
+
+	// Editing the anchored lines marks the anchor as changed
+	lines[5] = "\tcheckSignature()"
+	os.WriteFile(filepath.Join(src, "token.go"), []byte(strings.Join(lines, "\n")), 0644)
+	stdout, _, _ = bdcRun(t, dir, "blame", "src/token.go")
+	if !strings.Contains(stdout, "code changed since") {
+		t.Errorf("expected changed note, got:\n%s", stdout)
 	}
 }
 
-func TestCLI_UpgradeShowsInstructions(t *testing.T) {
-	dir := t.TempDir()
+func TestCLI_GitContextAndBranchFilter(t *testing.T) {
+	setGitIdentity(t)
+	dir := setupTestEnv(t)
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a\n"), 0644)
+	gitIn(t, dir, "add", "a.txt")
+	gitIn(t, dir, "commit", "-q", "--no-verify", "-m", "init")
+	mainBranch := gitIn(t, dir, "symbolic-ref", "--short", "HEAD")
+
+	bdcRun(t, dir, "capture", "--discovery", "learned on main")
+	gitIn(t, dir, "checkout", "-q", "-b", "feature/race")
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("b\n"), 0644)
+	stdout, _, err := bdcRun(t, dir, "capture", "--discovery", "found the race")
+	if err != nil {
+		t.Fatalf("capture failed: %v", err)
+	}
+	insID := extractInsightID(t, stdout)
+	head := gitIn(t, dir, "rev-parse", "HEAD")
 
-	stdout, _, _ := bdcRun(t, dir, "upgrade")
+	stdout, _, _ = bdcRun(t, dir, "show", insID)
+	if !strings.Contains(stdout, "Git: feature/race @ "+head[:7]+" (dirty)") {
+		t.Errorf("show should print git context, got:\n%s", stdout)
+	}
 
-	// Should always mention both upgrade paths
-	if !strings.Contains(stdout, "npm") {
-		t.Errorf("upgrade output should mention npm, got: %q", stdout)
+	stdout, _, _ = bdcRun(t, dir, "list", "--branch", "feature/race")
+	if !strings.Contains(stdout, "found the race") || strings.Contains(stdout, "learned on main") {
+		t.Errorf("--branch should keep only feature insights, got:\n%s", stdout)
 	}
-	if !strings.Contains(stdout, "go install") || !strings.Contains(stdout, "beadcrumbs") {
-		t.Errorf("upgrade output should mention go install path, got: %q", stdout)
+	stdout, _, _ = bdcRun(t, dir, "timeline", "--branch", mainBranch)
+	if !strings.Contains(stdout, "learned on main") || strings.Contains(stdout, "found the race") {
+		t.Errorf("timeline --branch should keep only main insights, got:\n%s", stdout)
+	}
+	stdout, _, _ = bdcRun(t, dir, "list", "--commit", head[:8], "--json")
+	if !strings.Contains(stdout, `"worktree"`) || !strings.Contains(stdout, "found the race") {
+		t.Errorf("--commit should match by SHA prefix, got:\n%s", stdout)
+	}
+
+	// Opting out records nothing, so branch filters skip the insight
+	bdcRun(t, dir, "capture", "--no-git-context", "--discovery", "untracked thought")
+	stdout, _, _ = bdcRun(t, dir, "list", "--branch", "feature/race")
+	if strings.Contains(stdout, "untracked thought") {
+		t.Errorf("--no-git-context insight should not match a branch filter, got:\n%s", stdout)
 	}
 }
 
-func TestCLI_ShowInsightOrigin(t *testing.T) {
+func TestCLI_CommitTrailersAndWhy(t *testing.T) {
+	setGitIdentity(t)
+	// Hooks call bdc from PATH
+	t.Setenv("PATH", filepath.Dir(testBinary)+string(os.PathListSeparator)+os.Getenv("PATH"))
 	dir := setupTestEnv(t)
 
-	tOut, _, _ := bdcRun(t, dir, "thread", "new", "Show Origin Test")
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a\n"), 0644)
+	gitIn(t, dir, "add", "a.txt")
+	gitIn(t, dir, "commit", "-q", "-m", "init")
+
+	tOut, _, _ := bdcRun(t, dir, "thread", "new", "Refresh race")
 	thrID := extractThreadID(t, tOut)
+	stdout, _, _ := bdcRun(t, dir, "capture", "--thread", thrID, "--decision", "Serialize refresh with a mutex")
+	insID := extractInsightID(t, stdout)
 
-	cOut, _, _ := bdcRun(t, dir, "capture", "--thread", thrID, "--origin", "notion:page-abc", "--feedback", "from notion")
+	stdout, _, err := bdcRun(t, dir, "commit-msg", "--dry-run", "-")
+	if err != nil {
+		t.Fatalf("commit-msg --dry-run failed: %v", err)
+	}
+	if strings.TrimSpace(stdout) != "Insight-Refs: "+insID+", "+thrID {
+		t.Errorf("proposed trailer = %q", stdout)
+	}
+
+	// The prepare-commit-msg hook adds the trailer
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("b\n"), 0644)
+	gitIn(t, dir, "commit", "-q", "-am", "Fix refresh race")
+	msg := gitIn(t, dir, "log", "-1", "--format=%B")
+	if !strings.Contains(msg, "Insight-Refs: "+insID+", "+thrID) {
+		t.Fatalf("commit message should carry the trailer, got:\n%s", msg)
+	}
+
+	stdout, _, err = bdcRun(t, dir, "why")
+	if err != nil {
+		t.Fatalf("why failed: %v", err)
+	}
+	if !strings.Contains(stdout, "Fix refresh race") || !strings.Contains(stdout, "Serialize refresh with a mutex") ||
+		!strings.Contains(stdout, "Thread: Refresh race") {
+		t.Errorf("why should show the insight and thread trail, got:\n%s", stdout)
+	}
+
+	stdout, _, _ = bdcRun(t, dir, "why", "--file", "a.txt", "--json")
+	var commits []whyCommit
+	if err := json.Unmarshal([]byte(stdout), &commits); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout)
+	}
+	if len(commits) != 1 || commits[0].Subject != "Fix refresh race" || len(commits[0].Threads) != 1 {
+		t.Errorf("expected only the linked commit, got %+v", commits)
+	}
+
+	// Nothing new since the last commit: no trailer
+	stdout, _, _ = bdcRun(t, dir, "commit-msg", "--dry-run", "-")
+	if strings.TrimSpace(stdout) != "" {
+		t.Errorf("expected no proposal right after committing, got %q", stdout)
+	}
+}
+
+func TestCLI_SyncThroughBareRemote(t *testing.T) {
+	setGitIdentity(t)
+	bare := t.TempDir()
+	gitIn(t, bare, "init", "-q", "--bare")
+
+	a := setupTestEnv(t)
+	b := setupTestEnv(t)
+	gitIn(t, a, "remote", "add", "origin", bare)
+	gitIn(t, b, "remote", "add", "origin", bare)
+
+	stdout, _, _ := bdcRun(t, a, "capture", "--discovery", "Tokens expire after 15 minutes")
+	insA := extractInsightID(t, stdout)
+	stdout, stderr, err := bdcRun(t, a, "sync")
+	if err != nil {
+		t.Fatalf("sync in A failed: %v\n%s", err, stderr)
+	}
+	if !strings.Contains(stdout, "pushed 1") {
+		t.Errorf("expected one pushed record, got %q", stdout)
+	}
+	if gitIn(t, bare, "rev-parse", "refs/beadcrumbs/data") == "" {
+		t.Fatal("bare remote should have refs/beadcrumbs/data")
+	}
+
+	// B pulls A's insight and adds its own, concurrently with A
+	stdout, _, _ = bdcRun(t, b, "capture", "--decision", "Refresh tokens proactively")
+	insB := extractInsightID(t, stdout)
+	stdout, _, _ = bdcRun(t, a, "capture", "--question", "What about clock skew?")
+	insA2 := extractInsightID(t, stdout)
+
+	stdout, stderr, err = bdcRun(t, b, "sync")
+	if err != nil {
+		t.Fatalf("sync in B failed: %v\n%s", err, stderr)
+	}
+	if !strings.Contains(stdout, "pulled 1, pushed 1") {
+		t.Errorf("B should pull A's insight and push its decision, got %q", stdout)
+	}
+	stdout, stderr, err = bdcRun(t, a, "sync")
+	if err != nil {
+		t.Fatalf("second sync in A failed: %v\n%s", err, stderr)
+	}
+	if !strings.Contains(stdout, "pulled 1, pushed 1") {
+		t.Errorf("A should pull B's insight and push its question, got %q", stdout)
+	}
+	stdout, _, _ = bdcRun(t, b, "sync")
+	if !strings.Contains(stdout, "pulled 1, pushed 0") {
+		t.Errorf("B should fast-forward to A's merge, got %q", stdout)
+	}
+
+	for _, dir := range []string{a, b} {
+		for _, id := range []string{insA, insA2, insB} {
+			if _, _, err := bdcRun(t, dir, "show", id); err != nil {
+				t.Errorf("%s missing after sync: %v", id, err)
+			}
+		}
+		stdout, _, _ = bdcRun(t, dir, "sync")
+		if !strings.Contains(stdout, "pulled 0, pushed 0") {
+			t.Errorf("expected a no-op sync once converged, got %q", stdout)
+		}
+	}
+	if gitIn(t, a, "rev-parse", "refs/beadcrumbs/data") != gitIn(t, b, "rev-parse", "refs/beadcrumbs/data") {
+		t.Error("both clones should end on the same commit")
+	}
+
+	// Nothing on a branch changed
+	if out := gitIn(t, a, "status", "--porcelain", "--untracked-files=no"); out != "" {
+		t.Errorf("sync should not touch the working tree, got %q", out)
+	}
+}
+
+func TestCLI_SyncBranchMode(t *testing.T) {
+	setGitIdentity(t)
+	// Hooks call bdc from PATH
+	t.Setenv("PATH", filepath.Dir(testBinary)+string(os.PathListSeparator)+os.Getenv("PATH"))
+	bare := t.TempDir()
+	gitIn(t, bare, "init", "-q", "--bare")
+
+	a := t.TempDir()
+	gitIn(t, a, "init", "-q")
+	gitIn(t, a, "remote", "add", "origin", bare)
+	if _, stderr, err := bdcRun(t, a, "init", "--quiet", "--sync-branch", "beadcrumbs-sync"); err != nil {
+		t.Fatalf("init --sync-branch failed: %v\n%s", err, stderr)
+	}
+	stdout, _, _ := bdcRun(t, a, "stealth", "--status")
+	if strings.TrimSpace(stdout) != "sync-branch" {
+		t.Errorf("status = %q, want sync-branch", stdout)
+	}
+
+	// A commit on the project branch exports to the sync branch, not the commit
+	stdout, _, _ = bdcRun(t, a, "capture", "--decision", "Use a sync branch")
+	insA := extractInsightID(t, stdout)
+	os.WriteFile(filepath.Join(a, "main.go"), []byte("package main\n"), 0644)
+	gitIn(t, a, "add", "main.go")
+	gitIn(t, a, "commit", "-q", "-m", "Add main")
+	if files := gitIn(t, a, "ls-tree", "-r", "--name-only", "HEAD"); files != "main.go" {
+		t.Errorf("project commit should only contain main.go, got %q", files)
+	}
+	if data := gitIn(t, a, "show", "beadcrumbs-sync:insights.jsonl"); !strings.Contains(data, insA) {
+		t.Errorf("sync branch should hold %s, got %q", insA, data)
+	}
+
+	stdout, stderr, err := bdcRun(t, a, "sync")
+	if err != nil {
+		t.Fatalf("sync failed: %v\n%s", err, stderr)
+	}
+	if !strings.Contains(stdout, "Synced beadcrumbs-sync with origin: pulled 0, pushed 1") {
+		t.Errorf("unexpected sync output %q", stdout)
+	}
+
+	// A second clone imports the branch on init and syncs back
+	b := t.TempDir()
+	gitIn(t, b, "init", "-q")
+	gitIn(t, b, "remote", "add", "origin", bare)
+	gitIn(t, b, "fetch", "-q", "origin")
+	if _, stderr, err := bdcRun(t, b, "init", "--quiet", "--sync-branch", "beadcrumbs-sync"); err != nil {
+		t.Fatalf("init in B failed: %v\n%s", err, stderr)
+	}
+	if _, _, err := bdcRun(t, b, "show", insA); err != nil {
+		t.Fatalf("B should have imported %s: %v", insA, err)
+	}
+	stdout, _, _ = bdcRun(t, b, "capture", "--discovery", "Merges are by ID")
+	insB := extractInsightID(t, stdout)
+	stdout, _, _ = bdcRun(t, a, "capture", "--question", "Who prunes old insights?")
+	insA2 := extractInsightID(t, stdout)
+	if _, stderr, err := bdcRun(t, b, "sync"); err != nil {
+		t.Fatalf("sync in B failed: %v\n%s", err, stderr)
+	}
+	stdout, stderr, err = bdcRun(t, a, "sync")
+	if err != nil {
+		t.Fatalf("second sync in A failed: %v\n%s", err, stderr)
+	}
+	if !strings.Contains(stdout, "pulled 1, pushed 1") {
+		t.Errorf("A should merge B's insight and push its own, got %q", stdout)
+	}
+	if _, _, err := bdcRun(t, a, "show", insB); err != nil {
+		t.Errorf("A should have %s after sync: %v", insB, err)
+	}
+	if _, _, err := bdcRun(t, b, "sync"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := bdcRun(t, b, "show", insA2); err != nil {
+		t.Errorf("B should have %s after sync: %v", insA2, err)
+	}
+
+	// unstealth switches to normal mode and its hooks
+	if _, stderr, err := bdcRun(t, a, "unstealth"); err != nil {
+		t.Fatalf("unstealth failed: %v\n%s", err, stderr)
+	}
+	stdout, _, _ = bdcRun(t, a, "stealth", "--status")
+	if strings.TrimSpace(stdout) != "normal" {
+		t.Errorf("status after unstealth = %q, want normal", stdout)
+	}
+	hook, _ := os.ReadFile(filepath.Join(a, ".git", "hooks", "post-commit"))
+	if !strings.Contains(string(hook), "bdc export") || strings.Contains(string(hook), "bdc sync") {
+		t.Errorf("post-commit should be the normal hook, got:\n%s", hook)
+	}
+	if _, err := os.Stat(filepath.Join(a, ".git", "beadcrumbs-worktrees", "beadcrumbs-sync")); !os.IsNotExist(err) {
+		t.Error("sync worktree should be removed after unstealth")
+	}
+}
+
+func TestCLI_BranchThreads(t *testing.T) {
+	setGitIdentity(t)
+	dir := setupTestEnv(t)
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a\n"), 0644)
+	gitIn(t, dir, "add", "a.txt")
+	gitIn(t, dir, "commit", "-q", "-m", "init")
+	gitIn(t, dir, "branch", "-M", "main")
+
+	// Off by default
+	gitIn(t, dir, "checkout", "-q", "-b", "feature/refresh")
+	stdout, _, _ := bdcRun(t, dir, "capture", "--discovery", "No thread yet")
+	if strings.Contains(stdout, "Thread:") {
+		t.Errorf("branch threads should be opt-in, got %q", stdout)
+	}
+
+	if _, _, err := bdcRun(t, dir, "thread", "config", "auto_brnach", "true"); err == nil {
+		t.Error("thread config should reject unknown keys")
+	}
+	if _, _, err := bdcRun(t, dir, "thread", "config", "auto_branch", "yes"); err == nil {
+		t.Error("thread config should reject non-boolean auto_branch values")
+	}
+	if _, _, err := bdcRun(t, dir, "thread", "config", "auto_branch", "1"); err != nil {
+		t.Fatalf("thread config failed: %v", err)
+	}
+	stdout, _, _ = bdcRun(t, dir, "capture", "--hypothesis", "Refresh races the request")
+	thrID := extractThreadID(t, stdout)
+	if !strings.Contains(stdout, "linked to Branch: feature/refresh") {
+		t.Errorf("expected a new branch thread, got %q", stdout)
+	}
+	stdout, _, _ = bdcRun(t, dir, "capture", "--decision", "Serialize refreshes")
+	if extractThreadID(t, stdout) != thrID {
+		t.Errorf("second capture should reuse %s, got %q", thrID, stdout)
+	}
+	stdout, _, _ = bdcRun(t, dir, "capture", "--thread", "thr-none", "--discovery", "Explicit thread wins")
+	if strings.Contains(stdout, thrID) {
+		t.Errorf("--thread should override the branch thread, got %q", stdout)
+	}
+	stdout, _, _ = bdcRun(t, dir, "thread", "show", thrID)
+	if !strings.Contains(stdout, "feature/refresh") {
+		t.Errorf("thread should be titled after the branch, got:\n%s", stdout)
+	}
+
+	// The default branch never gets a thread
+	gitIn(t, dir, "checkout", "-q", "main")
+	stdout, _, _ = bdcRun(t, dir, "capture", "--discovery", "On main")
+	if strings.Contains(stdout, "Thread:") {
+		t.Errorf("main should not get a branch thread, got %q", stdout)
+	}
+
+	// Unmerged branches stay open; merged ones are concluded
+	stdout, _, _ = bdcRun(t, dir, "thread", "close", "--branch")
+	if !strings.Contains(stdout, "No branch threads to close") {
+		t.Errorf("unmerged branch should stay open, got %q", stdout)
+	}
+	gitIn(t, dir, "checkout", "-q", "feature/refresh")
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("b\n"), 0644)
+	gitIn(t, dir, "commit", "-q", "-am", "Fix refresh")
+	gitIn(t, dir, "checkout", "-q", "main")
+	stdout, _, _ = bdcRun(t, dir, "thread", "close", "--branch")
+	if !strings.Contains(stdout, "No branch threads to close") {
+		t.Errorf("branch with unmerged commits should stay open, got %q", stdout)
+	}
+	gitIn(t, dir, "merge", "-q", "feature/refresh")
+	stdout, _, err := bdcRun(t, dir, "thread", "close", "--branch")
+	if err != nil {
+		t.Fatalf("thread close --branch failed: %v", err)
+	}
+	if !strings.Contains(stdout, "Thread "+thrID+" closed with status: concluded (branch feature/refresh merged)") {
+		t.Errorf("merged branch thread should be concluded, got %q", stdout)
+	}
+
+	// Deleted branches are concluded too
+	gitIn(t, dir, "checkout", "-q", "-b", "experiment")
+	stdout, _, _ = bdcRun(t, dir, "capture", "--hypothesis", "Maybe caching helps")
+	expID := extractThreadID(t, stdout)
+	gitIn(t, dir, "checkout", "-q", "main")
+	gitIn(t, dir, "branch", "-D", "experiment")
+	stdout, _, _ = bdcRun(t, dir, "thread", "close", "--branch")
+	if !strings.Contains(stdout, "Thread "+expID+" closed with status: concluded (branch experiment deleted)") {
+		t.Errorf("deleted branch thread should be concluded, got %q", stdout)
+	}
+
+	// Closing by name
+	gitIn(t, dir, "checkout", "-q", "-b", "spike")
+	stdout, _, _ = bdcRun(t, dir, "capture", "--question", "Is the spike worth it?")
+	spikeID := extractThreadID(t, stdout)
+	stdout, _, _ = bdcRun(t, dir, "thread", "close", "--branch", "spike", "--status", "abandoned")
+	if !strings.Contains(stdout, "Thread "+spikeID+" closed with status: abandoned") {
+		t.Errorf("expected spike thread abandoned, got %q", stdout)
+	}
+	if _, _, err := bdcRun(t, dir, "thread", "close", "--branch", "nope"); err == nil {
+		t.Error("closing an unknown branch should fail")
+	}
+
+	// Rebase merges bring the branch's patches in under new hashes
+	gitIn(t, dir, "checkout", "-q", "main")
+	gitIn(t, dir, "checkout", "-q", "-b", "rebased")
+	stdout, _, _ = bdcRun(t, dir, "capture", "--decision", "Retry on 503")
+	rebasedID := extractThreadID(t, stdout)
+	os.WriteFile(filepath.Join(dir, "b.txt"), []byte("retry\n"), 0644)
+	gitIn(t, dir, "add", "b.txt")
+	gitIn(t, dir, "commit", "-q", "-m", "Retry on 503")
+	gitIn(t, dir, "checkout", "-q", "main")
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("c\n"), 0644)
+	gitIn(t, dir, "commit", "-q", "-am", "Unrelated")
+	gitIn(t, dir, "cherry-pick", "rebased")
+	stdout, _, _ = bdcRun(t, dir, "thread", "close", "--branch")
+	if !strings.Contains(stdout, "Thread "+rebasedID+" closed with status: concluded (branch rebased merged)") {
+		t.Errorf("rebase-merged branch thread should be concluded, got %q", stdout)
+	}
+
+	// Squash merges leave nothing to compare, but the remote branch is deleted
+	remote := t.TempDir()
+	gitIn(t, remote, "init", "-q", "--bare")
+	gitIn(t, dir, "remote", "add", "origin", remote)
+	gitIn(t, dir, "checkout", "-q", "-b", "squashed")
+	stdout, _, _ = bdcRun(t, dir, "capture", "--decision", "Batch the writes")
+	squashedID := extractThreadID(t, stdout)
+	os.WriteFile(filepath.Join(dir, "c.txt"), []byte("batch\n"), 0644)
+	gitIn(t, dir, "add", "c.txt")
+	gitIn(t, dir, "commit", "-q", "-m", "Batch the writes")
+	gitIn(t, dir, "push", "-q", "-u", "origin", "squashed")
+	gitIn(t, dir, "checkout", "-q", "main")
+	stdout, _, _ = bdcRun(t, dir, "thread", "close", "--branch")
+	if strings.Contains(stdout, squashedID) {
+		t.Errorf("branch with a live upstream should stay open, got %q", stdout)
+	}
+	gitIn(t, dir, "push", "-q", "origin", "--delete", "squashed")
+	gitIn(t, dir, "fetch", "-q", "--prune", "origin")
+	stdout, _, _ = bdcRun(t, dir, "thread", "close", "--branch")
+	if !strings.Contains(stdout, "Thread "+squashedID+" closed with status: concluded (branch squashed upstream gone)") {
+		t.Errorf("branch whose upstream is gone should be concluded, got %q", stdout)
+	}
+}
+
+func TestCLI_ContextForFiles(t *testing.T) {
+	setGitIdentity(t)
+	dir := setupTestEnv(t)
+	os.MkdirAll(filepath.Join(dir, "internal", "auth"), 0755)
+	var body strings.Builder
+	for i := 1; i <= 30; i++ {
+		fmt.Fprintf(&body, "line %d\n", i)
+	}
+	os.WriteFile(filepath.Join(dir, "internal", "auth", "jwt.go"), []byte(body.String()), 0644)
+	os.WriteFile(filepath.Join(dir, "other.go"), []byte("package other\n"), 0644)
+	gitIn(t, dir, "add", ".")
+	gitIn(t, dir, "commit", "-q", "-m", "init")
+	os.WriteFile(filepath.Join(dir, ".beadcrumbs", "context.yaml"),
+		[]byte("version: 1\nlabels:\n  auth: [\"internal/auth/**\"]\n"), 0644)
+
+	stdout, _, _ := bdcRun(t, dir, "capture", "--decision", "--at", "internal/auth/jwt.go:10-12", "Validate exp before signature")
+	anchored := extractInsightID(t, stdout)
+	stdout, _, _ = bdcRun(t, dir, "capture", "--question", "--label", "auth", "Should refresh tokens rotate?")
+	labeled := extractInsightID(t, stdout)
+	bdcRun(t, dir, "capture", "--discovery", "--at", "internal/auth/jwt.go:1", "Discoveries are not injected")
+	stdout, _, _ = bdcRun(t, dir, "capture", "--question", "--label", "auth", "Old question")
+	old := extractInsightID(t, stdout)
+	stdout, _, _ = bdcRun(t, dir, "capture", "--decision", "--label", "auth", "Answer to the old question")
+	answer := extractInsightID(t, stdout)
+	bdcRun(t, dir, "link", answer, "--supersedes", old)
+
+	stdout, stderr, err := bdcRun(t, dir, "context", "--files", "internal/auth/jwt.go")
+	if err != nil {
+		t.Fatalf("context failed: %v\n%s", err, stderr)
+	}
+	for _, want := range []string{
+		"DECISION " + anchored + ": Validate exp before signature (anchor internal/auth/jwt.go:10-12)",
+		"QUESTION " + labeled + ": Should refresh tokens rotate? (label auth)",
+		"DECISION " + answer,
+	} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("context should contain %q, got:\n%s", want, stdout)
+		}
+	}
+	if strings.Contains(stdout, "Discoveries are not injected") || strings.Contains(stdout, old) {
+		t.Errorf("context should skip discoveries and superseded questions, got:\n%s", stdout)
+	}
+	if strings.Index(stdout, anchored) > strings.Index(stdout, labeled) {
+		t.Errorf("anchored insight should rank first, got:\n%s", stdout)
+	}
+
+	// Unrelated files: nothing
+	stdout, _, _ = bdcRun(t, dir, "context", "--files", "other.go")
+	if stdout != "" {
+		t.Errorf("expected no output for an unrelated file, got %q", stdout)
+	}
+
+	// A tight budget keeps the best match and reports the rest
+	stdout, _, _ = bdcRun(t, dir, "context", "--files", "internal/auth/jwt.go", "--budget", "90")
+	if !strings.Contains(stdout, anchored) || !strings.Contains(stdout, "2 more omitted to fit the 90-token budget") {
+		t.Errorf("budget should trim to the anchored decision, got:\n%s", stdout)
+	}
+
+	// Hook mode answers once per session
+	event := `{"session_id":"sess-1","tool_name":"Edit","tool_input":{"file_path":"` +
+		filepath.ToSlash(filepath.Join(dir, "internal", "auth", "jwt.go")) + `"}}`
+	t.Cleanup(func() { os.Remove(filepath.Join(os.TempDir(), "bdc-context-sess-1")) })
+	runHook := func() string {
+		cmd := exec.Command(testBinary, "context", "--hook")
+		cmd.Dir = dir
+		cmd.Stdin = strings.NewReader(event)
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("context --hook failed: %v", err)
+		}
+		return string(out)
+	}
+	var resp struct {
+		HookSpecificOutput struct {
+			HookEventName     string `json:"hookEventName"`
+			AdditionalContext string `json:"additionalContext"`
+		} `json:"hookSpecificOutput"`
+	}
+	if err := json.Unmarshal([]byte(runHook()), &resp); err != nil {
+		t.Fatalf("hook output is not JSON: %v", err)
+	}
+	if resp.HookSpecificOutput.HookEventName != "PreToolUse" || !strings.Contains(resp.HookSpecificOutput.AdditionalContext, anchored) {
+		t.Errorf("unexpected hook output: %+v", resp)
+	}
+	if out := runHook(); out != "" {
+		t.Errorf("second edit in the same session should add nothing, got %q", out)
+	}
+}
+
+func TestCLI_SetupEditors(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := setupTestEnv(t)
+	os.WriteFile(filepath.Join(dir, "AGENTS.md"), []byte("# Agents\n"), 0644)
+
+	if _, _, err := bdcRun(t, dir, "setup", "--check"); err == nil {
+		t.Error("setup --check should fail with nothing installed")
+	}
+
+	for _, editor := range []string{"codex", "cursor", "aider", "windsurf", "gemini"} {
+		if _, stderr, err := bdcRun(t, dir, "setup", editor, "--project"); err != nil {
+			t.Fatalf("setup %s failed: %v\n%s", editor, err, stderr)
+		}
+	}
+	stdout, _, _ := bdcRun(t, dir, "setup", "codex", "--project")
+	if !strings.Contains(stdout, "Already up to date") {
+		t.Errorf("reinstalling should be a no-op, got: %s", stdout)
+	}
+	agents, _ := os.ReadFile(filepath.Join(dir, "AGENTS.md"))
+	if strings.Count(string(agents), setupBlockBegin) != 1 || !strings.HasPrefix(string(agents), "# Agents\n") ||
+		!strings.Contains(string(agents), "--author codex:<model>") {
+		t.Errorf("unexpected AGENTS.md:\n%s", agents)
+	}
+	for _, f := range []string{".cursor/rules/beadcrumbs.mdc", ".windsurf/rules/beadcrumbs.md", "GEMINI.md", "CONVENTIONS.md"} {
+		if _, err := os.Stat(filepath.Join(dir, f)); err != nil {
+			t.Errorf("expected %s: %v", f, err)
+		}
+	}
+	conf, _ := os.ReadFile(filepath.Join(dir, ".aider.conf.yml"))
+	if !strings.Contains(string(conf), "CONVENTIONS.md") {
+		t.Errorf(".aider.conf.yml should read CONVENTIONS.md, got:\n%s", conf)
+	}
+
+	if stdout, _, err := bdcRun(t, dir, "setup", "--check"); err != nil {
+		t.Errorf("setup --check failed: %v\n%s", err, stdout)
+	}
+
+	// A hand-edited block is reported as outdated
+	edited := strings.Replace(string(agents), "bdc prime", "bdc prime --old", 1)
+	os.WriteFile(filepath.Join(dir, "AGENTS.md"), []byte(edited), 0644)
+	if stdout, _, err := bdcRun(t, dir, "setup", "--check"); err == nil || !strings.Contains(stdout, "outdated") {
+		t.Errorf("setup --check should flag the outdated codex block, got err=%v:\n%s", err, stdout)
+	}
+
+	bdcRun(t, dir, "setup", "codex", "--project", "--remove")
+	agents, _ = os.ReadFile(filepath.Join(dir, "AGENTS.md"))
+	if string(agents) != "# Agents\n" {
+		t.Errorf("remove should restore AGENTS.md, got %q", agents)
+	}
+	bdcRun(t, dir, "setup", "cursor", "--remove")
+	if _, err := os.Stat(filepath.Join(dir, ".cursor/rules/beadcrumbs.mdc")); !os.IsNotExist(err) {
+		t.Error("cursor rules file should be removed")
+	}
+	bdcRun(t, dir, "setup", "aider", "--project", "--remove")
+	if _, err := os.Stat(filepath.Join(dir, ".aider.conf.yml")); !os.IsNotExist(err) {
+		t.Error(".aider.conf.yml created by setup should be removed")
+	}
+
+	if _, _, err := bdcRun(t, dir, "setup", "vim"); err == nil {
+		t.Error("setup should reject unknown editors")
+	}
+}
+
+func TestCLI_IngestSession(t *testing.T) {
+	dir := setupTestEnv(t)
+	stdout, _, _ := bdcRun(t, dir, "thread", "new", "Token expiry")
+	thrID := extractThreadID(t, stdout)
+	bdcRun(t, dir, "capture", "--thread", thrID, "--origin", "claude:sess-9", "--decision",
+		"Normalize token expiry checks to seconds everywhere", "--author", "cc:sonnet")
+
+	now := time.Now().UTC()
+	line := func(offset time.Duration, role, text string) string {
+		return claudeTranscriptLine("sess-9", now, offset, role, text)
+	}
+	transcript := filepath.Join(dir, "sess-9.jsonl")
+	os.WriteFile(transcript, []byte(
+		line(-2*time.Minute, "user", "Why do tokens expire early?")+
+			line(-time.Minute, "assistant", "Found: the validator compares seconds to milliseconds.\n\n"+
+				"Decision: normalize token expiry checks to seconds everywhere.\n\nRunning the tests now.")), 0644)
+
+	stdout, stderr, err := bdcRun(t, dir, "ingest-session", transcript, "--dry-run")
+	if err != nil {
+		t.Fatalf("ingest-session --dry-run failed: %v\n%s", err, stderr)
+	}
+	if !strings.Contains(stdout, "2 new insights, 1 already recorded") {
+		t.Errorf("dry run should find 2 new insights and skip the captured decision, got:\n%s", stdout)
+	}
+
+	// Hook mode reads the transcript path from stdin
+	cmd := exec.Command(testBinary, "ingest-session", "--hook")
+	cmd.Dir = dir
+	cmd.Stdin = strings.NewReader(`{"session_id":"sess-9","hook_event_name":"Stop","transcript_path":"` + filepath.ToSlash(transcript) + `"}`)
+	if out, err := cmd.CombinedOutput(); err != nil || len(out) != 0 {
+		t.Fatalf("ingest-session --hook: err=%v output=%q", err, out)
+	}
+
+	stdout, _, _ = bdcRun(t, dir, "list", "--origin", "claude:sess-9", "--json")
+	var insights []types.Insight
+	if err := json.Unmarshal([]byte(stdout), &insights); err != nil {
+		t.Fatalf("list --json: %v", err)
+	}
+	if len(insights) != 3 {
+		t.Fatalf("expected the captured decision plus 2 ingested insights, got %d", len(insights))
+	}
+	for _, ins := range insights {
+		if ins.ThreadID != thrID {
+			t.Errorf("%s should join the session's thread %s, got %q", ins.ID, thrID, ins.ThreadID)
+		}
+		if ins.Type == types.InsightDiscovery && (ins.AuthorID != "claude-sonnet-4-5" || ins.Timestamp.Sub(now) > -time.Minute+time.Second) {
+			t.Errorf("discovery should keep the model and message time, got %+v", ins)
+		}
+	}
+
+	// Running again after the next turn adds nothing
+	stdout, _, _ = bdcRun(t, dir, "ingest-session", transcript)
+	if !strings.Contains(stdout, "Ingested 0 insights") {
+		t.Errorf("re-ingesting should add nothing, got:\n%s", stdout)
+	}
+
+	// The hook reads only messages added since its last run: the paragraph
+	// added to an earlier message is not seen, the new message is
+	os.WriteFile(transcript, []byte(
+		line(-2*time.Minute, "user", "Why do tokens expire early?")+
+			line(-time.Minute, "assistant", "Found: the validator compares seconds to milliseconds.\n\n"+
+				"Decision: normalize token expiry checks to seconds everywhere.\n\nRunning the tests now.\n\n"+
+				"Decision: hash refresh tokens before storing them.")+
+			line(0, "assistant", "Decision: rotate the signing keys every week.")), 0644)
+	cmd = exec.Command(testBinary, "ingest-session", "--hook")
+	cmd.Dir = dir
+	cmd.Stdin = strings.NewReader(`{"session_id":"sess-9","hook_event_name":"Stop","transcript_path":"` + filepath.ToSlash(transcript) + `"}`)
+	if out, err := cmd.CombinedOutput(); err != nil || len(out) != 0 {
+		t.Fatalf("ingest-session --hook: err=%v output=%q", err, out)
+	}
+	stdout, _, _ = bdcRun(t, dir, "list", "--origin", "claude:sess-9")
+	if !strings.Contains(stdout, "signing keys") || strings.Contains(stdout, "refresh tokens") {
+		t.Errorf("the hook should ingest only the new message, got:\n%s", stdout)
+	}
+
+	// setup claude --ingest registers the transcript hooks
+	t.Setenv("HOME", t.TempDir())
+	bdcRun(t, dir, "setup", "claude", "--ingest")
+	home, _ := os.UserHomeDir()
+	for _, event := range []string{"Stop", "SessionEnd", "PreCompact"} {
+		if !settingsHasHook(filepath.Join(home, ".claude", "settings.json"), []string{event}, ingestHookCommand) {
+			t.Errorf("setup claude --ingest should register the %s hook", event)
+		}
+	}
+}
+
+// A hook run late in a session still sees what the agent captured in its
+// first turn, so a restatement is skipped and joins the same thread.
+func TestCLI_IngestSession_HookRestatement(t *testing.T) {
+	dir := setupTestEnv(t)
+	stdout, _, _ := bdcRun(t, dir, "thread", "new", "Token expiry")
+	thrID := extractThreadID(t, stdout)
+
+	now := time.Now().UTC()
+	line := func(offset time.Duration, role, text string) string {
+		return claudeTranscriptLine("sess-7", now, offset, role, text)
+	}
+	transcript := filepath.Join(dir, "sess-7.jsonl")
+	hook := func() {
+		t.Helper()
+		cmd := exec.Command(testBinary, "ingest-session", "--hook")
+		cmd.Dir = dir
+		cmd.Stdin = strings.NewReader(`{"session_id":"sess-7","hook_event_name":"Stop","transcript_path":"` + filepath.ToSlash(transcript) + `"}`)
+		if out, err := cmd.CombinedOutput(); err != nil || len(out) != 0 {
+			t.Fatalf("ingest-session --hook: err=%v output=%q", err, out)
+		}
+	}
+
+	// Turn 1: the agent captures the decision itself, with no origin
+	turn1 := line(-2*time.Minute, "user", "Why do tokens expire early?") +
+		line(-time.Minute, "assistant", "Looking at the validator.")
+	os.WriteFile(transcript, []byte(turn1), 0644)
+	bdcRun(t, dir, "capture", "--thread", thrID, "--decision", "Normalize token expiry checks to seconds everywhere")
+	hook()
+
+	// Turn 2, minutes later, restates it
+	os.WriteFile(transcript, []byte(turn1+
+		line(5*time.Minute, "user", "Anything else?")+
+		line(6*time.Minute, "assistant", "Decision: normalize token expiry checks to seconds everywhere.\n\n"+
+			"Decision: rotate the signing keys every week.")), 0644)
+	hook()
+
+	stdout, _, _ = bdcRun(t, dir, "list", "--json")
+	var insights []types.Insight
+	if err := json.Unmarshal([]byte(stdout), &insights); err != nil {
+		t.Fatalf("list --json: %v", err)
+	}
+	expiry, signing := 0, 0
+	for _, ins := range insights {
+		if strings.Contains(strings.ToLower(ins.Content), "token expiry") {
+			expiry++
+		}
+		if strings.Contains(ins.Content, "signing keys") {
+			signing++
+			if ins.ThreadID != thrID {
+				t.Errorf("the new decision should join the session's thread %s, got %q", thrID, ins.ThreadID)
+			}
+		}
+	}
+	if expiry != 1 || signing != 1 {
+		t.Errorf("expected the captured decision once and the new one ingested, got:\n%s", stdout)
+	}
+}
+
+// claudeTranscriptLine returns a Claude Code transcript line for a message
+// of session sent offset after now.
+func claudeTranscriptLine(session string, now time.Time, offset time.Duration, role, text string) string {
+	msg := map[string]interface{}{
+		"type": role, "sessionId": session, "uuid": fmt.Sprint(offset),
+		"timestamp": now.Add(offset).Format(time.RFC3339Nano),
+		"message": map[string]interface{}{
+			"role": role, "model": "claude-sonnet-4-5",
+			"content": []map[string]string{{"type": "text", "text": text}},
+		},
+	}
+	data, _ := json.Marshal(msg)
+	return string(data) + "\n"
+}
+
+// ============================================================================
+// Import Operations (PR #6)
+// ============================================================================
+
+func TestCLI_ImportCSV_DryRun(t *testing.T) {
+	dir := setupTestEnv(t)
+
+	// Create CSV file
+	csvContent := "content,type\nTest CSV insight,hypothesis\nAnother insight,decision\n"
+	csvPath := filepath.Join(dir, "test.csv")
+	if err := os.WriteFile(csvPath, []byte(csvContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, _, err := bdcRun(t, dir, "import", "--dry-run", csvPath)
+	if err != nil {
+		t.Fatalf("import --dry-run failed: %v", err)
+	}
+
+	if !strings.Contains(stdout, "Test CSV insight") && !strings.Contains(stdout, "hypothesis") {
+		t.Errorf("dry-run output should show preview, got: %q", stdout)
+	}
+}
+
+func TestCLI_ImportJSONL_DryRun(t *testing.T) {
+	dir := setupTestEnv(t)
+
+	// Create JSONL file
+	jsonlContent := `{"content":"JSONL test insight","type":"hypothesis"}
+{"content":"Another JSONL insight","type":"decision"}
+`
+	jsonlPath := filepath.Join(dir, "test.jsonl")
+	if err := os.WriteFile(jsonlPath, []byte(jsonlContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, _, err := bdcRun(t, dir, "import", "--dry-run", jsonlPath)
+	if err != nil {
+		t.Fatalf("import --dry-run JSONL failed: %v", err)
+	}
+
+	if !strings.Contains(stdout, "JSONL test insight") {
+		t.Errorf("dry-run output should show preview, got: %q", stdout)
+	}
+}
+
+func TestCLI_ImportClaudeTranscript(t *testing.T) {
+	dir := setupTestEnv(t)
+
+	transcript := `{"type":"summary","summary":"Cache work","leafUuid":"x"}
+{"type":"user","sessionId":"sess-42","uuid":"u1","timestamp":"2025-03-04T09:00:00Z","message":{"role":"user","content":"Should the cache be per tenant?"}}
+{"type":"assistant","sessionId":"sess-42","uuid":"a1","timestamp":"2025-03-04T09:05:00Z","message":{"role":"assistant","model":"claude-opus-4-1","content":[{"type":"text","text":"Decision: we'll use one cache per tenant."},{"type":"tool_use","id":"t","name":"Bash","input":{"command":"go test"}}]}}
+{"type":"user","sessionId":"sess-42","uuid":"u2","timestamp":"2025-03-04T09:06:00Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t","content":"ok  all tests passed here"}]}}
+`
+	path := filepath.Join(dir, "sess-42.jsonl")
+	if err := os.WriteFile(path, []byte(transcript), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Detected from content even though the extension is plain .jsonl
+	stdout, stderr, err := bdcRun(t, dir, "import", path)
+	if err != nil {
+		t.Fatalf("import failed: %v\n%s", err, stderr)
+	}
+	if !strings.Contains(stdout, "Extracted 2 insights") || strings.Contains(stdout, "all tests passed") {
+		t.Errorf("expected the question and decision only, got:\n%s", stdout)
+	}
+
+	stdout, _, _ = bdcRun(t, dir, "list", "--origin", "claude:sess-42", "--json")
+	var insights []types.Insight
+	if err := json.Unmarshal([]byte(stdout), &insights); err != nil {
+		t.Fatalf("list --json: %v\n%s", err, stdout)
+	}
+	if len(insights) != 2 {
+		t.Fatalf("expected 2 insights with origin claude:sess-42, got %d", len(insights))
+	}
+	for _, ins := range insights {
+		switch ins.Type {
+		case types.InsightDecision:
+			if ins.AuthorID != "claude-opus-4-1" || !ins.Timestamp.Equal(time.Date(2025, 3, 4, 9, 5, 0, 0, time.UTC)) {
+				t.Errorf("decision should carry the model and message time, got %s at %v", ins.AuthorID, ins.Timestamp)
+			}
+		case types.InsightQuestion:
+			if !ins.Timestamp.Equal(time.Date(2025, 3, 4, 9, 0, 0, 0, time.UTC)) {
+				t.Errorf("question should carry its message time, got %v", ins.Timestamp)
+			}
+		default:
+			t.Errorf("unexpected insight %s: %s", ins.Type, ins.Content)
+		}
+	}
+}
+
+func TestCLI_ImportAgentTranscripts(t *testing.T) {
+	dir := setupTestEnv(t)
+
+	aider := `# aider chat started at 2024-05-01 10:22:33
+
+> Main model: gpt-4o with diff edit format
+
+#### Should the retry queue be per worker?
+
+Decision: we'll keep one shared retry queue.
+`
+	aiderPath := filepath.Join(dir, ".aider.chat.history.md")
+	if err := os.WriteFile(aiderPath, []byte(aider), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stdout, stderr, err := bdcRun(t, dir, "import", aiderPath, "--dry-run")
+	if err != nil {
+		t.Fatalf("import failed: %v\n%s", err, stderr)
+	}
+	if !strings.Contains(stdout, "Extracted 2 insights") {
+		t.Errorf("expected aider history to be detected, got:\n%s", stdout)
+	}
+
+	chatgpt := `[{"conversation_id":"conv-7","current_node":"b","mapping":{
+  "a":{"parent":null,"message":{"author":{"role":"user"},"create_time":1714550010,"content":{"content_type":"text","parts":["Should we shard the events table?"]},"metadata":{}}},
+  "b":{"parent":"a","message":{"author":{"role":"assistant"},"create_time":1714550020,"content":{"content_type":"text","parts":["Decision: going with monthly partitions instead."]},"metadata":{"model_slug":"gpt-4o"}}}}}]`
+	// Named so that only --format identifies it
+	chatPath := filepath.Join(dir, "export.data")
+	if err := os.WriteFile(chatPath, []byte(chatgpt), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, stderr, err := bdcRun(t, dir, "import", chatPath, "--format", "chatgpt", "--quiet"); err != nil {
+		t.Fatalf("import --format chatgpt failed: %v\n%s", err, stderr)
+	}
+	stdout, _, _ = bdcRun(t, dir, "list", "--origin", "chatgpt:conv-7", "--json")
+	var insights []types.Insight
+	if err := json.Unmarshal([]byte(stdout), &insights); err != nil {
+		t.Fatalf("list --json: %v\n%s", err, stdout)
+	}
+	if len(insights) != 2 {
+		t.Fatalf("expected 2 insights with origin chatgpt:conv-7, got %d", len(insights))
+	}
+	for _, ins := range insights {
+		if ins.Type == types.InsightDecision && (ins.AuthorID != "gpt-4o" || !ins.Timestamp.Equal(time.Unix(1714550020, 0))) {
+			t.Errorf("decision should carry the model and message time, got %s at %v", ins.AuthorID, ins.Timestamp)
+		}
+	}
+
+	if _, _, err := bdcRun(t, dir, "import", chatPath, "--format", "bogus"); err == nil {
+		t.Error("expected an error for an unknown --format")
+	}
+}
+
+func TestCLI_ImportExternalFormats(t *testing.T) {
+	dir := setupTestEnv(t)
+
+	script := filepath.Join(dir, "tools", "tsv-import")
+	os.MkdirAll(filepath.Dir(script), 0755)
+	body := "#!/bin/sh\n" +
+		"# Reads \"type<TAB>content\" lines\n" +
+		"while IFS='\t' read -r kind text; do\n" +
+		"  printf '{\"type\":\"%s\",\"content\":\"%s\",\"source_ref\":\"%s\"}\\n' \"$kind\" \"$text\" \"$1\"\n" +
+		"done\n"
+	if err := os.WriteFile(script, []byte(body), 0755); err != nil {
+		t.Fatal(err)
+	}
+	cfg := `version: 1
+importers:
+  - name: tsv
+    description: Tab-separated notes
+    command: ./tools/tsv-import
+    extensions: [".tsv"]
+    options:
+      - name: ref
+        description: reference to record
+`
+	if err := os.WriteFile(filepath.Join(dir, ".beadcrumbs", "importers.yaml"), []byte(cfg), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, stderr, err := bdcRun(t, dir, "import", "--list-formats")
+	if err != nil {
+		t.Fatalf("--list-formats failed: %v\n%s", err, stderr)
+	}
+	for _, want := range []string{"ai-session", "chatgpt", "map-content", "tsv", "Tab-separated notes", "--option ref="} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("--list-formats should mention %q, got:\n%s", want, stdout)
+		}
+	}
+
+	data := "decision\tWe ship the TSV importer\nquestion\tDoes it handle quoting?\n"
+	path := filepath.Join(dir, "notes.tsv")
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// The repository's importers only claim files once trusted locally
+	if stdout, _, _ := bdcRun(t, dir, "import", path, "--dry-run"); strings.Contains(stdout, "tsv") {
+		t.Fatalf("untrusted importer should not be auto-detected:\n%s", stdout)
+	}
+	if _, stderr, err := bdcRun(t, dir, "config", "import.trust_external", "true"); err != nil {
+		t.Fatalf("config failed: %v\n%s", err, stderr)
+	}
+
+	// Detected from the configured extension
+	if _, stderr, err := bdcRun(t, dir, "import", path, "--option", "ref=tsv-1", "--quiet"); err != nil {
+		t.Fatalf("external import failed: %v\n%s", err, stderr)
+	}
+	stdout, _, _ = bdcRun(t, dir, "list", "--json")
+	var insights []types.Insight
+	if err := json.Unmarshal([]byte(stdout), &insights); err != nil {
+		t.Fatalf("list --json: %v\n%s", err, stdout)
+	}
+	if len(insights) != 2 {
+		t.Fatalf("expected 2 imported insights, got %d", len(insights))
+	}
+	for _, ins := range insights {
+		if ins.Source.Type != "tsv" || ins.Source.Ref != "--ref=tsv-1" {
+			t.Errorf("insight should come from the tsv importer with its option, got %+v", ins.Source)
+		}
+	}
+
+	if _, _, err := bdcRun(t, dir, "import", path, "--option", "bogus=1"); err == nil {
+		t.Error("expected an error for an undeclared option")
+	}
+}
+
+func TestCLI_ImportMinConfidence(t *testing.T) {
+	dir := setupTestEnv(t)
+
+	session := "Sure, let me look.\n\n" +
+		"The scheduler runs every job twice\nwhen the leader changes.\n\n" +
+		"Decision: we'll use a lease in the database because it survives restarts.\n\n" +
+		"```go\nlease.Acquire(ctx) // found: not an insight\n```\n\n" +
+		"| job | runs |\n|-----|------|\n"
+	path := filepath.Join(dir, "session.md")
+	if err := os.WriteFile(path, []byte(session), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, stderr, err := bdcRun(t, dir, "import", path, "--dry-run")
+	if err != nil {
+		t.Fatalf("import failed: %v\n%s", err, stderr)
+	}
+	if !strings.Contains(stdout, "Extracted 2 insights") || !strings.Contains(stdout, "Skipped 1 segments") {
+		t.Errorf("expected the wrapped paragraph and decision only, got:\n%s", stdout)
+	}
+	if !strings.Contains(stdout, "runs every job twice when the leader") || strings.Contains(stdout, "not an insight") {
+		t.Errorf("wrapped lines should be joined and code skipped, got:\n%s", stdout)
+	}
+
+	stdout, _, _ = bdcRun(t, dir, "import", path, "--dry-run", "--min-confidence", "0.8")
+	if !strings.Contains(stdout, "Extracted 1 insights") || !strings.Contains(stdout, "DECISION") {
+		t.Errorf("--min-confidence 0.8 should keep only the decision, got:\n%s", stdout)
+	}
+}
+
+func TestCLI_ImportLLMExtractor(t *testing.T) {
+	dir := setupTestEnv(t)
+
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if r.URL.Path != "/v1/chat/completions" || r.Header.Get("Authorization") != "Bearer sk-env" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		reply := `{"insights": [
+			{"passage": 0, "type": "question", "content": "Should sessions move to Redis?", "confidence": 0.9},
+			{"passage": 1, "type": "decision", "content": "Sessions stay in Postgres to avoid running Redis.", "confidence": 0.8,
+			 "links": [{"to": 0, "type": "builds-on"}]}]}`
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": map[string]string{"role": "assistant", "content": reply}}},
+		})
+	}))
+	defer srv.Close()
+
+	if _, _, err := bdcRun(t, dir, "config", "extract.extractor", "gpt"); err == nil {
+		t.Error("expected an unknown extractor to be rejected")
+	}
+	for _, kv := range [][2]string{
+		{"extract.endpoint", srv.URL + "/v1"},
+		{"extract.model", "test-model"},
+		{"extract.extractor", "llm"},
+	} {
+		if _, stderr, err := bdcRun(t, dir, "config", kv[0], kv[1]); err != nil {
+			t.Fatalf("config %s failed: %v\n%s", kv[0], err, stderr)
+		}
+	}
+	stdout, _, _ := bdcRun(t, dir, "config")
+	if !strings.Contains(stdout, "extract.model") || !strings.Contains(stdout, "test-model") {
+		t.Errorf("config should list keys and values, got:\n%s", stdout)
+	}
+
+	path := filepath.Join(dir, "session.txt")
+	os.WriteFile(path, []byte("Human: Should we move sessions to Redis?\nAI: Let's keep them in Postgres.\n"), 0644)
+
+	t.Setenv("BDC_EXTRACT_API_KEY", "sk-env")
+	stdout, stderr, err := bdcRun(t, dir, "import", path)
+	if err != nil {
+		t.Fatalf("import failed: %v\n%s", err, stderr)
+	}
+	if !strings.Contains(stdout, "Extracted 2 insights") || !strings.Contains(stdout, "Linked 1 pairs") {
+		t.Errorf("expected the model's insights and link, got:\n%s", stdout)
+	}
+
+	// Re-importing is answered from the cache
+	stdout, _, _ = bdcRun(t, dir, "import", path, "--dry-run")
+	if n := atomic.LoadInt32(&calls); n != 1 || !strings.Contains(stdout, "Sessions stay in Postgres") {
+		t.Errorf("expected 1 request and the cached insights, got %d requests:\n%s", n, stdout)
+	}
+
+	// --extractor overrides the config
+	stdout, _, _ = bdcRun(t, dir, "import", path, "--dry-run", "--extractor", "heuristic")
+	if strings.Contains(stdout, "Sessions stay in Postgres") {
+		t.Errorf("heuristic extractor should not use the model, got:\n%s", stdout)
+	}
+}
+
+func TestCLI_Dedupe(t *testing.T) {
+	dir := setupTestEnv(t)
+
+	out, _, err := bdcRun(t, dir, "capture", "--decision", "--timestamp", "2024-01-01", "We will keep sessions in Postgres for now")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keepID := extractInsightID(t, out)
+	out, _, _ = bdcRun(t, dir, "capture", "--discovery", "Redis would add an ops burden")
+	otherID := extractInsightID(t, out)
+
+	out, stderr, err := bdcRun(t, dir, "capture", "--decision", "we'll keep the sessions in postgres for now.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dupID := extractInsightID(t, out)
+	if !strings.Contains(stderr, "similar to "+keepID) {
+		t.Errorf("capture should warn about the near-duplicate, got stderr:\n%s", stderr)
+	}
+	bdcRun(t, dir, "link", otherID, "--builds-on", dupID)
+
+	stdout, _, _ := bdcRun(t, dir, "dedupe")
+	if !strings.Contains(stdout, "Found 1 groups") || !strings.Contains(stdout, keepID) || !strings.Contains(stdout, dupID) {
+		t.Errorf("expected one group, got:\n%s", stdout)
+	}
+
+	stdout, stderr, err = bdcRun(t, dir, "dedupe", "--apply")
+	if err != nil || !strings.Contains(stdout, "Merged 1 near-duplicates") {
+		t.Fatalf("dedupe --apply: %v\n%s%s", err, stdout, stderr)
+	}
+	stdout, _, _ = bdcRun(t, dir, "show", dupID)
+	if !strings.Contains(stdout, dupID+" -> "+keepID+" [duplicates]") {
+		t.Errorf("expected a duplicates link, got:\n%s", stdout)
+	}
+	stdout, _, _ = bdcRun(t, dir, "show", otherID)
+	if !strings.Contains(stdout, otherID+" -> "+keepID+" [builds-on]") {
+		t.Errorf("links should move to the kept insight, got:\n%s", stdout)
+	}
+	stdout, _, _ = bdcRun(t, dir, "dedupe")
+	if !strings.Contains(stdout, "No near-duplicates") {
+		t.Errorf("merged insights should not be listed again, got:\n%s", stdout)
+	}
+
+	// Imports can skip or merge near-duplicates
+	path := filepath.Join(dir, "notes.csv")
+	os.WriteFile(path, []byte("content,type\nWe will keep sessions in Postgres for now!,decision\nThe export job leaks memory,discovery\n"), 0644)
+	stdout, _, _ = bdcRun(t, dir, "import", path, "--dedupe", "skip")
+	if !strings.Contains(stdout, "Skipped 1 near-duplicates") || !strings.Contains(stdout, "Saved 1 insights") {
+		t.Errorf("expected one skipped near-duplicate, got:\n%s", stdout)
+	}
+	os.WriteFile(path, []byte("content,type\nThe export job leaks memory!!,discovery\n"), 0644)
+	stdout, _, _ = bdcRun(t, dir, "import", path, "--dedupe", "merge")
+	if !strings.Contains(stdout, "Merged 1 near-duplicates") {
+		t.Errorf("expected the near-duplicate to be merged, got:\n%s", stdout)
+	}
+	if _, _, err := bdcRun(t, dir, "import", path, "--dedupe", "drop"); err == nil {
+		t.Error("expected an unknown --dedupe mode to fail")
+	}
+}
+
+func TestCLI_Related(t *testing.T) {
+	dir := setupTestEnv(t)
+
+	out, _, _ := bdcRun(t, dir, "thread", "new", "Payments")
+	payments := extractThreadID(t, out)
+	out, _, _ = bdcRun(t, dir, "thread", "new", "Redis outage")
+	outage := extractThreadID(t, out)
+
+	out, _, _ = bdcRun(t, dir, "capture", "--thread", payments, "--discovery", "Redis connections time out when the pool is exhausted")
+	pastID := extractInsightID(t, out)
+	bdcRun(t, dir, "capture", "--thread", payments, "--decision", "Retry payment calls with exponential backoff")
+	out, _, _ = bdcRun(t, dir, "capture", "--thread", outage, "--discovery", "Redis timeouts again after failover")
+	nowID := extractInsightID(t, out)
+
+	stdout, stderr, err := bdcRun(t, dir, "related", nowID)
+	if err != nil {
+		t.Fatalf("related: %v\n%s", err, stderr)
+	}
+	if !strings.Contains(stdout, pastID) || strings.Contains(stdout, "backoff") {
+		t.Errorf("expected only the earlier Redis insight, got:\n%s", stdout)
+	}
+
+	stdout, _, _ = bdcRun(t, dir, "related", "--json", "exponential", "backoff")
+	var related []struct {
+		ID    string  `json:"id"`
+		Score float64 `json:"score"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &related); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout)
+	}
+	if len(related) != 1 || related[0].Score <= 0 {
+		t.Errorf("expected one scored match, got %+v", related)
+	}
+
+	// Suggested links come from the same thread and are not created
+	stdout, _, err = bdcRun(t, dir, "capture", "--thread", outage, "--pivot", "Pool size was not the cause, failover DNS is", "--suggest-links")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pivotID := extractInsightID(t, stdout)
+	if !strings.Contains(stdout, "bdc link "+pivotID+" --contradicts "+nowID) || strings.Contains(stdout, pastID) {
+		t.Errorf("expected a contradicts suggestion for %s only, got:\n%s", nowID, stdout)
+	}
+	stdout, _, _ = bdcRun(t, dir, "show", pivotID)
+	if strings.Contains(stdout, "[contradicts]") {
+		t.Errorf("suggested links should not be created, got:\n%s", stdout)
+	}
+
+	// A pivot supersedes the direction lint would pick
+	out, _, _ = bdcRun(t, dir, "capture", "--thread", outage, "--hypothesis", "Raise the DNS TTL to ride out failover")
+	hypID := extractInsightID(t, out)
+	stdout, _, _ = bdcRun(t, dir, "capture", "--thread", outage, "--pivot", "Lowering the DNS TTL instead", "--suggest-links")
+	if !strings.Contains(stdout, "--supersedes "+hypID) {
+		t.Errorf("expected a supersedes suggestion for %s, got:\n%s", hypID, stdout)
+	}
+
+	// With --json the suggestions are next to, not inside, the insight
+	stdout, _, err = bdcRun(t, dir, "capture", "--thread", outage, "--discovery", "Failover DNS has a 300s TTL", "--suggest-links", "--json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var captured struct {
+		ID             string             `json:"id"`
+		Links          []types.Dependency `json:"links"`
+		SuggestedLinks []types.Dependency `json:"suggested_links"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &captured); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout)
+	}
+	if captured.ID == "" || captured.Links != nil || len(captured.SuggestedLinks) == 0 || captured.SuggestedLinks[0].From != captured.ID {
+		t.Errorf("expected suggested_links from the new insight, got:\n%s", stdout)
+	}
+}
+
+func TestCLI_Ask(t *testing.T) {
+	dir := setupTestEnv(t)
+
+	out, _, _ := bdcRun(t, dir, "thread", "new", "Payment retries")
+	threadID := extractThreadID(t, out)
+	bdcRun(t, dir, "thread", "understand", threadID, "Retries back off exponentially")
+
+	out, _, _ = bdcRun(t, dir, "capture", "--thread", threadID, "--decision", "Retry failed payment calls every 5 seconds")
+	oldID := extractInsightID(t, out)
+	out, _, _ = bdcRun(t, dir, "capture", "--thread", threadID, "--decision", "Switch payment retries to exponential backoff. Fixed delays hammered the provider after outages.")
+	newID := extractInsightID(t, out)
+	bdcRun(t, dir, "capture", "--discovery", "The export job leaks memory")
+	bdcRun(t, dir, "link", newID, "--supersedes", oldID)
+
+	stdout, stderr, err := bdcRun(t, dir, "ask", "why did we choose exponential backoff for payment retries?")
+	if err != nil {
+		t.Fatalf("ask: %v\n%s", err, stderr)
+	}
+	if !strings.HasPrefix(stdout, "Switch payment retries to exponential backoff") || !strings.Contains(stdout, "[1] "+newID) {
+		t.Errorf("expected the current decision first, got:\n%s", stdout)
+	}
+	if !strings.Contains(stdout, "supersedes "+oldID) || !strings.Contains(stdout, `"Payment retries": Retries back off exponentially`) {
+		t.Errorf("expected the link and thread context, got:\n%s", stdout)
+	}
+	if strings.Contains(stdout, "export") {
+		t.Errorf("unrelated insights should not be cited, got:\n%s", stdout)
+	}
+
+	stdout, _, _ = bdcRun(t, dir, "ask", "--json", "retry payment calls")
+	var a struct {
+		Answer    string `json:"answer"`
+		Citations []struct {
+			ID           string   `json:"id"`
+			SupersededBy []string `json:"superseded_by"`
+			Thread       struct {
+				Title string `json:"title"`
+			} `json:"thread"`
+		} `json:"citations"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &a); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout)
+	}
+	if len(a.Citations) != 2 || a.Citations[0].ID != newID || a.Citations[1].SupersededBy[0] != newID || a.Citations[0].Thread.Title != "Payment retries" {
+		t.Errorf("unexpected JSON answer: %+v", a)
+	}
+
+	stdout, _, _ = bdcRun(t, dir, "ask", "what about kafka?")
+	if !strings.Contains(stdout, "No insights found") {
+		t.Errorf("expected no answer, got:\n%s", stdout)
+	}
+}
+
+func TestCLI_ImportNonexistentFile(t *testing.T) {
+	dir := setupTestEnv(t)
+
+	_, _, err := bdcRun(t, dir, "import", "nonexistent.csv")
+	if err == nil {
+		t.Error("expected error importing non-existent file")
+	}
+}
+
+func TestCLI_ImportAutoJSONL(t *testing.T) {
+	dir := setupTestEnv(t)
+
+	// First, create some data via the CLI and export it
+	tOut, _, _ := bdcRun(t, dir, "thread", "new", "Auto-import Source Thread")
+	thrID := extractThreadID(t, tOut)
+	bdcRun(t, dir, "capture", "--thread", thrID, "--decision", "auto import content")
+	bdcRun(t, dir, "export", "--quiet")
+
+	// Read the exported JSONL to verify it exists
+	threadsData, err := os.ReadFile(filepath.Join(dir, ".beadcrumbs", "threads.jsonl"))
+	if err != nil {
+		t.Fatalf("failed to read exported threads.jsonl: %v", err)
+	}
+	if len(threadsData) == 0 {
+		t.Fatal("exported threads.jsonl is empty")
+	}
+
+	// Create a fresh environment and copy the JSONL files
+	dir2 := t.TempDir()
+	gitCmd := exec.Command("git", "init")
+	gitCmd.Dir = dir2
+	gitCmd.Run()
+	bdcRun(t, dir2, "init", "--quiet")
+
+	// Copy threads.jsonl from first env
+	if err := os.WriteFile(filepath.Join(dir2, ".beadcrumbs", "threads.jsonl"), threadsData, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Run auto import
+	_, _, err = bdcRun(t, dir2, "import", "--auto", "--quiet")
+	if err != nil {
+		t.Fatalf("import --auto failed: %v", err)
+	}
+
+	// Verify thread was imported (must specify --status since default list requires it)
+	stdout, _, _ := bdcRun(t, dir2, "thread", "list", "--status=active")
+	if !strings.Contains(stdout, "Auto-import Source Thread") {
+		t.Errorf("auto import should have imported thread, thread list: %q", stdout)
+	}
+}
+
+// ============================================================================
+// Prime (PRs #1, #8)
+// ============================================================================
+
+func TestCLI_PrimeOutput(t *testing.T) {
+	dir := setupTestEnv(t)
+
+	stdout, _, _ := bdcRun(t, dir, "prime")
+	if !strings.Contains(stdout, "Beadcrumbs") {
+		t.Errorf("prime output should contain 'Beadcrumbs', got: %q", stdout)
+	}
+}
+
+func TestCLI_PrimeSilentWhenAbsent(t *testing.T) {
+	dir := t.TempDir() // No .beadcrumbs/
+
+	stdout, _, _ := bdcRun(t, dir, "prime")
+	if stdout != "" {
+		t.Errorf("prime should produce no stdout when .beadcrumbs/ absent, got: %q", stdout)
+	}
+}
+
+func TestCLI_PrimeCustomOverride(t *testing.T) {
+	dir := setupTestEnv(t)
+
+	// Create custom PRIME.md
+	primePath := filepath.Join(dir, ".beadcrumbs", "PRIME.md")
+	if err := os.WriteFile(primePath, []byte("CUSTOM PRIME CONTENT"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, _, _ := bdcRun(t, dir, "prime")
+	if !strings.Contains(stdout, "CUSTOM PRIME CONTENT") {
+		t.Errorf("prime should use PRIME.md override, got: %q", stdout)
+	}
+	if strings.Contains(stdout, "Beadcrumbs Insight Tracker Active") {
+		t.Error("prime should NOT show default content when PRIME.md exists")
+	}
+}
+
+func TestCLI_PrimeExportFlag(t *testing.T) {
+	dir := setupTestEnv(t)
+
+	// Create custom PRIME.md
+	primePath := filepath.Join(dir, ".beadcrumbs", "PRIME.md")
+	if err := os.WriteFile(primePath, []byte("CUSTOM"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, _, _ := bdcRun(t, dir, "prime", "--export")
+	if strings.Contains(stdout, "CUSTOM") {
+		t.Error("prime --export should ignore PRIME.md")
+	}
+	if !strings.Contains(stdout, "Beadcrumbs") {
+		t.Errorf("prime --export should show default content, got: %q", stdout)
+	}
+}
+
+func TestCLI_PrimeState(t *testing.T) {
+	t.Setenv("BDC_ORIGIN", "")
+	dir := setupTestEnv(t)
+
+	stdout, _, _ := bdcRun(t, dir, "thread", "new", "Auth bug")
+	thrID := extractThreadID(t, stdout)
+	bdcRun(t, dir, "thread", "understand", thrID, "JWT exp check is inverted")
+	stdout, _, _ = bdcRun(t, dir, "capture", "--thread", thrID, "--decision", "Allow 30s clock skew")
+	decision := extractInsightID(t, stdout)
+	stdout, _, _ = bdcRun(t, dir, "capture", "--thread", thrID, "--question", "Rotate refresh tokens?")
+	question := extractInsightID(t, stdout)
+	stdout, _, _ = bdcRun(t, dir, "capture", "--thread", thrID, "--question", "Is exp checked at all?")
+	answered := extractInsightID(t, stdout)
+	stdout, _, _ = bdcRun(t, dir, "capture", "--thread", thrID, "--discovery", "Yes, but inverted")
+	bdcRun(t, dir, "link", extractInsightID(t, stdout), "--supersedes", answered)
+	bdcRun(t, dir, "origin", "set", "claude:sess-1")
+
+	stdout, _, _ = bdcRun(t, dir, "prime")
+	state := stdout[strings.Index(stdout, "# Current State"):]
+	want := "# Current State\n\n" +
+		"## Active Threads\n- " + thrID + ": Auth bug -- understanding: JWT exp check is inverted\n\n" +
+		"## Recent Pivots & Decisions\n- DECISION " + decision + ": Allow 30s clock skew\n\n" +
+		"## Unresolved Questions\n- QUESTION " + question + ": Rotate refresh tokens?\n\n" +
+		"## Origin\n- claude:sess-1\n"
+	if state != want {
+		t.Errorf("prime state =\n%s\nwant\n%s", state, want)
+	}
+	if !strings.Contains(stdout, "Beadcrumbs Insight Tracker Active") {
+		t.Error("prime should still lead with the workflow guide")
+	}
+
+	// A tight budget drops entries but keeps the layout
+	stdout, _, _ = bdcRun(t, dir, "prime", "--budget", "45")
+	if !strings.Contains(stdout, "## Unresolved Questions\n") || !strings.Contains(stdout, "more omitted)") {
+		t.Errorf("prime --budget should keep headings and note omissions, got:\n%s", stdout)
+	}
+
+	// JSON has the same sections
+	stdout, _, err := bdcRun(t, dir, "prime", "--json")
+	if err != nil {
+		t.Fatalf("prime --json failed: %v", err)
+	}
+	var st struct {
+		Sections []struct {
+			Name    string `json:"name"`
+			Entries []struct {
+				ID string `json:"id"`
+			} `json:"entries"`
+		} `json:"sections"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &st); err != nil {
+		t.Fatalf("prime --json is not JSON: %v\n%s", err, stdout)
+	}
+	var names []string
+	for _, sec := range st.Sections {
+		names = append(names, sec.Name)
+	}
+	if strings.Join(names, ",") != "threads,recent,questions,origin" || st.Sections[2].Entries[0].ID != question {
+		t.Errorf("unexpected JSON sections: %+v", st.Sections)
+	}
+
+	// PRIME.md placeholders mix custom text with live data
+	primePath := filepath.Join(dir, ".beadcrumbs", "PRIME.md")
+	os.WriteFile(primePath, []byte("Team rules first.\n{{questions}}"), 0644)
+	stdout, _, _ = bdcRun(t, dir, "prime")
+	if stdout != "Team rules first.\n## Unresolved Questions\n- QUESTION "+question+": Rotate refresh tokens?\n" {
+		t.Errorf("unexpected templated prime output: %q", stdout)
+	}
+
+	// The exported default template keeps the state placeholder
+	stdout, _, _ = bdcRun(t, dir, "prime", "--export")
+	if !strings.Contains(stdout, "{{state}}") || strings.Contains(stdout, "Team rules") {
+		t.Errorf("prime --export should print the default template, got tail: %q", stdout[len(stdout)-40:])
+	}
+}
+
+// ============================================================================
+// Timeline + Filtering (PR #9)
+// ============================================================================
+
+func TestCLI_TimelineOriginFilter(t *testing.T) {
+	dir := setupTestEnv(t)
+
+	tOut, _, _ := bdcRun(t, dir, "thread", "new", "Timeline Filter Test")
+	thrID := extractThreadID(t, tOut)
+
+	// Two insights from different origins
+	bdcRun(t, dir, "capture", "--thread", thrID, "--origin", "claude:sess_x", "--decision", "from claude")
+	bdcRun(t, dir, "capture", "--thread", thrID, "--origin", "cursor:ws_y", "--hypothesis", "from cursor")
+
+	// Filter by claude origin
+	stdout, _, err := bdcRun(t, dir, "timeline", thrID, "--origin", "claude:sess_x")
+	if err != nil {
+		t.Fatalf("timeline with origin filter failed: %v", err)
+	}
+
+	if !strings.Contains(stdout, "from claude") {
+		t.Errorf("timeline should contain 'from claude', got: %q", stdout)
+	}
+	if strings.Contains(stdout, "from cursor") {
+		t.Errorf("timeline should NOT contain 'from cursor' with claude filter, got: %q", stdout)
+	}
+}
+
+// ============================================================================
+// Slack Error Paths (PR #6)
+// ============================================================================
+
+func TestCLI_SlackFetchNoAuth(t *testing.T) {
+	dir := setupTestEnv(t)
+
+	_, stderr, err := bdcRun(t, dir, "slack", "fetch", "general")
+	if err == nil {
+		t.Error("expected error for slack fetch without auth")
+	}
+	combined := stderr
+	if !strings.Contains(combined, "token") && !strings.Contains(combined, "auth") && !strings.Contains(combined, "configured") {
+		t.Errorf("expected helpful auth error, got: %q", combined)
+	}
+}
+
+func TestCLI_ImportCSV_CustomMapping(t *testing.T) {
+	dir := setupTestEnv(t)
+
+	// Create CSV with custom column names
+	csvContent := "body,category,who\nMy insight text,hypothesis,brian\n"
+	csvPath := filepath.Join(dir, "custom.csv")
+	if err := os.WriteFile(csvPath, []byte(csvContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, _, err := bdcRun(t, dir, "import", "--dry-run", "--csv", "--map-content=body", "--map-type=category", "--map-author=who", csvPath)
+	if err != nil {
+		t.Fatalf("import with custom mapping failed: %v", err)
+	}
+
+	if !strings.Contains(stdout, "My insight text") {
+		t.Errorf("import should show mapped content, got: %q", stdout)
+	}
+}
+
+// ============================================================================
+// Version & Upgrade (PR #15)
+// ============================================================================
+
+func TestCLI_VersionOutput(t *testing.T) {
+	// version should work without any .beadcrumbs directory
+	dir := t.TempDir()
+
+	stdout, _, err := bdcRun(t, dir, "version")
+	if err != nil {
+		t.Fatalf("bdc version failed: %v", err)
+	}
+
+	if !strings.Contains(stdout, "bdc version") {
+		t.Errorf("expected 'bdc version' in output, got: %q", stdout)
+	}
+	if !strings.Contains(stdout, "0.9.0") {
+		t.Errorf("expected version '0.9.0' in output, got: %q", stdout)
+	}
+}
+
+func TestCLI_VersionNoDBRequired(t *testing.T) {
+	// version must not require a database or git repo
+	dir := t.TempDir()
+
+	_, stderr, err := bdcRun(t, dir, "version")
+	if err != nil {
+		t.Fatalf("bdc version should succeed without .beadcrumbs, got err: %v stderr: %q", err, stderr)
+	}
+}
+
+func TestCLI_UpgradeNoDBRequired(t *testing.T) {
+	// upgrade must not require a database or git repo
+	dir := t.TempDir()
+
+	stdout, _, err := bdcRun(t, dir, "upgrade")
+	if err != nil {
+		t.Fatalf("bdc upgrade should succeed without .beadcrumbs, got err: %v", err)
+	}
+
+	// When built locally, it should show the "could not detect" fallback
+	if !strings.Contains(stdout, "upgrade") && !strings.Contains(stdout, "npm") {
+		t.Errorf("expected upgrade instructions in output, got: %q", stdout)
+	}
+}
+
+func TestCLI_UpgradeShowsInstructions(t *testing.T) {
+	dir := t.TempDir()
+
+	stdout, _, _ := bdcRun(t, dir, "upgrade")
+
+	// Should always mention both upgrade paths
+	if !strings.Contains(stdout, "npm") {
+		t.Errorf("upgrade output should mention npm, got: %q", stdout)
+	}
+	if !strings.Contains(stdout, "go install") || !strings.Contains(stdout, "beadcrumbs") {
+		t.Errorf("upgrade output should mention go install path, got: %q", stdout)
+	}
+}
+
+func TestCLI_ShowInsightOrigin(t *testing.T) {
+	dir := setupTestEnv(t)
+
+	tOut, _, _ := bdcRun(t, dir, "thread", "new", "Show Origin Test")
+	thrID := extractThreadID(t, tOut)
+
+	cOut, _, _ := bdcRun(t, dir, "capture", "--thread", thrID, "--origin", "notion:page-abc", "--feedback", "from notion")
 	insID := extractInsightID(t, cOut)
 
 	stdout, _, _ := bdcRun(t, dir, "show", insID)
@@ -634,3 +2306,44 @@ func TestCLI_ShowInsightOrigin(t *testing.T) {
 		t.Errorf("show should display origin, got: %q", stdout)
 	}
 }
+
+// ============================================================================
+// Lint
+// ============================================================================
+
+func TestCLI_LintExitCodes(t *testing.T) {
+	dir := setupTestEnv(t)
+
+	// Empty database: clean run
+	stdout, _, err := bdcRun(t, dir, "lint")
+	if err != nil {
+		t.Fatalf("lint on empty db failed: %v", err)
+	}
+	if !strings.Contains(stdout, "No lint findings") {
+		t.Errorf("expected 'No lint findings', got: %q", stdout)
+	}
+
+	// A concluded thread without a decision is a warning, which fails under --strict
+	tOut, _, _ := bdcRun(t, dir, "thread", "new", "Lint Test")
+	thrID := extractThreadID(t, tOut)
+	bdcRun(t, dir, "capture", "--thread", thrID, "--discovery", "found something")
+	bdcRun(t, dir, "thread", "close", thrID)
+
+	stdout, _, err = bdcRun(t, dir, "lint", "--json", "--strict")
+	if err == nil {
+		t.Fatal("expected lint to fail with a concluded thread lacking a decision")
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() != 1 {
+		t.Errorf("exit code = %d, want 1", exitErr.ExitCode())
+	}
+	if !strings.Contains(stdout, "concluded-without-decision") {
+		t.Errorf("JSON output missing rule id: %q", stdout)
+	}
+
+	// An unknown rule in the config is a usage error
+	os.WriteFile(filepath.Join(dir, ".beadcrumbs", "lint.yaml"), []byte("rules:\n  bogus:\n    severity: off\n"), 0644)
+	_, _, err = bdcRun(t, dir, "lint")
+	if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 2 {
+		t.Errorf("expected exit code 2 for bad config, got: %v", err)
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// insightRefsTrailer is the commit trailer linking a commit to insights.
+const insightRefsTrailer = "Insight-Refs"
+
+// maxProposedInsightRefs caps how many insights a proposed trailer lists;
+// their threads are always included.
+const maxProposedInsightRefs = 10
+
+var commitMsgDryRun bool
+
+var commitMsgCmd = &cobra.Command{
+	Use:   "commit-msg <message-file> [source]",
+	Short: "Propose an Insight-Refs trailer for a commit message",
+	Long: `Add an "Insight-Refs: ins-xxx, thr-yyy" trailer to a commit message file,
+listing insights captured since the last commit in the current origin or on
+the current branch, plus their threads.
+
+This is what the prepare-commit-msg hook installed by 'bdc init' runs. The
+trailer is only a proposal: delete it in the editor to leave it out. Merge,
+squash and amend messages (source "merge", "squash" or "commit") and messages
+that already carry the trailer are left alone.
+
+Examples:
+  bdc commit-msg .git/COMMIT_EDITMSG
+  bdc commit-msg --dry-run -          # Print the proposed trailer only`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runCommitMsg,
+}
+
+func runCommitMsg(cmd *cobra.Command, args []string) error {
+	if len(args) == 2 {
+		switch args[1] {
+		case "merge", "squash", "commit":
+			return nil
+		}
+	}
+
+	s, err := getReadOnlyStore()
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	refs, err := proposeInsightRefs(s)
+	if err != nil {
+		return err
+	}
+	if len(refs) == 0 {
+		return nil
+	}
+	trailer := fmt.Sprintf("%s: %s", insightRefsTrailer, strings.Join(refs, ", "))
+
+	if commitMsgDryRun {
+		fmt.Println(trailer)
+		return nil
+	}
+
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to read commit message: %w", err)
+	}
+	if len(parseInsightRefs(string(data))) > 0 {
+		return nil // Already linked (e.g. reused message)
+	}
+	if _, err := gitOutput("interpret-trailers", "--in-place", "--trailer", trailer, args[0]); err != nil {
+		return fmt.Errorf("failed to add trailer: %w", err)
+	}
+	return nil
+}
+
+// proposeInsightRefs returns the IDs of insights captured since HEAD was
+// committed in the current origin or on the current branch, most recent
+// first, followed by their thread IDs.
+func proposeInsightRefs(s store.Storage) ([]string, error) {
+	// Commit times have one-second resolution, so insights already linked
+	// from HEAD are excluded explicitly as well.
+	var since time.Time
+	linked := make(map[string]bool)
+	if ts, err := gitOutput("log", "-1", "--format=%ct"); err == nil && ts != "" {
+		var secs int64
+		if _, err := fmt.Sscan(ts, &secs); err == nil {
+			since = time.Unix(secs, 0)
+		}
+		if body, err := gitOutput("log", "-1", "--format=%B"); err == nil {
+			for _, id := range parseInsightRefs(body) {
+				linked[id] = true
+			}
+		}
+	}
+
+	origin := os.Getenv("BDC_ORIGIN")
+	if origin == "" {
+		origin = readOriginFile()
+	}
+	branch, _ := gitOutput("symbolic-ref", "--short", "-q", "HEAD")
+	if origin == "" && branch == "" {
+		return nil, nil
+	}
+
+	insights, err := s.ListInsights("", "", time.Time{}, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list insights: %w", err)
+	}
+
+	var refs, threads []string
+	seenThread := make(map[string]bool)
+	for _, ins := range insights { // newest first
+		if ins.CreatedAt.Before(since) || linked[ins.ID] {
+			continue
+		}
+		inOrigin := origin != "" && ins.Source.Ref == origin
+		onBranch := branch != "" && ins.Source.Git != nil && ins.Source.Git.Branch == branch
+		if !inOrigin && !onBranch {
+			continue
+		}
+		if len(refs) >= maxProposedInsightRefs {
+			break
+		}
+		refs = append(refs, ins.ID)
+		if ins.ThreadID != "" && !seenThread[ins.ThreadID] {
+			seenThread[ins.ThreadID] = true
+			threads = append(threads, ins.ThreadID)
+		}
+	}
+	sort.Strings(threads)
+	return append(refs, threads...), nil
+}
+
+var (
+	insightRefsLine = regexp.MustCompile(`(?im)^` + insightRefsTrailer + `:\s*(.+)$`)
+	insightRefID    = regexp.MustCompile(`\b(?:ins|thr)-[a-z0-9]+\b`)
+)
+
+// parseInsightRefs extracts the insight and thread IDs from all
+// Insight-Refs trailers in a commit message, in order and without duplicates.
+func parseInsightRefs(message string) []string {
+	var refs []string
+	seen := make(map[string]bool)
+	for _, m := range insightRefsLine.FindAllStringSubmatch(message, -1) {
+		for _, id := range insightRefID.FindAllString(strings.ToLower(m[1]), -1) {
+			if !seen[id] {
+				seen[id] = true
+				refs = append(refs, id)
+			}
+		}
+	}
+	return refs
+}
+
+func init() {
+	rootCmd.AddCommand(commitMsgCmd)
+	commitMsgCmd.Flags().BoolVar(&commitMsgDryRun, "dry-run", false, "print the proposed trailer instead of editing the file")
+}
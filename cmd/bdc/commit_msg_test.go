@@ -0,0 +1,26 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseInsightRefs(t *testing.T) {
+	msg := `Fix token refresh race
+
+Serialize refresh with a mutex.
+
+Insight-Refs: ins-7f2a, thr-9e1b
+insight-refs: INS-0c3d thr-9e1b
+Signed-off-by: Someone <someone@example.com>
+`
+	got := parseInsightRefs(msg)
+	want := []string{"ins-7f2a", "thr-9e1b", "ins-0c3d"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseInsightRefs = %v, want %v", got, want)
+	}
+
+	if refs := parseInsightRefs("Mentions ins-7f2a in the body only"); len(refs) != 0 {
+		t.Errorf("IDs outside the trailer should be ignored, got %v", refs)
+	}
+}
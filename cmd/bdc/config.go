@@ -0,0 +1,231 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	importer "github.com/brianevanmiller/beadcrumbs/internal/import"
+	"github.com/brianevanmiller/beadcrumbs/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// configKeys are the keys bdc config accepts, in display order.
+// Integrations keep their own keys (see bdc slack config, bdc github config, ...).
+// The keys are described in configCmd's help.
+var configKeys = []struct {
+	Key    string
+	Secret bool
+}{
+	{"extract.extractor", false},
+	{"extract.endpoint", false},
+	{"extract.model", false},
+	{"extract.api_key", true},
+	{"extract.timeout", false},
+	{"extract.cache", false},
+	{"dedupe.threshold", false},
+	{"import.trust_external", false},
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config [key] [value]",
+	Short: "Get or set project config",
+	Long: `Get or set project configuration. With no arguments, list every key.
+
+Keys:
+  extract.extractor   heuristic (default) or llm
+  extract.endpoint    OpenAI-compatible API base URL
+  extract.model       Model name
+  extract.api_key     Bearer token for the endpoint
+  extract.timeout     Seconds to wait for each request (default 120)
+  extract.cache       Cache responses by content hash (default true)
+  dedupe.threshold    Similarity at which insights are near-duplicates (default 0.8)
+  import.trust_external
+                      Let .beadcrumbs/importers.yaml importers detect files by
+                      extension (default false: they run only with --format)
+
+With extract.extractor set to llm, bdc import sends AI sessions and agent
+transcripts to the model, which returns insights with a type, summary,
+confidence and links between them. Any server that speaks the OpenAI chat
+completions API works, including a local Ollama or llama.cpp server.
+Responses are cached in .beadcrumbs/extract-cache/ under a hash of the
+request, so re-importing the same file gives the same insights. The cache
+holds transcript text, so it is never committed: the directory ignores
+itself.
+
+API key precedence (highest wins):
+  1. BDC_EXTRACT_API_KEY env var
+  2. extract.api_key config (this command)
+
+Config is per-repository.
+
+Examples:
+  bdc config extract.endpoint http://localhost:11434/v1
+  bdc config extract.model llama3.1
+  bdc config extract.extractor llm
+  bdc config dedupe.threshold 0.7
+  bdc config`,
+	Args: cobra.MaximumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := getStore()
+		if err != nil {
+			return err
+		}
+		defer closeStore()
+
+		if len(args) == 0 {
+			for _, k := range configKeys {
+				value, err := s.GetConfig(k.Key)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("%-18s %s\n", k.Key, displayConfigValue(value, k.Secret))
+			}
+			return nil
+		}
+
+		key := args[0]
+		secret, known := false, false
+		for _, k := range configKeys {
+			if k.Key == key {
+				secret, known = k.Secret, true
+			}
+		}
+		if !known {
+			return fmt.Errorf("unknown config key %q (run 'bdc config' to list keys)", key)
+		}
+
+		if len(args) == 1 {
+			// Get
+			value, err := s.GetConfig(key)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%s: %s\n", key, displayConfigValue(value, secret))
+			return nil
+		}
+
+		// Set
+		if err := validateConfigValue(key, args[1]); err != nil {
+			return err
+		}
+		if err := s.SetConfig(key, args[1]); err != nil {
+			return err
+		}
+		if secret {
+			fmt.Printf("Set %s\n", key)
+		} else {
+			fmt.Printf("Set %s = %s\n", key, args[1])
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+}
+
+// displayConfigValue shows unset values and masks secrets.
+func displayConfigValue(value string, secret bool) string {
+	switch {
+	case value == "":
+		return "(not set)"
+	case secret && len(value) > 12:
+		return value[:8] + "..." + value[len(value)-4:]
+	case secret:
+		return "(set)"
+	}
+	return value
+}
+
+// validateConfigValue rejects values the key can't use.
+func validateConfigValue(key, value string) error {
+	switch key {
+	case "extract.extractor":
+		if value != "heuristic" && value != "llm" {
+			return fmt.Errorf("extract.extractor must be heuristic or llm")
+		}
+	case "extract.timeout":
+		if n, err := strconv.Atoi(value); err != nil || n <= 0 {
+			return fmt.Errorf("extract.timeout must be a positive number of seconds")
+		}
+	case "extract.cache":
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("extract.cache must be true or false")
+		}
+	case "import.trust_external":
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("import.trust_external must be true or false")
+		}
+	case "dedupe.threshold":
+		if f, err := strconv.ParseFloat(value, 64); err != nil || f <= 0 || f > 1 {
+			return fmt.Errorf("dedupe.threshold must be a number above 0 and at most 1")
+		}
+	}
+	return nil
+}
+
+// readConfig returns the bdc config keys' values, or nil when there's no
+// database yet. It opens its own read-only connection so commands can
+// read config before deciding whether to open the store for writing.
+func readConfig() (map[string]string, error) {
+	if _, err := os.Stat(dbPath); err != nil {
+		return nil, nil
+	}
+	s, err := store.NewReadOnlyStore(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database (read-only): %w", err)
+	}
+	defer s.Close()
+	cfg := make(map[string]string)
+	for _, k := range configKeys {
+		cfg[k.Key], _ = s.GetConfig(k.Key)
+	}
+	return cfg, nil
+}
+
+// configureExtractor registers the extractor bdc import uses: name if
+// given (from --extractor), else the extract.extractor config. Without a
+// database the heuristic extractor is used unless llm is asked for.
+func configureExtractor(name string) error {
+	cfg, err := readConfig()
+	if err != nil {
+		return err
+	}
+
+	if name == "" {
+		name = cfg["extract.extractor"]
+	}
+	switch name {
+	case "", "heuristic":
+		importer.SetExtractor(nil)
+		return nil
+	case "llm":
+	default:
+		return fmt.Errorf("unknown extractor %q (use heuristic or llm)", name)
+	}
+
+	llm := importer.LLMConfig{
+		Endpoint: cfg["extract.endpoint"],
+		Model:    cfg["extract.model"],
+		APIKey:   cfg["extract.api_key"],
+		CacheDir: filepath.Join(filepath.Dir(dbPath), importer.ExtractCacheDirName),
+	}
+	if key := os.Getenv("BDC_EXTRACT_API_KEY"); key != "" {
+		llm.APIKey = key
+	}
+	if n, err := strconv.Atoi(cfg["extract.timeout"]); err == nil && n > 0 {
+		llm.Timeout = time.Duration(n) * time.Second
+	}
+	if on, err := strconv.ParseBool(cfg["extract.cache"]); err == nil && !on {
+		llm.CacheDir = ""
+	}
+	e, err := importer.NewLLMExtractor(llm)
+	if err != nil {
+		return err
+	}
+	importer.SetExtractor(e)
+	return nil
+}
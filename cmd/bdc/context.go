@@ -0,0 +1,306 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/agentctx"
+	"github.com/brianevanmiller/beadcrumbs/internal/anchor"
+	"github.com/brianevanmiller/beadcrumbs/internal/store"
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	contextFiles  []string
+	contextBudget int
+	contextHook   bool
+)
+
+var contextCmd = &cobra.Command{
+	Use:   "context --files <path>[,<path>...]",
+	Short: "Show the decisions and open questions relevant to files",
+	Long: `Output the decisions, pivots and unresolved questions relevant to the files
+about to be edited, in markdown sized for an AI agent's context.
+
+An insight is relevant when:
+  - it has a code anchor in one of the files (bdc capture --at)
+  - it carries a label mapped to a glob matching one of the files
+  - it belongs to the current git branch's thread (bdc thread config auto_branch)
+
+Superseded insights are left out. The most relevant insights are kept until
+the token budget (--budget, or "budget" in the config, default 1000) is used.
+
+Labels are mapped to paths in .beadcrumbs/context.yaml:
+
+  version: 1
+  budget: 800
+  labels:
+    auth: ["internal/auth/**", "cmd/login.go"]
+
+With --hook, the files are read from a Claude Code PreToolUse event on stdin
+and the result is returned as additional context. 'bdc setup claude'
+installs this hook for Edit, MultiEdit, Write and NotebookEdit. Within one
+session each insight is injected only once.
+
+Examples:
+  bdc context --files internal/auth/jwt.go
+  bdc context --files a.go,b.go --budget 400
+  bdc context --files a.go --json`,
+	Args: cobra.NoArgs,
+	RunE: runContext,
+}
+
+func runContext(cmd *cobra.Command, args []string) error {
+	var event *preToolUseEvent
+	files := contextFiles
+	if contextHook {
+		// Hooks fire in every project; stay silent outside beadcrumbs ones
+		if findBeadcrumbsDir() == "" {
+			return nil
+		}
+		var err error
+		if event, err = readPreToolUseEvent(os.Stdin); err != nil || len(event.files()) == 0 {
+			return nil
+		}
+		files = event.files()
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no files given (use --files a.go,b.go)")
+	}
+
+	rel := make([]string, 0, len(files))
+	for _, f := range files {
+		r, err := repoRelativePath(f)
+		if err != nil {
+			if contextHook {
+				continue // e.g. a file outside the repository
+			}
+			return err
+		}
+		if r != "" {
+			rel = append(rel, r)
+		}
+	}
+	if len(rel) == 0 {
+		return nil
+	}
+
+	cfg, err := agentctx.LoadConfig(filepath.Join(filepath.Dir(dbPath), agentctx.ConfigFileName))
+	if err != nil {
+		return err
+	}
+	budget := cfg.Budget
+	if cmd.Flags().Changed("budget") {
+		budget = contextBudget
+	}
+
+	s, err := getReadOnlyStore()
+	if err != nil {
+		if contextHook {
+			return nil
+		}
+		return err
+	}
+	defer closeStore()
+
+	items, err := relevantInsights(s, cfg, rel)
+	if err != nil {
+		return err
+	}
+
+	var seen *contextSeen
+	if event != nil && event.SessionID != "" {
+		seen = loadContextSeen(event.SessionID)
+		items = seen.filter(items)
+	}
+
+	agentctx.Rank(items)
+	kept, omitted := agentctx.Fit(rel, items, budget)
+
+	if jsonOutput {
+		if kept == nil {
+			kept = []agentctx.Item{}
+		}
+		out, err := json.MarshalIndent(struct {
+			Files    []string        `json:"files"`
+			Budget   int             `json:"budget"`
+			Insights []agentctx.Item `json:"insights"`
+			Omitted  int             `json:"omitted"`
+		}{rel, budget, kept, omitted}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	text := agentctx.Render(rel, kept, omitted, budget)
+	if text == "" {
+		return nil
+	}
+	if event == nil {
+		fmt.Print(text)
+		return nil
+	}
+
+	if seen != nil {
+		seen.add(kept)
+	}
+	out, err := json.Marshal(map[string]interface{}{
+		"hookSpecificOutput": map[string]interface{}{
+			"hookEventName":     "PreToolUse",
+			"additionalContext": text,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	fmt.Println(string(out))
+	return nil
+}
+
+// relevantInsights collects the unsuperseded decisions, pivots and questions
+// related to files by code anchor, mapped label or the current branch's
+// thread.
+func relevantInsights(s store.Storage, cfg *agentctx.Config, files []string) ([]agentctx.Item, error) {
+	all, err := s.ListInsights("", "", time.Time{}, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list insights: %w", err)
+	}
+	var candidates []*types.Insight
+	for _, ins := range all {
+		if agentctx.Relevant(ins.Type) {
+			candidates = append(candidates, ins)
+		}
+	}
+	candidates, err = filterUnresolved(s, candidates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check superseded insights: %w", err)
+	}
+
+	wanted := make(map[string]bool, len(files))
+	labels := make(map[string]bool)
+	for _, f := range files {
+		wanted[f] = true
+		for _, l := range cfg.LabelsFor(f) {
+			labels[l] = true
+		}
+	}
+
+	var branchThread string
+	if branch, err := gitOutput("symbolic-ref", "--quiet", "--short", "HEAD"); err == nil {
+		if m, err := s.GetExternalRefMappingByRef(branchThreadRef(branch)); err == nil && m != nil {
+			branchThread = redirectThreadID(s, m.ThreadID)
+		}
+	}
+
+	remapper := newAnchorRemapper()
+	var items []agentctx.Item
+	for _, ins := range candidates {
+		it := agentctx.Item{Insight: ins}
+		for _, a := range ins.Anchors {
+			cur, status := remapper.current(a)
+			if status != anchor.StatusDeleted && wanted[cur.Path] {
+				it.Add("anchor "+cur.Path+anchor.FormatLines(cur), agentctx.WeightAnchor)
+			}
+		}
+		for _, tag := range ins.Tags {
+			if labels[tag] {
+				it.Add("label "+tag, agentctx.WeightLabel)
+			}
+		}
+		if branchThread != "" && ins.ThreadID == branchThread {
+			it.Add("branch thread", agentctx.WeightBranch)
+		}
+		if it.Score > 0 {
+			items = append(items, it)
+		}
+	}
+	return items, nil
+}
+
+// preToolUseEvent is the part of a Claude Code PreToolUse hook payload that
+// names the files a tool is about to touch.
+type preToolUseEvent struct {
+	SessionID string `json:"session_id"`
+	ToolInput struct {
+		FilePath     string `json:"file_path"`
+		NotebookPath string `json:"notebook_path"`
+	} `json:"tool_input"`
+}
+
+func readPreToolUseEvent(r io.Reader) (*preToolUseEvent, error) {
+	var e preToolUseEvent
+	if err := json.NewDecoder(r).Decode(&e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (e *preToolUseEvent) files() []string {
+	var files []string
+	for _, f := range []string{e.ToolInput.FilePath, e.ToolInput.NotebookPath} {
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+// contextSeen remembers which insights were already injected in a Claude
+// session, so editing the same files repeatedly doesn't repeat them.
+type contextSeen struct {
+	path string
+	ids  map[string]bool
+}
+
+var sessionIDUnsafe = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+func loadContextSeen(sessionID string) *contextSeen {
+	seen := &contextSeen{
+		path: filepath.Join(os.TempDir(), "bdc-context-"+sessionIDUnsafe.ReplaceAllString(sessionID, "_")),
+		ids:  make(map[string]bool),
+	}
+	if data, err := os.ReadFile(seen.path); err == nil {
+		for _, id := range strings.Fields(string(data)) {
+			seen.ids[id] = true
+		}
+	}
+	return seen
+}
+
+func (c *contextSeen) filter(items []agentctx.Item) []agentctx.Item {
+	var out []agentctx.Item
+	for _, it := range items {
+		if !c.ids[it.Insight.ID] {
+			out = append(out, it)
+		}
+	}
+	return out
+}
+
+// add records injected insights (best-effort).
+func (c *contextSeen) add(items []agentctx.Item) {
+	f, err := os.OpenFile(c.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	for _, it := range items {
+		fmt.Fprintln(f, it.Insight.ID)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(contextCmd)
+	contextCmd.Flags().StringSliceVar(&contextFiles, "files", nil, "files about to be edited (comma-separated or repeated)")
+	contextCmd.Flags().IntVar(&contextBudget, "budget", agentctx.DefaultBudget, "maximum output size in tokens (approximate)")
+	contextCmd.Flags().BoolVar(&contextHook, "hook", false, "read files from a Claude Code PreToolUse event on stdin and answer in hook JSON")
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/typeconfig"
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+	"github.com/spf13/cobra"
+)
+
+// captureCustomFlags holds the capture --<flag> aliases for custom types.
+var captureCustomFlags = map[types.InsightType]*bool{}
+
+var typesCmd = &cobra.Command{
+	Use:   "types",
+	Short: "List insight types, including project-defined ones",
+	Long: `List the built-in insight types and any custom types defined in
+.beadcrumbs/types.yaml.
+
+Custom types get their own timeline symbol, summary section, import trigger
+phrases and capture flag:
+
+  version: 1
+  types:
+    - name: risk
+      symbol: "⚠"
+      plural: risks          # default: name + "s"
+      section: Open Risks    # default: capitalized plural
+      flag: risk             # bdc capture --risk (default: name)
+      detect: ["could break", "risk is"]`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		custom := types.CustomInsightTypes()
+
+		if jsonOutput {
+			out := struct {
+				Builtin []types.InsightType       `json:"builtin"`
+				Custom  []types.CustomInsightType `json:"custom"`
+			}{Custom: custom}
+			for _, t := range types.ValidInsightTypes() {
+				if t.IsBuiltin() {
+					out.Builtin = append(out.Builtin, t)
+				}
+			}
+			if out.Custom == nil {
+				out.Custom = []types.CustomInsightType{}
+			}
+			data, err := json.MarshalIndent(out, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal JSON: %w", err)
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+
+		fmt.Println("Built-in types:")
+		for _, t := range types.ValidInsightTypes() {
+			if t.IsBuiltin() {
+				fmt.Printf("  %s %-12s --%s\n", getInsightSymbol(t), t, t)
+			}
+		}
+		if len(custom) == 0 {
+			fmt.Printf("\nNo custom types (define them in %s)\n", customTypesPath())
+			return nil
+		}
+		fmt.Printf("\nCustom types (%s):\n", customTypesPath())
+		for _, def := range custom {
+			fmt.Printf("  %s %-12s --%-12s %s\n", def.Symbol, def.Name, def.Flag, def.Section)
+		}
+		return nil
+	},
+}
+
+// customTypesPath returns the types config next to the database.
+func customTypesPath() string {
+	return filepath.Join(filepath.Dir(dbPath), typeconfig.ConfigFileName)
+}
+
+// customFlagNames are the capture flags preloadCustomTypes registered.
+var customFlagNames = map[string]bool{}
+
+// loadCustomTypes registers the custom types for the resolved database.
+// A type whose flag is already one of capture's own flags is an error:
+// its alias could never be used.
+func loadCustomTypes() error {
+	if err := typeconfig.LoadAndApply(customTypesPath()); err != nil {
+		return fmt.Errorf("invalid %s: %w", customTypesPath(), err)
+	}
+	for _, def := range types.CustomInsightTypes() {
+		taken := captureCmd.Flags().Lookup(def.Flag) != nil || rootCmd.PersistentFlags().Lookup(def.Flag) != nil
+		if taken && !customFlagNames[def.Flag] {
+			types.SetCustomInsightTypes(nil)
+			return fmt.Errorf("invalid %s: type %q: flag --%s is already a capture flag", customTypesPath(), def.Name, def.Flag)
+		}
+	}
+	return nil
+}
+
+// preloadCustomTypes registers custom types before flag parsing so their
+// capture aliases exist. The database path is not resolved yet, so it is
+// guessed the same way resolveDBPath will; errors are left for the
+// authoritative load in PersistentPreRunE to report.
+func preloadCustomTypes(args []string) {
+	path := dbPath
+	if flagPath := dbFlagFromArgs(args); flagPath != "" {
+		path = flagPath
+	} else if discovered := discoverDBPath(); discovered != "" {
+		path = discovered
+	}
+
+	cfg, err := typeconfig.Load(filepath.Join(filepath.Dir(path), typeconfig.ConfigFileName))
+	if err != nil {
+		return
+	}
+	cfg.Apply()
+
+	for _, def := range cfg.Types {
+		if captureCmd.Flags().Lookup(def.Flag) != nil {
+			continue // reported by loadCustomTypes
+		}
+		usage := fmt.Sprintf("mark as %s (custom type)", def.Name)
+		if def.Description != "" {
+			usage = fmt.Sprintf("mark as %s (%s)", def.Name, def.Description)
+		}
+		captureCustomFlags[def.Name] = captureCmd.Flags().Bool(def.Flag, false, usage)
+		customFlagNames[def.Flag] = true
+	}
+}
+
+// dbFlagFromArgs returns the value of --db in raw command-line args.
+func dbFlagFromArgs(args []string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if arg == "--db" && i+1 < len(args) {
+			return args[i+1]
+		}
+		if strings.HasPrefix(arg, "--db=") {
+			return strings.TrimPrefix(arg, "--db=")
+		}
+	}
+	return ""
+}
+
+// warnUnknownInsightTypes reports imported insights whose type is neither
+// built in nor defined in types.yaml. They are kept as-is so nothing is lost,
+// but the warning is always printed so the gap doesn't go unnoticed.
+func warnUnknownInsightTypes(insights []*types.Insight) {
+	counts := make(map[string]int)
+	for _, ins := range insights {
+		if !ins.Type.IsValid() {
+			counts[string(ins.Type)]++
+		}
+	}
+	if len(counts) == 0 {
+		return
+	}
+
+	var names []string
+	total := 0
+	for name, n := range counts {
+		names = append(names, name)
+		total += n
+	}
+	sort.Strings(names)
+	fmt.Fprintf(os.Stderr, "Warning: %d imported insight(s) use unknown type(s): %s. Define them in %s\n",
+		total, strings.Join(names, ", "), customTypesPath())
+}
+
+func init() {
+	rootCmd.AddCommand(typesCmd)
+}
@@ -10,6 +10,7 @@ import (
 )
 
 var decisionsOrigin string
+var decisionsGitFilter gitFilter
 
 var decisionsCmd = &cobra.Command{
 	Use:   "decisions [thread-id]",
@@ -35,7 +36,7 @@ func runDecisions(cmd *cobra.Command, args []string) error {
 
 	var threadID string
 	if len(args) > 0 {
-		threadID = args[0]
+		threadID = redirectThreadID(st, args[0])
 	}
 
 	// Get decision insights
@@ -43,6 +44,7 @@ func runDecisions(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to list decisions: %w", err)
 	}
+	insights = decisionsGitFilter.apply(insights)
 
 	if len(insights) == 0 {
 		if jsonOutput {
@@ -89,4 +91,5 @@ func runDecisions(cmd *cobra.Command, args []string) error {
 func init() {
 	rootCmd.AddCommand(decisionsCmd)
 	decisionsCmd.Flags().StringVar(&decisionsOrigin, "origin", "", "filter by origin (exact match)")
+	decisionsGitFilter.register(decisionsCmd)
 }
@@ -0,0 +1,338 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/similar"
+	"github.com/brianevanmiller/beadcrumbs/internal/store"
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	dedupeThresholdFlag float64
+	dedupeApply         bool
+)
+
+var dedupeCmd = &cobra.Command{
+	Use:   "dedupe [<duplicate-id> <keep-id>]",
+	Short: "Find and merge near-duplicate insights",
+	Long: `Find insights of the same type whose wording is nearly the same, such as
+a re-imported Slack channel or a capture repeated in other words.
+
+Insights are compared as sets of words, ignoring case, punctuation and
+filler words; two count as near-duplicates when their Jaccard similarity
+reaches the threshold (--threshold, or 'bdc config dedupe.threshold',
+default 0.8). Below 0.5 every pair of insights of a type is compared,
+which is slower on large databases.
+
+Without arguments, near-duplicates are listed in groups for review, each
+with the oldest insight first. --apply merges each group into its oldest
+insight. With two IDs, the first insight is merged into the second
+whatever their similarity.
+
+Merging keeps both insights: the duplicate gets a "duplicates" link to
+the insight it was merged into, which takes over its links, labels and
+endorsements, all in one transaction. Merged insights are left out of
+later near-duplicate checks.
+
+Examples:
+  bdc dedupe                          # Review near-duplicates
+  bdc dedupe --threshold 0.6          # Looser matching
+  bdc dedupe --apply                  # Merge every group
+  bdc dedupe ins-b2c3 ins-a1b2        # Merge ins-b2c3 into ins-a1b2`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 && len(args) != 2 {
+			return fmt.Errorf("expected no arguments, or a duplicate ID and the ID to keep")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := getStore()
+		if err != nil {
+			return err
+		}
+		defer closeStore()
+
+		if len(args) == 2 {
+			dup, err := s.GetInsight(args[0])
+			if err != nil {
+				return fmt.Errorf("insight %s not found: %w", args[0], err)
+			}
+			keep, err := s.GetInsight(args[1])
+			if err != nil {
+				return fmt.Errorf("insight %s not found: %w", args[1], err)
+			}
+			if dup.ID == keep.ID {
+				return fmt.Errorf("cannot merge an insight into itself")
+			}
+			if err := s.MergeInsights(dup.ID, keep.ID); err != nil {
+				return fmt.Errorf("failed to merge %s into %s: %w", dup.ID, keep.ID, err)
+			}
+			fmt.Printf("Merged %s into %s\n", dup.ID, keep.ID)
+			return nil
+		}
+
+		threshold := dedupeThresholdFlag
+		if !cmd.Flags().Changed("threshold") {
+			threshold = dedupeThreshold(s)
+		}
+		if threshold <= 0 || threshold > 1 {
+			return fmt.Errorf("--threshold must be above 0 and at most 1")
+		}
+
+		groups, err := findDuplicateGroups(s, threshold)
+		if err != nil {
+			return err
+		}
+
+		if dedupeApply {
+			merged := 0
+			for _, g := range groups {
+				for _, dup := range g.Duplicates {
+					if err := s.MergeInsights(dup.Insight.ID, g.Keep.ID); err != nil {
+						return fmt.Errorf("failed to merge %s into %s: %w", dup.Insight.ID, g.Keep.ID, err)
+					}
+					merged++
+				}
+			}
+			if jsonOutput {
+				data, err := json.MarshalIndent(map[string]interface{}{"groups": groups, "merged": merged}, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal JSON: %w", err)
+				}
+				fmt.Println(string(data))
+				return nil
+			}
+			fmt.Printf("Merged %d near-duplicates into %d insights.\n", merged, len(groups))
+			return nil
+		}
+
+		if jsonOutput {
+			if groups == nil {
+				groups = []duplicateGroup{}
+			}
+			data, err := json.MarshalIndent(groups, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal JSON: %w", err)
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+
+		if len(groups) == 0 {
+			fmt.Printf("No near-duplicates at threshold %.2f.\n", threshold)
+			return nil
+		}
+		fmt.Printf("Found %d groups of near-duplicates (threshold %.2f):\n", len(groups), threshold)
+		for _, g := range groups {
+			fmt.Printf("\n  %s %s %q (keep)\n", getInsightSymbol(g.Keep.Type), g.Keep.ID, truncateContent(g.Keep.Content, 60))
+			for _, d := range g.Duplicates {
+				fmt.Printf("    %s %3.0f%% %q\n", d.Insight.ID, 100*d.Similarity, truncateContent(d.Insight.Content, 60))
+			}
+		}
+		fmt.Println("\nRun 'bdc dedupe --apply' to merge each group into its oldest insight,")
+		fmt.Println("or 'bdc dedupe <duplicate-id> <keep-id>' to merge one.")
+		return nil
+	},
+}
+
+func init() {
+	dedupeCmd.Flags().Float64Var(&dedupeThresholdFlag, "threshold", similar.DefaultThreshold, "similarity (0-1) at which insights are near-duplicates")
+	dedupeCmd.Flags().BoolVar(&dedupeApply, "apply", false, "merge every group into its oldest insight")
+	rootCmd.AddCommand(dedupeCmd)
+}
+
+// duplicateGroup is an insight and its near-duplicates.
+type duplicateGroup struct {
+	Keep       *types.Insight     `json:"keep"`
+	Duplicates []duplicateInsight `json:"duplicates"`
+}
+
+type duplicateInsight struct {
+	Insight    *types.Insight `json:"insight"`
+	Similarity float64        `json:"similarity"` // To Keep
+}
+
+// dedupeThreshold returns the dedupe.threshold config, or the default.
+func dedupeThreshold(s store.Storage) float64 {
+	value, _ := s.GetConfig("dedupe.threshold")
+	return parseDedupeThreshold(value)
+}
+
+func parseDedupeThreshold(value string) float64 {
+	if f, err := strconv.ParseFloat(value, 64); err == nil && f > 0 && f <= 1 {
+		return f
+	}
+	return similar.DefaultThreshold
+}
+
+// unmergedInsights returns the insights that haven't been merged into
+// another, oldest first.
+func unmergedInsights(s store.Storage) ([]*types.Insight, error) {
+	all, err := s.ListInsights("", "", time.Time{}, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list insights: %w", err)
+	}
+	merged, err := mergedInsightIDs(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var insights []*types.Insight
+	for _, ins := range all {
+		if !merged[ins.ID] {
+			insights = append(insights, ins)
+		}
+	}
+	sort.SliceStable(insights, func(i, j int) bool { return insights[i].Timestamp.Before(insights[j].Timestamp) })
+	return insights, nil
+}
+
+// mergedInsightIDs returns the IDs of insights merged into another.
+func mergedInsightIDs(s store.Storage) (map[string]bool, error) {
+	deps, err := s.ListAllDependencies()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dependencies: %w", err)
+	}
+	merged := make(map[string]bool)
+	for _, d := range deps {
+		if d.Type == types.DepDuplicates {
+			merged[d.From] = true
+		}
+	}
+	return merged, nil
+}
+
+// duplicateIndex indexes insights by type for near-duplicate lookups.
+func duplicateIndex(insights []*types.Insight) *similar.Index {
+	ix := similar.NewIndex()
+	for _, ins := range insights {
+		ix.Add(ins.ID, string(ins.Type), ins.Content)
+	}
+	return ix
+}
+
+// findDuplicateGroups groups unmerged insights that are near-duplicates,
+// directly or through each other. Each group is led by its oldest insight.
+func findDuplicateGroups(s store.Storage, threshold float64) ([]duplicateGroup, error) {
+	insights, err := unmergedInsights(s)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*types.Insight, len(insights))
+	pos := make(map[string]int, len(insights))
+	for i, ins := range insights {
+		byID[ins.ID] = ins
+		pos[ins.ID] = i
+	}
+
+	// Union-find, with the oldest insight of a group as its root
+	parent := make(map[string]string)
+	var find func(id string) string
+	find = func(id string) string {
+		p, ok := parent[id]
+		if !ok || p == id {
+			return id
+		}
+		root := find(p)
+		parent[id] = root
+		return root
+	}
+	for _, p := range duplicateIndex(insights).Pairs(threshold) {
+		a, b := find(p.A), find(p.B)
+		if pos[b] < pos[a] {
+			a, b = b, a
+		}
+		parent[a] = a
+		if a != b {
+			parent[b] = a
+		}
+	}
+
+	groupOf := make(map[string]int)
+	var groups []duplicateGroup
+	for _, ins := range insights {
+		if _, ok := parent[ins.ID]; !ok {
+			continue
+		}
+		root := find(ins.ID)
+		if root == ins.ID {
+			groupOf[root] = len(groups)
+			groups = append(groups, duplicateGroup{Keep: ins})
+			continue
+		}
+		g := &groups[groupOf[root]]
+		g.Duplicates = append(g.Duplicates, duplicateInsight{
+			Insight:    ins,
+			Similarity: similar.Similarity(byID[root].Content, ins.Content),
+		})
+	}
+	return groups, nil
+}
+
+// warnNearDuplicates tells the user on stderr which earlier insights of
+// the same type ins looks like.
+func warnNearDuplicates(s store.Storage, ins *types.Insight) {
+	threshold := dedupeThreshold(s)
+	insights, err := unmergedInsights(s)
+	if err != nil {
+		return
+	}
+	ix := similar.NewIndex()
+	byID := make(map[string]*types.Insight)
+	for _, other := range insights {
+		if other.ID != ins.ID && other.Type == ins.Type {
+			ix.Add(other.ID, string(other.Type), other.Content)
+			byID[other.ID] = other
+		}
+	}
+	matches := ix.Similar(string(ins.Type), ins.Content, threshold)
+	if len(matches) == 0 {
+		return
+	}
+	for i, m := range matches {
+		if i == 3 {
+			fmt.Fprintf(os.Stderr, "  ...and %d more\n", len(matches)-i)
+			break
+		}
+		fmt.Fprintf(os.Stderr, "Warning: %.0f%% similar to %s %q\n", 100*m.Similarity, m.ID, truncateContent(byID[m.ID].Content, 60))
+	}
+	fmt.Fprintf(os.Stderr, "  Merge with 'bdc dedupe %s %s' if it's the same insight.\n", ins.ID, matches[0].ID)
+}
+
+// importDuplicates maps each insight being imported that is a
+// near-duplicate of an existing insight, or of one earlier in the import,
+// to that insight's ID.
+func importDuplicates(insights []*types.Insight) (map[string]string, error) {
+	var existing []*types.Insight
+	threshold := similar.DefaultThreshold
+	if _, err := os.Stat(dbPath); err == nil {
+		s, err := store.NewReadOnlyStore(dbPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open database (read-only): %w", err)
+		}
+		threshold = dedupeThreshold(s)
+		existing, err = unmergedInsights(s)
+		s.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ix := duplicateIndex(existing)
+	duplicateOf := make(map[string]string)
+	for _, ins := range insights {
+		if m := ix.Similar(string(ins.Type), ins.Content, threshold); len(m) > 0 {
+			duplicateOf[ins.ID] = m[0].ID
+			continue
+		}
+		ix.Add(ins.ID, string(ins.Type), ins.Content)
+	}
+	return duplicateOf, nil
+}
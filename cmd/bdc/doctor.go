@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/brianevanmiller/beadcrumbs/internal/gitsync"
 	"github.com/spf13/cobra"
 )
 
@@ -44,6 +45,7 @@ Exit code 0 if all checks pass, 1 if any fail.`,
 		}()
 
 		// --- Check: SQLite integrity ---
+		var syncBranch string
 		if storeErr != nil {
 			fmt.Printf("  ✗ Failed to open database: %v\n", storeErr)
 			allPassed = false
@@ -62,7 +64,14 @@ Exit code 0 if all checks pass, 1 if any fail.`,
 			}
 
 			// --- Check: JSONL ↔ DB consistency ---
+			// In sync-branch mode the JSONL files live in the sync worktree
 			beadcrumbsDir := filepath.Dir(dbPath)
+			if syncBranch, _ = s.GetConfig(syncBranchConfigKey); syncBranch != "" {
+				repo := gitsync.Repo{Dir: beadcrumbsDir}
+				if wt, err := syncWorktreePath(repo, syncBranch); err == nil {
+					beadcrumbsDir = wt
+				}
+			}
 			type tableCheck struct {
 				jsonlFile string
 				table     string
@@ -155,6 +164,9 @@ Exit code 0 if all checks pass, 1 if any fail.`,
 		} else {
 			hooksDir := filepath.Join(gitDir, "hooks")
 			hookChecks := []string{"pre-commit", "post-merge"}
+			if syncBranch != "" {
+				hookChecks = []string{"post-commit", "post-merge"}
+			}
 			var hookFailures []string
 			for _, hook := range hookChecks {
 				hookPath := filepath.Join(hooksDir, hook)
@@ -169,7 +181,7 @@ Exit code 0 if all checks pass, 1 if any fail.`,
 				fmt.Printf("  ✗ Git hooks: %s\n", strings.Join(hookFailures, "; "))
 				allPassed = false
 			} else {
-				fmt.Printf("  ✓ Git hooks: pre-commit and post-merge installed\n")
+				fmt.Printf("  ✓ Git hooks: %s and %s installed\n", hookChecks[0], hookChecks[1])
 			}
 		}
 
@@ -27,6 +27,7 @@ Examples:
 
 var feedbackSince string
 var feedbackOrigin string
+var feedbackGitFilter gitFilter
 
 func runFeedback(cmd *cobra.Command, args []string) error {
 	st, err := getReadOnlyStore()
@@ -37,7 +38,7 @@ func runFeedback(cmd *cobra.Command, args []string) error {
 
 	var threadID string
 	if len(args) > 0 {
-		threadID = args[0]
+		threadID = redirectThreadID(st, args[0])
 	}
 
 	// Parse --since if provided
@@ -54,6 +55,7 @@ func runFeedback(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to list feedback: %w", err)
 	}
+	insights = feedbackGitFilter.apply(insights)
 
 	if len(insights) == 0 {
 		if jsonOutput {
@@ -101,4 +103,5 @@ func init() {
 	rootCmd.AddCommand(feedbackCmd)
 	feedbackCmd.Flags().StringVar(&feedbackSince, "since", "", "show feedback since (e.g., 1w, 2d, 3h)")
 	feedbackCmd.Flags().StringVar(&feedbackOrigin, "origin", "", "filter by origin (exact match)")
+	feedbackGitFilter.register(feedbackCmd)
 }
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+	"github.com/spf13/cobra"
+)
+
+// collectGitContext returns the branch, HEAD, dirty state and worktree of
+// the current directory, or nil outside a git repository.
+func collectGitContext() *types.GitContext {
+	worktree, err := gitOutput("rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil
+	}
+	ctx := &types.GitContext{Worktree: worktree}
+
+	// symbolic-ref fails on a detached HEAD, leaving Branch empty.
+	if branch, err := gitOutput("symbolic-ref", "--short", "-q", "HEAD"); err == nil {
+		ctx.Branch = branch
+	}
+	if commit, err := gitOutput("rev-parse", "-q", "--verify", "HEAD"); err == nil {
+		ctx.Commit = commit
+	}
+	if status, err := gitOutput("status", "--porcelain", "--untracked-files=no"); err == nil {
+		ctx.Dirty = status != ""
+	}
+	return ctx
+}
+
+// gitFilter holds the --branch/--commit filters of a list-style command.
+type gitFilter struct {
+	branch string
+	commit string
+}
+
+// register adds --branch and --commit to cmd.
+func (f *gitFilter) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.branch, "branch", "", "only insights captured on this git branch")
+	cmd.Flags().StringVar(&f.commit, "commit", "", "only insights captured at this commit (SHA prefix)")
+}
+
+// apply drops insights whose git context doesn't match the filters.
+// Insights without git context never match an active filter.
+func (f *gitFilter) apply(insights []*types.Insight) []*types.Insight {
+	if f.branch == "" && f.commit == "" {
+		return insights
+	}
+	var kept []*types.Insight
+	for _, ins := range insights {
+		git := ins.Source.Git
+		if git == nil {
+			continue
+		}
+		if f.branch != "" && git.Branch != f.branch {
+			continue
+		}
+		if f.commit != "" && !strings.HasPrefix(git.Commit, strings.ToLower(f.commit)) {
+			continue
+		}
+		kept = append(kept, ins)
+	}
+	return kept
+}
+
+// formatGitContext renders git context as "branch @ abc1234 (dirty)".
+func formatGitContext(git *types.GitContext) string {
+	branch := git.Branch
+	if branch == "" {
+		branch = "(detached)"
+	}
+	s := branch
+	if git.Commit != "" {
+		s += " @ " + shortSHA(git.Commit)
+	}
+	if git.Dirty {
+		s += " (dirty)"
+	}
+	return s
+}
@@ -166,6 +166,8 @@ var githubPushCmd = &cobra.Command{
 		}
 		defer closeStore()
 
+		threadID = redirectThreadID(s, threadID)
+
 		thread, err := s.GetThread(threadID)
 		if err != nil {
 			return fmt.Errorf("failed to get thread: %w", err)
@@ -199,7 +201,8 @@ var githubPushCmd = &cobra.Command{
 		if err != nil {
 			return fmt.Errorf("failed to get insights: %w", err)
 		}
-		if len(insights) == 0 {
+		children := childSummaries(s, threadID)
+		if len(insights) == 0 && len(children) == 0 {
 			return fmt.Errorf("no insights in thread %s", threadID)
 		}
 
@@ -208,7 +211,7 @@ var githubPushCmd = &cobra.Command{
 			return fmt.Errorf("invalid PR reference: %s", ghMapping.ExternalID)
 		}
 
-		body := summary.FormatSummary(thread, insights)
+		body := summary.FormatRollupSummary(thread, insights, children)
 		if err := ghCli.AddComment(repo, prNumber, body); err != nil {
 			return fmt.Errorf("failed to post comment: %w", err)
 		}
@@ -232,6 +235,8 @@ var githubLinkCmd = &cobra.Command{
 		}
 		defer closeStore()
 
+		threadID = redirectThreadID(s, threadID)
+
 		// Verify thread exists
 		thread, err := s.GetThread(threadID)
 		if err != nil {
@@ -1,9 +1,12 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -18,23 +21,65 @@ var importCmd = &cobra.Command{
 	Short: "Import insights from a file or directory",
 	Long: `Import insights from various sources:
   - AI session transcripts (text files with conversation)
+  - Claude Code session transcripts (~/.claude/projects/*/<session>.jsonl)
+  - Aider chat history (.aider.chat.history.md)
+  - Codex CLI session logs (~/.codex/sessions/.../rollout-*.jsonl)
+  - Cursor chat exports (markdown from "Export Chat")
+  - ChatGPT data exports (conversations.json)
   - Slack exports (directory with JSON files)
   - CSV files with column mapping
   - JSONL files with column mapping
 
-The format is auto-detected, or you can specify it with flags.
+The format is auto-detected from the extension and content, or you can
+specify it with --format (see --list-formats) or the older per-format
+flags. Transcript formats keep each turn's timestamp, speaker and model,
+and attribute the insights to the session (e.g. codex:<session-id>).
+
+Transcripts are read as markdown: code, tool output, tables and headings
+are skipped, wrapped lines form paragraphs, and paragraphs making several
+claims are split. Each segment is scored 0-1 for how likely it is an
+insight; those below --min-confidence (default 0.4) are not imported.
+Rows of CSV, JSONL, Slack and external formats are not filtered.
+Format options are set with --option name=value.
+
+With --extractor llm (or "bdc config extract.extractor llm"), AI sessions
+and transcripts are instead read by a chat model behind an
+OpenAI-compatible endpoint, which also suggests links between the
+insights; see "bdc config".
+
+--dedupe skip leaves out insights that are near-duplicates of existing
+ones or of each other (see "bdc dedupe"); --dedupe merge saves them and
+merges each into the insight it repeats.
+
+Other formats can be added as external importers: executables listed in
+.beadcrumbs/importers.yaml or named bdc-import-<format> on PATH. bdc runs
+the executable with the file on stdin (or a directory as its last
+argument) and --<option>=<value> for each option, and reads one JSON
+object per line from its stdout, with the keys of the jsonl format
+(content, type, timestamp, author, summary, source_ref, tags). Importers from
+importers.yaml detect files by extension only after
+"bdc config import.trust_external true"; otherwise pick them with --format.
 
 Examples:
   bdc import session.txt                          # Auto-detect format
   bdc import session.txt --ai-session             # Force AI session format
+  bdc import ~/.claude/projects/-src-app/4f1c.jsonl  # Claude Code session
+  bdc import .aider.chat.history.md               # Aider history
+  bdc import conversations.json --format chatgpt  # ChatGPT export
   bdc import slack-export/ --slack                # Import Slack export
   bdc import data.csv --csv                       # Import CSV file
   bdc import data.jsonl --jsonl                   # Import JSONL file
   bdc import data.csv --map-content=body          # Map 'body' column to content
+  bdc import data.csv --option map-content=body   # Same, as a format option
+  bdc import issues.xml --format jira             # External importer
+  bdc import --list-formats                       # Show formats and options
   bdc import data.csv --source-type=github-pr     # Set source type
   bdc import session.txt --thread=thr-xxx         # Add to existing thread
   bdc import session.txt --timestamp="2024-01-15" # Set timestamp for all insights
-  bdc import session.txt --dry-run                # Preview without saving`,
+  bdc import session.txt --dry-run                # Preview without saving
+  bdc import session.txt --min-confidence=0.7     # Keep only strong insights
+  bdc import session.txt --extractor llm          # Extract with the configured LLM
+  bdc import slack-export/ --dedupe skip          # Leave out near-duplicates`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runImport,
 }
@@ -42,6 +87,8 @@ Examples:
 var (
 	importThread    string
 	importAISession bool
+	importClaude    bool
+	importFormat    string
 	importSlack     bool
 	importCSV       bool
 	importJSONL     bool
@@ -50,6 +97,14 @@ var (
 	importAuto      bool
 	importQuiet     bool
 	importSourceType string
+	importGitContext bool
+
+	// Format listing and options
+	importListFormats bool
+	importOptionFlags []string
+	importMinConf     float64
+	importExtractor   string
+	importDedupe      string
 
 	// Column mapping flags
 	importMapContent   string
@@ -64,6 +119,13 @@ var (
 func init() {
 	importCmd.Flags().StringVar(&importThread, "thread", "", "add imported insights to this thread")
 	importCmd.Flags().BoolVar(&importAISession, "ai-session", false, "force AI session format")
+	importCmd.Flags().BoolVar(&importClaude, "claude-code", false, "force Claude Code transcript format")
+	importCmd.Flags().StringVar(&importFormat, "format", "", "force a format (see --list-formats)")
+	importCmd.Flags().BoolVar(&importListFormats, "list-formats", false, "list the available formats and their options")
+	importCmd.Flags().StringArrayVar(&importOptionFlags, "option", nil, "set a format option as name=value (repeatable)")
+	importCmd.Flags().Float64Var(&importMinConf, "min-confidence", importer.DefaultMinConfidence, "skip insights scored below this confidence (0-1)")
+	importCmd.Flags().StringVar(&importDedupe, "dedupe", "", "handle near-duplicates of existing insights: skip or merge")
+	importCmd.Flags().StringVar(&importExtractor, "extractor", "", "how to find insights in prose: heuristic or llm (default: extract.extractor config)")
 	importCmd.Flags().BoolVar(&importSlack, "slack", false, "force Slack export format")
 	importCmd.Flags().BoolVar(&importCSV, "csv", false, "force CSV format")
 	importCmd.Flags().BoolVar(&importJSONL, "jsonl", false, "force JSONL format")
@@ -72,6 +134,7 @@ func init() {
 	importCmd.Flags().BoolVar(&importAuto, "auto", false, "auto-import from JSONL (for git hooks)")
 	importCmd.Flags().BoolVar(&importQuiet, "quiet", false, "suppress output (for hooks)")
 	importCmd.Flags().StringVar(&importSourceType, "source-type", "", "set source type (e.g., github-pr, notion, asana)")
+	importCmd.Flags().BoolVar(&importGitContext, "git-context", false, "record the current branch and commit on imported insights")
 
 	// Column mapping flags
 	importCmd.Flags().StringVar(&importMapContent, "map-content", "", "CSV/JSONL column name for content field")
@@ -90,12 +153,18 @@ func runImport(cmd *cobra.Command, args []string) error {
 	if importAuto {
 		return runAutoImport()
 	}
+	if importListFormats {
+		return listImportFormats()
+	}
 
 	if len(args) == 0 {
 		return fmt.Errorf("requires a file or directory argument (or use --auto for JSONL import)")
 	}
 
 	path := args[0]
+	if importDedupe != "" && importDedupe != "skip" && importDedupe != "merge" {
+		return fmt.Errorf("--dedupe must be skip or merge")
+	}
 
 	// Parse timestamp if provided
 	var baseTimestamp time.Time
@@ -107,93 +176,90 @@ func runImport(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	reg, err := importRegistry()
+	if err != nil {
+		return err
+	}
+
 	// Determine format
-	format := detectFormat(path)
-	if importAISession {
-		format = "ai-session"
-	} else if importSlack {
-		format = "slack"
-	} else if importCSV {
-		format = "csv"
-	} else if importJSONL {
-		format = "jsonl"
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	imp, err := selectImporter(reg, path, info.IsDir())
+	if err != nil {
+		return err
+	}
+	opts, err := importOptions(imp)
+	if err != nil {
+		return err
+	}
+	if err := configureExtractor(importExtractor); err != nil {
+		return err
 	}
 
 	// Parse insights
 	var insights []*types.Insight
-	var err error
-
-	switch format {
-	case "ai-session":
-		content, readErr := os.ReadFile(path)
-		if readErr != nil {
-			return fmt.Errorf("failed to read file: %w", readErr)
+	if info.IsDir() {
+		dirImp, ok := imp.(importer.DirImporter)
+		if !ok {
+			return fmt.Errorf("format %s cannot import a directory", imp.Info().Name)
 		}
-
-		// Try conversation format first, fall back to plain
-		// Use timestamp-aware functions
-		insights, err = importer.ParseConversationWithTimestamp(string(content), baseTimestamp)
-		if err != nil || len(insights) == 0 {
-			insights, err = importer.ParseAISessionWithTimestamp(string(content), baseTimestamp)
+		insights, err = dirImp.ParseDir(path, opts)
+	} else {
+		f, openErr := os.Open(path)
+		if openErr != nil {
+			return fmt.Errorf("failed to read file: %w", openErr)
 		}
+		insights, err = imp.Parse(f, opts)
+		f.Close()
+	}
 
-	case "slack":
-		info, statErr := os.Stat(path)
-		if statErr != nil {
-			return fmt.Errorf("failed to stat path: %w", statErr)
+	// Insights keep their source's times, if any; --timestamp overrides
+	if !baseTimestamp.IsZero() {
+		for _, insight := range insights {
+			insight.Timestamp = baseTimestamp
 		}
+	}
 
-		if info.IsDir() {
-			insights, err = importer.ParseSlackExport(path)
-		} else {
-			// Single JSON file
-			content, readErr := os.ReadFile(path)
-			if readErr != nil {
-				return fmt.Errorf("failed to read file: %w", readErr)
-			}
-			insights, err = importer.ParseSlackJSON(content)
-		}
+	if err != nil {
+		return fmt.Errorf("failed to parse: %w", err)
+	}
 
-		// Note: Slack imports preserve their original timestamps automatically
-		// The --timestamp flag is only used to override if explicitly provided
-		if !baseTimestamp.IsZero() {
-			for _, insight := range insights {
-				insight.Timestamp = baseTimestamp
+	// Drop segments that scored too low to be insights
+	skipped := 0
+	if imp.Info().Scored {
+		kept := insights[:0]
+		for _, insight := range insights {
+			if float64(insight.Confidence) >= importMinConf {
+				kept = append(kept, insight)
 			}
 		}
+		skipped = len(insights) - len(kept)
+		insights = kept
+	}
+	if skipped > 0 && !importQuiet {
+		fmt.Printf("Skipped %d segments scored below %.2f (see --min-confidence).\n", skipped, importMinConf)
+	}
 
-	case "csv":
-		mapping := buildColumnMapping()
-		sourceType := importSourceType
-		if sourceType == "" {
-			sourceType = "csv-import"
+	// Find near-duplicates of existing insights and of each other
+	var duplicateOf map[string]string
+	if importDedupe != "" {
+		if duplicateOf, err = importDuplicates(insights); err != nil {
+			return err
 		}
-		insights, err = importer.ParseCSV(path, mapping, sourceType)
-		if !baseTimestamp.IsZero() {
+		if importDedupe == "skip" {
+			kept := insights[:0]
 			for _, insight := range insights {
-				insight.Timestamp = baseTimestamp
+				if duplicateOf[insight.ID] == "" {
+					kept = append(kept, insight)
+				}
 			}
-		}
-
-	case "jsonl":
-		mapping := buildColumnMapping()
-		sourceType := importSourceType
-		if sourceType == "" {
-			sourceType = "jsonl-import"
-		}
-		insights, err = importer.ParseGenericJSONL(path, mapping, sourceType)
-		if !baseTimestamp.IsZero() {
-			for _, insight := range insights {
-				insight.Timestamp = baseTimestamp
+			if n := len(insights) - len(kept); n > 0 && !importQuiet {
+				fmt.Printf("Skipped %d near-duplicates of existing insights (see --dedupe).\n", n)
 			}
+			insights = kept
 		}
-
-	default:
-		return fmt.Errorf("unknown format: %s (use --ai-session, --slack, --csv, or --jsonl)", format)
-	}
-
-	if err != nil {
-		return fmt.Errorf("failed to parse: %w", err)
 	}
 
 	if len(insights) == 0 {
@@ -210,6 +276,15 @@ func runImport(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Stamp the repository state if requested
+	if importGitContext {
+		if git := collectGitContext(); git != nil {
+			for _, insight := range insights {
+				insight.Source.Git = git
+			}
+		}
+	}
+
 	// Print extracted insights (unless quiet)
 	if !importQuiet {
 		fmt.Printf("Extracted %d insights:\n\n", len(insights))
@@ -223,6 +298,12 @@ func runImport(cmd *cobra.Command, args []string) error {
 			timestampStr := ""
 			if !baseTimestamp.IsZero() {
 				timestampStr = fmt.Sprintf(" @ %s", insight.Timestamp.Format("2006-01-02"))
+			} else if imp.Info().Timestamps {
+				timestampStr = fmt.Sprintf(" @ %s", insight.Timestamp.Local().Format("2006-01-02 15:04"))
+			}
+
+			if keep := duplicateOf[insight.ID]; keep != "" {
+				timestampStr += fmt.Sprintf(" (duplicate of %s)", keep)
 			}
 
 			fmt.Printf("  %d. %s \"%s\" [%s]%s\n", i+1, symbol, truncateContent(insight.Content, 60), typeStr, timestampStr)
@@ -255,6 +336,7 @@ func runImport(cmd *cobra.Command, args []string) error {
 
 	// Save insights
 	saved := 0
+	savedIDs := make(map[string]bool)
 	for _, insight := range insights {
 		if err := s.CreateInsight(insight); err != nil {
 			if !importQuiet {
@@ -262,53 +344,201 @@ func runImport(cmd *cobra.Command, args []string) error {
 			}
 			continue
 		}
+		savedIDs[insight.ID] = true
 		saved++
 	}
 
+	// Save the links the extractor suggested between saved insights
+	linked := 0
+	for _, insight := range insights {
+		for _, dep := range insight.Links {
+			if !savedIDs[dep.From] || !savedIDs[dep.To] {
+				continue
+			}
+			dep := dep
+			if err := s.AddDependency(&dep); err != nil {
+				if !importQuiet {
+					fmt.Printf("Warning: failed to save link: %v\n", err)
+				}
+				continue
+			}
+			linked++
+		}
+	}
+
+	// Merge near-duplicates into the insights they repeat
+	merged := 0
+	for _, insight := range insights {
+		keepID := duplicateOf[insight.ID]
+		if keepID == "" || !savedIDs[insight.ID] {
+			continue
+		}
+		if err := s.MergeInsights(insight.ID, keepID); err != nil {
+			if !importQuiet {
+				fmt.Printf("Warning: failed to merge %s into %s: %v\n", insight.ID, keepID, err)
+			}
+			continue
+		}
+		merged++
+	}
+
 	if !importQuiet {
 		fmt.Printf("Saved %d insights.\n", saved)
+		if linked > 0 {
+			fmt.Printf("Linked %d pairs of insights.\n", linked)
+		}
+		if merged > 0 {
+			fmt.Printf("Merged %d near-duplicates into existing insights (see bdc dedupe).\n", merged)
+		}
 	}
 	return nil
 }
 
-// detectFormat tries to determine the format from the path.
-func detectFormat(path string) string {
-	info, err := os.Stat(path)
+// importRegistry returns the built-in importers plus the project's
+// external importers from .beadcrumbs/importers.yaml and any
+// bdc-import-<format> executables on PATH. The project's importers detect
+// files by extension only when import.trust_external is set.
+func importRegistry() (*importer.Registry, error) {
+	reg := importer.NewRegistry()
+
+	cfg, err := readConfig()
 	if err != nil {
-		return "unknown"
+		return nil, err
 	}
+	trusted, _ := strconv.ParseBool(cfg["import.trust_external"])
 
-	// Directory = Slack export
-	if info.IsDir() {
-		return "slack"
-	}
-
-	// Check extension
-	ext := strings.ToLower(filepath.Ext(path))
-	switch ext {
-	case ".json":
-		return "slack"
-	case ".csv":
-		return "csv"
-	case ".jsonl":
-		return "jsonl"
-	case ".txt", ".md", ".log":
-		return "ai-session"
-	default:
-		// Try to peek at content
-		content, err := os.ReadFile(path)
-		if err != nil {
-			return "unknown"
+	dir := filepath.Dir(dbPath)
+	external, err := importer.LoadExternal(filepath.Join(dir, importer.ExternalConfigFileName), filepath.Dir(dir))
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range external {
+		e.Trusted = trusted
+		if err := reg.Register(e); err != nil {
+			return nil, fmt.Errorf("%s: %w", importer.ExternalConfigFileName, err)
 		}
+	}
+
+	// Executables on PATH never shadow a configured format
+	for _, e := range importer.FindExternalCommands(os.Getenv("PATH")) {
+		if reg.Lookup(e.Name) == nil {
+			reg.Register(e)
+		}
+	}
+	return reg, nil
+}
 
-		// If it starts with [ or {, probably JSON
-		trimmed := strings.TrimSpace(string(content))
-		if strings.HasPrefix(trimmed, "[") || strings.HasPrefix(trimmed, "{") {
-			return "slack"
+// selectImporter picks the importer named by --format or a per-format
+// flag, or detects one from the path and the start of the content.
+func selectImporter(reg *importer.Registry, path string, isDir bool) (importer.Importer, error) {
+	name := strings.ToLower(importFormat)
+	switch {
+	case name != "":
+	case importAISession:
+		name = "ai-session"
+	case importClaude:
+		name = "claude-code"
+	case importSlack:
+		name = "slack"
+	case importCSV:
+		name = "csv"
+	case importJSONL:
+		name = "jsonl"
+	}
+	if name != "" {
+		imp := reg.Lookup(name)
+		if imp == nil {
+			return nil, fmt.Errorf("unknown format: %s (use --format with one of: %s)", name, strings.Join(reg.Names(), ", "))
 		}
+		return imp, nil
+	}
 
-		return "ai-session"
+	sample := importer.Sample{Path: path, Ext: strings.ToLower(filepath.Ext(path)), Dir: isDir}
+	if !isDir {
+		sample.Head = readHead(path)
 	}
+	imp := reg.Detect(sample)
+	if imp == nil {
+		return nil, fmt.Errorf("could not detect the format of %s (use --format; see --list-formats)", path)
+	}
+	return imp, nil
+}
+
+// importOptions collects --option name=value pairs and the older --map-*
+// and --source-type flags. The older flags only apply to formats that
+// declare them; --option is checked against the format's options.
+func importOptions(imp importer.Importer) (importer.Options, error) {
+	opts := importer.Options{}
+	legacy := map[string]string{
+		"map-content":    importMapContent,
+		"map-type":       importMapType,
+		"map-timestamp":  importMapTimestamp,
+		"map-author":     importMapAuthor,
+		"map-summary":    importMapSummary,
+		"map-source-ref": importMapSourceRef,
+		"map-tags":       importMapTags,
+		"source-type":    importSourceType,
+	}
+	for _, o := range imp.Info().Options {
+		if v := legacy[o.Name]; v != "" {
+			opts[o.Name] = v
+		}
+	}
+	for _, kv := range importOptionFlags {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid --option %q (want name=value)", kv)
+		}
+		opts[name] = value
+	}
+	if err := importer.CheckOptions(imp, opts); err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
+
+// readHead returns up to the first 64 KiB of a file for sniffing.
+func readHead(path string) []byte {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	head := make([]byte, 64*1024)
+	n, _ := io.ReadFull(f, head)
+	return head[:n]
+}
+
+// listImportFormats prints the registered formats and their options.
+func listImportFormats() error {
+	reg, err := importRegistry()
+	if err != nil {
+		return err
+	}
+	var infos []importer.Info
+	for _, imp := range reg.All() {
+		infos = append(infos, imp.Info())
+	}
+
+	if jsonOutput {
+		data, err := json.MarshalIndent(infos, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	for _, info := range infos {
+		fmt.Printf("%-12s %s\n", info.Name, info.Description)
+		if info.Command != "" {
+			fmt.Printf("%-12s   command: %s\n", "", info.Command)
+		}
+		for _, o := range info.Options {
+			fmt.Printf("%-12s   --option %s=...  %s\n", "", o.Name, o.Description)
+		}
+	}
+	return nil
 }
 
 func truncateContent(s string, maxLen int) string {
@@ -322,19 +552,6 @@ func truncateContent(s string, maxLen int) string {
 	return s[:maxLen-3] + "..."
 }
 
-// buildColumnMapping creates a ColumnMapping from the --map-* flags.
-func buildColumnMapping() importer.ColumnMapping {
-	return importer.ColumnMapping{
-		Content:   importMapContent,
-		Type:      importMapType,
-		Timestamp: importMapTimestamp,
-		Author:    importMapAuthor,
-		Summary:   importMapSummary,
-		SourceRef: importMapSourceRef,
-		Tags:      importMapTags,
-	}
-}
-
 // runAutoImport imports threads, insights, and dependencies from JSONL files
 // in the .beadcrumbs/ directory. Used by git hooks to sync data across worktrees.
 func runAutoImport() error {
@@ -378,6 +595,7 @@ func runAutoImport() error {
 				fmt.Printf("Warning: failed to import insights: %v\n", err)
 			}
 		} else {
+			warnUnknownInsightTypes(insights)
 			for _, insight := range insights {
 				if err := s.UpsertInsight(insight); err != nil {
 					if !importQuiet {
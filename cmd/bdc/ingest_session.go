@@ -0,0 +1,358 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	importer "github.com/brianevanmiller/beadcrumbs/internal/import"
+	"github.com/brianevanmiller/beadcrumbs/internal/store"
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	ingestThread string
+	ingestHook   bool
+	ingestDryRun bool
+	ingestQuiet  bool
+)
+
+// ingestSimilarity is the share of words two insights must have in common
+// to count as the same idea.
+const ingestSimilarity = 0.6
+
+// overlapMinWords is the smallest insight compared by containment.
+const overlapMinWords = 5
+
+var ingestSessionCmd = &cobra.Command{
+	Use:   "ingest-session [transcript.jsonl]",
+	Short: "Capture insights from a Claude Code session transcript",
+	Long: `Extract insights from a Claude Code session transcript, for when the agent
+forgot to capture them.
+
+Every prose paragraph of the session's user and assistant messages is
+classified. Paragraphs a classifier rule gives a type to are kept; chatter
+no rule matches is not, even when a model from 'bdc classify train' would
+type it. Tool calls, tool output and code blocks are
+ignored. Insights keep their message's timestamp, are attributed to the
+session origin (claude:<session-id>) and, for assistant messages, to the
+model.
+
+Candidates that repeat an insight already recorded during the session
+(captured by the agent, or ingested earlier) are skipped, so running this
+after every turn is safe. With --hook, only the messages added since the
+hook last ran for the session are read.
+
+The thread is --thread, else the thread most of the session's insights went
+to, else the branch thread (bdc thread config auto_branch).
+
+With --hook, the transcript path and session are read from a Claude Code
+hook event on stdin. 'bdc setup claude --ingest' installs this for the Stop,
+SessionEnd and PreCompact events.
+
+Examples:
+  bdc ingest-session ~/.claude/projects/-src-app/4f1c....jsonl --dry-run
+  bdc ingest-session session.jsonl --thread thr-7f2a`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runIngestSession,
+}
+
+// sessionHookEvent is the part of a Claude Code Stop, SessionEnd or
+// PreCompact hook payload ingest-session needs.
+type sessionHookEvent struct {
+	SessionID      string `json:"session_id"`
+	TranscriptPath string `json:"transcript_path"`
+}
+
+func runIngestSession(cmd *cobra.Command, args []string) error {
+	path := ""
+	if len(args) > 0 {
+		path = args[0]
+	}
+	quiet := ingestQuiet
+	if ingestHook {
+		// Hooks fire in every project; stay silent outside beadcrumbs ones
+		if findBeadcrumbsDir() == "" {
+			return nil
+		}
+		var event sessionHookEvent
+		if err := json.NewDecoder(os.Stdin).Decode(&event); err != nil || event.TranscriptPath == "" {
+			return nil
+		}
+		path = event.TranscriptPath
+		quiet = true
+	}
+	if path == "" {
+		return fmt.Errorf("requires a transcript path (or --hook)")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open transcript: %w", err)
+	}
+	messages, err := importer.ParseClaudeTranscript(f)
+	f.Close()
+	if err != nil {
+		return err
+	}
+
+	s, err := getStore()
+	if err != nil {
+		if ingestHook {
+			return nil
+		}
+		return err
+	}
+	defer closeStore()
+
+	// The session's earlier insights are looked up from its first message,
+	// whatever part of it this run reads
+	var start time.Time
+	if len(messages) > 0 {
+		start = messages[0].Timestamp
+	}
+
+	// Hooks fire after every turn, so they only read the messages added
+	// since the last run
+	stateKey := ""
+	if ingestHook {
+		stateKey = ingestStateKey(messages)
+		last, err := s.GetConfig(stateKey)
+		if err != nil {
+			return err
+		}
+		messages = messagesAfter(messages, last)
+		if len(messages) == 0 {
+			return nil
+		}
+	}
+
+	found, err := importer.ClaudeMessagesToInsights(messages)
+	if err != nil {
+		return err
+	}
+	var candidates []*types.Insight
+	for _, ins := range found {
+		if ins.Typed && ins.Confidence >= importer.DefaultMinConfidence {
+			candidates = append(candidates, ins)
+		}
+	}
+	if len(candidates) == 0 {
+		if !quiet {
+			fmt.Println("No insights found in the session.")
+		}
+		return markIngested(s, stateKey, messages)
+	}
+
+	ref := candidates[0].Source.Ref
+	existing, err := sessionInsights(s, ref, start)
+	if err != nil {
+		return err
+	}
+
+	threadID, err := ingestThreadID(existing)
+	if err != nil {
+		return err
+	}
+
+	var fresh []*types.Insight
+	skipped := 0
+	for _, ins := range candidates {
+		if repeatsAny(ins, existing) {
+			skipped++
+			continue
+		}
+		ins.ThreadID = threadID
+		fresh = append(fresh, ins)
+		existing = append(existing, ins) // a session often restates itself
+	}
+
+	if !quiet {
+		for i, ins := range fresh {
+			fmt.Printf("  %d. %s \"%s\" [%s]\n", i+1, getInsightSymbol(ins.Type), truncateContent(ins.Content, 60), ins.Type)
+		}
+	}
+	if ingestDryRun {
+		if !quiet {
+			fmt.Printf("Dry run - %d new insights, %d already recorded.\n", len(fresh), skipped)
+		}
+		return nil
+	}
+
+	saved := 0
+	for _, ins := range fresh {
+		if err := s.CreateInsight(ins); err != nil {
+			if errors.Is(err, store.ErrDuplicateInsight) {
+				skipped++
+			} else if !quiet {
+				fmt.Fprintf(os.Stderr, "warning: failed to save insight: %v\n", err)
+			}
+			continue
+		}
+		saved++
+	}
+	if !quiet {
+		fmt.Printf("Ingested %d insights from %s (%d already recorded).\n", saved, ref, skipped)
+	}
+	return markIngested(s, stateKey, messages)
+}
+
+// ingestStateKey is the config key holding the UUID of the last message
+// the hook ingested from the session, or "" when the session is unknown.
+func ingestStateKey(messages []importer.ClaudeMessage) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if id := messages[i].SessionID; id != "" {
+			return "ingest.last_message." + id
+		}
+	}
+	return ""
+}
+
+// messagesAfter returns the messages after the one with UUID last, or all
+// of them when last is not found.
+func messagesAfter(messages []importer.ClaudeMessage, last string) []importer.ClaudeMessage {
+	if last == "" {
+		return messages
+	}
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].UUID == last {
+			return messages[i+1:]
+		}
+	}
+	return messages
+}
+
+// markIngested records the last of messages under stateKey so the next run
+// starts after it. Dry runs and runs without a key record nothing.
+func markIngested(s store.Storage, stateKey string, messages []importer.ClaudeMessage) error {
+	if stateKey == "" || ingestDryRun || len(messages) == 0 {
+		return nil
+	}
+	last := messages[len(messages)-1].UUID
+	if last == "" {
+		return nil
+	}
+	if err := s.SetConfig(stateKey, last); err != nil {
+		return fmt.Errorf("failed to record ingested messages: %w", err)
+	}
+	return nil
+}
+
+// sessionInsights returns the insights attributed to the session origin or
+// recorded since the session began.
+func sessionInsights(s store.Storage, ref string, start time.Time) ([]*types.Insight, error) {
+	var since time.Time
+	if !start.IsZero() {
+		since = start.Add(-time.Minute)
+	}
+	recent, err := s.ListInsights("", "", since, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list insights: %w", err)
+	}
+	if ref == "" || since.IsZero() {
+		return recent, nil
+	}
+	tagged, err := s.ListInsights("", "", time.Time{}, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list insights: %w", err)
+	}
+	seen := make(map[string]bool, len(recent))
+	for _, ins := range recent {
+		seen[ins.ID] = true
+	}
+	for _, ins := range tagged {
+		if !seen[ins.ID] {
+			recent = append(recent, ins)
+		}
+	}
+	return recent, nil
+}
+
+// ingestThreadID picks the thread for ingested insights: --thread, the
+// session's most used thread, or the branch thread.
+func ingestThreadID(existing []*types.Insight) (string, error) {
+	if ingestThread != "" {
+		return resolveThreadRef(ingestThread)
+	}
+	counts := make(map[string]int)
+	for _, ins := range existing {
+		if ins.ThreadID != "" {
+			counts[ins.ThreadID]++
+		}
+	}
+	if len(counts) > 0 {
+		ids := make([]string, 0, len(counts))
+		for id := range counts {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(i, j int) bool {
+			if counts[ids[i]] != counts[ids[j]] {
+				return counts[ids[i]] > counts[ids[j]]
+			}
+			return ids[i] < ids[j]
+		})
+		return ids[0], nil
+	}
+	if git := collectGitContext(); git != nil {
+		return autoBranchThread(git.Branch)
+	}
+	return "", nil
+}
+
+// repeatsAny reports whether ins says the same as one of existing.
+func repeatsAny(ins *types.Insight, existing []*types.Insight) bool {
+	words := wordSet(ins.Content)
+	for _, other := range existing {
+		if overlap(words, wordSet(other.Content)) >= ingestSimilarity {
+			return true
+		}
+	}
+	return false
+}
+
+// wordSet returns the lowercased words of text, ignoring punctuation.
+func wordSet(text string) map[string]bool {
+	words := make(map[string]bool)
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r > 127)
+	}) {
+		words[w] = true
+	}
+	return words
+}
+
+// overlap is the share of the smaller set's words found in the other, so a
+// capture summarizing a long paragraph still matches. Sets of fewer than
+// overlapMinWords words are compared as a whole (Jaccard), so a three-word
+// capture doesn't swallow every paragraph that uses those words.
+func overlap(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	common := 0
+	for w := range a {
+		if b[w] {
+			common++
+		}
+	}
+	if len(a) < overlapMinWords {
+		return float64(common) / float64(len(a)+len(b)-common)
+	}
+	return float64(common) / float64(len(a))
+}
+
+func init() {
+	rootCmd.AddCommand(ingestSessionCmd)
+	ingestSessionCmd.Flags().StringVar(&ingestThread, "thread", "", "add ingested insights to this thread")
+	ingestSessionCmd.Flags().BoolVar(&ingestHook, "hook", false, "read the transcript path from a Claude Code hook event on stdin")
+	ingestSessionCmd.Flags().BoolVar(&ingestDryRun, "dry-run", false, "preview without saving")
+	ingestSessionCmd.Flags().BoolVarP(&ingestQuiet, "quiet", "q", false, "suppress output")
+}
@@ -9,6 +9,8 @@ import (
 	"strings"
 
 	gh "github.com/brianevanmiller/beadcrumbs/internal/github"
+	"github.com/brianevanmiller/beadcrumbs/internal/gitsync"
+	importer "github.com/brianevanmiller/beadcrumbs/internal/import"
 	"github.com/brianevanmiller/beadcrumbs/internal/linear"
 	"github.com/brianevanmiller/beadcrumbs/internal/store"
 	"github.com/spf13/cobra"
@@ -16,6 +18,7 @@ import (
 
 var initStealth bool
 var initQuiet bool
+var initSyncBranch string
 
 var initCmd = &cobra.Command{
 	Use:   "init",
@@ -23,10 +26,27 @@ var initCmd = &cobra.Command{
 	Long: `Creates the .beadcrumbs directory and initializes the database and JSONL files.
 
 Use --stealth for local-only installation that won't appear in git status.
-This is useful when you want to use beadcrumbs without committing files to the repo.`,
+This is useful when you want to use beadcrumbs without committing files to the repo.
+
+Use --sync-branch <name> to keep JSONL exports out of your branches: they are
+committed to <name> through a worktree under .git/beadcrumbs-worktrees/, and
+'bdc sync' merges and pushes that branch. If the branch already exists (locally
+or on origin), its insights are imported into the new database.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		dir := filepath.Dir(dbPath)
 
+		if initSyncBranch != "" {
+			if initStealth {
+				return fmt.Errorf("--stealth and --sync-branch cannot be combined")
+			}
+			if !isGitRepo() {
+				return fmt.Errorf("--sync-branch requires a git repository")
+			}
+			if !(gitsync.Repo{}).IsBranchName(initSyncBranch) {
+				return fmt.Errorf("invalid branch name %q", initSyncBranch)
+			}
+		}
+
 		// Create .beadcrumbs directory
 		if err := os.MkdirAll(dir, 0755); err != nil {
 			return fmt.Errorf("failed to create directory %s: %w", dir, err)
@@ -59,6 +79,14 @@ This is useful when you want to use beadcrumbs without committing files to the r
 			}
 		}
 
+		// Set up sync-branch mode if requested
+		if initSyncBranch != "" {
+			if err := setupSyncBranchMode(s); err != nil {
+				s.Close()
+				return fmt.Errorf("failed to set up sync branch: %w", err)
+			}
+		}
+
 		s.Close()
 
 		// Create empty JSONL files (skip if they already exist, e.g., from git)
@@ -68,6 +96,10 @@ This is useful when you want to use beadcrumbs without committing files to the r
 			filepath.Join(dir, "deps.jsonl"),
 		}
 
+		if initSyncBranch != "" {
+			jsonlFiles = nil // They live on the sync branch
+		}
+
 		for _, file := range jsonlFiles {
 			if _, err := os.Stat(file); err == nil {
 				continue // Don't overwrite existing JSONL files
@@ -79,8 +111,8 @@ This is useful when you want to use beadcrumbs without committing files to the r
 			f.Close()
 		}
 
-		// Add .gitignore entries (non-stealth mode only)
-		if !initStealth {
+		// Add .gitignore entries (normal mode only)
+		if !initStealth && initSyncBranch == "" {
 			if err := addGitignoreEntries(); err != nil {
 				if !initQuiet {
 					fmt.Printf("Warning: failed to update .gitignore: %v\n", err)
@@ -92,7 +124,11 @@ This is useful when you want to use beadcrumbs without committing files to the r
 
 		// Install git hooks (non-stealth mode only)
 		if !initStealth {
-			if err := installGitHooks(); err != nil {
+			install := installGitHooks
+			if initSyncBranch != "" {
+				install = installSyncBranchHooks
+			}
+			if err := install(); err != nil {
 				if !initQuiet {
 					fmt.Printf("Warning: failed to install git hooks: %v\n", err)
 					fmt.Println("Git hooks are optional - beadcrumbs will work without them")
@@ -168,6 +204,36 @@ func setupStealthMode() error {
 	return nil
 }
 
+// setupSyncBranchMode records the sync branch, hides .beadcrumbs/ from the
+// project's branches and checks the sync branch out into its worktree,
+// importing whatever it already holds.
+func setupSyncBranchMode(s *store.Store) error {
+	if err := setupStealthMode(); err != nil {
+		return err
+	}
+	if err := s.SetConfig(syncBranchConfigKey, initSyncBranch); err != nil {
+		return fmt.Errorf("failed to save sync branch config: %w", err)
+	}
+
+	wt, err := ensureSyncWorktree(gitsync.Repo{}, initSyncBranch, "origin")
+	if err != nil {
+		return err
+	}
+	imported, err := importSyncBranch(s, wt)
+	if err != nil {
+		return fmt.Errorf("failed to import %s: %w", initSyncBranch, err)
+	}
+
+	if !initQuiet {
+		fmt.Printf("Sync-branch mode enabled - insights are committed to branch %s\n", initSyncBranch)
+		fmt.Printf("  Worktree: %s\n", wt.Dir)
+		if imported > 0 {
+			fmt.Printf("  Imported %d records from %s\n", imported, initSyncBranch)
+		}
+	}
+	return nil
+}
+
 // getGitDir returns the path to the .git directory.
 // Uses git rev-parse --git-common-dir to handle worktrees correctly.
 func getGitDir() (string, error) {
@@ -193,6 +259,22 @@ func containsExactPattern(content, pattern string) bool {
 
 // installGitHooks installs git hooks for beadcrumbs.
 func installGitHooks() error {
+	return writeGitHooks(normalHooks)
+}
+
+// installSyncBranchHooks replaces any beadcrumbs hooks with the sync-branch
+// mode ones, which commit to the sync branch instead of staging JSONL in the
+// project's commits.
+func installSyncBranchHooks() error {
+	if _, err := removeBeadcrumbsHooks(); err != nil {
+		return err
+	}
+	return writeGitHooks(syncBranchHooks)
+}
+
+// writeGitHooks adds each hook script to .git/hooks, appending to existing
+// hooks and skipping hooks that already contain a beadcrumbs section.
+func writeGitHooks(hooks map[string]string) error {
 	gitDir, err := getGitDir()
 	if err != nil {
 		return fmt.Errorf("not a git repository: %w", err)
@@ -203,9 +285,51 @@ func installGitHooks() error {
 		return fmt.Errorf("failed to create hooks directory: %w", err)
 	}
 
-	// Define hooks to install
-	hooks := map[string]string{
-		"pre-commit": `#!/bin/sh
+	for hookName, hookContent := range hooks {
+		hookPath := filepath.Join(hooksDir, hookName)
+
+		// Check if hook already exists
+		if _, err := os.Stat(hookPath); err == nil {
+			// Read existing hook
+			existing, err := os.ReadFile(hookPath)
+			if err != nil {
+				return fmt.Errorf("failed to read existing %s hook: %w", hookName, err)
+			}
+
+			// Check if our hook is already there
+			if strings.Contains(string(existing), "beadcrumbs") {
+				continue // Already installed
+			}
+
+			// Append to existing hook
+			hookContent = string(existing) + "\n" + hookContent
+		}
+
+		// Write hook
+		if err := os.WriteFile(hookPath, []byte(hookContent), 0755); err != nil {
+			return fmt.Errorf("failed to write %s hook: %w", hookName, err)
+		}
+	}
+
+	return nil
+}
+
+// prepareCommitMsgHook proposes Insight-Refs trailers; it is the same in
+// every mode that installs hooks.
+const prepareCommitMsgHook = `#!/bin/sh
+# beadcrumbs: Propose Insight-Refs trailers for insights captured since the last commit
+# Skips merges, squashes and amends; remove the trailer in the editor to opt out
+case "$2" in
+    merge|squash|commit) exit 0 ;;
+esac
+if command -v bdc >/dev/null 2>&1; then
+    bdc commit-msg "$1" "$2" 2>/dev/null || true
+fi
+`
+
+// normalHooks keep .beadcrumbs/ JSONL committed alongside the project.
+var normalHooks = map[string]string{
+	"pre-commit": `#!/bin/sh
 # beadcrumbs: Export and stage JSONL before commit
 # Ensures insights are always in sync with the commit
 if command -v bdc >/dev/null 2>&1; then
@@ -219,21 +343,22 @@ if command -v bdc >/dev/null 2>&1; then
     done
 fi
 `,
-		"post-commit": `#!/bin/sh
+	"post-commit": `#!/bin/sh
 # beadcrumbs: Export insights after commit
 # This hook exports local insights to JSONL for version control
 if command -v bdc >/dev/null 2>&1; then
     bdc export --quiet 2>/dev/null || true
 fi
 `,
-		"post-merge": `#!/bin/sh
+	"prepare-commit-msg": prepareCommitMsgHook,
+	"post-merge": `#!/bin/sh
 # beadcrumbs: Import insights after merge/pull
 # This hook imports JSONL changes from other collaborators
 if command -v bdc >/dev/null 2>&1; then
     bdc import --auto --quiet 2>/dev/null || true
 fi
 `,
-		"post-checkout": `#!/bin/sh
+	"post-checkout": `#!/bin/sh
 # beadcrumbs: Auto-bootstrap and import insights after checkout
 # Creates .beadcrumbs in new worktrees and imports JSONL changes
 if command -v bdc >/dev/null 2>&1; then
@@ -243,38 +368,35 @@ if command -v bdc >/dev/null 2>&1; then
     bdc import --auto --quiet 2>/dev/null || true
 fi
 `,
-	}
-
-	for hookName, hookContent := range hooks {
-		hookPath := filepath.Join(hooksDir, hookName)
-
-		// Check if hook already exists
-		if _, err := os.Stat(hookPath); err == nil {
-			// Read existing hook
-			existing, err := os.ReadFile(hookPath)
-			if err != nil {
-				return fmt.Errorf("failed to read existing %s hook: %w", hookName, err)
-			}
-
-			// Check if our hook is already there
-			if strings.Contains(string(existing), "beadcrumbs") {
-				continue // Already installed
-			}
-
-			// Append to existing hook
-			hookContent = string(existing) + "\n" + hookContent
-		}
-
-		// Write hook
-		if err := os.WriteFile(hookPath, []byte(hookContent), 0755); err != nil {
-			return fmt.Errorf("failed to write %s hook: %w", hookName, err)
-		}
-	}
+}
 
-	return nil
+// syncBranchHooks commit the database to the sync branch after commits and
+// merge the collaborators' copy fetched by git pull. Nothing is staged in the
+// project's own commits.
+var syncBranchHooks = map[string]string{
+	"prepare-commit-msg": prepareCommitMsgHook,
+	"post-commit": `#!/bin/sh
+# beadcrumbs: Commit insights to the sync branch after each commit
+# Exports go to the sync branch worktree, never to the current branch
+if command -v bdc >/dev/null 2>&1; then
+    bdc sync --local --quiet 2>/dev/null || true
+fi
+`,
+	"post-merge": `#!/bin/sh
+# beadcrumbs: Merge the sync branch fetched by pull and import insights
+# Run 'bdc sync' to fetch and push the sync branch explicitly
+if command -v bdc >/dev/null 2>&1; then
+    bdc sync --local --quiet 2>/dev/null || true
+fi
+`,
 }
 
-// addGitignoreEntries adds .gitignore entries to track JSONL but ignore SQLite.
+// extractCachePattern ignores the cache of LLM extraction responses, which
+// can hold verbatim transcript text.
+const extractCachePattern = ".beadcrumbs/" + importer.ExtractCacheDirName + "/"
+
+// addGitignoreEntries adds .gitignore entries to track JSONL but ignore SQLite
+// and the extraction cache.
 func addGitignoreEntries() error {
 	gitignorePath := ".gitignore"
 
@@ -283,13 +405,14 @@ func addGitignoreEntries() error {
 		existingContent = string(content)
 	}
 
-	// Skip if beadcrumbs DB is already ignored or entire dir is ignored
-	if strings.Contains(existingContent, "beadcrumbs.db") ||
-		containsExactPattern(existingContent, ".beadcrumbs/") ||
+	// Skip if the entire dir is ignored
+	if containsExactPattern(existingContent, ".beadcrumbs/") ||
 		containsExactPattern(existingContent, ".beadcrumbs") {
 		return nil
 	}
 
+	cacheEntry := `# Beadcrumbs LLM extraction cache (raw model responses, local only)
+` + extractCachePattern + "\n"
 	entries := `
 # Beadcrumbs database (ephemeral, rebuilt from JSONL)
 .beadcrumbs/beadcrumbs.db
@@ -298,7 +421,15 @@ func addGitignoreEntries() error {
 .beadcrumbs/beadcrumbs.db-shm
 # Beadcrumbs origin file (session-local, not for version control)
 .beadcrumbs/origin
-`
+` + cacheEntry
+
+	// Older installs ignore the database but not the extraction cache
+	if strings.Contains(existingContent, "beadcrumbs.db") {
+		if containsExactPattern(existingContent, extractCachePattern) {
+			return nil
+		}
+		entries = cacheEntry
+	}
 
 	f, err := os.OpenFile(gitignorePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
@@ -374,4 +505,5 @@ func init() {
 	rootCmd.AddCommand(initCmd)
 	initCmd.Flags().BoolVar(&initStealth, "stealth", false, "local-only installation (uses .git/info/exclude)")
 	initCmd.Flags().BoolVar(&initQuiet, "quiet", false, "suppress output (for hooks and automation)")
+	initCmd.Flags().StringVar(&initSyncBranch, "sync-branch", "", "commit JSONL exports to this branch (via an internal worktree) instead of the current branch")
 }
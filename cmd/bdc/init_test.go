@@ -68,6 +68,9 @@ func TestAddGitignoreEntries(t *testing.T) {
 	if !strings.Contains(s, ".beadcrumbs/origin") {
 		t.Error(".gitignore missing .beadcrumbs/origin entry")
 	}
+	if !strings.Contains(s, ".beadcrumbs/extract-cache/") {
+		t.Error(".gitignore missing .beadcrumbs/extract-cache/ entry")
+	}
 }
 
 func TestAddGitignoreEntries_Idempotent(t *testing.T) {
@@ -129,8 +132,17 @@ func TestAddGitignoreEntries_SkipsExisting(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	// Should not have added new entries since beadcrumbs.db is already present
-	if string(content) != existing {
+	// Only the extraction cache should be added since beadcrumbs.db is already present
+	want := existing + "# Beadcrumbs LLM extraction cache (raw model responses, local only)\n.beadcrumbs/extract-cache/\n"
+	if string(content) != want {
+		t.Errorf("expected only the cache entry added, got:\n%s", string(content))
+	}
+
+	// Nothing more to add
+	if err := addGitignoreEntries(); err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := os.ReadFile(".gitignore"); string(content) != want {
 		t.Errorf("expected .gitignore unchanged, got:\n%s", string(content))
 	}
 }
@@ -209,6 +209,8 @@ var linearPushCmd = &cobra.Command{
 		}
 		defer closeStore()
 
+		threadID = redirectThreadID(s, threadID)
+
 		thread, err := s.GetThread(threadID)
 		if err != nil {
 			return fmt.Errorf("failed to get thread: %w", err)
@@ -244,11 +246,12 @@ var linearPushCmd = &cobra.Command{
 		if err != nil {
 			return fmt.Errorf("failed to get insights: %w", err)
 		}
-		if len(insights) == 0 {
+		children := childSummaries(s, threadID)
+		if len(insights) == 0 && len(children) == 0 {
 			return fmt.Errorf("no insights in thread %s", threadID)
 		}
 
-		body := summary.FormatSummary(thread, insights)
+		body := summary.FormatRollupSummary(thread, insights, children)
 		if err := adapter.AddComment(linearMapping.ExternalID, body); err != nil {
 			return fmt.Errorf("failed to post comment: %w", err)
 		}
@@ -272,6 +275,8 @@ var linearLinkCmd = &cobra.Command{
 		}
 		defer closeStore()
 
+		threadID = redirectThreadID(s, threadID)
+
 		// Verify thread exists
 		thread, err := s.GetThread(threadID)
 		if err != nil {
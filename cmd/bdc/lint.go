@@ -0,0 +1,229 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/lint"
+	"github.com/brianevanmiller/beadcrumbs/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	lintFix        bool
+	lintStrict     bool
+	lintConfigPath string
+	lintListRules  bool
+)
+
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Check the insight graph against quality rules",
+	Long: `Run quality rules over threads, insights, and dependencies.
+
+Rules can be tuned in .beadcrumbs/lint.yaml:
+
+  version: 1
+  rules:
+    concluded-without-decision:
+      severity: error        # error | warning | off
+    orphan-insight:
+      ignore: [ins-7f2a]     # suppress specific findings
+
+Use --fix to apply mechanical repairs (adding a supersedes edge for a pivot,
+assigning an orphan insight to its origin's only thread, removing edges to
+deleted insights). Findings that need judgement are only reported.
+
+Exit codes:
+  0  no errors (warnings allowed unless --strict)
+  1  one or more errors (or warnings with --strict)
+  2  lint could not run (bad config, database error)
+
+Examples:
+  bdc lint                     # Report findings
+  bdc lint --fix               # Apply mechanical fixes
+  bdc lint --json --strict     # CI / pre-commit gate
+  bdc lint --rules             # List available rules`,
+	RunE: runLint,
+}
+
+// lintReport is the JSON shape of a lint run.
+type lintReport struct {
+	Findings []lint.Finding `json:"findings"`
+	Errors   int            `json:"errors"`
+	Warnings int            `json:"warnings"`
+	Fixed    int            `json:"fixed"`
+}
+
+func runLint(cmd *cobra.Command, args []string) error {
+	if lintListRules {
+		return printLintRules()
+	}
+
+	configPath := lintConfigPath
+	if configPath == "" {
+		configPath = filepath.Join(filepath.Dir(dbPath), lint.ConfigFileName)
+	}
+	cfg, err := lint.LoadConfig(configPath)
+	if err != nil {
+		return &exitCodeError{code: 2, err: err}
+	}
+
+	var s store.Storage
+	if lintFix {
+		s, err = getStore()
+	} else {
+		s, err = getReadOnlyStore()
+	}
+	if err != nil {
+		return &exitCodeError{code: 2, err: err}
+	}
+	defer closeStore()
+
+	g, err := loadLintGraph(s)
+	if err != nil {
+		return &exitCodeError{code: 2, err: err}
+	}
+
+	findings := lint.Run(g, cfg)
+
+	fixed := 0
+	if lintFix {
+		for i := range findings {
+			if findings[i].Fix == nil {
+				continue
+			}
+			if err := applyLintFix(s, findings[i].Fix); err != nil {
+				if !jsonOutput {
+					fmt.Printf("Warning: could not fix %s (%s): %v\n", findings[i].Subject, findings[i].Rule, err)
+				}
+				continue
+			}
+			findings[i].Fixed = true
+			fixed++
+		}
+	}
+
+	errCount, warnCount := lint.Counts(findings)
+
+	if jsonOutput {
+		report := lintReport{Findings: findings, Errors: errCount, Warnings: warnCount, Fixed: fixed}
+		if report.Findings == nil {
+			report.Findings = []lint.Finding{}
+		}
+		out, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return &exitCodeError{code: 2, err: fmt.Errorf("failed to marshal JSON: %w", err)}
+		}
+		fmt.Println(string(out))
+	} else {
+		printLintFindings(findings, errCount, warnCount, fixed)
+	}
+
+	if errCount > 0 || (lintStrict && warnCount > 0) {
+		return &exitCodeError{code: 1, err: fmt.Errorf("lint failed: %d error(s), %d warning(s)", errCount, warnCount)}
+	}
+	return nil
+}
+
+// loadLintGraph snapshots all threads, insights, and dependencies.
+func loadLintGraph(s store.Storage) (*lint.Graph, error) {
+	threads, err := s.ListThreads("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list threads: %w", err)
+	}
+	insights, err := s.ListInsights("", "", time.Time{}, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list insights: %w", err)
+	}
+	deps, err := s.ListAllDependencies()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dependencies: %w", err)
+	}
+	return lint.NewGraph(threads, insights, deps), nil
+}
+
+// applyLintFix performs the store write described by a fix.
+func applyLintFix(s store.Storage, fix *lint.Fix) error {
+	switch {
+	case fix.AddDep != nil:
+		return s.AddDependency(fix.AddDep)
+	case fix.RemoveDep != nil:
+		return s.RemoveDependency(fix.RemoveDep.From, fix.RemoveDep.To, fix.RemoveDep.Type)
+	case fix.InsightID != "" && fix.ThreadID != "":
+		insight, err := s.GetInsight(fix.InsightID)
+		if err != nil {
+			return err
+		}
+		insight.ThreadID = fix.ThreadID
+		return s.UpdateInsight(insight)
+	default:
+		return fmt.Errorf("empty fix")
+	}
+}
+
+func printLintFindings(findings []lint.Finding, errCount, warnCount, fixed int) {
+	if len(findings) == 0 {
+		fmt.Println("No lint findings")
+		return
+	}
+
+	fixable := 0
+	for _, f := range findings {
+		status := string(f.Severity)
+		if f.Fixed {
+			status = "fixed"
+		}
+		fmt.Printf("%-8s %-28s %s\n", status, f.Rule, f.Subject)
+		fmt.Printf("         %s\n", f.Message)
+		if f.Fix != nil && !f.Fixed {
+			fixable++
+			fmt.Printf("         fix: %s\n", f.Fix.Description)
+		}
+	}
+
+	fmt.Printf("\n%d error(s), %d warning(s)", errCount, warnCount)
+	if fixed > 0 {
+		fmt.Printf(", %d fixed", fixed)
+	}
+	if fixable > 0 {
+		fmt.Printf(", %d fixable with --fix", fixable)
+	}
+	fmt.Println()
+}
+
+func printLintRules() error {
+	rules := lint.Rules()
+	if jsonOutput {
+		type ruleJSON struct {
+			ID       string        `json:"id"`
+			Severity lint.Severity `json:"default_severity"`
+			Summary  string        `json:"description"`
+		}
+		out := make([]ruleJSON, len(rules))
+		for i, r := range rules {
+			out[i] = ruleJSON{ID: r.ID, Severity: r.DefaultSeverity, Summary: r.Description}
+		}
+		data, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	for _, r := range rules {
+		fmt.Printf("%-28s %-8s %s\n", r.ID, r.DefaultSeverity, r.Description)
+	}
+	return nil
+}
+
+func init() {
+	lintCmd.Flags().BoolVar(&lintFix, "fix", false, "apply mechanical fixes")
+	lintCmd.Flags().BoolVar(&lintStrict, "strict", false, "treat warnings as errors for the exit code")
+	lintCmd.Flags().StringVar(&lintConfigPath, "config", "", "path to lint config (default .beadcrumbs/lint.yaml)")
+	lintCmd.Flags().BoolVar(&lintListRules, "rules", false, "list available rules and exit")
+	rootCmd.AddCommand(lintCmd)
+}
@@ -10,11 +10,12 @@ import (
 )
 
 var (
-	listThreadID string
-	listType     string
-	listSince    string
-	listAuthor   string
-	listOrigin   string
+	listThreadID  string
+	listType      string
+	listSince     string
+	listAuthor    string
+	listOrigin    string
+	listGitFilter gitFilter
 )
 
 var listCmd = &cobra.Command{
@@ -87,6 +88,8 @@ Examples:
 			}
 		}
 
+		insights = listGitFilter.apply(insights)
+
 		if len(insights) == 0 {
 			if jsonOutput {
 				fmt.Println("[]")
@@ -196,4 +199,5 @@ func init() {
 	listCmd.Flags().StringVar(&listSince, "since", "", "show insights since (e.g., 1w, 2d, 3h)")
 	listCmd.Flags().StringVar(&listAuthor, "author", "", "filter by author (exact match)")
 	listCmd.Flags().StringVar(&listOrigin, "origin", "", "filter by origin (exact match)")
+	listGitFilter.register(listCmd)
 }
@@ -1,11 +1,28 @@
 package main
 
 import (
+	"errors"
 	"os"
 )
 
+// exitCodeError lets a command choose a specific process exit code.
+// Commands used as CI gates (e.g., lint) return it to distinguish
+// "checks failed" from "could not run".
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e *exitCodeError) Error() string { return e.err.Error() }
+func (e *exitCodeError) Unwrap() error { return e.err }
+
 func main() {
+	preloadCustomTypes(os.Args[1:])
 	if err := rootCmd.Execute(); err != nil {
+		var exitErr *exitCodeError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code)
+		}
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var mvThread string
+
+var mvCmd = &cobra.Command{
+	Use:   "mv <insight-id>... --thread <thread>",
+	Short: "Move insights to another thread",
+	Long: `Reassign one or more insights to a thread.
+
+--thread accepts the same references as capture: a thread ID, a bead ID, or an
+external ref such as linear:ENG-456. Insight IDs and dependency edges are
+unchanged.
+
+Examples:
+  bdc mv ins-a1b2 --thread thr-c3d4
+  bdc mv ins-a1b2 ins-e5f6 --thread bd-x7y8`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if mvThread == "" {
+			return fmt.Errorf("--thread is required")
+		}
+
+		s, err := getStore()
+		if err != nil {
+			return err
+		}
+		defer closeStore()
+
+		threadID, err := resolveThreadRef(mvThread)
+		if err != nil {
+			return fmt.Errorf("failed to resolve thread reference: %w", err)
+		}
+		thread, err := s.GetThread(threadID)
+		if err != nil {
+			return fmt.Errorf("failed to get thread: %w", err)
+		}
+
+		for _, id := range args {
+			if _, err := s.GetInsight(id); err != nil {
+				return fmt.Errorf("failed to get insight: %w", err)
+			}
+		}
+
+		if err := s.MoveInsights(args, thread.ID); err != nil {
+			return fmt.Errorf("failed to move insights: %w", err)
+		}
+
+		if jsonOutput {
+			result := map[string]interface{}{
+				"thread":   thread.ID,
+				"insights": args,
+			}
+			out, err := json.MarshalIndent(result, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal JSON: %w", err)
+			}
+			fmt.Println(string(out))
+			return nil
+		}
+
+		fmt.Printf("Moved %d insight(s) to %s\n", len(args), thread.ID)
+		return nil
+	},
+}
+
+func init() {
+	mvCmd.Flags().StringVar(&mvThread, "thread", "", "thread/bead/external ref to move insights to")
+	rootCmd.AddCommand(mvCmd)
+}
@@ -10,6 +10,7 @@ import (
 )
 
 var pivotsOrigin string
+var pivotsGitFilter gitFilter
 
 var pivotsCmd = &cobra.Command{
 	Use:   "pivots [thread-id]",
@@ -35,7 +36,7 @@ func runPivots(cmd *cobra.Command, args []string) error {
 
 	var threadID string
 	if len(args) > 0 {
-		threadID = args[0]
+		threadID = redirectThreadID(st, args[0])
 	}
 
 	// Get pivot insights
@@ -43,6 +44,7 @@ func runPivots(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to list pivots: %w", err)
 	}
+	insights = pivotsGitFilter.apply(insights)
 
 	if len(insights) == 0 {
 		if jsonOutput {
@@ -89,4 +91,5 @@ func runPivots(cmd *cobra.Command, args []string) error {
 func init() {
 	rootCmd.AddCommand(pivotsCmd)
 	pivotsCmd.Flags().StringVar(&pivotsOrigin, "origin", "", "filter by origin (exact match)")
+	pivotsGitFilter.register(pivotsCmd)
 }
@@ -1,17 +1,32 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/brianevanmiller/beadcrumbs/internal/agentctx"
+	"github.com/brianevanmiller/beadcrumbs/internal/store"
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
 	"github.com/spf13/cobra"
 )
 
-var primeExportMode bool
+var (
+	primeExportMode bool
+	primeBudget     int
+)
+
+// primeMaxEntries caps each state section before the token budget applies.
+const primeMaxEntries = 10
+
+// primeDefaultTemplate is the default output: the workflow guide followed by
+// the live state.
+const primeDefaultTemplate = "{{workflow}}\n{{state}}"
 
 var primeCmd = &cobra.Command{
 	Use:   "prime",
@@ -24,8 +39,25 @@ beadcrumbs workflow instructions into AI agent context automatically.
 When .beadcrumbs/ is not found, exits silently (exit 0, no output).
 This enables safe cross-project hook integration.
 
-Place a .beadcrumbs/PRIME.md file to override the default output entirely.
-Use --export to dump the default content for customization.`,
+After the workflow guide comes the current state, in ranked order and with
+a fixed layout (one "## " heading per section, "- (none)" when empty):
+
+  ## Active Threads              threads and their current understanding
+  ## Recent Pivots & Decisions   newest first, superseded ones left out
+  ## Unresolved Questions        newest first
+  ## Origin                      the origin captures are attributed to
+
+--budget caps the state at roughly that many tokens (0 for no limit); the
+workflow guide is not counted. --json outputs the sections as JSON.
+
+Place a .beadcrumbs/PRIME.md file to override the default output. It is
+printed as-is, except for these placeholders:
+
+  {{workflow}}   the default workflow guide
+  {{state}}      all state sections
+  {{threads}}, {{recent}}, {{questions}}, {{origin}}   one section each
+
+Use --export to dump the default template for customization.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		bcDir := findBeadcrumbsDir()
 		if bcDir == "" {
@@ -39,20 +71,135 @@ Use --export to dump the default content for customization.`,
 			os.Exit(0)
 		}
 
-		// Check for custom PRIME.md override (unless --export flag)
-		if !primeExportMode {
-			primePath := filepath.Join(bcDir, "PRIME.md")
-			if content, err := os.ReadFile(primePath); err == nil {
-				fmt.Print(string(content))
-				return
+		if primeExportMode {
+			var guide strings.Builder
+			outputPrimeContext(&guide)
+			fmt.Println(strings.Replace(primeDefaultTemplate, "{{workflow}}", guide.String(), 1))
+			return
+		}
+
+		// Check for custom PRIME.md override
+		template := primeDefaultTemplate
+		if content, err := os.ReadFile(filepath.Join(bcDir, "PRIME.md")); err == nil {
+			template = string(content)
+		}
+
+		sections := agentctx.Placeholders(template)
+		if jsonOutput {
+			sections = agentctx.Placeholders("{{state}}")
+		}
+		state := &agentctx.State{Budget: primeBudget}
+		if len(sections) > 0 {
+			// The state is best-effort: prime runs from session hooks and must
+			// still deliver the guide when the database can't be read
+			if s, err := getReadOnlyStore(); err == nil {
+				state, err = buildPrimeState(s, primeBudget)
+				closeStore()
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+					state = &agentctx.State{Budget: primeBudget}
+				}
+				state.Fit(sections)
+			}
+		}
+
+		if jsonOutput {
+			if state.Sections == nil {
+				state.Sections = []agentctx.Section{}
+			}
+			out, err := json.MarshalIndent(state, "", "  ")
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: failed to marshal JSON: %v\n", err)
+				os.Exit(1)
 			}
+			fmt.Println(string(out))
+			return
 		}
 
-		// Output default workflow context
-		outputPrimeContext(os.Stdout)
+		var guide strings.Builder
+		if strings.Contains(template, "{{workflow}}") {
+			outputPrimeContext(&guide)
+		}
+		fmt.Print(state.Expand(template, guide.String()))
 	},
 }
 
+// buildPrimeState collects the live state prime injects, each section
+// ranked and capped at primeMaxEntries.
+func buildPrimeState(s store.Storage, budget int) (*agentctx.State, error) {
+	state := &agentctx.State{Budget: budget}
+	add := func(name string, entries []agentctx.Entry) {
+		if len(entries) > primeMaxEntries {
+			entries = entries[:primeMaxEntries]
+		}
+		if entries == nil {
+			entries = []agentctx.Entry{}
+		}
+		state.Sections = append(state.Sections, agentctx.Section{
+			Name: name, Title: agentctx.SectionTitles[name], Entries: entries,
+		})
+	}
+
+	// Active threads, most recently updated first
+	threads, err := s.ListThreads(types.ThreadActive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list threads: %w", err)
+	}
+	var entries []agentctx.Entry
+	for _, t := range threads {
+		text := t.Title
+		if t.CurrentUnderstanding != "" {
+			text += " -- understanding: " + t.CurrentUnderstanding
+		}
+		entries = append(entries, agentctx.Entry{ID: t.ID, Kind: "thread", Text: text})
+	}
+	add(agentctx.SectionThreads, entries)
+
+	// Pivots and decisions still in force, newest first
+	all, err := s.ListInsights("", "", time.Time{}, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list insights: %w", err)
+	}
+	var recent, questions []*types.Insight
+	for _, ins := range all {
+		switch ins.Type {
+		case types.InsightPivot, types.InsightDecision:
+			recent = append(recent, ins)
+		case types.InsightQuestion:
+			questions = append(questions, ins)
+		}
+	}
+	for _, sec := range []struct {
+		name     string
+		insights []*types.Insight
+	}{{agentctx.SectionRecent, recent}, {agentctx.SectionQuestions, questions}} {
+		unresolved, err := filterUnresolved(s, sec.insights)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check superseded insights: %w", err)
+		}
+		entries = nil
+		for _, ins := range unresolved {
+			text := ins.Summary
+			if text == "" {
+				text = ins.Content
+			}
+			entries = append(entries, agentctx.Entry{ID: ins.ID, Kind: string(ins.Type), Text: text})
+		}
+		add(sec.name, entries)
+	}
+
+	entries = nil
+	origin := strings.TrimSpace(os.Getenv("BDC_ORIGIN"))
+	if origin == "" {
+		origin = readOriginFile()
+	}
+	if origin != "" {
+		entries = append(entries, agentctx.Entry{Text: origin})
+	}
+	add(agentctx.SectionOrigin, entries)
+	return state, nil
+}
+
 // repoTracksBeadcrumbs checks if the current git repo tracks .beadcrumbs/ files.
 // This detects cloned repos that use beadcrumbs but haven't been initialized locally.
 func repoTracksBeadcrumbs() bool {
@@ -207,6 +354,7 @@ bdc questions --unresolved
 }
 
 func init() {
-	primeCmd.Flags().BoolVar(&primeExportMode, "export", false, "Output default content (ignores PRIME.md override)")
+	primeCmd.Flags().BoolVar(&primeExportMode, "export", false, "Output the default template (ignores PRIME.md override)")
+	primeCmd.Flags().IntVar(&primeBudget, "budget", agentctx.DefaultPrimeBudget, "maximum size of the state sections in tokens (approximate, 0 for no limit)")
 	rootCmd.AddCommand(primeCmd)
 }
@@ -10,8 +10,9 @@ import (
 )
 
 var (
-	unresolvedOnly  bool
-	questionsOrigin string
+	unresolvedOnly     bool
+	questionsOrigin    string
+	questionsGitFilter gitFilter
 )
 
 var questionsCmd = &cobra.Command{
@@ -37,6 +38,7 @@ func init() {
 	rootCmd.AddCommand(questionsCmd)
 	questionsCmd.Flags().BoolVar(&unresolvedOnly, "unresolved", false, "Show only unresolved questions")
 	questionsCmd.Flags().StringVar(&questionsOrigin, "origin", "", "filter by origin (exact match)")
+	questionsGitFilter.register(questionsCmd)
 }
 
 func runQuestions(cmd *cobra.Command, args []string) error {
@@ -48,7 +50,7 @@ func runQuestions(cmd *cobra.Command, args []string) error {
 
 	var threadID string
 	if len(args) > 0 {
-		threadID = args[0]
+		threadID = redirectThreadID(st, args[0])
 	}
 
 	// Get question insights
@@ -56,6 +58,7 @@ func runQuestions(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to list questions: %w", err)
 	}
+	insights = questionsGitFilter.apply(insights)
 
 	// Filter to unresolved if requested
 	if unresolvedOnly {
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/lint"
+	"github.com/brianevanmiller/beadcrumbs/internal/similar"
+	"github.com/brianevanmiller/beadcrumbs/internal/store"
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	relatedLimit      int
+	relatedSameThread bool
+)
+
+var relatedCmd = &cobra.Command{
+	Use:   "related <insight-id | text>",
+	Short: "Find insights related to an insight or text",
+	Long: `Find past insights about the same thing as an insight or a piece of text,
+most relevant first, such as the last time a Redis issue came up.
+
+Insights sharing words with the query are found with the full-text index
+and scored by it with BM25 over their content and summary, so words shared
+with few other insights count for more than common ones. The index is kept
+up to date as insights are captured, imported, edited or deleted.
+
+Given an insight ID, insights from its own thread are left out (see
+--same-thread), as are insights merged into another by 'bdc dedupe'.
+
+Examples:
+  bdc related ins-a1b2
+  bdc related "redis connection timeouts"
+  bdc related ins-a1b2 --same-thread --limit 5
+  bdc related ins-a1b2 --json`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := getReadOnlyStore()
+		if err != nil {
+			return err
+		}
+		defer closeStore()
+
+		var source *types.Insight
+		query := strings.Join(args, " ")
+		if len(args) == 1 && strings.HasPrefix(args[0], "ins-") {
+			source, err = s.GetInsight(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to get insight: %w", err)
+			}
+			query = source.Content + "\n" + source.Summary
+		}
+
+		merged, err := mergedInsightIDs(s)
+		if err != nil {
+			return err
+		}
+		ranked, err := searchInsights(s, query, "", 0)
+		if err != nil {
+			return err
+		}
+
+		related := []*store.ScoredInsight{}
+		for _, r := range ranked {
+			if merged[r.ID] {
+				continue
+			}
+			if source != nil {
+				if r.ID == source.ID {
+					continue
+				}
+				if !relatedSameThread && source.ThreadID != "" && r.ThreadID == source.ThreadID {
+					continue
+				}
+			}
+			related = append(related, r)
+			if relatedLimit > 0 && len(related) == relatedLimit {
+				break
+			}
+		}
+
+		if jsonOutput {
+			out, err := json.MarshalIndent(related, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal JSON: %w", err)
+			}
+			fmt.Println(string(out))
+			return nil
+		}
+
+		if source != nil {
+			fmt.Printf("Related to %s: %s\n", source.ID, truncate(source.Content, 60))
+		}
+		if len(related) == 0 {
+			fmt.Println("No related insights found.")
+			return nil
+		}
+		for _, r := range related {
+			thread := r.ThreadID
+			if thread == "" {
+				thread = "-"
+			}
+			fmt.Printf("  %5.2f  %s  %-12s %-9s %s\n", r.Score, r.ID, "["+string(r.Type)+"]", thread, truncate(r.Content, 60))
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(relatedCmd)
+
+	relatedCmd.Flags().IntVar(&relatedLimit, "limit", 10, "maximum number of insights to show (0 for all)")
+	relatedCmd.Flags().BoolVar(&relatedSameThread, "same-thread", false, "include insights from the insight's own thread")
+}
+
+// searchInsights finds the insights containing any word of query with the
+// FTS5 index, best match first, each with its BM25 score from the same
+// index. Pass a threadID to search only that thread, and a limit of 0 for
+// every match.
+func searchInsights(s store.Storage, query, threadID string, limit int) ([]*store.ScoredInsight, error) {
+	match := store.MatchAny(query)
+	if match == "" {
+		return nil, nil
+	}
+	return s.SearchInsightsRanked(match, threadID, limit)
+}
+
+// contrastWords mark an insight that disagrees with one it follows.
+var contrastWords = map[string]bool{
+	"not": true, "no": true, "never": true, "cannot": true, "instead": true,
+	"actually": true, "but": true, "however": true, "wrong": true, "false": true,
+}
+
+// suggestLinks returns up to n links from ins to the most related earlier
+// insights of its thread, with the insights they point at. A pivot first
+// supersedes the direction it turns away from, the one 'bdc lint' would
+// suggest. Other links are contradicts when ins contrasts with the other
+// insight ("not", "instead", ...), and builds-on otherwise.
+func suggestLinks(s store.Storage, ins *types.Insight, n int) ([]*store.ScoredInsight, []types.Dependency, error) {
+	if ins.ThreadID == "" || n <= 0 {
+		return nil, nil, nil
+	}
+	ranked, err := searchInsights(s, ins.Content+"\n"+ins.Summary, ins.ThreadID, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	merged, err := mergedInsightIDs(s)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var targets []*store.ScoredInsight
+	var links []types.Dependency
+	var superseded string
+	if ins.Type == types.InsightPivot {
+		g, err := loadLintGraph(s)
+		if err != nil {
+			return nil, nil, err
+		}
+		if target := lint.PivotTarget(g, ins); target != nil {
+			superseded = target.ID
+			scored := &store.ScoredInsight{Insight: target}
+			for _, r := range ranked {
+				if r.ID == target.ID {
+					scored = r
+				}
+			}
+			targets = append(targets, scored)
+			links = append(links, *types.NewDependency(ins.ID, target.ID, types.DepSupersedes))
+		}
+	}
+	for _, r := range ranked {
+		if len(targets) == n {
+			break
+		}
+		if r.ID == ins.ID || r.ID == superseded || merged[r.ID] || r.Timestamp.After(ins.Timestamp) {
+			continue
+		}
+		targets = append(targets, r)
+		links = append(links, *types.NewDependency(ins.ID, r.ID, suggestedLinkType(ins, r.Insight)))
+	}
+	return targets, links, nil
+}
+
+// suggestedLinkType picks contradicts or builds-on for a link from ins to
+// target.
+func suggestedLinkType(ins, target *types.Insight) types.DependencyType {
+	targetWords := make(map[string]bool)
+	for _, w := range similar.Words(target.Content) {
+		targetWords[w] = true
+	}
+	for _, w := range similar.Words(ins.Content) {
+		if contrastWords[w] && !targetWords[w] {
+			return types.DepContradicts
+		}
+	}
+	return types.DepBuildsOn
+}
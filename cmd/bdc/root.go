@@ -38,10 +38,43 @@ func init() {
 			return nil
 		}
 		resolveDBPath(cmd)
+
+		// A broken types.yaml or classify.yaml only stops the commands
+		// that would store wrongly typed insights. Everything else,
+		// including the git and Claude hooks, warns and uses the defaults.
+		if err := loadCustomTypes(); err != nil {
+			if strictConfigFor(cmd, "capture", "import", "lint") {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Warning: %v (custom types ignored)\n", err)
+		}
+		if err := loadClassifier(); err != nil {
+			if strictConfigFor(cmd, "import", "classify", "ingest-session") {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Warning: %v (using the default classifier)\n", err)
+		}
 		return nil
 	}
 }
 
+// strictConfigFor reports whether cmd is, or is a subcommand of, one of
+// the named top-level commands and isn't running as a hook.
+func strictConfigFor(cmd *cobra.Command, names ...string) bool {
+	if importAuto || ingestHook {
+		return false
+	}
+	for cmd.HasParent() && cmd.Parent() != rootCmd {
+		cmd = cmd.Parent()
+	}
+	for _, name := range names {
+		if cmd.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
 // getStore returns the store instance (read-write), initializing it if necessary.
 func getStore() (store.Storage, error) {
 	if storeInstance != nil {
@@ -105,18 +138,21 @@ func resolveDBPath(cmd *cobra.Command) {
 	if cmd.Flags().Changed("db") {
 		return
 	}
+	if resolved := discoverDBPath(); resolved != "" {
+		dbPath = resolved
+	}
+}
+
+// discoverDBPath finds the database via BDC_DB_PATH, walking up from CWD,
+// or the main worktree's .beadcrumbs/. Returns "" if none is found.
+func discoverDBPath() string {
 	if envPath := os.Getenv("BDC_DB_PATH"); envPath != "" {
-		dbPath = envPath
-		return
+		return envPath
 	}
 	if resolved := walkUpForDB(); resolved != "" {
-		dbPath = resolved
-		return
-	}
-	if resolved := resolveViaGitCommonDir(); resolved != "" {
-		dbPath = resolved
-		return
+		return resolved
 	}
+	return resolveViaGitCommonDir()
 }
 
 // walkUpForDB walks up from CWD looking for .beadcrumbs/beadcrumbs.db.
@@ -14,38 +14,58 @@ var (
 	setupProject bool
 	setupCheck   bool
 	setupRemove  bool
+	setupIngest  bool
 )
 
 var setupCmd = &cobra.Command{
-	Use:   "setup <editor>",
+	Use:   "setup [editor]",
 	Short: "Set up integration with AI editors",
 	Long: `Set up integration with AI editors and coding assistants.
 
-Currently supported editors:
-  claude    Claude Code (hooks in ~/.claude/settings.json)
+Supported editors:
+` + editorIntegrationList() + `
+Editors configured through instruction files get a block between
+<!-- beadcrumbs:begin --> and <!-- beadcrumbs:end --> markers; reinstalling
+updates the block in place and --remove deletes only the block. Global
+installs go in your home directory, --project installs in the current
+directory (Cursor rules are always per project).
+
+--check without an editor verifies every installed integration and fails if
+one is outdated or none is installed.
 
 Examples:
   bdc setup claude             # Install Claude Code integration (global)
   bdc setup claude --project   # Install for this project only
+  bdc setup claude --ingest    # Also capture insights from session transcripts
+  bdc setup codex --project    # Add instructions to ./AGENTS.md
   bdc setup claude --check     # Verify installation status
+  bdc setup --check            # Verify every installed integration
   bdc setup claude --remove    # Uninstall integration`,
-	Args: cobra.ExactArgs(1),
+	Args: cobra.MaximumNArgs(1),
 	RunE: runSetup,
 }
 
 func runSetup(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		if !setupCheck {
+			return fmt.Errorf("an editor is required (supported: %s)", strings.Join(editorIntegrationNames(), ", "))
+		}
+		return checkAllIntegrations()
+	}
+
 	editor := strings.ToLower(args[0])
-	if editor != "claude" {
-		return fmt.Errorf("unsupported editor: %s (supported: claude)", editor)
+	integration := findEditorIntegration(editor)
+	if integration == nil {
+		return fmt.Errorf("unsupported editor: %s (supported: %s)", editor, strings.Join(editorIntegrationNames(), ", "))
 	}
 
 	if setupCheck {
-		return checkClaudeHooks()
+		return checkIntegration(integration)
 	}
 	if setupRemove {
-		return removeClaudeHooks(setupProject)
+		return integration.Remove(setupProject)
 	}
-	return installClaudeHooks(setupProject)
+	return integration.Install(setupProject)
 }
 
 func setupProjectSettingsPath(base string) string {
@@ -109,6 +129,16 @@ func installClaudeHooks(project bool) error {
 	if addHookCommand(hooks, "PreCompact", command) {
 		fmt.Println("  Registered PreCompact hook")
 	}
+	if addMatchedHookCommand(hooks, "PreToolUse", contextHookMatcher, contextHookCommand) {
+		fmt.Println("  Registered PreToolUse hook (file context before edits)")
+	}
+	if setupIngest {
+		for _, event := range ingestHookEvents {
+			if addHookCommand(hooks, event, ingestHookCommand) {
+				fmt.Printf("  Registered %s hook (session transcript ingestion)\n", event)
+			}
+		}
+	}
 
 	// Write settings back
 	data, err := json.MarshalIndent(settings, "", "  ")
@@ -126,33 +156,6 @@ func installClaudeHooks(project bool) error {
 	return nil
 }
 
-func checkClaudeHooks() error {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return fmt.Errorf("home directory: %w", err)
-	}
-	workDir, err := os.Getwd()
-	if err != nil {
-		return fmt.Errorf("working directory: %w", err)
-	}
-
-	globalSettings := setupGlobalSettingsPath(home)
-	projectSettings := setupProjectSettingsPath(workDir)
-
-	switch {
-	case hasBeadcrumbsHooks(globalSettings):
-		fmt.Printf("  Global hooks installed: %s\n", globalSettings)
-		return nil
-	case hasBeadcrumbsHooks(projectSettings):
-		fmt.Printf("  Project hooks installed: %s\n", projectSettings)
-		return nil
-	default:
-		fmt.Println("  No hooks installed")
-		fmt.Println("  Run: bdc setup claude")
-		return fmt.Errorf("claude hooks not installed")
-	}
-}
-
 func removeClaudeHooks(project bool) error {
 	var settingsPath string
 	if project {
@@ -190,6 +193,10 @@ func removeClaudeHooks(project bool) error {
 
 	removeHookCommand(hooks, "SessionStart", "bdc prime")
 	removeHookCommand(hooks, "PreCompact", "bdc prime")
+	removeHookCommand(hooks, "PreToolUse", contextHookCommand)
+	for _, event := range ingestHookEvents {
+		removeHookCommand(hooks, event, ingestHookCommand)
+	}
 
 	data, err = json.MarshalIndent(settings, "", "  ")
 	if err != nil {
@@ -204,9 +211,28 @@ func removeClaudeHooks(project bool) error {
 	return nil
 }
 
+// contextHookCommand injects file-specific insights before Claude edits a file.
+const contextHookCommand = "bdc context --hook"
+
+// contextHookMatcher selects the Claude tools that edit files.
+const contextHookMatcher = "Edit|MultiEdit|Write|NotebookEdit"
+
+// ingestHookCommand captures what the agent forgot to from the session
+// transcript; installed with bdc setup claude --ingest.
+const ingestHookCommand = "bdc ingest-session --hook"
+
+// ingestHookEvents are the Claude events after which the transcript is read.
+var ingestHookEvents = []string{"Stop", "SessionEnd", "PreCompact"}
+
 // addHookCommand adds a hook command to an event if not already present.
 // Returns true if hook was added, false if already exists.
 func addHookCommand(hooks map[string]interface{}, event, command string) bool {
+	return addMatchedHookCommand(hooks, event, "", command)
+}
+
+// addMatchedHookCommand is addHookCommand for hooks limited to the tools
+// matched by matcher ("" matches everything).
+func addMatchedHookCommand(hooks map[string]interface{}, event, matcher, command string) bool {
 	eventHooks, ok := hooks[event].([]interface{})
 	if !ok {
 		eventHooks = []interface{}{}
@@ -236,7 +262,7 @@ func addHookCommand(hooks map[string]interface{}, event, command string) bool {
 
 	// Add bdc hook to array
 	newHook := map[string]interface{}{
-		"matcher": "",
+		"matcher": matcher,
 		"hooks": []interface{}{
 			map[string]interface{}{
 				"type":    "command",
@@ -299,6 +325,12 @@ func removeHookCommand(hooks map[string]interface{}, event, command string) {
 
 // hasBeadcrumbsHooks checks if a settings file has bdc prime hooks.
 func hasBeadcrumbsHooks(settingsPath string) bool {
+	return settingsHasHook(settingsPath, []string{"SessionStart", "PreCompact"}, "bdc prime")
+}
+
+// settingsHasHook checks if a settings file registers command for any of
+// the events.
+func settingsHasHook(settingsPath string, events []string, command string) bool {
 	data, err := os.ReadFile(settingsPath)
 	if err != nil {
 		return false
@@ -314,7 +346,7 @@ func hasBeadcrumbsHooks(settingsPath string) bool {
 		return false
 	}
 
-	for _, event := range []string{"SessionStart", "PreCompact"} {
+	for _, event := range events {
 		eventHooks, ok := hooks[event].([]interface{})
 		if !ok {
 			continue
@@ -334,7 +366,7 @@ func hasBeadcrumbsHooks(settingsPath string) bool {
 				if !ok {
 					continue
 				}
-				if cmdMap["command"] == "bdc prime" {
+				if cmdMap["command"] == command {
 					return true
 				}
 			}
@@ -349,4 +381,5 @@ func init() {
 	setupCmd.Flags().BoolVar(&setupCheck, "check", false, "Check if integration is installed")
 	setupCmd.Flags().BoolVar(&setupRemove, "remove", false, "Remove the integration")
 	setupCmd.Flags().BoolVar(&setupProject, "project", false, "Install for this project only")
+	setupCmd.Flags().BoolVar(&setupIngest, "ingest", false, "Claude: also ingest the session transcript on Stop, SessionEnd and PreCompact")
 }
@@ -0,0 +1,631 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// editorIntegration installs, verifies and removes beadcrumbs support for
+// one AI editor or coding agent.
+type editorIntegration interface {
+	// Name is the argument to bdc setup, e.g. "cursor".
+	Name() string
+	// Description is one line for the setup help.
+	Description() string
+	// Install adds the integration globally or, with project, for the
+	// current directory. Installing again updates it in place.
+	Install(project bool) error
+	// Remove undoes Install, leaving unrelated content alone.
+	Remove(project bool) error
+	// Check returns where the integration is installed, and an error if an
+	// installation is outdated or broken.
+	Check() ([]string, error)
+}
+
+// editorIntegrations lists the supported editors in help order.
+var editorIntegrations = []editorIntegration{
+	claudeIntegration{},
+	&rulesFileIntegration{
+		name:          "cursor",
+		description:   "Cursor (rules in .cursor/rules/beadcrumbs.mdc; project only)",
+		author:        "cursor",
+		projectPath:   filepath.Join(".cursor", "rules", "beadcrumbs.mdc"),
+		projectHeader: "---\ndescription: Record the reasoning behind changes with beadcrumbs (bdc)\nalwaysApply: true\n---\n\n",
+	},
+	&rulesFileIntegration{
+		name:        "codex",
+		description: "Codex CLI (block in AGENTS.md, or ~/.codex/AGENTS.md)",
+		author:      "codex",
+		projectPath: "AGENTS.md",
+		globalPath:  filepath.Join(".codex", "AGENTS.md"),
+	},
+	&rulesFileIntegration{
+		name:        "gemini",
+		description: "Gemini CLI (block in GEMINI.md, or ~/.gemini/GEMINI.md)",
+		author:      "gemini",
+		projectPath: "GEMINI.md",
+		globalPath:  filepath.Join(".gemini", "GEMINI.md"),
+	},
+	&aiderIntegration{rulesFileIntegration{
+		name:        "aider",
+		description: "Aider (block in CONVENTIONS.md, loaded via .aider.conf.yml)",
+		author:      "aider",
+		projectPath: "CONVENTIONS.md",
+		globalPath:  filepath.Join(".aider", "beadcrumbs.md"),
+	}},
+	&rulesFileIntegration{
+		name:          "windsurf",
+		description:   "Windsurf (.windsurf/rules/beadcrumbs.md, or global_rules.md)",
+		author:        "windsurf",
+		projectPath:   filepath.Join(".windsurf", "rules", "beadcrumbs.md"),
+		globalPath:    filepath.Join(".codeium", "windsurf", "memories", "global_rules.md"),
+		projectHeader: "---\ntrigger: always_on\n---\n\n",
+	},
+}
+
+func findEditorIntegration(name string) editorIntegration {
+	for _, e := range editorIntegrations {
+		if e.Name() == name {
+			return e
+		}
+	}
+	return nil
+}
+
+func editorIntegrationNames() []string {
+	names := make([]string, 0, len(editorIntegrations))
+	for _, e := range editorIntegrations {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+// editorIntegrationList formats the supported editors for the setup help.
+func editorIntegrationList() string {
+	var b strings.Builder
+	for _, e := range editorIntegrations {
+		fmt.Fprintf(&b, "  %-9s %s\n", e.Name(), e.Description())
+	}
+	return b.String()
+}
+
+// checkIntegration reports on one editor and fails if it is not installed
+// or outdated.
+func checkIntegration(e editorIntegration) error {
+	installed, err := e.Check()
+	for _, where := range installed {
+		fmt.Printf("  %s installed: %s\n", e.Name(), where)
+	}
+	if err != nil {
+		fmt.Printf("  %s: %v\n", e.Name(), err)
+		fmt.Printf("  Run: bdc setup %s\n", e.Name())
+		return fmt.Errorf("%s integration is outdated", e.Name())
+	}
+	if len(installed) == 0 {
+		fmt.Printf("  %s: not installed\n", e.Name())
+		fmt.Printf("  Run: bdc setup %s\n", e.Name())
+		return fmt.Errorf("%s integration not installed", e.Name())
+	}
+	return nil
+}
+
+// checkAllIntegrations verifies every installed integration.
+func checkAllIntegrations() error {
+	var found int
+	var outdated []string
+	for _, e := range editorIntegrations {
+		installed, err := e.Check()
+		for _, where := range installed {
+			fmt.Printf("  %-9s %s\n", e.Name(), where)
+		}
+		found += len(installed)
+		if err != nil {
+			fmt.Printf("  %-9s %v (run: bdc setup %s)\n", e.Name(), err, e.Name())
+			outdated = append(outdated, e.Name())
+		}
+	}
+	if len(outdated) > 0 {
+		return fmt.Errorf("outdated integrations: %s", strings.Join(outdated, ", "))
+	}
+	if found == 0 {
+		fmt.Println("  No integrations installed")
+		fmt.Println("  Run: bdc setup <editor>")
+		return fmt.Errorf("no editor integrations installed")
+	}
+	return nil
+}
+
+// claudeIntegration registers bdc hooks in Claude Code settings.
+type claudeIntegration struct{}
+
+func (claudeIntegration) Name() string { return "claude" }
+
+func (claudeIntegration) Description() string {
+	return "Claude Code (hooks in ~/.claude/settings.json)"
+}
+
+func (claudeIntegration) Install(project bool) error { return installClaudeHooks(project) }
+
+func (claudeIntegration) Remove(project bool) error { return removeClaudeHooks(project) }
+
+func (claudeIntegration) Check() ([]string, error) {
+	var paths []string
+	if home, err := os.UserHomeDir(); err == nil {
+		paths = append(paths, setupGlobalSettingsPath(home))
+	}
+	if workDir, err := os.Getwd(); err == nil {
+		paths = append(paths, setupProjectSettingsPath(workDir))
+	}
+
+	var installed []string
+	for _, p := range paths {
+		if !hasBeadcrumbsHooks(p) {
+			continue
+		}
+		installed = append(installed, p)
+		// Installs from before bdc context lack the PreToolUse hook
+		if !settingsHasHook(p, []string{"PreToolUse"}, contextHookCommand) {
+			return installed, fmt.Errorf("%s lacks the PreToolUse context hook", p)
+		}
+	}
+	return installed, nil
+}
+
+// Markers delimiting the beadcrumbs block in instruction files.
+const (
+	setupBlockBegin = "<!-- beadcrumbs:begin -->"
+	setupBlockEnd   = "<!-- beadcrumbs:end -->"
+)
+
+// agentInstructions is the block written to instruction files for agents
+// that can't run hooks. author is the capture --author prefix.
+func agentInstructions(author string) string {
+	return `## Beadcrumbs
+
+This project records the reasoning behind its code with beadcrumbs (` + "`bdc`" + `).
+bdc tracks *why*; bd (beads), if present, tracks *what*.
+
+- At the start of a session, run ` + "`bdc prime`" + ` and read its output: the workflow,
+  the active threads, recent decisions and open questions.
+- Before editing a file, run ` + "`bdc context --files <path>`" + ` and follow the
+  decisions it lists, or capture a pivot before changing course.
+- Capture reasoning as it evolves:
+  ` + "`bdc capture --thread <ref> --<type> \"...\" --author " + author + ":<model>`" + `
+  with type hypothesis, discovery, question, feedback, pivot or decision.
+- Do not capture routine tool calls, acknowledgments or mechanical steps.
+`
+}
+
+// markedBlock wraps content in the beadcrumbs markers.
+func markedBlock(content string) string {
+	return setupBlockBegin + "\n" + content + setupBlockEnd + "\n"
+}
+
+// findMarkedBlock returns the byte range of the beadcrumbs block in text,
+// including the markers and the newline after the end marker.
+func findMarkedBlock(text string) (start, end int, ok bool) {
+	start = strings.Index(text, setupBlockBegin)
+	if start < 0 {
+		return 0, 0, false
+	}
+	rel := strings.Index(text[start:], setupBlockEnd)
+	if rel < 0 {
+		return 0, 0, false
+	}
+	end = start + rel + len(setupBlockEnd)
+	if end < len(text) && text[end] == '\n' {
+		end++
+	}
+	return start, end, true
+}
+
+// upsertMarkedBlock writes content between the markers in path, replacing a
+// previous block or appending one. A new file starts with header. It
+// reports whether the file changed.
+func upsertMarkedBlock(path, header, content string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	text := string(data)
+	block := markedBlock(content)
+
+	var updated string
+	switch start, end, ok := findMarkedBlock(text); {
+	case ok:
+		updated = text[:start] + block + text[end:]
+	case text == "":
+		updated = header + block
+	default:
+		if !strings.HasSuffix(text, "\n") {
+			text += "\n"
+		}
+		updated = text + "\n" + block
+	}
+	if updated == string(data) {
+		return false, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return false, fmt.Errorf("create directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(updated), 0o644); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return true, nil
+}
+
+// removeMarkedBlock deletes the beadcrumbs block from path. The file is
+// deleted when nothing but header and whitespace remains. It reports
+// whether a block was removed.
+func removeMarkedBlock(path, header string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	text := string(data)
+	start, end, ok := findMarkedBlock(text)
+	if !ok {
+		return false, nil
+	}
+
+	rest := text[:start] + text[end:]
+	if strings.TrimSpace(strings.TrimPrefix(rest, header)) == "" {
+		if err := os.Remove(path); err != nil {
+			return false, fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+		return true, nil
+	}
+	// Drop the blank line upsertMarkedBlock put before an appended block
+	if strings.HasSuffix(text[:start], "\n\n") {
+		rest = text[:start-1] + text[end:]
+	}
+	if err := os.WriteFile(path, []byte(rest), 0o644); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return true, nil
+}
+
+// readMarkedBlock returns the content between the markers in path.
+func readMarkedBlock(path string) (string, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	text := string(data)
+	start, end, ok := findMarkedBlock(text)
+	if !ok {
+		return "", false
+	}
+	block := strings.TrimSuffix(text[start:end], "\n")
+	block = strings.TrimPrefix(block, setupBlockBegin+"\n")
+	return strings.TrimSuffix(block, setupBlockEnd), true
+}
+
+// rulesFileIntegration keeps the agent instructions in a marked block of a
+// rules or instructions file the editor reads at session start.
+type rulesFileIntegration struct {
+	name        string
+	description string
+	author      string // capture --author prefix
+	projectPath string // relative to the working directory
+	globalPath  string // relative to the home directory; "" if only per project
+	// projectHeader is written first when creating the project file, e.g.
+	// rule frontmatter. Global files don't take one.
+	projectHeader string
+}
+
+func (r *rulesFileIntegration) Name() string        { return r.name }
+func (r *rulesFileIntegration) Description() string { return r.description }
+
+// path resolves the instructions file for a scope. Editors without global
+// rules files always install per project.
+func (r *rulesFileIntegration) path(project bool) (string, error) {
+	if project || r.globalPath == "" {
+		workDir, err := os.Getwd()
+		if err != nil {
+			return "", fmt.Errorf("working directory: %w", err)
+		}
+		return filepath.Join(workDir, r.projectPath), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("home directory: %w", err)
+	}
+	return filepath.Join(home, r.globalPath), nil
+}
+
+// header returns the text a new file for the scope starts with.
+func (r *rulesFileIntegration) header(project bool) string {
+	if project || r.globalPath == "" {
+		return r.projectHeader
+	}
+	return ""
+}
+
+// paths returns the instructions file of every supported scope.
+func (r *rulesFileIntegration) paths() []string {
+	var paths []string
+	for _, project := range []bool{false, true} {
+		if !project && r.globalPath == "" {
+			continue
+		}
+		if p, err := r.path(project); err == nil {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
+
+func (r *rulesFileIntegration) Install(project bool) error {
+	path, err := r.path(project)
+	if err != nil {
+		return err
+	}
+	if !project && r.globalPath == "" {
+		fmt.Printf("%s rules are per project; installing for this project...\n", r.name)
+	} else if project {
+		fmt.Printf("Installing %s instructions for this project...\n", r.name)
+	} else {
+		fmt.Printf("Installing %s instructions globally...\n", r.name)
+	}
+
+	changed, err := upsertMarkedBlock(path, r.header(project), agentInstructions(r.author))
+	if err != nil {
+		return err
+	}
+	if changed {
+		fmt.Printf("  Wrote beadcrumbs block: %s\n", path)
+	} else {
+		fmt.Printf("  Already up to date: %s\n", path)
+	}
+	return nil
+}
+
+func (r *rulesFileIntegration) Remove(project bool) error {
+	path, err := r.path(project)
+	if err != nil {
+		return err
+	}
+	removed, err := removeMarkedBlock(path, r.header(project))
+	if err != nil {
+		return err
+	}
+	if removed {
+		fmt.Printf("  Removed beadcrumbs block: %s\n", path)
+	} else {
+		fmt.Printf("  No beadcrumbs block in %s\n", path)
+	}
+	return nil
+}
+
+func (r *rulesFileIntegration) Check() ([]string, error) {
+	var installed []string
+	for _, p := range r.paths() {
+		content, ok := readMarkedBlock(p)
+		if !ok {
+			continue
+		}
+		installed = append(installed, p)
+		if content != agentInstructions(r.author) {
+			return installed, fmt.Errorf("%s has outdated instructions", p)
+		}
+	}
+	return installed, nil
+}
+
+// aiderConfigFileName is Aider's config file, read from the git root and
+// the home directory.
+const aiderConfigFileName = ".aider.conf.yml"
+
+// aiderIntegration writes the instructions to a conventions file and lists
+// it under "read:" in .aider.conf.yml, as Aider only loads files it is told
+// about.
+type aiderIntegration struct {
+	rulesFileIntegration
+}
+
+// config returns the config file for a scope and how it refers to the
+// conventions file.
+func (a *aiderIntegration) config(project bool) (configPath, readEntry string, err error) {
+	path, err := a.path(project)
+	if err != nil {
+		return "", "", err
+	}
+	if project {
+		return filepath.Join(filepath.Dir(path), aiderConfigFileName), a.projectPath, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", "", fmt.Errorf("home directory: %w", err)
+	}
+	return filepath.Join(home, aiderConfigFileName), path, nil
+}
+
+func (a *aiderIntegration) Install(project bool) error {
+	if err := a.rulesFileIntegration.Install(project); err != nil {
+		return err
+	}
+	configPath, entry, err := a.config(project)
+	if err != nil {
+		return err
+	}
+	changed, err := updateAiderRead(configPath, entry, true)
+	if err != nil {
+		return err
+	}
+	if changed {
+		fmt.Printf("  Added %s to read: in %s\n", entry, configPath)
+	}
+	return nil
+}
+
+func (a *aiderIntegration) Remove(project bool) error {
+	if err := a.rulesFileIntegration.Remove(project); err != nil {
+		return err
+	}
+	configPath, entry, err := a.config(project)
+	if err != nil {
+		return err
+	}
+	changed, err := updateAiderRead(configPath, entry, false)
+	if err != nil {
+		return err
+	}
+	if changed {
+		fmt.Printf("  Removed %s from read: in %s\n", entry, configPath)
+	}
+	return nil
+}
+
+func (a *aiderIntegration) Check() ([]string, error) {
+	installed, err := a.rulesFileIntegration.Check()
+	if err != nil {
+		return installed, err
+	}
+	for _, project := range []bool{false, true} {
+		path, _ := a.path(project)
+		if _, ok := readMarkedBlock(path); !ok {
+			continue
+		}
+		configPath, entry, err := a.config(project)
+		if err != nil {
+			return installed, err
+		}
+		reads, err := aiderReadEntries(configPath)
+		if err != nil {
+			return installed, err
+		}
+		if !slices.Contains(reads, entry) {
+			return installed, fmt.Errorf("%s does not read %s", configPath, entry)
+		}
+	}
+	return installed, nil
+}
+
+// loadAiderConfig parses an Aider config file into its top-level mapping,
+// creating an empty one when the file doesn't exist.
+func loadAiderConfig(path string) (*yaml.Node, *yaml.Node, error) {
+	doc := &yaml.Node{Kind: yaml.DocumentNode}
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if len(data) > 0 {
+		if err := yaml.Unmarshal(data, doc); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	}
+	if len(doc.Content) == 0 {
+		doc.Kind = yaml.DocumentNode
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("%s is not a YAML mapping", path)
+	}
+	return doc, root, nil
+}
+
+// aiderReadEntries returns the files an Aider config lists under "read:".
+func aiderReadEntries(path string) ([]string, error) {
+	_, root, err := loadAiderConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != "read" {
+			continue
+		}
+		value := root.Content[i+1]
+		if value.Kind == yaml.ScalarNode {
+			return []string{value.Value}, nil
+		}
+		var entries []string
+		for _, n := range value.Content {
+			entries = append(entries, n.Value)
+		}
+		return entries, nil
+	}
+	return nil, nil
+}
+
+// updateAiderRead adds entry to, or removes it from, the "read:" list of an
+// Aider config, keeping other settings and comments. A config left empty
+// is deleted. It reports whether the file changed.
+func updateAiderRead(path, entry string, add bool) (bool, error) {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) && !add {
+		return false, nil
+	}
+	doc, root, err := loadAiderConfig(path)
+	if err != nil {
+		return false, err
+	}
+
+	var list *yaml.Node
+	keyIndex := -1
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "read" {
+			keyIndex = i
+			list = root.Content[i+1]
+			break
+		}
+	}
+	if list != nil && list.Kind == yaml.ScalarNode {
+		// read: FILE is shorthand for a one-element list
+		scalar := *list
+		list.Kind, list.Tag, list.Value, list.Style = yaml.SequenceNode, "!!seq", "", 0
+		list.Content = []*yaml.Node{&scalar}
+	}
+
+	index := -1
+	if list != nil {
+		for i, n := range list.Content {
+			if n.Value == entry {
+				index = i
+			}
+		}
+	}
+
+	switch {
+	case add && index >= 0, !add && index < 0:
+		return false, nil
+	case add && list == nil:
+		root.Content = append(root.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "read"},
+			&yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: []*yaml.Node{
+				{Kind: yaml.ScalarNode, Tag: "!!str", Value: entry},
+			}})
+	case add:
+		list.Content = append(list.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: entry})
+	default:
+		list.Content = append(list.Content[:index], list.Content[index+1:]...)
+		if len(list.Content) == 0 {
+			root.Content = append(root.Content[:keyIndex], root.Content[keyIndex+2:]...)
+		}
+	}
+
+	if len(root.Content) == 0 {
+		if err := os.Remove(path); err != nil {
+			return false, fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+		return true, nil
+	}
+	data, err := yaml.Marshal(doc)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return true, nil
+}
@@ -3,6 +3,8 @@ package main
 import (
 	"encoding/json"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -29,6 +31,22 @@ func TestAddHookCommand(t *testing.T) {
 	}
 }
 
+func TestAddMatchedHookCommand(t *testing.T) {
+	hooks := make(map[string]interface{})
+	if !addMatchedHookCommand(hooks, "PreToolUse", contextHookMatcher, contextHookCommand) {
+		t.Fatal("addMatchedHookCommand returned false for new hook")
+	}
+
+	hookMap := hooks["PreToolUse"].([]interface{})[0].(map[string]interface{})
+	if hookMap["matcher"] != contextHookMatcher {
+		t.Errorf("matcher = %v, want %q", hookMap["matcher"], contextHookMatcher)
+	}
+	cmdMap := hookMap["hooks"].([]interface{})[0].(map[string]interface{})
+	if cmdMap["command"] != contextHookCommand {
+		t.Errorf("command = %v, want %q", cmdMap["command"], contextHookCommand)
+	}
+}
+
 func TestAddHookCommand_Idempotent(t *testing.T) {
 	hooks := make(map[string]interface{})
 	addHookCommand(hooks, "SessionStart", "bdc prime")
@@ -190,3 +208,118 @@ func TestHasBeadcrumbsHooks_WrongCommand(t *testing.T) {
 		t.Error("hasBeadcrumbsHooks should return false for 'bd prime' (not 'bdc prime')")
 	}
 }
+
+func TestUpsertMarkedBlock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "AGENTS.md")
+	if err := os.WriteFile(path, []byte("# Agents\n\nBe nice."), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if changed, err := upsertMarkedBlock(path, "", "v1\n"); err != nil || !changed {
+		t.Fatalf("first upsert: changed=%v err=%v", changed, err)
+	}
+	if changed, _ := upsertMarkedBlock(path, "", "v1\n"); changed {
+		t.Error("upserting the same block again should not change the file")
+	}
+	upsertMarkedBlock(path, "", "v2\n")
+	data, _ := os.ReadFile(path)
+	want := "# Agents\n\nBe nice.\n\n" + setupBlockBegin + "\nv2\n" + setupBlockEnd + "\n"
+	if string(data) != want {
+		t.Errorf("file = %q, want %q", data, want)
+	}
+	if content, ok := readMarkedBlock(path); !ok || content != "v2\n" {
+		t.Errorf("readMarkedBlock() = %q, %v", content, ok)
+	}
+
+	if removed, err := removeMarkedBlock(path, ""); err != nil || !removed {
+		t.Fatalf("remove: removed=%v err=%v", removed, err)
+	}
+	data, _ = os.ReadFile(path)
+	if string(data) != "# Agents\n\nBe nice.\n" {
+		t.Errorf("after remove file = %q", data)
+	}
+}
+
+func TestRemoveMarkedBlock_DeletesOwnedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules", "beadcrumbs.md")
+	header := "---\ntrigger: always_on\n---\n\n"
+	if _, err := upsertMarkedBlock(path, header, "rules\n"); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.HasPrefix(string(data), header+setupBlockBegin) {
+		t.Errorf("new file should start with the header, got %q", data)
+	}
+	if _, err := removeMarkedBlock(path, header); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("file holding only the header and block should be deleted")
+	}
+}
+
+func TestRulesFileIntegration_WindsurfHeader(t *testing.T) {
+	origDir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(origDir)
+
+	home, work := t.TempDir(), t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.Chdir(work); err != nil {
+		t.Fatal(err)
+	}
+	windsurf := findEditorIntegration("windsurf")
+
+	for _, project := range []bool{false, true} {
+		if err := windsurf.Install(project); err != nil {
+			t.Fatal(err)
+		}
+	}
+	global, _ := os.ReadFile(filepath.Join(home, ".codeium", "windsurf", "memories", "global_rules.md"))
+	if !strings.HasPrefix(string(global), setupBlockBegin) {
+		t.Errorf("global rules should hold the plain block, got %q", global)
+	}
+	rules, _ := os.ReadFile(filepath.Join(work, ".windsurf", "rules", "beadcrumbs.md"))
+	if !strings.HasPrefix(string(rules), "---\ntrigger: always_on\n---\n\n"+setupBlockBegin) {
+		t.Errorf("project rules should start with the frontmatter, got %q", rules)
+	}
+}
+
+func TestUpdateAiderRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), aiderConfigFileName)
+	if err := os.WriteFile(path, []byte("# my settings\nmodel: sonnet\nread: NOTES.md\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if changed, err := updateAiderRead(path, "CONVENTIONS.md", true); err != nil || !changed {
+		t.Fatalf("add: changed=%v err=%v", changed, err)
+	}
+	if changed, _ := updateAiderRead(path, "CONVENTIONS.md", true); changed {
+		t.Error("adding an entry twice should not change the file")
+	}
+	reads, err := aiderReadEntries(path)
+	if err != nil || strings.Join(reads, ",") != "NOTES.md,CONVENTIONS.md" {
+		t.Errorf("read = %v, err %v", reads, err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "# my settings") || !strings.Contains(string(data), "model: sonnet") {
+		t.Errorf("other settings should be kept, got:\n%s", data)
+	}
+
+	updateAiderRead(path, "CONVENTIONS.md", false)
+	updateAiderRead(path, "NOTES.md", false)
+	reads, _ = aiderReadEntries(path)
+	if len(reads) != 0 {
+		t.Errorf("read = %v after removing every entry", reads)
+	}
+
+	// A config holding only our entry is deleted again
+	own := filepath.Join(t.TempDir(), aiderConfigFileName)
+	updateAiderRead(own, "CONVENTIONS.md", true)
+	updateAiderRead(own, "CONVENTIONS.md", false)
+	if _, err := os.Stat(own); !os.IsNotExist(err) {
+		t.Error("empty aider config should be deleted")
+	}
+}
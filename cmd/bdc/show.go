@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/brianevanmiller/beadcrumbs/internal/anchor"
 	"github.com/brianevanmiller/beadcrumbs/internal/store"
 	"github.com/brianevanmiller/beadcrumbs/internal/types"
 	"github.com/spf13/cobra"
@@ -29,7 +30,7 @@ var showCmd = &cobra.Command{
 		if strings.HasPrefix(id, "ins-") {
 			return showInsight(s, id)
 		} else if strings.HasPrefix(id, "thr-") {
-			return showThread(s, id)
+			return showThread(s, redirectThreadID(s, id))
 		} else {
 			return fmt.Errorf("invalid ID format: %s (expected ins-xxxx or thr-xxxx)", id)
 		}
@@ -63,7 +64,17 @@ func showInsight(s store.Storage, id string) error {
 	if insight.Source.Ref != "" {
 		fmt.Printf("Origin: %s (%s)\n", insight.Source.Ref, insight.Source.Type)
 	}
+	if insight.Source.Git != nil {
+		fmt.Printf("Git: %s\n", formatGitContext(insight.Source.Git))
+	}
 	fmt.Printf("Created: %s\n", insight.CreatedAt.Format("2006-01-02 15:04:05"))
+	for _, a := range insight.Anchors {
+		at := ""
+		if a.Commit != "" {
+			at = " @ " + shortSHA(a.Commit)
+		}
+		fmt.Printf("Anchor: %s%s\n", anchor.Format(a), at)
+	}
 
 	fmt.Printf("\nContent:\n%s\n", insight.Content)
 
@@ -95,7 +106,8 @@ func showInsight(s store.Storage, id string) error {
 // threadDetail is a JSON-serializable view of a thread with its insights.
 type threadDetail struct {
 	*types.InsightThread
-	Insights []*types.Insight `json:"insights"`
+	Children []*types.InsightThread `json:"children,omitempty"`
+	Insights []*types.Insight       `json:"insights"`
 }
 
 func showThread(s store.Storage, id string) error {
@@ -139,9 +151,9 @@ func showThread(s store.Storage, id string) error {
 		}
 	}
 
-	if thread.CurrentUnderstanding != "" {
-		fmt.Printf("\nCurrent Understanding:\n%s\n", thread.CurrentUnderstanding)
-	}
+	printThreadHierarchy(s, thread)
+
+	printCurrentUnderstanding(thread)
 
 	// Collect distinct origins from insights in this thread
 	seenOrigins := make(map[string]bool)
@@ -23,8 +23,11 @@ This hides .beadcrumbs/ from git without deleting any data:
   - Removes beadcrumbs entries from .gitignore
   - Removes beadcrumbs git hooks
   - Unstages .beadcrumbs/ JSONL files from git tracking
+  - In sync-branch mode, removes the sync branch worktree (the branch is kept)
 
-Your existing insights are preserved. Run 'bdc unstealth' to reverse.`,
+Your existing insights are preserved. Run 'bdc unstealth' to reverse.
+
+'bdc stealth --status' prints the current mode: stealth, sync-branch or normal.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if stealthStatus {
 			return showStealthStatus()
@@ -43,6 +46,7 @@ This makes .beadcrumbs/ visible to git for team collaboration:
   - Adds SQLite database to .gitignore (JSONL files get tracked)
   - Installs git hooks for auto-sync
   - Exports current insights to JSONL
+  - In sync-branch mode, removes the sync branch worktree (the branch is kept)
 
 After unstealthing, 'git add .beadcrumbs/' will stage the JSONL files.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -60,9 +64,16 @@ func showStealthStatus() error {
 	if err != nil {
 		return fmt.Errorf("failed to read config: %w", err)
 	}
-	if val == "true" {
+	branch, err := s.GetConfig(syncBranchConfigKey)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	switch {
+	case branch != "":
+		fmt.Println("sync-branch")
+	case val == "true":
 		fmt.Println("stealth")
-	} else {
+	default:
 		fmt.Println("normal")
 	}
 	return nil
@@ -80,7 +91,11 @@ func enableStealth() error {
 	if err != nil {
 		return fmt.Errorf("failed to read config: %w", err)
 	}
-	if val == "true" {
+	branch, err := s.GetConfig(syncBranchConfigKey)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	if val == "true" && branch == "" {
 		fmt.Println("Already in stealth mode.")
 		return nil
 	}
@@ -92,7 +107,13 @@ func enableStealth() error {
 	if err := s.SetConfig("stealth_mode", "true"); err != nil {
 		return fmt.Errorf("failed to update config: %w", err)
 	}
+	if _, err := leaveSyncBranchMode(s); err != nil {
+		return err
+	}
 	closeStore()
+	if branch != "" {
+		fmt.Printf("  Left sync-branch mode (branch %s is kept)\n", branch)
+	}
 
 	// Step 2: Add .beadcrumbs/ to .git/info/exclude
 	if err := setupStealthMode(); err != nil {
@@ -137,7 +158,11 @@ func disableStealth() error {
 	if err != nil {
 		return fmt.Errorf("failed to read config: %w", err)
 	}
-	if val != "true" {
+	branch, err := s.GetConfig(syncBranchConfigKey)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	if val != "true" && branch == "" {
 		fmt.Println("Already in normal mode.")
 		return nil
 	}
@@ -148,7 +173,17 @@ func disableStealth() error {
 	if err := s.SetConfig("stealth_mode", "false"); err != nil {
 		return fmt.Errorf("failed to update config: %w", err)
 	}
+	if _, err := leaveSyncBranchMode(s); err != nil {
+		return err
+	}
 	closeStore()
+	if branch != "" {
+		// Replace the sync-branch hooks below
+		if _, err := removeBeadcrumbsHooks(); err != nil {
+			fmt.Printf("  Warning: failed to remove git hooks: %v\n", err)
+		}
+		fmt.Printf("  Left sync-branch mode (branch %s is kept)\n", branch)
+	}
 
 	// Step 2: Remove .beadcrumbs/ from .git/info/exclude
 	if removed, err := removeExcludeEntry(); err != nil {
@@ -168,7 +203,7 @@ func disableStealth() error {
 	if err := installGitHooks(); err != nil {
 		fmt.Printf("  Warning: failed to install git hooks: %v\n", err)
 	} else {
-		fmt.Println("  Installed git hooks (pre-commit, post-commit, post-merge, post-checkout)")
+		fmt.Println("  Installed git hooks (pre-commit, prepare-commit-msg, post-commit, post-merge, post-checkout)")
 	}
 
 	// Step 5: Export current insights to JSONL
@@ -259,7 +294,9 @@ func removeGitignoreEntries() (bool, error) {
 	for _, line := range lines {
 		trimmed := strings.TrimSpace(line)
 		if trimmed == "# Beadcrumbs database (ephemeral, rebuilt from JSONL)" ||
-			strings.HasPrefix(trimmed, ".beadcrumbs/beadcrumbs.db") {
+			strings.HasPrefix(trimmed, ".beadcrumbs/beadcrumbs.db") ||
+			strings.HasPrefix(trimmed, "# Beadcrumbs LLM extraction cache") ||
+			trimmed == extractCachePattern {
 			removed = true
 			continue
 		}
@@ -299,7 +336,7 @@ func removeBeadcrumbsHooks() (bool, error) {
 	}
 
 	hooksDir := filepath.Join(gitDir, "hooks")
-	hookNames := []string{"pre-commit", "post-commit", "post-merge", "post-checkout"}
+	hookNames := []string{"pre-commit", "prepare-commit-msg", "post-commit", "post-merge", "post-checkout"}
 	anyRemoved := false
 
 	for _, hookName := range hookNames {
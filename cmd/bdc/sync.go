@@ -0,0 +1,246 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/gitsync"
+	"github.com/brianevanmiller/beadcrumbs/internal/store"
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	syncRemote string
+	syncRef    string
+	syncLocal  bool
+	syncQuiet  bool
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Share insights through a git ref (refs/beadcrumbs/data)",
+	Long: `Synchronize the database with a git ref instead of committed JSONL files.
+
+The data lives on refs/beadcrumbs/data as commits whose tree holds
+insights.jsonl, threads.jsonl and deps.jsonl, so it never touches your
+branches or working tree. bdc sync:
+
+  1. fetches the ref from the remote (into refs/beadcrumbs/remotes/<remote>)
+  2. merges it with the local database by ID, using the last synced snapshot
+     as the base: a record changed on one side only takes that side's
+     version; a record changed on both sides keeps the local version
+  3. writes the merged records to the database and to the local ref
+  4. pushes the ref back to the remote
+
+Deleted insights and links are deleted on the other side too, unless the
+other side changed them since the last sync, in which case they are kept.
+Threads are never deleted. Run it again if the push is rejected because
+someone else synced in between.
+
+In sync-branch mode (bdc init --sync-branch <name>) the data is committed
+to that branch instead, through a worktree under .git/beadcrumbs-worktrees/,
+and the same fetch, merge, import and push steps apply to the branch. With
+--local the branch is merged with the remote copy last fetched (e.g. by git
+pull) without touching the network; the git hooks run this after commits
+and merges.
+
+Example:
+  bdc sync                    # fetch, merge and push with origin
+  bdc sync --remote upstream  # use another remote
+  bdc sync --local            # only snapshot the database to the local ref`,
+	Args: cobra.NoArgs,
+	RunE: runSync,
+}
+
+// syncResult is the --json output of bdc sync.
+type syncResult struct {
+	Ref       string   `json:"ref"`
+	Remote    string   `json:"remote,omitempty"`
+	Commit    string   `json:"commit"`
+	Pulled    int      `json:"pulled"`
+	Pushed    int      `json:"pushed"`
+	Conflicts []string `json:"conflicts,omitempty"`
+}
+
+func runSync(cmd *cobra.Command, args []string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	repo := gitsync.Repo{Dir: filepath.Dir(dbPath)}
+	if !syncLocal && !repo.HasRemote(syncRemote) {
+		return fmt.Errorf("remote %q not found (use --local to only update the local ref)", syncRemote)
+	}
+
+	branch, err := s.GetConfig(syncBranchConfigKey)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	if branch != "" {
+		result, err := runBranchSync(s, repo, branch)
+		if err != nil {
+			return err
+		}
+		return printSyncResult(result)
+	}
+
+	// Base: what we last synced. Ours: the database.
+	local := repo.ResolveRef(syncRef)
+	base, err := repo.ReadSnapshot(local)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", syncRef, err)
+	}
+	ours, err := databaseSnapshot(s)
+	if err != nil {
+		return err
+	}
+
+	// Theirs: the remote ref, or the base when there is nothing to pull.
+	var remote string
+	theirs := base
+	if !syncLocal {
+		remote, err = repo.Fetch(syncRemote, syncRef, "refs/beadcrumbs/remotes/"+syncRemote)
+		if err != nil {
+			return fmt.Errorf("failed to fetch from %s: %w", syncRemote, err)
+		}
+		if remote != "" {
+			if theirs, err = repo.ReadSnapshot(remote); err != nil {
+				return fmt.Errorf("failed to read %s from %s: %w", syncRef, syncRemote, err)
+			}
+		}
+	}
+
+	res := gitsync.Merge(base, ours, theirs)
+
+	pulled, err := applySnapshot(s, ours, res.Merged)
+	if err != nil {
+		return err
+	}
+
+	tree, err := repo.WriteTree(res.Merged)
+	if err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	commit, err := syncCommit(repo, tree, local, remote)
+	if err != nil {
+		return err
+	}
+	if commit != local {
+		if err := repo.UpdateRef(syncRef, commit, local); err != nil {
+			return fmt.Errorf("failed to update %s: %w", syncRef, err)
+		}
+	}
+
+	pushed := 0
+	if !syncLocal && commit != remote {
+		pushed = gitsync.Changed(theirs, res.Merged).Len()
+		if err := repo.Push(syncRemote, syncRef); err != nil {
+			return fmt.Errorf("failed to push to %s (run bdc sync again): %w", syncRemote, err)
+		}
+	}
+
+	result := syncResult{
+		Ref:       syncRef,
+		Commit:    commit,
+		Pulled:    pulled,
+		Pushed:    pushed,
+		Conflicts: res.Conflicts,
+	}
+	if !syncLocal {
+		result.Remote = syncRemote
+	}
+
+	return printSyncResult(result)
+}
+
+func printSyncResult(result syncResult) error {
+	if jsonOutput {
+		out, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	for _, key := range result.Conflicts {
+		fmt.Fprintf(os.Stderr, "Warning: %s changed locally and on %s; kept the local version\n", key, syncRemote)
+	}
+	if syncQuiet {
+		return nil
+	}
+	name := strings.TrimPrefix(result.Ref, "refs/heads/")
+	if result.Remote == "" {
+		fmt.Printf("Wrote %s (%s)\n", name, shortSHA(result.Commit))
+	} else {
+		fmt.Printf("Synced %s with %s: pulled %d, pushed %d\n", name, result.Remote, result.Pulled, result.Pushed)
+	}
+	return nil
+}
+
+// databaseSnapshot reads every record from the database.
+func databaseSnapshot(s store.Storage) (*gitsync.Snapshot, error) {
+	insights, err := s.ListInsights("", types.InsightType(""), time.Time{}, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list insights: %w", err)
+	}
+	threads, err := s.ListThreads(types.ThreadStatus(""))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list threads: %w", err)
+	}
+	deps, err := s.ListAllDependencies()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dependencies: %w", err)
+	}
+	return &gitsync.Snapshot{Insights: insights, Threads: threads, Deps: deps}, nil
+}
+
+// applySnapshot upserts the merged records that differ from the database
+// and deletes the insights and dependencies the merge dropped, all in one
+// transaction, and returns how many records were written or deleted.
+func applySnapshot(s store.Storage, ours, merged *gitsync.Snapshot) (int, error) {
+	deleted := gitsync.Deleted(ours, merged)
+	changed := gitsync.Changed(ours, merged)
+	warnUnknownInsightTypes(changed.Insights)
+
+	err := s.ApplyRecords(
+		store.Records{Insights: deleted.Insights, Deps: deleted.Deps},
+		store.Records{Insights: changed.Insights, Threads: changed.Threads, Deps: changed.Deps},
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to apply merged records: %w", err)
+	}
+	return changed.Len() + len(deleted.Deps) + len(deleted.Insights), nil
+}
+
+// syncCommit picks the commit the local ref should point at: the remote
+// commit when the merge adds nothing to it, the local commit when nothing
+// changed locally, and otherwise a new commit on top of both.
+func syncCommit(repo gitsync.Repo, tree, local, remote string) (string, error) {
+	if remote != "" && repo.TreeOf(remote) == tree && (local == "" || repo.IsAncestor(local, remote)) {
+		return remote, nil
+	}
+	if local != "" && repo.TreeOf(local) == tree && (remote == "" || repo.IsAncestor(remote, local)) {
+		return local, nil
+	}
+	commit, err := repo.CommitTree(tree, "bdc sync", local, remote)
+	if err != nil {
+		return "", fmt.Errorf("failed to commit snapshot: %w", err)
+	}
+	return commit, nil
+}
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+	syncCmd.Flags().StringVar(&syncRemote, "remote", "origin", "remote to fetch from and push to")
+	syncCmd.Flags().StringVar(&syncRef, "ref", gitsync.DefaultRef, "git ref that stores the data")
+	syncCmd.Flags().BoolVar(&syncLocal, "local", false, "only write the database to the local ref or branch (no fetch or push)")
+	syncCmd.Flags().BoolVarP(&syncQuiet, "quiet", "q", false, "suppress output (for hooks)")
+}
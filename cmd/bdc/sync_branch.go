@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/gitsync"
+	"github.com/brianevanmiller/beadcrumbs/internal/store"
+)
+
+// syncBranchConfigKey holds the sync branch name when the repository is in
+// sync-branch mode (set by bdc init --sync-branch).
+const syncBranchConfigKey = "sync_branch"
+
+// syncWorktreePath returns where the sync branch is checked out: inside the
+// shared .git directory, so it is invisible to the project's worktrees.
+func syncWorktreePath(repo gitsync.Repo, branch string) (string, error) {
+	commonDir, err := repo.CommonDir()
+	if err != nil {
+		return "", fmt.Errorf("not a git repository: %w", err)
+	}
+	return filepath.Join(commonDir, "beadcrumbs-worktrees", branch), nil
+}
+
+// ensureSyncWorktree returns the sync branch's worktree, checking it out
+// (and creating the branch) if needed.
+func ensureSyncWorktree(repo gitsync.Repo, branch, remote string) (gitsync.Repo, error) {
+	path, err := syncWorktreePath(repo, branch)
+	if err != nil {
+		return gitsync.Repo{}, err
+	}
+	if _, err := os.Stat(filepath.Join(path, ".git")); err != nil {
+		if err := repo.AddWorktree(path, branch, remote); err != nil {
+			return gitsync.Repo{}, fmt.Errorf("failed to check out sync branch %s: %w", branch, err)
+		}
+	}
+	return gitsync.Repo{Dir: path}, nil
+}
+
+// runBranchSync commits the database to the sync branch, merges the remote
+// copy of the branch by ID, imports the result and pushes it. With --local it
+// skips the fetch and push but still merges a remote copy fetched earlier
+// (e.g. by git pull).
+func runBranchSync(s store.Storage, repo gitsync.Repo, branch string) (syncResult, error) {
+	result := syncResult{Ref: "refs/heads/" + branch}
+
+	wt, err := ensureSyncWorktree(repo, branch, syncRemote)
+	if err != nil {
+		return result, err
+	}
+
+	// Commit the database as it is now
+	ours, err := databaseSnapshot(s)
+	if err != nil {
+		return result, err
+	}
+	if err := ours.WriteFiles(wt.Dir); err != nil {
+		return result, fmt.Errorf("failed to export to %s: %w", wt.Dir, err)
+	}
+	msg := fmt.Sprintf("bdc: export %d insights, %d threads, %d dependencies",
+		len(ours.Insights), len(ours.Threads), len(ours.Deps))
+	if _, err := wt.CommitAll(msg); err != nil {
+		return result, fmt.Errorf("failed to commit to %s: %w", branch, err)
+	}
+	head := wt.ResolveRef("HEAD")
+
+	var remote string
+	if syncLocal {
+		remote = wt.ResolveRef("refs/remotes/" + syncRemote + "/" + branch)
+	} else {
+		result.Remote = syncRemote
+		if remote, err = wt.FetchBranch(syncRemote, branch); err != nil {
+			return result, fmt.Errorf("failed to fetch %s from %s: %w", branch, syncRemote, err)
+		}
+	}
+	theirs, err := wt.ReadSnapshot(remote)
+	if err != nil {
+		return result, fmt.Errorf("failed to read %s/%s: %w", syncRemote, branch, err)
+	}
+
+	if remote != "" && !wt.IsAncestor(remote, head) {
+		if wt.IsAncestor(head, remote) {
+			if err := wt.FastForward(remote); err != nil {
+				return result, fmt.Errorf("failed to fast-forward %s: %w", branch, err)
+			}
+		} else {
+			base, err := wt.ReadSnapshot(wt.MergeBase(head, remote))
+			if err != nil {
+				return result, fmt.Errorf("failed to read merge base: %w", err)
+			}
+			res := gitsync.Merge(base, ours, theirs)
+			result.Conflicts = res.Conflicts
+			if err := res.Merged.WriteFiles(wt.Dir); err != nil {
+				return result, fmt.Errorf("failed to export to %s: %w", wt.Dir, err)
+			}
+			msg := fmt.Sprintf("bdc sync: merge %s/%s", syncRemote, branch)
+			if _, err := wt.CommitAll(msg, remote); err != nil {
+				return result, fmt.Errorf("failed to commit merge to %s: %w", branch, err)
+			}
+		}
+
+		head = wt.ResolveRef("HEAD")
+		merged, err := wt.ReadSnapshot(head)
+		if err != nil {
+			return result, fmt.Errorf("failed to read %s: %w", branch, err)
+		}
+		if result.Pulled, err = applySnapshot(s, ours, merged); err != nil {
+			return result, err
+		}
+	}
+	result.Commit = head
+
+	if !syncLocal && head != remote {
+		final, err := wt.ReadSnapshot(head)
+		if err != nil {
+			return result, fmt.Errorf("failed to read %s: %w", branch, err)
+		}
+		result.Pushed = gitsync.Changed(theirs, final).Len()
+		if err := wt.PushBranch(syncRemote, branch); err != nil {
+			return result, fmt.Errorf("failed to push %s to %s (run bdc sync again): %w", branch, syncRemote, err)
+		}
+	}
+	return result, nil
+}
+
+// importSyncBranch loads an existing sync branch into a new database, e.g.
+// after cloning a repository whose team already uses sync-branch mode.
+func importSyncBranch(s store.Storage, wt gitsync.Repo) (int, error) {
+	snap, err := wt.ReadSnapshot(wt.ResolveRef("HEAD"))
+	if err != nil {
+		return 0, err
+	}
+	return applySnapshot(s, &gitsync.Snapshot{}, snap)
+}
+
+// leaveSyncBranchMode clears the sync branch setting and removes its
+// worktree. The branch itself is kept. It returns the branch name, or "" if
+// the repository was not in sync-branch mode.
+func leaveSyncBranchMode(s store.Storage) (string, error) {
+	branch, err := s.GetConfig(syncBranchConfigKey)
+	if err != nil || branch == "" {
+		return "", err
+	}
+	if err := s.SetConfig(syncBranchConfigKey, ""); err != nil {
+		return "", fmt.Errorf("failed to update config: %w", err)
+	}
+	repo := gitsync.Repo{Dir: filepath.Dir(dbPath)}
+	if path, err := syncWorktreePath(repo, branch); err == nil {
+		if err := repo.RemoveWorktree(path); err != nil {
+			fmt.Printf("  Warning: failed to remove sync worktree: %v\n", err)
+		}
+	}
+	return branch, nil
+}
@@ -18,10 +18,14 @@ import (
 )
 
 var (
-	threadStatus    string
-	threadLinearRef string
-	threadBeadRef   string
-	threadGitHubRef string
+	threadStatus      string
+	threadCloseStatus string
+	threadLinearRef   string
+	threadBeadRef     string
+	threadGitHubRef   string
+	threadParentID    string
+	threadRollup      bool
+	threadCloseBranch bool
 )
 
 var threadCmd = &cobra.Command{
@@ -33,7 +37,16 @@ var threadCmd = &cobra.Command{
 var threadNewCmd = &cobra.Command{
 	Use:   "new <title>",
 	Short: "Create a new thread",
-	Args:  cobra.ExactArgs(1),
+	Long: `Create a new insight thread.
+
+Use --parent to nest the thread as a sub-investigation of an existing one.
+Sub-threads appear under their parent in 'thread list' and 'thread show',
+and their decisions are included in the parent's Linear/GitHub summaries.
+
+Examples:
+  bdc thread new "Auth token expiry"
+  bdc thread new "Refresh token race" --parent thr-7f2a`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		title := args[0]
 
@@ -47,6 +60,14 @@ var threadNewCmd = &cobra.Command{
 		}
 		defer closeStore()
 
+		if threadParentID != "" {
+			parent, err := s.GetThread(redirectThreadID(s, threadParentID))
+			if err != nil {
+				return fmt.Errorf("failed to get parent thread: %w", err)
+			}
+			thread.ParentID = parent.ID
+		}
+
 		if err := s.CreateThread(thread); err != nil {
 			return fmt.Errorf("failed to save thread: %w", err)
 		}
@@ -82,6 +103,9 @@ var threadNewCmd = &cobra.Command{
 		}
 
 		fmt.Printf("Created thread: %s\n", thread.ID)
+		if thread.ParentID != "" {
+			fmt.Printf("  Parent: %s\n", thread.ParentID)
+		}
 		return nil
 	},
 }
@@ -269,6 +293,8 @@ Examples:
 		}
 		defer closeStore()
 
+		threadID = redirectThreadID(s, threadID)
+
 		// Verify thread exists
 		thread, err := s.GetThread(threadID)
 		if err != nil {
@@ -321,7 +347,10 @@ Examples:
 var threadShowCmd = &cobra.Command{
 	Use:   "show <id>",
 	Short: "Show thread details",
-	Args:  cobra.ExactArgs(1),
+	Long: `Show a thread, its sub-threads, and its insights.
+
+Use --rollup to include insights from all sub-threads.`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		threadID := args[0]
 
@@ -331,6 +360,8 @@ var threadShowCmd = &cobra.Command{
 		}
 		defer closeStore()
 
+		threadID = redirectThreadID(s, threadID)
+
 		// Get the thread
 		thread, err := s.GetThread(threadID)
 		if err != nil {
@@ -340,15 +371,25 @@ var threadShowCmd = &cobra.Command{
 			return fmt.Errorf("thread not found: %s", threadID)
 		}
 
-		// Get insights in this thread
-		insights, err := s.ListInsights(threadID, "", time.Time{}, "")
+		// Get insights in this thread (and its sub-threads with --rollup)
+		var insights []*types.Insight
+		if threadRollup {
+			insights, err = rollupInsights(s, threadID, "")
+		} else {
+			insights, err = s.ListInsights(threadID, "", time.Time{}, "")
+		}
 		if err != nil {
 			return fmt.Errorf("failed to get insights: %w", err)
 		}
 
 		if jsonOutput {
+			children, err := threadDescendants(s, threadID)
+			if err != nil {
+				return err
+			}
 			detail := threadDetail{
 				InsightThread: thread,
+				Children:      children,
 				Insights:      insights,
 			}
 			out, err := json.MarshalIndent(detail, "", "  ")
@@ -372,14 +413,18 @@ var threadShowCmd = &cobra.Command{
 			fmt.Printf("Linked: %s (%s)\n", m.ExternalID, m.System)
 		}
 
-		if thread.CurrentUnderstanding != "" {
-			fmt.Printf("\nCurrent Understanding:\n%s\n", thread.CurrentUnderstanding)
-		}
+		printThreadHierarchy(s, thread)
+
+		printCurrentUnderstanding(thread)
 
 		if len(insights) > 0 {
 			fmt.Printf("\nInsights (%d):\n", len(insights))
 			for _, insight := range insights {
-				fmt.Printf("  %s [%s] %s\n", insight.ID, insight.Type, truncate(insight.Content, 60))
+				line := fmt.Sprintf("  %s [%s] %s", insight.ID, insight.Type, truncate(insight.Content, 60))
+				if insight.ThreadID != threadID {
+					line += fmt.Sprintf(" (%s)", insight.ThreadID)
+				}
+				fmt.Println(line)
 			}
 		}
 
@@ -409,6 +454,17 @@ var threadListCmd = &cobra.Command{
 			return fmt.Errorf("failed to get threads: %w", err)
 		}
 
+		// Merged threads are redirects; only list them when asked for
+		if status != types.ThreadMerged {
+			live := threads[:0]
+			for _, t := range threads {
+				if t.Status != types.ThreadMerged {
+					live = append(live, t)
+				}
+			}
+			threads = live
+		}
+
 		if len(threads) == 0 {
 			if jsonOutput {
 				fmt.Println("[]")
@@ -427,28 +483,61 @@ var threadListCmd = &cobra.Command{
 			return nil
 		}
 
-		// Display threads
-		for _, thread := range threads {
-			fmt.Printf("%s [%s] %s\n", thread.ID, thread.Status, thread.Title)
-		}
+		// Display threads, nesting sub-threads under their parents
+		printThreadTree(threads)
 
 		return nil
 	},
 }
 
 var threadCloseCmd = &cobra.Command{
-	Use:   "close <id>",
+	Use:   "close <id> | --branch [name]",
 	Short: "Close a thread",
-	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		threadID := args[0]
+	Long: `Close a thread, pushing a summary to linked Linear/GitHub items on conclude.
 
+With --branch, close branch threads (see 'bdc thread config auto_branch')
+instead: the named branch's thread, or without a name every active branch
+thread whose branch has been merged into HEAD (including rebase merges),
+whose upstream is gone, or that was deleted. Name the branch to close a
+squash-merged branch that never had an upstream.
+
+Examples:
+  bdc thread close thr-7f2a
+  bdc thread close thr-7f2a --status abandoned
+  bdc thread close --branch                  # after merging or deleting branches
+  bdc thread close --branch feature/refresh`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if threadCloseBranch {
+			return cobra.MaximumNArgs(1)(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
 		s, err := getStore()
 		if err != nil {
 			return err
 		}
 		defer closeStore()
 
+		// Determine the new status
+		newStatus := types.ThreadConcluded
+		if threadCloseStatus != "" {
+			newStatus = types.ThreadStatus(threadCloseStatus)
+			if newStatus != types.ThreadConcluded && newStatus != types.ThreadAbandoned {
+				return fmt.Errorf("invalid status: %s. Use 'concluded' or 'abandoned'", threadCloseStatus)
+			}
+		}
+
+		if threadCloseBranch {
+			var branch string
+			if len(args) > 0 {
+				branch = args[0]
+			}
+			return closeBranchThreads(s, branch, newStatus)
+		}
+
+		threadID := redirectThreadID(s, args[0])
+
 		// Get the thread
 		thread, err := s.GetThread(threadID)
 		if err != nil {
@@ -458,26 +547,8 @@ var threadCloseCmd = &cobra.Command{
 			return fmt.Errorf("thread not found: %s", threadID)
 		}
 
-		// Determine the new status
-		newStatus := types.ThreadConcluded
-		if threadStatus != "" {
-			newStatus = types.ThreadStatus(threadStatus)
-			if newStatus != types.ThreadConcluded && newStatus != types.ThreadAbandoned {
-				return fmt.Errorf("invalid status: %s. Use 'concluded' or 'abandoned'", threadStatus)
-			}
-		}
-
-		// Update the thread status
-		thread.Status = newStatus
-		thread.UpdatedAt = time.Now()
-		if err := s.UpdateThread(thread); err != nil {
-			return fmt.Errorf("failed to update thread status: %w", err)
-		}
-
-		// Push summaries to integrations on conclude
-		if newStatus == types.ThreadConcluded {
-			pushLinearSummaryOnClose(s, thread)
-			pushGitHubSummaryOnClose(s, thread)
+		if err := closeThread(s, thread, newStatus); err != nil {
+			return err
 		}
 
 		if jsonOutput {
@@ -494,6 +565,34 @@ var threadCloseCmd = &cobra.Command{
 	},
 }
 
+// closeThread sets a thread's status and, on conclude, pushes summaries to
+// its linked integrations.
+func closeThread(s store.Storage, thread *types.InsightThread, status types.ThreadStatus) error {
+	// Warn (but don't block) when sub-investigations are still open
+	if active := activeChildren(s, thread.ID); len(active) > 0 {
+		ids := make([]string, len(active))
+		for i, c := range active {
+			ids[i] = c.ID
+		}
+		fmt.Fprintf(os.Stderr, "Warning: thread %s has %d active sub-thread(s): %s\n",
+			thread.ID, len(active), strings.Join(ids, ", "))
+	}
+
+	// Update the thread status
+	thread.Status = status
+	thread.UpdatedAt = time.Now()
+	if err := s.UpdateThread(thread); err != nil {
+		return fmt.Errorf("failed to update thread status: %w", err)
+	}
+
+	// Push summaries to integrations on conclude
+	if status == types.ThreadConcluded {
+		pushLinearSummaryOnClose(s, thread)
+		pushGitHubSummaryOnClose(s, thread)
+	}
+	return nil
+}
+
 // pushLinearSummaryOnClose posts a summary comment to the linked Linear issue.
 func pushLinearSummaryOnClose(s store.Storage, thread *types.InsightThread) {
 	// Check if auto-push is disabled
@@ -529,14 +628,18 @@ func pushLinearSummaryOnClose(s store.Storage, thread *types.InsightThread) {
 		return
 	}
 
-	// Gather insights
+	// Gather insights, plus sub-thread decisions
 	insights, err := s.ListInsights(thread.ID, "", time.Time{}, "")
-	if err != nil || len(insights) == 0 {
+	if err != nil {
+		return
+	}
+	children := childSummaries(s, thread.ID)
+	if len(insights) == 0 && len(children) == 0 {
 		return
 	}
 
 	// Format and post
-	body := summary.FormatSummary(thread, insights)
+	body := summary.FormatRollupSummary(thread, insights, children)
 	if err := adapter.AddComment(linearMapping.ExternalID, body); err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: Failed to post summary to Linear %s: %v\n", linearMapping.ExternalID, err)
 		return
@@ -581,13 +684,19 @@ func pushGitHubSummaryOnClose(s store.Storage, thread *types.InsightThread) {
 		// Parse "owner/repo#42" from ExternalID
 		prRepo, prNumber = parseGitHubPRRef(ghMapping.ExternalID)
 	} else {
-		// Auto-detect PR from current branch if not explicitly disabled
+		// Auto-detect the PR if not explicitly disabled
 		autoDetect, _ := s.GetConfig("github.auto_detect")
 		if autoDetect == "false" {
 			return
 		}
 
-		pr, err := ghCli.CurrentBranchPR()
+		// Branch threads use their branch's PR; others the checked-out branch's
+		var pr *gh.PRInfo
+		if branch := branchThreadName(s, thread.ID); branch != "" {
+			pr, err = ghCli.ViewPR(branch, "")
+		} else {
+			pr, err = ghCli.CurrentBranchPR()
+		}
 		if err != nil || pr == nil {
 			return // no PR for current branch, silently skip
 		}
@@ -615,14 +724,18 @@ func pushGitHubSummaryOnClose(s store.Storage, thread *types.InsightThread) {
 		return
 	}
 
-	// Gather insights
+	// Gather insights, plus sub-thread decisions
 	insights, err := s.ListInsights(thread.ID, "", time.Time{}, "")
-	if err != nil || len(insights) == 0 {
+	if err != nil {
+		return
+	}
+	children := childSummaries(s, thread.ID)
+	if len(insights) == 0 && len(children) == 0 {
 		return
 	}
 
 	// Format and post
-	body := summary.FormatSummary(thread, insights)
+	body := summary.FormatRollupSummary(thread, insights, children)
 	if err := ghCli.AddComment(prRepo, prNumber, body); err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: Failed to post summary to GitHub %s#%d: %v\n", prRepo, prNumber, err)
 		return
@@ -642,6 +755,9 @@ func init() {
 	threadNewCmd.Flags().StringVar(&threadLinearRef, "linear", "", "link to Linear issue (e.g., ENG-456)")
 	threadNewCmd.Flags().StringVar(&threadBeadRef, "bead", "", "link to bead task (e.g., bd-abc1)")
 	threadNewCmd.Flags().StringVar(&threadGitHubRef, "github", "", "link to GitHub PR (e.g., owner/repo#42)")
-	threadListCmd.Flags().StringVar(&threadStatus, "status", "", "filter by status (active|concluded|abandoned)")
-	threadCloseCmd.Flags().StringVar(&threadStatus, "status", "concluded", "status to set (concluded|abandoned)")
+	threadNewCmd.Flags().StringVar(&threadParentID, "parent", "", "parent thread ID (creates a sub-thread)")
+	threadShowCmd.Flags().BoolVar(&threadRollup, "rollup", false, "include insights from sub-threads")
+	threadListCmd.Flags().StringVar(&threadStatus, "status", "", "filter by status (active|concluded|abandoned|merged)")
+	threadCloseCmd.Flags().StringVar(&threadCloseStatus, "status", "concluded", "status to set (concluded|abandoned)")
+	threadCloseCmd.Flags().BoolVar(&threadCloseBranch, "branch", false, "close branch threads (the named branch's, or those of merged/deleted branches)")
 }
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/store"
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+	"github.com/spf13/cobra"
+)
+
+var threadSplitFrom string
+var threadSplitTitle string
+
+var threadMergeCmd = &cobra.Command{
+	Use:   "merge <src> <dst>",
+	Short: "Merge one thread into another",
+	Long: `Move all insights, external ref links, and sub-threads from <src> into <dst>.
+
+Insight IDs and dependency edges are unchanged. The source thread is kept as a
+redirect (status "merged"), so commands given the old ID, including IDs quoted
+in Linear or GitHub comments, resolve to the destination.
+
+Example:
+  bdc thread merge thr-a1b2 thr-c3d4`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := getStore()
+		if err != nil {
+			return err
+		}
+		defer closeStore()
+
+		src, err := s.GetThread(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to get thread: %w", err)
+		}
+		if src.Status == types.ThreadMerged {
+			return fmt.Errorf("thread %s was already merged into %s", src.ID, src.MergedInto)
+		}
+		dst, err := s.GetThread(redirectThreadID(s, args[1]))
+		if err != nil {
+			return fmt.Errorf("failed to get thread: %w", err)
+		}
+		if src.ID == dst.ID {
+			return fmt.Errorf("cannot merge thread %s into itself", src.ID)
+		}
+		if isThreadDescendant(s, dst.ID, src.ID) {
+			return fmt.Errorf("cannot merge %s into its own sub-thread %s", src.ID, dst.ID)
+		}
+
+		insights, err := s.ListInsights(src.ID, "", time.Time{}, "")
+		if err != nil {
+			return fmt.Errorf("failed to get insights: %w", err)
+		}
+		mappings, _ := s.GetExternalRefMappingsByThread(src.ID)
+
+		if err := s.MergeThreads(src.ID, dst.ID); err != nil {
+			return fmt.Errorf("failed to merge threads: %w", err)
+		}
+
+		if jsonOutput {
+			result := map[string]interface{}{
+				"from":          src.ID,
+				"into":          dst.ID,
+				"insights":      len(insights),
+				"external_refs": len(mappings),
+			}
+			out, err := json.MarshalIndent(result, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal JSON: %w", err)
+			}
+			fmt.Println(string(out))
+			return nil
+		}
+
+		fmt.Printf("Merged %s into %s (%d insights, %d external refs)\n", src.ID, dst.ID, len(insights), len(mappings))
+		fmt.Printf("  %s now redirects to %s\n", src.ID, dst.ID)
+		return nil
+	},
+}
+
+var threadSplitCmd = &cobra.Command{
+	Use:   "split <thread-id> --from <insight-id>",
+	Short: "Split a thread into two at an insight",
+	Long: `Move an insight and every later insight in the thread into a new thread.
+
+The new thread shares the original's parent. Insight IDs and dependency edges
+are unchanged.
+
+Examples:
+  bdc thread split thr-a1b2 --from ins-9f3e
+  bdc thread split thr-a1b2 --from ins-9f3e --title "Cache invalidation"`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if threadSplitFrom == "" {
+			return fmt.Errorf("--from is required")
+		}
+
+		s, err := getStore()
+		if err != nil {
+			return err
+		}
+		defer closeStore()
+
+		thread, err := s.GetThread(redirectThreadID(s, args[0]))
+		if err != nil {
+			return fmt.Errorf("failed to get thread: %w", err)
+		}
+
+		insights, err := s.ListInsights(thread.ID, "", time.Time{}, "")
+		if err != nil {
+			return fmt.Errorf("failed to get insights: %w", err)
+		}
+		reverseInsights(insights)
+
+		start := -1
+		for i, ins := range insights {
+			if ins.ID == threadSplitFrom {
+				start = i
+				break
+			}
+		}
+		if start < 0 {
+			return fmt.Errorf("insight %s is not in thread %s", threadSplitFrom, thread.ID)
+		}
+
+		title := threadSplitTitle
+		if title == "" {
+			title = thread.Title + " (split)"
+		}
+		newThread := types.NewThread(title)
+		newThread.ParentID = thread.ParentID
+		if err := s.CreateThread(newThread); err != nil {
+			return fmt.Errorf("failed to save thread: %w", err)
+		}
+
+		var ids []string
+		for _, ins := range insights[start:] {
+			ids = append(ids, ins.ID)
+		}
+		if err := s.MoveInsights(ids, newThread.ID); err != nil {
+			return fmt.Errorf("failed to move insights: %w", err)
+		}
+
+		if jsonOutput {
+			result := map[string]interface{}{
+				"from":     thread.ID,
+				"thread":   newThread,
+				"insights": ids,
+			}
+			out, err := json.MarshalIndent(result, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal JSON: %w", err)
+			}
+			fmt.Println(string(out))
+			return nil
+		}
+
+		fmt.Printf("Created thread: %s\n", newThread.ID)
+		fmt.Printf("  Moved %d insight(s) from %s\n", len(ids), thread.ID)
+		return nil
+	},
+}
+
+// followThreadRedirect returns the live thread ID for id, following merge
+// redirects. IDs that are not merged (or not found) are returned unchanged.
+func followThreadRedirect(s store.Storage, id string) string {
+	seen := make(map[string]bool)
+	for !seen[id] {
+		seen[id] = true
+		thread, err := s.GetThread(id)
+		if err != nil || thread.MergedInto == "" {
+			return id
+		}
+		id = thread.MergedInto
+	}
+	return id
+}
+
+// redirectThreadID is followThreadRedirect plus a note on stderr when the
+// ID was redirected.
+func redirectThreadID(s store.Storage, id string) string {
+	resolved := followThreadRedirect(s, id)
+	if resolved != id && !jsonOutput {
+		fmt.Fprintf(os.Stderr, "Note: %s was merged into %s\n", id, resolved)
+	}
+	return resolved
+}
+
+// isThreadDescendant reports whether id is below ancestorID in the thread tree.
+func isThreadDescendant(s store.Storage, id, ancestorID string) bool {
+	descendants, err := threadDescendants(s, ancestorID)
+	if err != nil {
+		return false
+	}
+	for _, d := range descendants {
+		if d.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	threadCmd.AddCommand(threadMergeCmd)
+	threadCmd.AddCommand(threadSplitCmd)
+
+	threadSplitCmd.Flags().StringVar(&threadSplitFrom, "from", "", "first insight to move to the new thread")
+	threadSplitCmd.Flags().StringVar(&threadSplitTitle, "title", "", "title for the new thread (default: original title + \" (split)\")")
+}
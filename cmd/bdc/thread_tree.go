@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/store"
+	"github.com/brianevanmiller/beadcrumbs/internal/summary"
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+)
+
+// threadDescendants returns every thread below id, depth-first with each
+// thread's children oldest first. A visited set guards against cycles in
+// hand-edited JSONL.
+func threadDescendants(s store.Storage, id string) ([]*types.InsightThread, error) {
+	var out []*types.InsightThread
+	seen := map[string]bool{id: true}
+
+	var walk func(parentID string) error
+	walk = func(parentID string) error {
+		children, err := s.ListChildThreads(parentID)
+		if err != nil {
+			return err
+		}
+		for _, child := range children {
+			if seen[child.ID] {
+				continue
+			}
+			seen[child.ID] = true
+			out = append(out, child)
+			if err := walk(child.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := walk(id); err != nil {
+		return nil, fmt.Errorf("failed to list sub-threads: %w", err)
+	}
+	return out, nil
+}
+
+// rollupInsights returns the insights of a thread and all its descendants,
+// newest first like ListInsights.
+func rollupInsights(s store.Storage, threadID, origin string) ([]*types.Insight, error) {
+	insights, err := s.ListInsights(threadID, "", time.Time{}, origin)
+	if err != nil {
+		return nil, err
+	}
+	descendants, err := threadDescendants(s, threadID)
+	if err != nil {
+		return nil, err
+	}
+	for _, child := range descendants {
+		childInsights, err := s.ListInsights(child.ID, "", time.Time{}, origin)
+		if err != nil {
+			return nil, err
+		}
+		insights = append(insights, childInsights...)
+	}
+	sort.SliceStable(insights, func(i, j int) bool {
+		return insights[i].Timestamp.After(insights[j].Timestamp)
+	})
+	return insights, nil
+}
+
+// childSummaries collects each descendant thread with its insights for
+// summary.FormatRollupSummary.
+func childSummaries(s store.Storage, threadID string) []summary.ChildThread {
+	descendants, err := threadDescendants(s, threadID)
+	if err != nil {
+		return nil
+	}
+	var children []summary.ChildThread
+	for _, child := range descendants {
+		insights, err := s.ListInsights(child.ID, "", time.Time{}, "")
+		if err != nil {
+			continue
+		}
+		reverseInsights(insights)
+		children = append(children, summary.ChildThread{Thread: child, Insights: insights})
+	}
+	return children
+}
+
+// printThreadTree renders threads as a forest. Threads whose parent is not in
+// the list (or who have none) are printed as roots, in list order.
+func printThreadTree(threads []*types.InsightThread) {
+	present := make(map[string]bool, len(threads))
+	for _, t := range threads {
+		present[t.ID] = true
+	}
+
+	children := make(map[string][]*types.InsightThread)
+	var roots []*types.InsightThread
+	for _, t := range threads {
+		if t.ParentID != "" && present[t.ParentID] && t.ParentID != t.ID {
+			children[t.ParentID] = append(children[t.ParentID], t)
+		} else {
+			roots = append(roots, t)
+		}
+	}
+
+	seen := make(map[string]bool)
+	var walk func(t *types.InsightThread, prefix, branch string)
+	walk = func(t *types.InsightThread, prefix, branch string) {
+		if seen[t.ID] {
+			return
+		}
+		seen[t.ID] = true
+		fmt.Printf("%s%s%s [%s] %s\n", prefix, branch, t.ID, t.Status, t.Title)
+
+		childPrefix := prefix
+		switch branch {
+		case "├── ":
+			childPrefix += "│   "
+		case "└── ":
+			childPrefix += "    "
+		}
+		kids := children[t.ID]
+		for i, kid := range kids {
+			if i == len(kids)-1 {
+				walk(kid, childPrefix, "└── ")
+			} else {
+				walk(kid, childPrefix, "├── ")
+			}
+		}
+	}
+
+	for _, root := range roots {
+		walk(root, "", "")
+	}
+}
+
+// printThreadHierarchy prints a thread's parent and its sub-thread tree.
+func printThreadHierarchy(s store.Storage, thread *types.InsightThread) {
+	if thread.ParentID != "" {
+		if parent, err := s.GetThread(thread.ParentID); err == nil {
+			fmt.Printf("Parent: %s (%s)\n", parent.ID, parent.Title)
+		} else {
+			fmt.Printf("Parent: %s\n", thread.ParentID)
+		}
+	}
+
+	descendants, err := threadDescendants(s, thread.ID)
+	if err != nil || len(descendants) == 0 {
+		return
+	}
+	fmt.Printf("\nSub-threads (%d):\n", len(descendants))
+	printIndentedThreadTree(descendants)
+}
+
+// printIndentedThreadTree prints threads listed depth-first (as returned by
+// threadDescendants), indenting each one under its parent.
+func printIndentedThreadTree(threads []*types.InsightThread) {
+	depth := make(map[string]int, len(threads))
+	for _, t := range threads {
+		if d, ok := depth[t.ParentID]; ok {
+			depth[t.ID] = d + 1
+		} else {
+			depth[t.ID] = 0
+		}
+		fmt.Printf("  %s%s [%s] %s\n", strings.Repeat("  ", depth[t.ID]), t.ID, t.Status, t.Title)
+	}
+}
+
+// activeChildren returns the direct and indirect sub-threads still active.
+func activeChildren(s store.Storage, threadID string) []*types.InsightThread {
+	descendants, err := threadDescendants(s, threadID)
+	if err != nil {
+		return nil
+	}
+	var active []*types.InsightThread
+	for _, d := range descendants {
+		if d.Status == types.ThreadActive {
+			active = append(active, d)
+		}
+	}
+	return active
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/summary"
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	understandAuthor  string
+	understandSuggest bool
+	understandEdit    bool
+	understandHistory bool
+)
+
+var threadUnderstandCmd = &cobra.Command{
+	Use:   "understand <thread-id> [text]",
+	Short: "Set a thread's current understanding",
+	Long: `Set the "current understanding" summary of a thread.
+
+With text, the understanding is set directly. Without text, $VISUAL or $EDITOR
+opens with the current understanding for editing. Every change is recorded
+with its author and timestamp; --history lists previous versions.
+
+--suggest drafts an understanding from the thread's latest decisions and
+pivots. On its own it only prints the draft; add --edit to open the draft in
+your editor and save the result.
+
+Examples:
+  bdc thread understand thr-7f2a "Token refresh races with logout; fix with a mutex"
+  bdc thread understand thr-7f2a                  # Edit in $EDITOR
+  bdc thread understand thr-7f2a --suggest        # Print a draft
+  bdc thread understand thr-7f2a --suggest --edit # Edit the draft, then save
+  bdc thread understand thr-7f2a --history`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runThreadUnderstand,
+}
+
+func runThreadUnderstand(cmd *cobra.Command, args []string) error {
+	s, err := getStore()
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	thread, err := s.GetThread(redirectThreadID(s, args[0]))
+	if err != nil {
+		return fmt.Errorf("failed to get thread: %w", err)
+	}
+
+	if understandHistory {
+		return printUnderstandingHistory(thread.ID, thread.UnderstandingHistory)
+	}
+
+	var content string
+	switch {
+	case len(args) == 2:
+		content = args[1]
+
+	case understandSuggest:
+		insights, err := s.ListInsights(thread.ID, "", time.Time{}, "")
+		if err != nil {
+			return fmt.Errorf("failed to get insights: %w", err)
+		}
+		reverseInsights(insights)
+		draft := summary.DraftUnderstanding(insights)
+		if draft == "" {
+			return fmt.Errorf("thread %s has no decisions or pivots to draft from", thread.ID)
+		}
+		if !understandEdit {
+			fmt.Println(draft)
+			return nil
+		}
+		content, err = editText(draft)
+		if err != nil {
+			return err
+		}
+
+	default:
+		content, err = editText(thread.CurrentUnderstanding)
+		if err != nil {
+			return err
+		}
+	}
+
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return fmt.Errorf("aborting: understanding is empty")
+	}
+	if content == thread.CurrentUnderstanding {
+		if !jsonOutput {
+			fmt.Println("Understanding unchanged")
+		}
+		return nil
+	}
+
+	thread.SetUnderstanding(content, understandingAuthor(), time.Now())
+	if err := s.UpdateThread(thread); err != nil {
+		return fmt.Errorf("failed to update thread: %w", err)
+	}
+
+	if jsonOutput {
+		out, err := json.MarshalIndent(thread, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	fmt.Printf("Updated understanding for %s (revision %d)\n", thread.ID, len(thread.UnderstandingHistory))
+	return nil
+}
+
+// understandingAuthor returns --author, falling back to git user.name.
+func understandingAuthor() string {
+	if understandAuthor != "" {
+		return understandAuthor
+	}
+	out, err := exec.Command("git", "config", "user.name").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// scissorsLine marks the end of the text in the editor; like git's
+// commit.cleanup=scissors, everything from it on is dropped, so Markdown
+// headings above it survive.
+const scissorsLine = "# ------------------------ >8 ------------------------"
+
+// editText opens initial in $VISUAL/$EDITOR (default vi) and returns the
+// saved text up to the scissors line.
+func editText(initial string) (string, error) {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	f, err := os.CreateTemp("", "bdc-understanding-*.md")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	body := initial + "\n\n" + scissorsLine + "\n# Do not modify or remove the line above.\n# Write the thread's current understanding above it; everything below is\n# ignored, and an empty message aborts.\n"
+	if _, err := f.WriteString(body); err != nil {
+		f.Close()
+		return "", fmt.Errorf("failed to write temp file: %w", err)
+	}
+	f.Close()
+
+	// Run through the shell so EDITOR values like "code --wait" work.
+	c := exec.Command("sh", "-c", editor+` "$1"`, "sh", f.Name())
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	if err := c.Run(); err != nil {
+		return "", fmt.Errorf("editor %q failed: %w", editor, err)
+	}
+
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		return "", fmt.Errorf("failed to read edited file: %w", err)
+	}
+
+	var kept []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line == scissorsLine {
+			break
+		}
+		kept = append(kept, line)
+	}
+	return strings.Join(kept, "\n"), nil
+}
+
+// printCurrentUnderstanding prints a thread's understanding with attribution.
+func printCurrentUnderstanding(thread *types.InsightThread) {
+	if thread.CurrentUnderstanding == "" {
+		return
+	}
+	fmt.Printf("\nCurrent Understanding:\n%s\n", thread.CurrentUnderstanding)
+	if rev := thread.LatestUnderstanding(); rev != nil {
+		by := ""
+		if rev.AuthorID != "" {
+			by = " by " + rev.AuthorID
+		}
+		fmt.Printf("(updated %s%s)\n", rev.UpdatedAt.Format("2006-01-02 15:04"), by)
+	}
+}
+
+func printUnderstandingHistory(threadID string, history []types.UnderstandingRevision) error {
+	if jsonOutput {
+		if history == nil {
+			history = []types.UnderstandingRevision{}
+		}
+		out, err := json.MarshalIndent(history, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	if len(history) == 0 {
+		fmt.Printf("No understanding recorded for %s\n", threadID)
+		return nil
+	}
+
+	// Newest first
+	for i := len(history) - 1; i >= 0; i-- {
+		rev := history[i]
+		author := rev.AuthorID
+		if author == "" {
+			author = "unknown"
+		}
+		fmt.Printf("#%d  %s  %s\n", i+1, rev.UpdatedAt.Format("2006-01-02 15:04"), author)
+		for _, line := range strings.Split(rev.Content, "\n") {
+			fmt.Printf("    %s\n", line)
+		}
+	}
+	return nil
+}
+
+func init() {
+	threadCmd.AddCommand(threadUnderstandCmd)
+
+	threadUnderstandCmd.Flags().StringVar(&understandAuthor, "author", "", "who wrote this understanding (default: git user.name)")
+	threadUnderstandCmd.Flags().BoolVar(&understandSuggest, "suggest", false, "draft from the thread's latest decisions and pivots")
+	threadUnderstandCmd.Flags().BoolVar(&understandEdit, "edit", false, "with --suggest, edit the draft in $EDITOR and save it")
+	threadUnderstandCmd.Flags().BoolVar(&understandHistory, "history", false, "show previous understandings")
+}
@@ -10,7 +10,11 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var timelineOrigin string
+var (
+	timelineOrigin    string
+	timelineRollup    bool
+	timelineGitFilter gitFilter
+)
 
 var timelineCmd = &cobra.Command{
 	Use:   "timeline [thread-id]",
@@ -25,8 +29,9 @@ Symbols:
   ◆ - decision (committed to approach)
 
 Example:
-  bdc timeline              # Show all insights
-  bdc timeline thr-7f2a     # Show insights for specific thread`,
+  bdc timeline                       # Show all insights
+  bdc timeline thr-7f2a              # Show insights for specific thread
+  bdc timeline thr-7f2a --rollup     # Include insights from sub-threads`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runTimeline,
 }
@@ -40,14 +45,20 @@ func runTimeline(cmd *cobra.Command, args []string) error {
 
 	var threadID string
 	if len(args) > 0 {
-		threadID = args[0]
+		threadID = redirectThreadID(st, args[0])
 	}
 
 	// Get insights
-	insights, err := st.ListInsights(threadID, "", time.Time{}, timelineOrigin)
+	var insights []*types.Insight
+	if timelineRollup && threadID != "" {
+		insights, err = rollupInsights(st, threadID, timelineOrigin)
+	} else {
+		insights, err = st.ListInsights(threadID, "", time.Time{}, timelineOrigin)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to list insights: %w", err)
 	}
+	insights = timelineGitFilter.apply(insights)
 
 	if len(insights) == 0 {
 		if jsonOutput {
@@ -146,6 +157,9 @@ func getInsightSymbol(insightType types.InsightType) string {
 	case types.InsightDecision:
 		return "◆"
 	default:
+		if def, ok := types.LookupCustomInsightType(insightType); ok {
+			return def.Symbol
+		}
 		return "○"
 	}
 }
@@ -159,4 +173,6 @@ func reverseInsights(insights []*types.Insight) {
 func init() {
 	rootCmd.AddCommand(timelineCmd)
 	timelineCmd.Flags().StringVar(&timelineOrigin, "origin", "", "filter by origin (exact match)")
+	timelineGitFilter.register(timelineCmd)
+	timelineCmd.Flags().BoolVar(&timelineRollup, "rollup", false, "include insights from sub-threads of the given thread")
 }
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/store"
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+	"github.com/spf13/cobra"
+)
+
+var whyFile string
+
+var whyCmd = &cobra.Command{
+	Use:   "why [commit-ish]",
+	Short: "Show the insights that motivated a commit",
+	Long: `Explain why a commit exists by following its Insight-Refs trailers to the
+linked insights and the trail of each thread involved.
+
+Trailers are proposed automatically by the prepare-commit-msg hook that
+'bdc init' installs (see 'bdc commit-msg'). With --file, every commit in the
+file's history (following renames) that carries a trailer is shown, newest
+first.
+
+Examples:
+  bdc why                     # HEAD
+  bdc why a1b2c3d
+  bdc why --file src/auth/jwt.go
+  bdc why HEAD~3 --json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runWhy,
+}
+
+// whyCommit is a commit and the insights its trailers point to.
+type whyCommit struct {
+	SHA      string            `json:"sha"`
+	Subject  string            `json:"subject"`
+	Author   string            `json:"author"`
+	Date     time.Time         `json:"date"`
+	Refs     []string          `json:"refs"`
+	Insights []*types.Insight  `json:"insights"`
+	Threads  []whyThreadDetail `json:"threads"`
+	Missing  []string          `json:"missing,omitempty"` // Refs not in this database
+}
+
+// whyThreadDetail is a referenced thread with its full insight trail.
+type whyThreadDetail struct {
+	Thread *types.InsightThread `json:"thread"`
+	Trail  []*types.Insight     `json:"trail"`
+}
+
+func runWhy(cmd *cobra.Command, args []string) error {
+	if whyFile != "" && len(args) > 0 {
+		return fmt.Errorf("use either a commit or --file, not both")
+	}
+
+	var commits []whyCommit
+	var err error
+	if whyFile != "" {
+		commits, err = gitCommitsWithRefs("log", "--follow", "--", whyFile)
+	} else {
+		rev := "HEAD"
+		if len(args) > 0 {
+			rev = args[0]
+		}
+		commits, err = gitCommitsWithRefs("log", "-1", rev, "--")
+	}
+	if err != nil {
+		return err
+	}
+
+	s, err := getReadOnlyStore()
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	for i := range commits {
+		if err := resolveWhyRefs(s, &commits[i]); err != nil {
+			return err
+		}
+	}
+
+	if whyFile != "" {
+		// Only commits that actually reference insights are interesting.
+		var linked []whyCommit
+		for _, c := range commits {
+			if len(c.Refs) > 0 {
+				linked = append(linked, c)
+			}
+		}
+		commits = linked
+	}
+
+	if jsonOutput {
+		if commits == nil {
+			commits = []whyCommit{}
+		}
+		var out []byte
+		if whyFile != "" {
+			out, err = json.MarshalIndent(commits, "", "  ")
+		} else {
+			out, err = json.MarshalIndent(commits[0], "", "  ")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	if len(commits) == 0 {
+		fmt.Printf("No commits touching %s reference insights\n", whyFile)
+		return nil
+	}
+	for i, c := range commits {
+		if i > 0 {
+			fmt.Println()
+		}
+		printWhyCommit(s, c)
+	}
+	return nil
+}
+
+// gitCommitsWithRefs runs git with a fixed format and parses each commit's
+// header and Insight-Refs trailers.
+func gitCommitsWithRefs(args ...string) ([]whyCommit, error) {
+	const format = "--format=%H%x1f%an%x1f%aI%x1f%s%x1f%B%x1e"
+	out, err := gitOutput(append([]string{args[0], format}, args[1:]...)...)
+	if err != nil {
+		return nil, fmt.Errorf("git %s failed (not a commit or not a git repository?)", args[0])
+	}
+
+	var commits []whyCommit
+	for _, record := range strings.Split(out, "\x1e") {
+		fields := strings.SplitN(strings.TrimSpace(record), "\x1f", 5)
+		if len(fields) < 5 {
+			continue
+		}
+		date, _ := time.Parse(time.RFC3339, fields[2])
+		commits = append(commits, whyCommit{
+			SHA:     fields[0],
+			Author:  fields[1],
+			Date:    date,
+			Subject: fields[3],
+			Refs:    parseInsightRefs(fields[4]),
+		})
+	}
+	return commits, nil
+}
+
+// resolveWhyRefs loads the insights and threads a commit references. A
+// referenced insight pulls in its thread, so the trail is always shown.
+func resolveWhyRefs(s store.Storage, c *whyCommit) error {
+	c.Insights = []*types.Insight{}
+	c.Threads = []whyThreadDetail{}
+
+	var threadIDs []string
+	seenThread := make(map[string]bool)
+	addThread := func(id string) {
+		if id != "" && !seenThread[id] {
+			seenThread[id] = true
+			threadIDs = append(threadIDs, id)
+		}
+	}
+
+	for _, ref := range c.Refs {
+		if strings.HasPrefix(ref, "thr-") {
+			addThread(followThreadRedirect(s, ref))
+			continue
+		}
+		ins, err := s.GetInsight(ref)
+		if err != nil {
+			c.Missing = append(c.Missing, ref)
+			continue
+		}
+		c.Insights = append(c.Insights, ins)
+		addThread(followThreadRedirect(s, ins.ThreadID))
+	}
+
+	for _, id := range threadIDs {
+		thread, err := s.GetThread(id)
+		if err != nil {
+			c.Missing = append(c.Missing, id)
+			continue
+		}
+		trail, err := s.ListInsights(id, "", time.Time{}, "")
+		if err != nil {
+			return fmt.Errorf("failed to list insights: %w", err)
+		}
+		reverseInsights(trail)
+		c.Threads = append(c.Threads, whyThreadDetail{Thread: thread, Trail: trail})
+	}
+	return nil
+}
+
+func printWhyCommit(s store.Storage, c whyCommit) {
+	fmt.Printf("commit %s  %s\n", shortSHA(c.SHA), c.Subject)
+	fmt.Printf("Author: %s  %s\n", c.Author, c.Date.Format("2006-01-02 15:04"))
+
+	if len(c.Refs) == 0 {
+		fmt.Println("\nNo Insight-Refs trailer on this commit")
+		return
+	}
+
+	if len(c.Insights) > 0 {
+		fmt.Println("\nInsights:")
+		for _, ins := range c.Insights {
+			fmt.Printf("  %s %s [%s] %s\n", getInsightSymbol(ins.Type), ins.ID, ins.Type, truncate(ins.Content, 70))
+		}
+	}
+
+	for _, t := range c.Threads {
+		fmt.Printf("\nThread: %s (%s) [%s]\n", t.Thread.Title, t.Thread.ID, t.Thread.Status)
+		for _, ins := range t.Trail {
+			printInsightLine(s, ins)
+		}
+	}
+
+	if len(c.Missing) > 0 {
+		fmt.Printf("\nNot in this database: %s\n", strings.Join(c.Missing, ", "))
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(whyCmd)
+	whyCmd.Flags().StringVar(&whyFile, "file", "", "show the insights behind every commit that touched this file")
+}
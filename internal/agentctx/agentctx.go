@@ -0,0 +1,137 @@
+// Package agentctx selects the decisions, pivots and open questions that
+// matter for the files an agent is about to edit, and renders them within a
+// token budget for injection into the agent's context.
+package agentctx
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+)
+
+// Reason weights: a code anchor is the strongest signal that an insight
+// concerns a file, a mapped label the next, and sharing the branch's thread
+// the weakest.
+const (
+	WeightAnchor = 4
+	WeightLabel  = 2
+	WeightBranch = 1
+)
+
+// maxItemChars caps one insight's text so a single long insight can't use
+// the whole budget.
+const maxItemChars = 400
+
+// Item is one relevant insight and why it was selected.
+type Item struct {
+	Insight *types.Insight `json:"insight"`
+	Reasons []string       `json:"reasons"` // e.g. "anchor src/a.go:40-72", "label auth", "branch thread"
+	Score   int            `json:"score"`
+}
+
+// Add records a reason with its weight.
+func (it *Item) Add(reason string, weight int) {
+	for _, r := range it.Reasons {
+		if r == reason {
+			return
+		}
+	}
+	it.Reasons = append(it.Reasons, reason)
+	it.Score += weight
+}
+
+// Relevant reports whether an insight type belongs in agent context:
+// decisions and pivots constrain the code, questions are still open.
+func Relevant(t types.InsightType) bool {
+	return t == types.InsightDecision || t == types.InsightPivot || t == types.InsightQuestion
+}
+
+// typeRank orders types within the same score.
+func typeRank(t types.InsightType) int {
+	switch t {
+	case types.InsightDecision:
+		return 0
+	case types.InsightPivot:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Rank sorts items by score, then decisions before pivots before questions,
+// then newest first.
+func Rank(items []Item) {
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if ra, rb := typeRank(a.Insight.Type), typeRank(b.Insight.Type); ra != rb {
+			return ra < rb
+		}
+		return a.Insight.Timestamp.After(b.Insight.Timestamp)
+	})
+}
+
+// EstimateTokens approximates a token count as one token per four bytes.
+func EstimateTokens(s string) int {
+	return (len(s) + 3) / 4
+}
+
+// Fit keeps ranked items, in order, while the rendered output stays within
+// budget tokens. Items that don't fit are skipped so later, shorter ones can
+// still be included; it returns the kept items and how many were left out.
+func Fit(files []string, items []Item, budget int) ([]Item, int) {
+	used := EstimateTokens(header(files)) + EstimateTokens(footer(files, len(items), budget))
+	var kept []Item
+	for _, it := range items {
+		cost := EstimateTokens(line(it))
+		if used+cost > budget {
+			continue
+		}
+		used += cost
+		kept = append(kept, it)
+	}
+	return kept, len(items) - len(kept)
+}
+
+// Render formats the kept items as markdown. It returns "" when there is
+// nothing to say, so hooks stay silent.
+func Render(files []string, kept []Item, omitted, budget int) string {
+	if len(kept) == 0 && omitted == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(header(files))
+	for _, it := range kept {
+		b.WriteString(line(it))
+	}
+	if omitted > 0 {
+		b.WriteString(footer(files, omitted, budget))
+	}
+	return b.String()
+}
+
+func header(files []string) string {
+	return fmt.Sprintf("# Beadcrumbs context: %s\n\n"+
+		"Recorded decisions, pivots and open questions for these files. Follow them, or capture a pivot before changing course.\n\n",
+		strings.Join(files, ", "))
+}
+
+func footer(files []string, omitted, budget int) string {
+	return fmt.Sprintf("\n(%d more omitted to fit the %d-token budget; see bdc blame %s)\n", omitted, budget, strings.Join(files, " "))
+}
+
+func line(it Item) string {
+	text := it.Insight.Summary
+	if text == "" {
+		text = it.Insight.Content
+	}
+	text = strings.Join(strings.Fields(text), " ")
+	if r := []rune(text); len(r) > maxItemChars {
+		text = string(r[:maxItemChars-3]) + "..."
+	}
+	return fmt.Sprintf("- %s %s: %s (%s)\n", strings.ToUpper(string(it.Insight.Type)), it.Insight.ID, text, strings.Join(it.Reasons, "; "))
+}
@@ -0,0 +1,182 @@
+package agentctx
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"internal/auth/**", "internal/auth/jwt.go", true},
+		{"internal/auth/**", "internal/auth/sub/x.go", true},
+		{"internal/auth/**", "internal/authz/x.go", false},
+		{"internal/auth/", "internal/auth/jwt.go", true},
+		{"**/*_test.go", "a/b/c_test.go", true},
+		{"**/*_test.go", "c_test.go", true},
+		{"*.go", "main.go", true},
+		{"*.go", "cmd/main.go", false},
+		{"cmd/*/main.go", "cmd/bdc/main.go", true},
+		{"cmd/login.go", "cmd/login.go", true},
+	}
+	for _, tt := range tests {
+		if got := MatchGlob(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("MatchGlob(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	cfg, err := ParseConfig([]byte("version: 1\nlabels:\n  auth: [\"internal/auth/**\"]\n  db: [\"internal/store/*.go\", \"migrations/\"]\n"))
+	if err != nil {
+		t.Fatalf("ParseConfig: %v", err)
+	}
+	if cfg.Budget != DefaultBudget {
+		t.Errorf("budget = %d, want default %d", cfg.Budget, DefaultBudget)
+	}
+	if got := cfg.LabelsFor("internal/store/store.go"); len(got) != 1 || got[0] != "db" {
+		t.Errorf("LabelsFor = %v, want [db]", got)
+	}
+	if got := cfg.LabelsFor("README.md"); len(got) != 0 {
+		t.Errorf("LabelsFor(README.md) = %v, want none", got)
+	}
+
+	for _, bad := range []string{
+		"labels: {}\n",
+		"version: 2\n",
+		"version: 1\nbudget: -5\n",
+		"version: 1\nlabels:\n  auth: [\"[\"]\n",
+	} {
+		if _, err := ParseConfig([]byte(bad)); err == nil {
+			t.Errorf("ParseConfig(%q) should fail", bad)
+		}
+	}
+}
+
+func item(id string, typ types.InsightType, content string, age time.Duration, score int) Item {
+	return Item{
+		Insight: &types.Insight{ID: id, Type: typ, Content: content, Timestamp: time.Now().Add(-age)},
+		Reasons: []string{"test"},
+		Score:   score,
+	}
+}
+
+func TestRankAndFit(t *testing.T) {
+	items := []Item{
+		item("ins-q", types.InsightQuestion, "Open question", time.Hour, WeightLabel),
+		item("ins-old", types.InsightDecision, "Older decision", 2*time.Hour, WeightLabel),
+		item("ins-new", types.InsightDecision, "Newer decision", time.Minute, WeightLabel),
+		item("ins-anchor", types.InsightPivot, "Anchored pivot", 3*time.Hour, WeightAnchor),
+	}
+	Rank(items)
+	var order []string
+	for _, it := range items {
+		order = append(order, it.Insight.ID)
+	}
+	if got := strings.Join(order, ","); got != "ins-anchor,ins-new,ins-old,ins-q" {
+		t.Errorf("rank order = %s", got)
+	}
+
+	files := []string{"a.go"}
+	kept, omitted := Fit(files, items, 10000)
+	if len(kept) != 4 || omitted != 0 {
+		t.Fatalf("large budget kept %d, omitted %d", len(kept), omitted)
+	}
+
+	// Room for the header and roughly one line
+	small := EstimateTokens(header(files)) + EstimateTokens(footer(files, len(items), 100)) + 20
+	kept, omitted = Fit(files, items, small)
+	if len(kept) != 1 || kept[0].Insight.ID != "ins-anchor" || omitted != 3 {
+		t.Fatalf("small budget kept %v, omitted %d", kept, omitted)
+	}
+	out := Render(files, kept, omitted, small)
+	if EstimateTokens(out) > small {
+		t.Errorf("rendered %d tokens, budget %d", EstimateTokens(out), small)
+	}
+	if !strings.Contains(out, "PIVOT ins-anchor: Anchored pivot") || !strings.Contains(out, "3 more omitted") {
+		t.Errorf("unexpected render:\n%s", out)
+	}
+
+	if Render(files, nil, 0, 100) != "" {
+		t.Error("nothing relevant should render nothing")
+	}
+}
+
+func TestItemAdd(t *testing.T) {
+	var it Item
+	it.Add("label auth", WeightLabel)
+	it.Add("label auth", WeightLabel)
+	it.Add("branch thread", WeightBranch)
+	if it.Score != WeightLabel+WeightBranch || len(it.Reasons) != 2 {
+		t.Errorf("Add should ignore duplicate reasons, got %+v", it)
+	}
+}
+
+func testState(budget int) *State {
+	return &State{Budget: budget, Sections: []Section{
+		{Name: SectionThreads, Entries: []Entry{{ID: "thr-1", Kind: "thread", Text: "Auth bug"}}},
+		{Name: SectionRecent, Entries: []Entry{
+			{ID: "ins-1", Kind: "decision", Text: strings.Repeat("long ", 40)},
+			{ID: "ins-2", Kind: "pivot", Text: "Short"},
+		}},
+		{Name: SectionQuestions},
+		{Name: SectionOrigin, Entries: []Entry{{Text: "claude:s1"}}},
+	}}
+}
+
+func TestStateRenderLayout(t *testing.T) {
+	st := testState(0)
+	st.Fit(Placeholders("{{state}}"))
+	got := st.Render()
+	for _, want := range []string{
+		"# Current State\n\n## Active Threads\n- thr-1: Auth bug\n",
+		"## Recent Pivots & Decisions\n- DECISION ins-1: long",
+		"- PIVOT ins-2: Short\n",
+		"## Unresolved Questions\n- (none)\n",
+		"## Origin\n- claude:s1\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Render() missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestStateFitSkipsWhatDoesNotFit(t *testing.T) {
+	st := testState(60)
+	st.Fit(Placeholders("{{state}}"))
+	recent := st.Section(SectionRecent)
+	if len(recent.Entries) != 1 || recent.Entries[0].ID != "ins-2" || recent.Omitted != 1 {
+		t.Errorf("recent = %+v, want the long decision omitted and the pivot kept", recent)
+	}
+	if got := st.RenderSection(SectionRecent); !strings.Contains(got, "- (1 more omitted)") {
+		t.Errorf("RenderSection() should note the omission, got:\n%s", got)
+	}
+	if origin := st.Section(SectionOrigin); len(origin.Entries) != 1 {
+		t.Errorf("later sections should still fill the remaining budget, got %+v", origin)
+	}
+}
+
+func TestStateExpand(t *testing.T) {
+	tmpl := "# Team notes\n{{workflow}}\n{{questions}}{{origin}}{{unknown}}"
+	if got := Placeholders(tmpl); len(got) != 2 || got[0] != SectionQuestions || got[1] != SectionOrigin {
+		t.Fatalf("Placeholders() = %v", got)
+	}
+	st := testState(0)
+	st.Fit(Placeholders(tmpl))
+	got := st.Expand(tmpl, "GUIDE")
+	want := "# Team notes\nGUIDE\n## Unresolved Questions\n- (none)\n## Origin\n- claude:s1\n{{unknown}}"
+	if got != want {
+		t.Errorf("Expand() = %q, want %q", got, want)
+	}
+
+	// Without data every placeholder still disappears
+	empty := &State{}
+	if got := empty.Expand("{{state}}{{threads}}", ""); got != "" {
+		t.Errorf("Expand() on an empty state = %q, want \"\"", got)
+	}
+}
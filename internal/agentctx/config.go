@@ -0,0 +1,106 @@
+package agentctx
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileName is the context configuration file inside .beadcrumbs/.
+const ConfigFileName = "context.yaml"
+
+// CurrentVersion is the newest config version this bdc understands.
+const CurrentVersion = 1
+
+// DefaultBudget is the token budget used when neither the config nor the
+// command line sets one.
+const DefaultBudget = 1000
+
+// Config is the parsed form of .beadcrumbs/context.yaml.
+//
+//	version: 1
+//	budget: 800
+//	labels:
+//	  auth: ["internal/auth/**", "cmd/login.go"]
+//	  storage: ["internal/store/*.go"]
+type Config struct {
+	Version int                 `yaml:"version"`
+	Budget  int                 `yaml:"budget,omitempty"` // Tokens; 0 means DefaultBudget
+	Labels  map[string][]string `yaml:"labels,omitempty"` // Insight label → path globs
+}
+
+// DefaultConfig returns a config with no label mappings.
+func DefaultConfig() *Config {
+	return &Config{Version: CurrentVersion, Budget: DefaultBudget}
+}
+
+// LoadConfig reads and validates a context config file.
+// A missing file is not an error; the default config is returned instead.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return DefaultConfig(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read context config: %w", err)
+	}
+	return ParseConfig(data)
+}
+
+// ParseConfig parses and validates context config YAML.
+func ParseConfig(data []byte) (*Config, error) {
+	cfg := &Config{}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse context config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate rejects missing or future versions, negative budgets and
+// malformed globs, and fills in the default budget.
+func (c *Config) Validate() error {
+	if c.Version == 0 {
+		return fmt.Errorf("context config is missing \"version: %d\"", CurrentVersion)
+	}
+	if c.Version > CurrentVersion {
+		return fmt.Errorf("unsupported context config version %d (this bdc understands version %d)", c.Version, CurrentVersion)
+	}
+	if c.Budget < 0 {
+		return fmt.Errorf("context config budget must be positive, got %d", c.Budget)
+	}
+	if c.Budget == 0 {
+		c.Budget = DefaultBudget
+	}
+	for label, globs := range c.Labels {
+		if strings.TrimSpace(label) == "" {
+			return fmt.Errorf("context config has an empty label")
+		}
+		for _, g := range globs {
+			if err := ValidateGlob(g); err != nil {
+				return fmt.Errorf("label %q: %w", label, err)
+			}
+		}
+	}
+	return nil
+}
+
+// LabelsFor returns the labels whose globs match path, sorted.
+func (c *Config) LabelsFor(path string) []string {
+	var labels []string
+	for label, globs := range c.Labels {
+		for _, g := range globs {
+			if MatchGlob(g, path) {
+				labels = append(labels, label)
+				break
+			}
+		}
+	}
+	sort.Strings(labels)
+	return labels
+}
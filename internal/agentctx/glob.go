@@ -0,0 +1,53 @@
+package agentctx
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// MatchGlob reports whether a slash-separated, repo-relative path matches
+// pattern. Segments use path.Match syntax; a "**" segment matches any number
+// of directories (including none), and a trailing "/" matches everything
+// below a directory.
+func MatchGlob(pattern, name string) bool {
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "**"
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			rest := pattern[1:]
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(rest, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// ValidateGlob reports malformed patterns.
+func ValidateGlob(pattern string) error {
+	if pattern == "" {
+		return fmt.Errorf("empty path glob")
+	}
+	for _, seg := range strings.Split(pattern, "/") {
+		if _, err := path.Match(seg, ""); err != nil {
+			return fmt.Errorf("invalid path glob %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package agentctx
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultPrimeBudget is the token budget for the live state in bdc prime.
+const DefaultPrimeBudget = 1500
+
+// Prime state section names, in ranked order. They double as the PRIME.md
+// placeholders ({{threads}}, {{recent}}, ...) and the JSON section names, so
+// they must not change.
+const (
+	SectionThreads   = "threads"
+	SectionRecent    = "recent"
+	SectionQuestions = "questions"
+	SectionOrigin    = "origin"
+)
+
+var sectionNames = []string{SectionThreads, SectionRecent, SectionQuestions, SectionOrigin}
+
+// Entry is one line of a state section.
+type Entry struct {
+	ID   string `json:"id,omitempty"`
+	Kind string `json:"kind,omitempty"` // Insight type, or "thread"
+	Text string `json:"text"`
+}
+
+// Section is one part of the state prime injects, e.g. the active threads.
+type Section struct {
+	Name    string  `json:"name"`
+	Title   string  `json:"title"`
+	Entries []Entry `json:"entries"`
+	Omitted int     `json:"omitted"` // Entries dropped to fit the budget
+}
+
+// State is the live project state prime injects: active threads, recent
+// pivots and decisions, unresolved questions and the current origin.
+type State struct {
+	Budget   int       `json:"budget"` // Tokens; 0 means unlimited
+	Sections []Section `json:"sections"`
+}
+
+// SectionTitles maps each section name to its markdown heading.
+var SectionTitles = map[string]string{
+	SectionThreads:   "Active Threads",
+	SectionRecent:    "Recent Pivots & Decisions",
+	SectionQuestions: "Unresolved Questions",
+	SectionOrigin:    "Origin",
+}
+
+// Section returns the named section, or nil.
+func (st *State) Section(name string) *Section {
+	for i := range st.Sections {
+		if st.Sections[i].Name == name {
+			return &st.Sections[i]
+		}
+	}
+	return nil
+}
+
+// Fit drops entries until the named sections render within st.Budget
+// tokens. Sections are filled in order and entries that don't fit are
+// skipped so later, shorter ones can still be included. Sections not named
+// are emptied. A zero budget keeps everything.
+func (st *State) Fit(names []string) {
+	want := make(map[string]bool, len(names))
+	for _, n := range names {
+		want[n] = true
+	}
+	used := 0
+	for i := range st.Sections {
+		sec := &st.Sections[i]
+		if !want[sec.Name] {
+			sec.Entries = nil
+			continue
+		}
+		used += EstimateTokens(sec.heading())
+		if len(sec.Entries) > 0 {
+			used += EstimateTokens(sec.omittedLine(len(sec.Entries)))
+		}
+	}
+	if st.Budget <= 0 {
+		return
+	}
+	for i := range st.Sections {
+		sec := &st.Sections[i]
+		var kept []Entry
+		for _, e := range sec.Entries {
+			cost := EstimateTokens(entryLine(e))
+			if used+cost > st.Budget {
+				sec.Omitted++
+				continue
+			}
+			used += cost
+			kept = append(kept, e)
+		}
+		sec.Entries = kept
+	}
+}
+
+// RenderSection formats one section as markdown: a fixed "## " heading
+// followed by one "- " line per entry, "- (none)" when empty.
+func (st *State) RenderSection(name string) string {
+	sec := st.Section(name)
+	if sec == nil {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(sec.heading())
+	if len(sec.Entries) == 0 && sec.Omitted == 0 {
+		b.WriteString("- (none)\n")
+	}
+	for _, e := range sec.Entries {
+		b.WriteString(entryLine(e))
+	}
+	if sec.Omitted > 0 {
+		b.WriteString(sec.omittedLine(sec.Omitted))
+	}
+	return b.String()
+}
+
+// Render formats every section, in order, under a "# Current State" heading.
+// It returns "" for a state without sections.
+func (st *State) Render() string {
+	if len(st.Sections) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("# Current State\n")
+	for _, sec := range st.Sections {
+		b.WriteString("\n")
+		b.WriteString(st.RenderSection(sec.Name))
+	}
+	return b.String()
+}
+
+// Expand replaces the {{state}} and {{<section>}} placeholders in a PRIME.md
+// template and {{workflow}} with the default workflow guide.
+func (st *State) Expand(template, workflow string) string {
+	pairs := []string{"{{workflow}}", workflow, "{{state}}", st.Render()}
+	for _, name := range sectionNames {
+		pairs = append(pairs, "{{"+name+"}}", st.RenderSection(name))
+	}
+	return strings.NewReplacer(pairs...).Replace(template)
+}
+
+// Placeholders returns the state sections a template refers to, in order;
+// {{state}} refers to all of them.
+func Placeholders(template string) []string {
+	if strings.Contains(template, "{{state}}") {
+		return sectionNames
+	}
+	var names []string
+	for _, n := range sectionNames {
+		if strings.Contains(template, "{{"+n+"}}") {
+			names = append(names, n)
+		}
+	}
+	return names
+}
+
+func (sec *Section) heading() string {
+	title := sec.Title
+	if title == "" {
+		title = SectionTitles[sec.Name]
+	}
+	return "## " + title + "\n"
+}
+
+func (sec *Section) omittedLine(n int) string {
+	return fmt.Sprintf("- (%d more omitted)\n", n)
+}
+
+func entryLine(e Entry) string {
+	text := strings.Join(strings.Fields(e.Text), " ")
+	if r := []rune(text); len(r) > maxItemChars {
+		text = string(r[:maxItemChars-3]) + "..."
+	}
+	switch {
+	case e.ID == "":
+		return "- " + text + "\n"
+	case e.Kind == "" || e.Kind == "thread":
+		return fmt.Sprintf("- %s: %s\n", e.ID, text)
+	default:
+		return fmt.Sprintf("- %s %s: %s\n", strings.ToUpper(e.Kind), e.ID, text)
+	}
+}
@@ -0,0 +1,64 @@
+// Package anchor parses code anchor specs ("path:40-72") and re-maps anchored
+// line ranges across commits using unified diff hunks.
+package anchor
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+)
+
+var linesPattern = regexp.MustCompile(`^(\d+)(?:-(\d+))?$`)
+
+// Parse splits "path", "path:40" or "path:40-72" into a path and a 1-based
+// inclusive line range. A bare path returns zero lines (the whole file).
+func Parse(spec string) (path string, start, end int, err error) {
+	spec = strings.TrimSpace(spec)
+	if i := strings.LastIndex(spec, ":"); i > 0 {
+		if m := linesPattern.FindStringSubmatch(spec[i+1:]); m != nil {
+			path = spec[:i]
+			start, _ = strconv.Atoi(m[1])
+			end = start
+			if m[2] != "" {
+				end, _ = strconv.Atoi(m[2])
+			}
+			if start < 1 || end < start {
+				return "", 0, 0, fmt.Errorf("invalid line range in %q", spec)
+			}
+			return path, start, end, nil
+		}
+	}
+	if spec == "" {
+		return "", 0, 0, fmt.Errorf("empty anchor")
+	}
+	return spec, 0, 0, nil
+}
+
+// Format renders an anchor as "path", "path:40" or "path:40-72".
+func Format(a types.CodeAnchor) string {
+	return a.Path + FormatLines(a)
+}
+
+// FormatLines renders just the line part of an anchor (":40-72"), or "".
+func FormatLines(a types.CodeAnchor) string {
+	switch {
+	case a.StartLine == 0:
+		return ""
+	case a.StartLine == a.EndLine:
+		return fmt.Sprintf(":%d", a.StartLine)
+	default:
+		return fmt.Sprintf(":%d-%d", a.StartLine, a.EndLine)
+	}
+}
+
+// Overlaps reports whether a covers any line in [start, end]. Whole-file
+// anchors and a zero query range always overlap.
+func Overlaps(a types.CodeAnchor, start, end int) bool {
+	if a.StartLine == 0 || start == 0 {
+		return true
+	}
+	return a.StartLine <= end && start <= a.EndLine
+}
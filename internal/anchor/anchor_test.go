@@ -0,0 +1,134 @@
+package anchor
+
+import (
+	"testing"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		spec       string
+		path       string
+		start, end int
+		wantErr    bool
+	}{
+		{"src/auth/jwt.go:40-72", "src/auth/jwt.go", 40, 72, false},
+		{"src/auth/jwt.go:40", "src/auth/jwt.go", 40, 40, false},
+		{"src/auth/jwt.go", "src/auth/jwt.go", 0, 0, false},
+		{"weird:name.go", "weird:name.go", 0, 0, false},
+		{"a.go:72-40", "", 0, 0, true},
+		{"a.go:0", "", 0, 0, true},
+		{"", "", 0, 0, true},
+	}
+	for _, tt := range tests {
+		path, start, end, err := Parse(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Parse(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if path != tt.path || start != tt.start || end != tt.end {
+			t.Errorf("Parse(%q) = %q %d-%d, want %q %d-%d", tt.spec, path, start, end, tt.path, tt.start, tt.end)
+		}
+	}
+}
+
+func TestFormatAndOverlaps(t *testing.T) {
+	a := types.CodeAnchor{Path: "a.go", StartLine: 10, EndLine: 20}
+	if got := Format(a); got != "a.go:10-20" {
+		t.Errorf("Format = %q", got)
+	}
+	if got := Format(types.CodeAnchor{Path: "a.go", StartLine: 3, EndLine: 3}); got != "a.go:3" {
+		t.Errorf("Format single line = %q", got)
+	}
+	if !Overlaps(a, 20, 25) || Overlaps(a, 21, 30) || !Overlaps(a, 0, 0) {
+		t.Error("Overlaps gave wrong result")
+	}
+	if !Overlaps(types.CodeAnchor{Path: "a.go"}, 500, 500) {
+		t.Error("whole-file anchor should overlap any line")
+	}
+}
+
+const sampleDiff = `diff --git a/src/auth/jwt.go b/src/auth/token.go
+similarity index 90%
+rename from src/auth/jwt.go
+rename to src/auth/token.go
+index 1111111..2222222 100644
+--- a/src/auth/jwt.go
++++ b/src/auth/token.go
+@@ -3,0 +4,2 @@ package auth
++import "errors"
++
+@@ -50,2 +52 @@ func Validate() {
+-	old()
+-	older()
++	replacement()
+diff --git a/gone.go b/gone.go
+deleted file mode 100644
+index 3333333..0000000
+--- a/gone.go
++++ /dev/null
+@@ -1,3 +0,0 @@
+-package gone
+-
+-func x() {}
+`
+
+func TestParseDiff(t *testing.T) {
+	diffs := ParseDiff([]byte(sampleDiff))
+	if len(diffs) != 2 {
+		t.Fatalf("expected 2 file diffs, got %d", len(diffs))
+	}
+	if diffs[0].OldPath != "src/auth/jwt.go" || diffs[0].NewPath != "src/auth/token.go" {
+		t.Errorf("rename paths = %q -> %q", diffs[0].OldPath, diffs[0].NewPath)
+	}
+	want := []Hunk{{3, 0, 4, 2}, {50, 2, 52, 1}}
+	if len(diffs[0].Hunks) != 2 || diffs[0].Hunks[0] != want[0] || diffs[0].Hunks[1] != want[1] {
+		t.Errorf("hunks = %+v, want %+v", diffs[0].Hunks, want)
+	}
+	if diffs[1].OldPath != "gone.go" || diffs[1].NewPath != "" {
+		t.Errorf("deleted file paths = %q -> %q", diffs[1].OldPath, diffs[1].NewPath)
+	}
+}
+
+func TestRemap(t *testing.T) {
+	hunks := []Hunk{
+		{OldStart: 3, OldLines: 0, NewStart: 4, NewLines: 2},   // 2 lines inserted after line 3
+		{OldStart: 50, OldLines: 2, NewStart: 52, NewLines: 1}, // lines 50-51 replaced by one
+		{OldStart: 80, OldLines: 5, NewStart: 80, NewLines: 0}, // lines 80-84 deleted
+	}
+	tests := []struct {
+		name       string
+		start, end int
+		wantStart  int
+		wantEnd    int
+		wantStatus Status
+	}{
+		{"before all edits", 1, 3, 1, 3, StatusUnchanged},
+		{"shifted by insertion", 40, 45, 42, 47, StatusMoved},
+		{"overlaps replacement", 45, 50, 47, 52, StatusChanged},
+		{"after replacement", 60, 70, 61, 71, StatusMoved},
+		{"fully deleted", 80, 84, 0, 0, StatusDeleted},
+		{"partly deleted", 78, 90, 79, 86, StatusChanged},
+		{"insertion inside range", 2, 10, 2, 12, StatusChanged},
+		{"whole file", 0, 0, 0, 0, StatusUnchanged},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, e, st := Remap(tt.start, tt.end, hunks)
+			if s != tt.wantStart || e != tt.wantEnd || st != tt.wantStatus {
+				t.Errorf("Remap(%d, %d) = %d-%d %s, want %d-%d %s",
+					tt.start, tt.end, s, e, st, tt.wantStart, tt.wantEnd, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestInvertRoundTrip(t *testing.T) {
+	hunks := []Hunk{{OldStart: 3, OldLines: 0, NewStart: 4, NewLines: 2}}
+	s, e, _ := Remap(10, 12, hunks)
+	back, backEnd, _ := Remap(s, e, Invert(hunks))
+	if back != 10 || backEnd != 12 {
+		t.Errorf("round trip = %d-%d, want 10-12", back, backEnd)
+	}
+}
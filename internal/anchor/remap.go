@@ -0,0 +1,195 @@
+package anchor
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Hunk is one "@@ -a,b +c,d @@" change from a unified diff. A zero line
+// count means the hunk inserts (OldLines) or deletes (NewLines) after the
+// given start line.
+type Hunk struct {
+	OldStart, OldLines int
+	NewStart, NewLines int
+}
+
+// FileDiff holds the hunks for one file. OldPath is "" for added files,
+// NewPath is "" for deleted ones, and they differ when the file was renamed.
+type FileDiff struct {
+	OldPath string
+	NewPath string
+	Hunks   []Hunk
+}
+
+// Status describes what happened to an anchored range.
+type Status string
+
+const (
+	StatusUnchanged Status = "unchanged" // Same lines, same place
+	StatusMoved     Status = "moved"     // Same lines, shifted by edits elsewhere
+	StatusChanged   Status = "changed"   // Some anchored lines were edited
+	StatusDeleted   Status = "deleted"   // All anchored lines (or the file) are gone
+)
+
+var hunkPattern = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// ParseDiff parses `git diff -U0` output into per-file hunks.
+func ParseDiff(out []byte) []FileDiff {
+	var diffs []FileDiff
+	var cur *FileDiff
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			diffs = append(diffs, FileDiff{})
+			cur = &diffs[len(diffs)-1]
+			// Fallback paths for diffs without ---/+++ lines (pure renames).
+			if a, b, ok := splitGitPaths(strings.TrimPrefix(line, "diff --git ")); ok {
+				cur.OldPath, cur.NewPath = a, b
+			}
+		case cur == nil:
+			continue
+		case strings.HasPrefix(line, "rename from "):
+			cur.OldPath = strings.TrimPrefix(line, "rename from ")
+		case strings.HasPrefix(line, "rename to "):
+			cur.NewPath = strings.TrimPrefix(line, "rename to ")
+		case strings.HasPrefix(line, "--- "):
+			cur.OldPath = diffPath(strings.TrimPrefix(line, "--- "))
+		case strings.HasPrefix(line, "+++ "):
+			cur.NewPath = diffPath(strings.TrimPrefix(line, "+++ "))
+		case strings.HasPrefix(line, "@@ "):
+			if m := hunkPattern.FindStringSubmatch(line); m != nil {
+				cur.Hunks = append(cur.Hunks, Hunk{
+					OldStart: atoi(m[1]), OldLines: countOrOne(m[2]),
+					NewStart: atoi(m[3]), NewLines: countOrOne(m[4]),
+				})
+			}
+		}
+	}
+	return diffs
+}
+
+// Invert swaps the old and new sides of hunks, so ranges can be mapped from
+// the newer version back to the older one.
+func Invert(hunks []Hunk) []Hunk {
+	inv := make([]Hunk, len(hunks))
+	for i, h := range hunks {
+		inv[i] = Hunk{OldStart: h.NewStart, OldLines: h.NewLines, NewStart: h.OldStart, NewLines: h.OldLines}
+	}
+	return inv
+}
+
+// Remap maps the inclusive range [start, end] through hunks (sorted by
+// OldStart, as git emits them). Lines inside an edited hunk are clamped to
+// the hunk's replacement lines, so an anchor shrinks or grows with its code.
+func Remap(start, end int, hunks []Hunk) (newStart, newEnd int, status Status) {
+	if start == 0 {
+		return 0, 0, StatusUnchanged
+	}
+
+	newStart, startHit := mapLine(start, hunks, false)
+	newEnd, endHit := mapLine(end, hunks, true)
+	changed := startHit || endHit
+	for _, h := range hunks {
+		if h.OldLines > 0 && h.OldStart <= end && start <= h.OldStart+h.OldLines-1 {
+			changed = true
+		}
+		// Insertions strictly inside the range also change it.
+		if h.OldLines == 0 && h.OldStart >= start && h.OldStart < end {
+			changed = true
+		}
+	}
+
+	switch {
+	case newEnd < newStart:
+		return 0, 0, StatusDeleted
+	case changed:
+		return newStart, newEnd, StatusChanged
+	case newStart != start:
+		return newStart, newEnd, StatusMoved
+	default:
+		return newStart, newEnd, StatusUnchanged
+	}
+}
+
+// mapLine maps one old line number to the new file. hit reports whether
+// the line itself was replaced or removed; it then maps to the first (or,
+// for the end of a range, last) replacement line.
+func mapLine(line int, hunks []Hunk, isEnd bool) (mapped int, hit bool) {
+	delta := 0
+	for _, h := range hunks {
+		if h.OldLines == 0 {
+			// Pure insertion after OldStart.
+			if h.OldStart < line {
+				delta += h.NewLines
+				continue
+			}
+			break
+		}
+		oldEnd := h.OldStart + h.OldLines - 1
+		if oldEnd < line {
+			delta += h.NewLines - h.OldLines
+			continue
+		}
+		if h.OldStart > line {
+			break
+		}
+		// The line was replaced by h's new lines (possibly none).
+		if h.NewLines == 0 {
+			// Deleted: the next surviving line follows NewStart.
+			if isEnd {
+				return h.NewStart, true
+			}
+			return h.NewStart + 1, true
+		}
+		if isEnd {
+			return h.NewStart + h.NewLines - 1, true
+		}
+		return h.NewStart, true
+	}
+	return line + delta, false
+}
+
+// splitGitPaths splits "a/old b/new" from a diff --git header. Paths with
+// spaces are ambiguous there; ---/+++ lines override when present.
+func splitGitPaths(s string) (string, string, bool) {
+	if !strings.HasPrefix(s, "a/") {
+		return "", "", false
+	}
+	i := strings.Index(s, " b/")
+	if i < 0 {
+		return "", "", false
+	}
+	return s[2:i], s[i+3:], true
+}
+
+// diffPath strips the a/ or b/ prefix, returning "" for /dev/null.
+func diffPath(p string) string {
+	p = strings.TrimSuffix(p, "\t")
+	if p == "/dev/null" {
+		return ""
+	}
+	if strings.HasPrefix(p, "a/") || strings.HasPrefix(p, "b/") {
+		return p[2:]
+	}
+	return p
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// countOrOne parses a hunk line count, which git omits when it is 1.
+func countOrOne(s string) int {
+	if s == "" {
+		return 1
+	}
+	return atoi(s)
+}
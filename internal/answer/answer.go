@@ -0,0 +1,317 @@
+// Package answer builds extractive answers to questions about the
+// knowledge base without a language model: the insights most relevant to a
+// question are expanded through their links, ranked to prefer current
+// decisions and pivots, and quoted with citations and thread context.
+package answer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/similar"
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+)
+
+// DefaultLimit is how many insights an answer cites by default.
+const DefaultLimit = 3
+
+// Score factors. Insights reached through a link inherit part of the
+// score of the insight they were reached from; superseded insights keep
+// only a little of theirs so their replacements rank first.
+const (
+	weightSupersedes  = 0.9
+	weightBuildsOn    = 0.5
+	weightContradicts = 0.4
+	weightSuperseded  = 0.25
+
+	// minShare drops candidates scoring below this share of the best one.
+	minShare = 0.2
+
+	maxExcerptChars = 300
+)
+
+// questionWords are left out of the search query: they say what kind of
+// question it is, not what it is about.
+var questionWords = map[string]bool{
+	"why": true, "what": true, "when": true, "where": true, "who": true,
+	"whom": true, "which": true, "how": true, "do": true, "does": true,
+	"did": true, "were": true, "has": true, "have": true, "had": true,
+	"should": true, "would": true, "could": true, "can": true, "will": true,
+	"our": true, "us": true, "you": true, "choose": true, "chose": true,
+	"chosen": true, "pick": true, "picked": true, "decide": true,
+	"decided": true, "use": true, "used": true, "not": true,
+}
+
+// Query returns the words of question worth searching for.
+func Query(question string) string {
+	var words []string
+	for _, w := range similar.Words(question) {
+		if !questionWords[w] {
+			words = append(words, w)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// Hit is an insight found by searching for the question.
+type Hit struct {
+	Insight *types.Insight
+	Score   float64
+}
+
+// Corpus is the part of the knowledge base an answer can draw on.
+type Corpus struct {
+	Insights map[string]*types.Insight
+	Threads  map[string]*types.InsightThread
+	Deps     []*types.Dependency
+}
+
+// Thread is the context of a cited insight's thread.
+type Thread struct {
+	ID            string `json:"id"`
+	Title         string `json:"title"`
+	Understanding string `json:"current_understanding,omitempty"`
+}
+
+// Citation is one insight the answer quotes.
+type Citation struct {
+	N            int               `json:"n"`
+	ID           string            `json:"id"`
+	Type         types.InsightType `json:"type"`
+	Timestamp    time.Time         `json:"timestamp"`
+	Excerpt      string            `json:"excerpt"`
+	Score        float64           `json:"score"`
+	Reasons      []string          `json:"reasons"` // e.g. "matched backoff, retry", "supersedes ins-a1b2"
+	SupersededBy []string          `json:"superseded_by,omitempty"`
+	Thread       *Thread           `json:"thread,omitempty"`
+}
+
+// Answer is the cited excerpts answering a question, best first.
+type Answer struct {
+	Question  string     `json:"question"`
+	Answer    string     `json:"answer"` // Excerpts, one per line, each ending in its [n]
+	Citations []Citation `json:"citations"`
+}
+
+type candidate struct {
+	ins     *types.Insight
+	score   float64
+	reasons []string
+}
+
+func (c *candidate) add(score float64, reason string) {
+	if score > c.score {
+		c.score = score
+	}
+	for _, r := range c.reasons {
+		if r == reason {
+			return
+		}
+	}
+	c.reasons = append(c.reasons, reason)
+}
+
+// Ask answers question from the search hits, following links in corpus,
+// and cites at most limit insights (DefaultLimit if limit is 0 or less).
+func Ask(question string, hits []Hit, corpus Corpus, limit int) *Answer {
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	a := &Answer{Question: question, Citations: []Citation{}}
+	terms := similar.Terms(Query(question))
+
+	if len(hits) == 0 {
+		return a
+	}
+	var top float64
+	for _, h := range hits {
+		top = max(top, h.Score)
+	}
+
+	mergedInto := make(map[string]string)
+	supersededBy := make(map[string][]string)
+	for _, d := range corpus.Deps {
+		switch d.Type {
+		case types.DepDuplicates:
+			mergedInto[d.From] = d.To
+		case types.DepSupersedes:
+			supersededBy[d.To] = append(supersededBy[d.To], d.From)
+		}
+	}
+
+	cands := make(map[string]*candidate)
+	get := func(id string) *candidate {
+		if to, ok := mergedInto[id]; ok {
+			id = to
+		}
+		if c, ok := cands[id]; ok {
+			return c
+		}
+		ins := corpus.Insights[id]
+		if ins == nil {
+			return nil
+		}
+		c := &candidate{ins: ins}
+		cands[id] = c
+		return c
+	}
+
+	for _, h := range hits {
+		c := get(h.Insight.ID)
+		if c == nil {
+			continue
+		}
+		// Every hit matched the search; unscored ones count as the best
+		rel := 1.0
+		if top > 0 {
+			rel = h.Score / top
+		}
+		if words := matched(c.ins, terms); len(words) > 0 {
+			c.add(rel, "matched "+strings.Join(words, ", "))
+		} else {
+			c.add(rel, "search match")
+		}
+
+		// One hop through the graph
+		for _, d := range corpus.Deps {
+			var other, reason string
+			var weight float64
+			switch {
+			case d.To == c.ins.ID && d.Type == types.DepSupersedes:
+				other, reason, weight = d.From, "supersedes "+c.ins.ID, weightSupersedes
+			case d.To == c.ins.ID && d.Type == types.DepBuildsOn:
+				other, reason, weight = d.From, "builds on "+c.ins.ID, weightBuildsOn
+			case d.From == c.ins.ID && d.Type == types.DepBuildsOn:
+				other, reason, weight = d.To, "built on by "+c.ins.ID, weightBuildsOn
+			case d.Type == types.DepContradicts && (d.From == c.ins.ID || d.To == c.ins.ID):
+				other, reason, weight = d.From, "contradicts "+c.ins.ID, weightContradicts
+				if other == c.ins.ID {
+					other = d.To
+				}
+			default:
+				continue
+			}
+			if o := get(other); o != nil && o != c {
+				o.add(rel*weight, reason)
+			}
+		}
+	}
+
+	ranked := make([]*candidate, 0, len(cands))
+	for id, c := range cands {
+		c.score *= typeWeight(c.ins.Type) * (0.5 + 0.5*float64(c.ins.Confidence))
+		if len(supersededBy[id]) > 0 {
+			c.score *= weightSuperseded
+		}
+		ranked = append(ranked, c)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].score != ranked[j].score {
+			return ranked[i].score > ranked[j].score
+		}
+		return ranked[i].ins.Timestamp.After(ranked[j].ins.Timestamp)
+	})
+
+	var lines []string
+	for _, c := range ranked {
+		if len(a.Citations) == limit || c.score < minShare*ranked[0].score {
+			break
+		}
+		cit := Citation{
+			N:            len(a.Citations) + 1,
+			ID:           c.ins.ID,
+			Type:         c.ins.Type,
+			Timestamp:    c.ins.Timestamp,
+			Excerpt:      Excerpt(c.ins.Content, terms),
+			Score:        c.score,
+			Reasons:      c.reasons,
+			SupersededBy: supersededBy[c.ins.ID],
+		}
+		if t := corpus.Threads[c.ins.ThreadID]; t != nil {
+			cit.Thread = &Thread{ID: t.ID, Title: t.Title, Understanding: t.CurrentUnderstanding}
+		}
+		a.Citations = append(a.Citations, cit)
+
+		line := fmt.Sprintf("%s [%d]", cit.Excerpt, cit.N)
+		if len(cit.SupersededBy) > 0 {
+			line += fmt.Sprintf(" (superseded by %s)", strings.Join(cit.SupersededBy, ", "))
+		}
+		lines = append(lines, line)
+	}
+	a.Answer = strings.Join(lines, "\n")
+	return a
+}
+
+// typeWeight prefers decisions and pivots, which record what was settled,
+// over findings, and those over guesses and open questions.
+func typeWeight(t types.InsightType) float64 {
+	switch t {
+	case types.InsightDecision:
+		return 1.5
+	case types.InsightPivot:
+		return 1.4
+	case types.InsightHypothesis:
+		return 0.7
+	case types.InsightQuestion:
+		return 0.6
+	}
+	return 1
+}
+
+// matched returns the query terms in ins, sorted.
+func matched(ins *types.Insight, terms map[string]int) []string {
+	have := similar.Terms(ins.Content + "\n" + ins.Summary)
+	var out []string
+	for t := range terms {
+		if have[t] > 0 {
+			out = append(out, t)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Excerpt returns the sentence of content with the most query terms, or
+// all of content when it is short. Excerpts are cut at maxExcerptChars.
+func Excerpt(content string, terms map[string]int) string {
+	content = strings.Join(strings.Fields(content), " ")
+	if len(content) > maxExcerptChars {
+		best, bestHits := "", -1
+		for _, s := range sentences(content) {
+			hits := 0
+			for t := range similar.Terms(s) {
+				if terms[t] > 0 {
+					hits++
+				}
+			}
+			if hits > bestHits {
+				best, bestHits = s, hits
+			}
+		}
+		content = best
+	}
+	if r := []rune(content); len(r) > maxExcerptChars {
+		content = string(r[:maxExcerptChars-3]) + "..."
+	}
+	return content
+}
+
+// sentences splits text after ., ! and ? followed by a space.
+func sentences(text string) []string {
+	var out []string
+	start := 0
+	runes := []rune(text)
+	for i, r := range runes {
+		if (r == '.' || r == '!' || r == '?') && i+1 < len(runes) && unicode.IsSpace(runes[i+1]) {
+			out = append(out, strings.TrimSpace(string(runes[start:i+1])))
+			start = i + 1
+		}
+	}
+	if rest := strings.TrimSpace(string(runes[start:])); rest != "" {
+		out = append(out, rest)
+	}
+	return out
+}
@@ -0,0 +1,102 @@
+package answer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+)
+
+func insight(id string, typ types.InsightType, content, thread string, age time.Duration) *types.Insight {
+	return &types.Insight{ID: id, Type: typ, Content: content, ThreadID: thread, Confidence: 1, Timestamp: time.Now().Add(-age)}
+}
+
+func TestQuery(t *testing.T) {
+	if got := Query("Why did we choose exponential backoff?"); got != "exponential backoff" {
+		t.Errorf("Query = %q, want %q", got, "exponential backoff")
+	}
+}
+
+func TestAsk(t *testing.T) {
+	fixed := insight("ins-1", types.InsightDecision, "Retry failed payment calls every 5 seconds.", "thr-1", 72*time.Hour)
+	backoff := insight("ins-2", types.InsightDecision, "Switch retries to exponential backoff with jitter. Fixed delays hammered the provider after outages.", "thr-1", 48*time.Hour)
+	guess := insight("ins-3", types.InsightHypothesis, "Maybe exponential backoff is overkill here", "", 24*time.Hour)
+	storm := insight("ins-4", types.InsightDiscovery, "The provider rate-limits bursts of requests", "thr-1", 60*time.Hour)
+	dup := insight("ins-5", types.InsightDecision, "Switch retries to exponential backoff", "thr-1", 12*time.Hour)
+	corpus := Corpus{
+		Insights: map[string]*types.Insight{},
+		Threads: map[string]*types.InsightThread{
+			"thr-1": {ID: "thr-1", Title: "Payment retries", CurrentUnderstanding: "Backoff with jitter"},
+		},
+		Deps: []*types.Dependency{
+			{From: "ins-2", To: "ins-1", Type: types.DepSupersedes},
+			{From: "ins-2", To: "ins-4", Type: types.DepBuildsOn},
+			{From: "ins-5", To: "ins-2", Type: types.DepDuplicates},
+		},
+	}
+	for _, ins := range []*types.Insight{fixed, backoff, guess, storm, dup} {
+		corpus.Insights[ins.ID] = ins
+	}
+
+	hits := []Hit{{dup, 3}, {guess, 2.5}, {backoff, 2}, {fixed, 1}}
+	a := Ask("Why did we choose exponential backoff?", hits, corpus, 0)
+
+	if len(a.Citations) != DefaultLimit {
+		t.Fatalf("expected %d citations, got %+v", DefaultLimit, a.Citations)
+	}
+	first := a.Citations[0]
+	if first.ID != "ins-2" || first.Thread == nil || first.Thread.Title != "Payment retries" {
+		t.Errorf("the decision (with its duplicate's hit) should come first with its thread, got %+v", first)
+	}
+	if first.Excerpt != backoff.Content {
+		t.Errorf("short insights should be quoted whole, got %q", first.Excerpt)
+	}
+	var ids []string
+	for _, c := range a.Citations {
+		ids = append(ids, c.ID)
+	}
+	if strings.Contains(strings.Join(ids, " "), "ins-5") {
+		t.Errorf("merged duplicates should not be cited, got %v", ids)
+	}
+	if a.Citations[1].ID != "ins-3" && a.Citations[1].ID != "ins-4" {
+		t.Errorf("expected the hypothesis or linked discovery next, got %v", ids)
+	}
+	if !strings.Contains(a.Answer, "[1]") || !strings.HasPrefix(a.Answer, first.Excerpt) {
+		t.Errorf("answer should start with the first cited excerpt, got %q", a.Answer)
+	}
+
+	// Superseded insights rank below their replacement and say so
+	a = Ask("retry payment calls", []Hit{{fixed, 5}, {backoff, 1}}, corpus, 5)
+	if a.Citations[0].ID != "ins-2" {
+		t.Errorf("the superseding decision should come first, got %+v", a.Citations)
+	}
+	for _, c := range a.Citations {
+		if c.ID == "ins-1" && (len(c.SupersededBy) != 1 || !strings.Contains(a.Answer, "(superseded by ins-2)")) {
+			t.Errorf("superseded insight should be marked, got %+v / %q", c, a.Answer)
+		}
+	}
+
+	// Search hits are answers even when the search gave them no score
+	a = Ask("why auth?", []Hit{{storm, 0}}, corpus, 0)
+	if len(a.Citations) == 0 || a.Citations[0].ID != "ins-4" {
+		t.Errorf("an unscored hit should still be cited, got %+v", a.Citations)
+	}
+
+	if a := Ask("anything", nil, corpus, 0); len(a.Citations) != 0 || a.Answer != "" {
+		t.Errorf("no hits should give an empty answer, got %+v", a)
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	long := strings.Repeat("Filler sentence about nothing in particular. ", 8) +
+		"We chose exponential backoff because fixed delays caused retry storms. " +
+		strings.Repeat("More filler here. ", 4)
+	got := Excerpt(long, map[string]int{"exponential": 1, "backoff": 1})
+	if got != "We chose exponential backoff because fixed delays caused retry storms." {
+		t.Errorf("Excerpt = %q", got)
+	}
+	if got := Excerpt("Short  insight\ntext", nil); got != "Short insight text" {
+		t.Errorf("short content should be kept whole, got %q", got)
+	}
+}
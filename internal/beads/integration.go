@@ -210,6 +210,8 @@ func FormatExternalRef(ref *ExternalRef) string {
 		return "Slack: " + ref.ID
 	case "bead":
 		return "Bead: bd-" + ref.ID
+	case "git-branch":
+		return "Branch: " + ref.ID
 	default:
 		return ref.System + ": " + ref.ID
 	}
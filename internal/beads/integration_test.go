@@ -262,6 +262,11 @@ func TestFormatExternalRef(t *testing.T) {
 			ref:  &ExternalRef{System: "bead", ID: "abc1"},
 			want: "Bead: bd-abc1",
 		},
+		{
+			name: "git branch",
+			ref:  &ExternalRef{System: "git-branch", ID: "feature/refresh"},
+			want: "Branch: feature/refresh",
+		},
 		{
 			name: "unknown/custom system",
 			ref:  &ExternalRef{System: "custom", ID: "something"},
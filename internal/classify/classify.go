@@ -0,0 +1,484 @@
+// Package classify scores text against weighted rules to pick an insight
+// type, configured from .beadcrumbs/classify.yaml.
+package classify
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileName is the classifier config file inside .beadcrumbs/.
+const ConfigFileName = "classify.yaml"
+
+// CurrentVersion is the newest config version this bdc understands.
+const CurrentVersion = 1
+
+// Defaults for the optional config fields.
+const (
+	DefaultThreshold  = 0.5
+	DefaultConfidence = 0.3
+	DefaultCustomRule = 1.5 // Weight of a custom type's detect phrases
+	negationWindow    = 3   // Words before a match searched for negations
+)
+
+// Config is the parsed form of .beadcrumbs/classify.yaml.
+//
+//	version: 1
+//	threshold: 0.5            # lowest winning score; below it, default_type
+//	default_type: hypothesis
+//	default_confidence: 0.3
+//	negations: ["not", "no longer"]
+//	model_weight: 2           # how much a trained model counts
+//	rules:
+//	  - type: decision
+//	    match: "we agreed"    # substring, case-insensitive
+//	    weight: 1.5
+//	  - type: risk
+//	    regex: '\bmight (break|fail)\b'
+//	    negations: ["unlikely to"]
+//	sources:
+//	  slack:
+//	    weights: {question: 0.5}   # multiply type scores for this source
+//	    rules:
+//	      - type: decision
+//	        match: ":white_check_mark:"
+type Config struct {
+	Version           int                       `yaml:"version"`
+	Threshold         float64                   `yaml:"threshold"`
+	DefaultType       types.InsightType         `yaml:"default_type"`
+	DefaultConfidence float64                   `yaml:"default_confidence"`
+	Negations         []string                  `yaml:"negations"`
+	NoBuiltin         bool                      `yaml:"no_builtin"` // Drop the built-in rules
+	Rules             []Rule                    `yaml:"rules"`
+	Sources           map[string]SourceOverride `yaml:"sources"`
+	ModelWeight       float64                   `yaml:"model_weight"` // Scales the trained model's probabilities
+	NoModel           bool                      `yaml:"no_model"`     // Ignore a trained model
+
+	// Model is the model trained by bdc classify train, if any.
+	Model *Model `yaml:"-"`
+}
+
+// Rule adds Weight to Type's score when its pattern matches, unless a
+// negation appears just before the match.
+type Rule struct {
+	Name            string            `yaml:"name,omitempty" json:"name,omitempty"`
+	Type            types.InsightType `yaml:"type" json:"type"`
+	Match           string            `yaml:"match,omitempty" json:"match,omitempty"`
+	Regex           string            `yaml:"regex,omitempty" json:"regex,omitempty"`
+	Weight          float64           `yaml:"weight,omitempty" json:"weight"`
+	Negations       []string          `yaml:"negations,omitempty" json:"negations,omitempty"`
+	IgnoreNegations bool              `yaml:"ignore_negations,omitempty" json:"ignore_negations,omitempty"`
+
+	re *regexp.Regexp
+}
+
+// SourceOverride adjusts classification for one source type, such as
+// slack or ai-session.
+type SourceOverride struct {
+	Weights map[types.InsightType]float64 `yaml:"weights"`
+	Rules   []Rule                        `yaml:"rules"`
+}
+
+// BuiltinRules are the rules used unless a config sets no_builtin.
+func BuiltinRules() []Rule {
+	rules := []Rule{{Name: "question mark", Type: types.InsightQuestion, Regex: `\?\s*$`, Weight: 2.5, IgnoreNegations: true}}
+	add := func(t types.InsightType, phrases ...string) {
+		for _, p := range phrases {
+			rules = append(rules, Rule{Type: t, Match: p, Weight: 1})
+		}
+	}
+	add(types.InsightDecision, "decision:", "decided:", "let's go with", "we'll use", "going with", "chose to", "will use")
+	add(types.InsightPivot, "actually", "wait,", "but actually", "turns out", "however,", "on second thought")
+	add(types.InsightDiscovery, "found:", "discovered:", "noticed:", "realized:", "identified:")
+	return rules
+}
+
+// defaultNegations are checked when a config sets none.
+var defaultNegations = []string{
+	"not", "no", "never", "don't", "doesn't", "didn't", "isn't", "wasn't",
+	"won't", "haven't", "hasn't", "no longer",
+}
+
+// Load reads and validates a classifier config file.
+// A missing file is not an error; the default config is returned instead.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return DefaultConfig(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read classify config: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse parses, validates and fills defaults for classifier config YAML.
+func Parse(data []byte) (*Config, error) {
+	cfg := &Config{}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse classify config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// DefaultConfig is the config used without a classify.yaml.
+func DefaultConfig() *Config {
+	cfg := &Config{Version: CurrentVersion}
+	cfg.Validate()
+	return cfg
+}
+
+// Validate rejects missing or future versions, rules without a type or
+// pattern, bad regexes and negative source weights, and fills defaults.
+// Types must be built in or custom types registered beforehand.
+func (c *Config) Validate() error {
+	if c.Version == 0 {
+		return fmt.Errorf("classify config is missing \"version: %d\"", CurrentVersion)
+	}
+	if c.Version > CurrentVersion {
+		return fmt.Errorf("unsupported classify config version %d (this bdc understands version %d)", c.Version, CurrentVersion)
+	}
+	if c.Threshold == 0 {
+		c.Threshold = DefaultThreshold
+	}
+	if c.DefaultType == "" {
+		c.DefaultType = types.InsightHypothesis
+	}
+	if !c.DefaultType.IsValid() {
+		return fmt.Errorf("classify config: unknown default_type %q", c.DefaultType)
+	}
+	if c.DefaultConfidence == 0 {
+		c.DefaultConfidence = DefaultConfidence
+	}
+	if c.DefaultConfidence < 0 || c.DefaultConfidence > 1 {
+		return fmt.Errorf("classify config: default_confidence must be between 0 and 1")
+	}
+	if c.ModelWeight == 0 {
+		c.ModelWeight = DefaultModelWeight
+	}
+	if c.ModelWeight < 0 {
+		return fmt.Errorf("classify config: model_weight must not be negative")
+	}
+	if c.Negations == nil {
+		c.Negations = defaultNegations
+	}
+	c.Negations = lowerAll(c.Negations)
+
+	if err := validateRules(c.Rules, ""); err != nil {
+		return err
+	}
+	for source, o := range c.Sources {
+		for t, w := range o.Weights {
+			if !t.IsValid() {
+				return fmt.Errorf("classify config: source %s: unknown type %q", source, t)
+			}
+			if w < 0 {
+				return fmt.Errorf("classify config: source %s: weight for %s must not be negative", source, t)
+			}
+		}
+		if err := validateRules(o.Rules, source); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateRules(rules []Rule, source string) error {
+	where := "classify config"
+	if source != "" {
+		where += ": source " + source
+	}
+	for i := range rules {
+		r := &rules[i]
+		if !r.Type.IsValid() {
+			return fmt.Errorf("%s: rule %d: unknown type %q", where, i+1, r.Type)
+		}
+		if (r.Match == "") == (r.Regex == "") {
+			return fmt.Errorf("%s: rule %d: set exactly one of match or regex", where, i+1)
+		}
+		if err := r.compile(); err != nil {
+			return fmt.Errorf("%s: rule %d: %w", where, i+1, err)
+		}
+		if r.Weight == 0 {
+			r.Weight = 1
+		}
+		r.Negations = lowerAll(r.Negations)
+	}
+	return nil
+}
+
+func (r *Rule) compile() error {
+	if r.Regex == "" || r.re != nil {
+		r.Match = strings.ToLower(r.Match)
+		return nil
+	}
+	re, err := regexp.Compile("(?i)" + r.Regex)
+	if err != nil {
+		return fmt.Errorf("invalid regex %q: %w", r.Regex, err)
+	}
+	r.re = re
+	return nil
+}
+
+// Pattern is the rule's match text or regex, for display.
+func (r Rule) Pattern() string {
+	switch {
+	case r.Regex != "":
+		return "/" + r.Regex + "/"
+	case r.Match != "":
+		return fmt.Sprintf("%q", r.Match)
+	}
+	return ""
+}
+
+func lowerAll(ss []string) []string {
+	out := make([]string, 0, len(ss))
+	for _, s := range ss {
+		if s = strings.ToLower(strings.TrimSpace(s)); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// Hit is a rule that matched, and whether a negation cancelled it.
+type Hit struct {
+	Rule      Rule   `json:"rule"`
+	Source    string `json:"source"` // "config", "source:<name>", "custom" or "builtin"
+	Matched   string `json:"matched"`
+	NegatedBy string `json:"negated_by,omitempty"`
+}
+
+// Result is the classification of a text.
+type Result struct {
+	Type       types.InsightType             `json:"type"`
+	Confidence float64                       `json:"confidence"`
+	Scores     map[types.InsightType]float64 `json:"scores"`
+	Hits       []Hit                         `json:"hits"`
+	Fallback   bool                          `json:"fallback"` // No type reached the threshold
+}
+
+// Matched reports whether any rule fired without being negated. The
+// trained model's hits don't count.
+func (r Result) Matched() bool {
+	for _, h := range r.Hits {
+		if h.NegatedBy == "" && h.Source != "model" {
+			return true
+		}
+	}
+	return false
+}
+
+// Classify scores text for the given source type ("" for none). Rules are
+// tried in order: the source's rules, the config's rules, custom types'
+// detect phrases, then the built-in rules. Each non-negated hit adds its
+// weight to its type. A trained model then adds each type's probability
+// times model_weight, and the source's weights scale the totals. The
+// highest score wins, ties going to the type hit first. Confidence is the
+// winning score over the winning plus runner-up score plus 0.5, kept
+// within 0.05-0.95; below the threshold the default type and confidence
+// are returned.
+func (c *Config) Classify(text, source string) Result {
+	lower := strings.ToLower(text)
+	res := Result{Scores: make(map[types.InsightType]float64)}
+	var order []types.InsightType
+
+	try := func(rules []Rule, origin string) {
+		for _, r := range rules {
+			matched, before := r.find(text, lower)
+			if matched == "" {
+				continue
+			}
+			hit := Hit{Rule: r, Source: origin, Matched: matched}
+			if !r.IgnoreNegations {
+				if hit.NegatedBy = negatedBy(before, r.Negations); hit.NegatedBy == "" {
+					hit.NegatedBy = negatedBy(before, c.Negations)
+				}
+			}
+			res.Hits = append(res.Hits, hit)
+			if hit.NegatedBy != "" {
+				continue
+			}
+			if _, seen := res.Scores[r.Type]; !seen {
+				order = append(order, r.Type)
+			}
+			res.Scores[r.Type] += r.Weight
+		}
+	}
+
+	override := c.Sources[source]
+	try(override.Rules, "source:"+source)
+	try(c.Rules, "config")
+	try(customRules(), "custom")
+	if !c.NoBuiltin {
+		try(builtinRules(), "builtin")
+	}
+
+	if c.Model != nil && !c.NoModel {
+		probs := c.Model.Predict(text)
+		for _, t := range c.Model.Types() {
+			w := math.Round(c.ModelWeight*probs[t]*100) / 100
+			if w < 0.01 || !t.IsValid() {
+				continue
+			}
+			res.Hits = append(res.Hits, Hit{
+				Rule:    Rule{Name: "model", Type: t, Weight: w},
+				Source:  "model",
+				Matched: fmt.Sprintf("p=%.2f", probs[t]),
+			})
+			if _, seen := res.Scores[t]; !seen {
+				order = append(order, t)
+			}
+			res.Scores[t] += w
+		}
+	}
+
+	for t := range res.Scores {
+		if w, ok := override.Weights[t]; ok {
+			res.Scores[t] *= w
+		}
+	}
+
+	var best, second float64
+	for _, t := range order {
+		switch s := res.Scores[t]; {
+		case s > best:
+			second, best = best, s
+			res.Type = t
+		case s > second:
+			second = s
+		}
+	}
+	if best < c.Threshold {
+		res.Type, res.Confidence, res.Fallback = c.DefaultType, c.DefaultConfidence, true
+		return res
+	}
+	conf := math.Max(0.05, math.Min(0.95, best/(best+second+0.5)))
+	res.Confidence = math.Round(conf*100) / 100
+	return res
+}
+
+// find returns the matched text and the lower-cased text before it, or ""
+// if the rule doesn't match.
+func (r Rule) find(text, lower string) (string, string) {
+	if r.re != nil {
+		loc := r.re.FindStringIndex(text)
+		if loc == nil {
+			return "", ""
+		}
+		return text[loc[0]:loc[1]], strings.ToLower(text[:loc[0]])
+	}
+	if r.Match == "" {
+		return "", ""
+	}
+	i := strings.Index(lower, r.Match)
+	if i < 0 {
+		return "", ""
+	}
+	return lower[i : i+len(r.Match)], lower[:i]
+}
+
+// negatedBy returns the negation found among the last few words of
+// before, or "".
+func negatedBy(before string, negations []string) string {
+	words := strings.FieldsFunc(before, func(r rune) bool {
+		return !(r == '\'' || r == '-' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r > 127)
+	})
+	if len(words) > negationWindow {
+		words = words[len(words)-negationWindow:]
+	}
+	window := " " + strings.Join(words, " ") + " "
+	for _, n := range negations {
+		if strings.Contains(window, " "+n+" ") {
+			return n
+		}
+	}
+	return ""
+}
+
+// customRules turns the registered custom types' detect phrases into rules.
+func customRules() []Rule {
+	var rules []Rule
+	for _, def := range types.CustomInsightTypes() {
+		for _, phrase := range def.Detect {
+			rules = append(rules, Rule{Type: def.Name, Match: phrase, Weight: DefaultCustomRule})
+		}
+	}
+	return rules
+}
+
+var (
+	builtinOnce     sync.Once
+	builtinCompiled []Rule
+)
+
+func builtinRules() []Rule {
+	builtinOnce.Do(func() {
+		builtinCompiled = BuiltinRules()
+		validateRules(builtinCompiled, "")
+	})
+	return builtinCompiled
+}
+
+var (
+	defaultMu  sync.RWMutex
+	defaultCfg = DefaultConfig()
+)
+
+// Default returns the config registered with SetDefault, or the default
+// config.
+func Default() *Config {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultCfg
+}
+
+// SetDefault registers the config used by Default; nil restores the
+// default config.
+func SetDefault(c *Config) {
+	if c == nil {
+		c = DefaultConfig()
+	}
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultCfg = c
+}
+
+// LoadAndApply loads path and registers it with SetDefault. On error the
+// default config is registered so a broken file never leaves stale rules
+// behind.
+func LoadAndApply(path string) error {
+	cfg, err := Load(path)
+	if err != nil {
+		SetDefault(nil)
+		return err
+	}
+	SetDefault(cfg)
+	return nil
+}
+
+// SortedScores returns the types with a score, highest first.
+func (r Result) SortedScores() []types.InsightType {
+	var ts []types.InsightType
+	for t := range r.Scores {
+		ts = append(ts, t)
+	}
+	sort.SliceStable(ts, func(i, j int) bool {
+		if r.Scores[ts[i]] != r.Scores[ts[j]] {
+			return r.Scores[ts[i]] > r.Scores[ts[j]]
+		}
+		return ts[i] < ts[j]
+	})
+	return ts
+}
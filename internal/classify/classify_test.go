@@ -0,0 +1,166 @@
+package classify
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+)
+
+func TestClassify_Builtin(t *testing.T) {
+	cfg := DefaultConfig()
+	tests := []struct {
+		text string
+		want types.InsightType
+	}{
+		{"What should we use for caching?", types.InsightQuestion},
+		{"decided: we'll use Redis", types.InsightDecision},
+		{"turns out the library doesn't stream", types.InsightPivot},
+		{"found: the API returns 404", types.InsightDiscovery},
+		{"The caching layer needs work", types.InsightHypothesis},
+		{"Why is it not working?", types.InsightQuestion},
+	}
+	for _, tt := range tests {
+		if got := cfg.Classify(tt.text, "").Type; got != tt.want {
+			t.Errorf("Classify(%q) = %s, want %s", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestClassify_Confidence(t *testing.T) {
+	cfg := DefaultConfig()
+	one := cfg.Classify("going with the simple approach", "")
+	two := cfg.Classify("decided: going with the simple approach", "")
+	mixed := cfg.Classify("turns out we're going with the simple approach", "")
+	none := cfg.Classify("the simple approach", "")
+
+	if !(two.Confidence > one.Confidence && one.Confidence > mixed.Confidence) {
+		t.Errorf("confidence should grow with agreeing rules and shrink with competing ones: two=%v one=%v mixed=%v",
+			two.Confidence, one.Confidence, mixed.Confidence)
+	}
+	if !none.Fallback || none.Confidence != DefaultConfidence || none.Matched() {
+		t.Errorf("no match should fall back to the default, got %+v", none)
+	}
+	// Ties go to the type hit first: pivot rules come after decision rules
+	if mixed.Type != types.InsightDecision {
+		t.Errorf("tie should go to the earlier rule, got %s", mixed.Type)
+	}
+}
+
+func TestClassify_Negation(t *testing.T) {
+	res := DefaultConfig().Classify("We are not going with Redis", "")
+	if res.Type != types.InsightHypothesis || res.Matched() {
+		t.Errorf("negated rule should not count, got %s", res.Type)
+	}
+	if len(res.Hits) != 1 || res.Hits[0].NegatedBy != "not" {
+		t.Errorf("negated hit should be reported, got %+v", res.Hits)
+	}
+	// Negations further back than the window don't apply
+	res = DefaultConfig().Classify("Not sure about the rest, but we are going with Redis", "")
+	if res.Type != types.InsightDecision {
+		t.Errorf("distant negation should not apply, got %s", res.Type)
+	}
+}
+
+func TestParse_RulesAndSources(t *testing.T) {
+	types.SetCustomInsightTypes([]types.CustomInsightType{
+		{Name: "risk", Symbol: "⚠", Plural: "risks", Detect: []string{"could break"}},
+	})
+	t.Cleanup(func() { types.SetCustomInsightTypes(nil) })
+
+	cfg, err := Parse([]byte(`version: 1
+threshold: 1.5
+negations: ["hardly"]
+rules:
+  - type: decision
+    match: "We Agreed"
+    weight: 2
+  - type: risk
+    regex: '\bmight (break|fail)\b'
+    weight: 2
+    negations: ["unlikely to"]
+sources:
+  slack:
+    weights: {question: 0.2}
+    rules:
+      - type: decision
+        match: ":white_check_mark:"
+        weight: 2
+`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if got := cfg.Classify("we agreed to ship Friday", "").Type; got != types.InsightDecision {
+		t.Errorf("config rule: got %s", got)
+	}
+	if got := cfg.Classify("the migration might break logins", "").Type; got != "risk" {
+		t.Errorf("regex rule for a custom type: got %s", got)
+	}
+	if got := cfg.Classify("the migration is unlikely to break logins", "").Type; got != types.InsightHypothesis {
+		t.Errorf("rule negation: got %s", got)
+	}
+	if got := cfg.Classify("eviction could break sessions", "").Type; got != "risk" {
+		t.Errorf("custom type detect phrase: got %s", got)
+	}
+	// Threshold 1.5: a single weight-1 builtin hit is not enough
+	if got := cfg.Classify("found: hardly anything", "").Type; got != types.InsightHypothesis {
+		t.Errorf("threshold: got %s", got)
+	}
+	// The configured negations replace the defaults
+	if got := cfg.Classify("we did not agree, we agreed later", "").Type; got != types.InsightDecision {
+		t.Errorf("default negations should be replaced, got %s", got)
+	}
+
+	ship := cfg.Classify(":white_check_mark: ship it?", "slack")
+	if ship.Type != types.InsightDecision || ship.Scores[types.InsightQuestion] != 0.5 {
+		t.Errorf("slack override: got %s with scores %v", ship.Type, ship.Scores)
+	}
+	if got := cfg.Classify(":white_check_mark: ship it?", "").Type; got != types.InsightQuestion {
+		t.Errorf("source rules should only apply to their source, got %s", got)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, bad := range []string{
+		"rules: []\n",
+		"version: 2\n",
+		"version: 1\ndefault_type: nope\n",
+		"version: 1\nrules:\n  - type: nope\n    match: x\n",
+		"version: 1\nrules:\n  - type: decision\n",
+		"version: 1\nrules:\n  - type: decision\n    match: x\n    regex: y\n",
+		"version: 1\nrules:\n  - type: decision\n    regex: '('\n",
+		"version: 1\nsources:\n  slack:\n    weights: {question: -1}\n",
+	} {
+		if _, err := Parse([]byte(bad)); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+func TestLoadAndApply(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ConfigFileName)
+	t.Cleanup(func() { SetDefault(nil) })
+
+	if err := LoadAndApply(path); err != nil || Default().Threshold != DefaultThreshold {
+		t.Fatalf("missing file should load the default config, got %v", err)
+	}
+
+	os.WriteFile(path, []byte("version: 1\nrules:\n  - type: decision\n    match: ship it\n"), 0644)
+	if err := LoadAndApply(path); err != nil {
+		t.Fatalf("LoadAndApply() error = %v", err)
+	}
+	if got := Default().Classify("ok, ship it", "").Type; got != types.InsightDecision {
+		t.Errorf("loaded rule not applied, got %s", got)
+	}
+
+	os.WriteFile(path, []byte("version: 1\nrules:\n  - type: nope\n    match: x\n"), 0644)
+	if err := LoadAndApply(path); err == nil || !strings.Contains(err.Error(), "nope") {
+		t.Errorf("expected an error naming the bad type, got %v", err)
+	}
+	if got := Default().Classify("ok, ship it", "").Type; got != types.InsightHypothesis {
+		t.Errorf("a broken config should restore the defaults, got %s", got)
+	}
+}
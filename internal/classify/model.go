@@ -0,0 +1,242 @@
+package classify
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+)
+
+// ModelFileName is the trained model file inside .beadcrumbs/.
+const ModelFileName = "classify-model.json"
+
+// ModelVersion is the model file format this bdc reads and writes.
+const ModelVersion = 1
+
+// DefaultModelWeight scales the model's probabilities when they are added
+// to rule scores.
+const DefaultModelWeight = 2.0
+
+// Example is a labeled text to train or evaluate on.
+type Example struct {
+	Text string
+	Type types.InsightType
+}
+
+// Model is a multinomial naive Bayes classifier over word unigrams and
+// bigrams, trained with add-one smoothing.
+type Model struct {
+	Version   int                              `json:"version"`
+	TrainedAt time.Time                        `json:"trained_at"`
+	Examples  int                              `json:"examples"`
+	Vocab     int                              `json:"vocab"`
+	Classes   map[types.InsightType]*ClassStat `json:"classes"`
+}
+
+// ClassStat holds one type's training counts.
+type ClassStat struct {
+	Docs   int            `json:"docs"`
+	Tokens int            `json:"tokens"`
+	Counts map[string]int `json:"counts"`
+}
+
+// Train fits a model to the examples.
+func Train(examples []Example) *Model {
+	m := &Model{Version: ModelVersion, TrainedAt: time.Now().UTC(), Classes: make(map[types.InsightType]*ClassStat)}
+	vocab := make(map[string]bool)
+	for _, ex := range examples {
+		c := m.Classes[ex.Type]
+		if c == nil {
+			c = &ClassStat{Counts: make(map[string]int)}
+			m.Classes[ex.Type] = c
+		}
+		c.Docs++
+		for _, tok := range Tokenize(ex.Text) {
+			c.Counts[tok]++
+			c.Tokens++
+			vocab[tok] = true
+		}
+		m.Examples++
+	}
+	m.Vocab = len(vocab)
+	return m
+}
+
+// Predict returns the probability of each type for text, summing to 1.
+func (m *Model) Predict(text string) map[types.InsightType]float64 {
+	tokens := Tokenize(text)
+	logp := make(map[types.InsightType]float64, len(m.Classes))
+	maxLog := math.Inf(-1)
+	for t, c := range m.Classes {
+		lp := math.Log(float64(c.Docs) / float64(m.Examples))
+		denom := math.Log(float64(c.Tokens + m.Vocab + 1))
+		for _, tok := range tokens {
+			lp += math.Log(float64(c.Counts[tok]+1)) - denom
+		}
+		logp[t] = lp
+		maxLog = math.Max(maxLog, lp)
+	}
+
+	probs := make(map[types.InsightType]float64, len(logp))
+	var sum float64
+	for t, lp := range logp {
+		probs[t] = math.Exp(lp - maxLog)
+		sum += probs[t]
+	}
+	for t := range probs {
+		probs[t] /= sum
+	}
+	return probs
+}
+
+// Best returns the most probable type and its probability.
+func (m *Model) Best(text string) (types.InsightType, float64) {
+	var best types.InsightType
+	bestP := -1.0
+	for t, p := range m.Predict(text) {
+		if p > bestP || (p == bestP && t < best) {
+			best, bestP = t, p
+		}
+	}
+	return best, bestP
+}
+
+// Tokenize lower-cases text into word unigrams and bigrams. A trailing
+// question mark becomes the token "?".
+func Tokenize(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	})
+	tokens := make([]string, 0, 2*len(words)+1)
+	for i, w := range words {
+		w = strings.Trim(w, "'")
+		if w == "" {
+			continue
+		}
+		tokens = append(tokens, w)
+		if i > 0 {
+			if prev := strings.Trim(words[i-1], "'"); prev != "" {
+				tokens = append(tokens, prev+" "+w)
+			}
+		}
+	}
+	if strings.HasSuffix(strings.TrimSpace(text), "?") {
+		tokens = append(tokens, "?")
+	}
+	return tokens
+}
+
+// LoadModel reads a model file. A missing file gives a nil model and no
+// error.
+func LoadModel(path string) (*Model, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read classifier model: %w", err)
+	}
+	m := &Model{}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("failed to parse classifier model: %w", err)
+	}
+	if m.Version != ModelVersion {
+		return nil, fmt.Errorf("unsupported classifier model version %d (retrain with bdc classify train)", m.Version)
+	}
+	if m.Examples == 0 || len(m.Classes) == 0 {
+		return nil, fmt.Errorf("classifier model has no training examples")
+	}
+	for t, c := range m.Classes {
+		if c == nil || c.Counts == nil {
+			return nil, fmt.Errorf("classifier model: class %s has no counts", t)
+		}
+	}
+	return m, nil
+}
+
+// Save writes the model to path.
+func (m *Model) Save(path string) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("failed to marshal classifier model: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write classifier model: %w", err)
+	}
+	return nil
+}
+
+// Types returns the model's types, sorted.
+func (m *Model) Types() []types.InsightType {
+	ts := make([]types.InsightType, 0, len(m.Classes))
+	for t := range m.Classes {
+		ts = append(ts, t)
+	}
+	sort.Slice(ts, func(i, j int) bool { return ts[i] < ts[j] })
+	return ts
+}
+
+// Evaluation compares predictions with the labels of held-out examples.
+type Evaluation struct {
+	Types    []types.InsightType                             `json:"types"`
+	Matrix   map[types.InsightType]map[types.InsightType]int `json:"matrix"` // Matrix[actual][predicted]
+	Total    int                                             `json:"total"`
+	Correct  int                                             `json:"correct"`
+	Accuracy float64                                         `json:"accuracy"`
+}
+
+// Evaluate classifies each example with predict and tallies the results.
+func Evaluate(examples []Example, predict func(text string) types.InsightType) *Evaluation {
+	ev := &Evaluation{Matrix: make(map[types.InsightType]map[types.InsightType]int)}
+	seen := make(map[types.InsightType]bool)
+	for _, ex := range examples {
+		got := predict(ex.Text)
+		if ev.Matrix[ex.Type] == nil {
+			ev.Matrix[ex.Type] = make(map[types.InsightType]int)
+		}
+		ev.Matrix[ex.Type][got]++
+		seen[ex.Type], seen[got] = true, true
+		ev.Total++
+		if got == ex.Type {
+			ev.Correct++
+		}
+	}
+	for t := range seen {
+		ev.Types = append(ev.Types, t)
+	}
+	sort.Slice(ev.Types, func(i, j int) bool { return ev.Types[i] < ev.Types[j] })
+	if ev.Total > 0 {
+		ev.Accuracy = float64(ev.Correct) / float64(ev.Total)
+	}
+	return ev
+}
+
+// Precision is the share of predictions of t that were right.
+func (ev *Evaluation) Precision(t types.InsightType) float64 {
+	var predicted int
+	for _, row := range ev.Matrix {
+		predicted += row[t]
+	}
+	if predicted == 0 {
+		return 0
+	}
+	return float64(ev.Matrix[t][t]) / float64(predicted)
+}
+
+// Recall is the share of examples of t that were predicted as t.
+func (ev *Evaluation) Recall(t types.InsightType) float64 {
+	var actual int
+	for _, n := range ev.Matrix[t] {
+		actual += n
+	}
+	if actual == 0 {
+		return 0
+	}
+	return float64(ev.Matrix[t][t]) / float64(actual)
+}
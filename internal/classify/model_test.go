@@ -0,0 +1,133 @@
+package classify
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+)
+
+var trainingExamples = []Example{
+	{"the import job runs out of memory on large exports", types.InsightDiscovery},
+	{"the cache key ignores the tenant id", types.InsightDiscovery},
+	{"the staging database has no index on events", types.InsightDiscovery},
+	{"we will keep the monolith for billing", types.InsightDecision},
+	{"we will use postgres for the queue", types.InsightDecision},
+	{"we will ship the cli before the web ui", types.InsightDecision},
+	{"maybe the retries cause the duplicate rows", types.InsightHypothesis},
+	{"maybe the slow page is the n+1 query", types.InsightHypothesis},
+}
+
+func TestModel_TrainPredict(t *testing.T) {
+	m := Train(trainingExamples)
+	if m.Examples != len(trainingExamples) || len(m.Classes) != 3 || m.Classes[types.InsightDecision].Docs != 3 {
+		t.Fatalf("unexpected training counts: %+v", m)
+	}
+
+	tests := []struct {
+		text string
+		want types.InsightType
+	}{
+		{"we will use redis for sessions", types.InsightDecision},
+		{"maybe the retries time out", types.InsightHypothesis},
+		{"the export job has no index", types.InsightDiscovery},
+	}
+	for _, tt := range tests {
+		if got, p := m.Best(tt.text); got != tt.want || p <= 0.5 {
+			t.Errorf("Best(%q) = %s (%.2f), want %s", tt.text, got, p, tt.want)
+		}
+	}
+
+	var sum float64
+	for _, p := range m.Predict("anything at all") {
+		sum += p
+	}
+	if sum < 0.999 || sum > 1.001 {
+		t.Errorf("probabilities should sum to 1, got %v", sum)
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	got := Tokenize("Don't ship it, yet?")
+	want := []string{"don't", "ship", "don't ship", "it", "ship it", "yet", "it yet", "?"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Tokenize = %q, want %q", got, want)
+	}
+}
+
+func TestModel_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ModelFileName)
+	if m, err := LoadModel(path); m != nil || err != nil {
+		t.Fatalf("missing model should give nil, nil; got %v, %v", m, err)
+	}
+
+	m := Train(trainingExamples)
+	if err := m.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadModel(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := m.Predict("we will use redis")
+	for typ, p := range loaded.Predict("we will use redis") {
+		if math.Abs(p-want[typ]) > 1e-9 {
+			t.Errorf("loaded model predicts %s %v, want %v", typ, p, want[typ])
+		}
+	}
+
+	os.WriteFile(path, []byte(`{"version": 99}`), 0644)
+	if _, err := LoadModel(path); err == nil {
+		t.Error("expected an unsupported version to fail")
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	examples := []Example{
+		{"a", types.InsightDecision},
+		{"b", types.InsightDecision},
+		{"c", types.InsightQuestion},
+		{"d", types.InsightQuestion},
+	}
+	predicted := map[string]types.InsightType{
+		"a": types.InsightDecision,
+		"b": types.InsightQuestion,
+		"c": types.InsightQuestion,
+		"d": types.InsightQuestion,
+	}
+	ev := Evaluate(examples, func(text string) types.InsightType { return predicted[text] })
+
+	if ev.Total != 4 || ev.Correct != 3 || ev.Accuracy != 0.75 {
+		t.Errorf("unexpected totals: %+v", ev)
+	}
+	if ev.Matrix[types.InsightDecision][types.InsightQuestion] != 1 {
+		t.Errorf("expected one decision predicted as question, got %v", ev.Matrix)
+	}
+	if p := ev.Precision(types.InsightQuestion); p < 0.66 || p > 0.67 {
+		t.Errorf("question precision = %v, want 2/3", p)
+	}
+	if r := ev.Recall(types.InsightDecision); r != 0.5 {
+		t.Errorf("decision recall = %v, want 0.5", r)
+	}
+}
+
+func TestClassify_Model(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Model = Train(trainingExamples)
+
+	res := cfg.Classify("we will keep postgres", "")
+	if res.Type != types.InsightDecision || res.Fallback {
+		t.Errorf("model should type a plain statement, got %+v", res)
+	}
+	if res.Matched() {
+		t.Error("model hits should not count as rule matches")
+	}
+
+	cfg.NoModel = true
+	if res := cfg.Classify("we will keep postgres", ""); !res.Fallback {
+		t.Errorf("no_model should ignore the model, got %+v", res)
+	}
+}
@@ -0,0 +1,254 @@
+// Package gitsync stores beadcrumbs data on a git ref (refs/beadcrumbs/data
+// by default) instead of the working tree, so it can be shared with plain
+// git push/fetch of that ref. Each sync writes a commit whose tree holds
+// insights.jsonl, threads.jsonl and deps.jsonl.
+package gitsync
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/jsonl"
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+)
+
+// DefaultRef is the ref beadcrumbs data is stored on. It holds plain
+// commits, not git notes, so it lives outside refs/notes/ where git notes
+// tools would misread it.
+const DefaultRef = "refs/beadcrumbs/data"
+
+// File names inside a snapshot tree (the same as in .beadcrumbs/).
+const (
+	insightsFile = "insights.jsonl"
+	threadsFile  = "threads.jsonl"
+	depsFile     = "deps.jsonl"
+)
+
+// Snapshot is the full set of records stored in one sync commit.
+type Snapshot struct {
+	Insights []*types.Insight
+	Threads  []*types.InsightThread
+	Deps     []*types.Dependency
+}
+
+// Repo runs git plumbing in a repository. An empty Dir means the current
+// directory.
+type Repo struct {
+	Dir string
+}
+
+// repoEnvVars locate a repository. Git sets some of them when running hooks;
+// they must not leak into commands aimed at another worktree.
+var repoEnvVars = []string{
+	"GIT_DIR", "GIT_WORK_TREE", "GIT_INDEX_FILE", "GIT_COMMON_DIR",
+	"GIT_PREFIX", "GIT_OBJECT_DIRECTORY", "GIT_ALTERNATE_OBJECT_DIRECTORIES",
+}
+
+// command builds a git command that runs in r.Dir.
+func (r Repo) command(args ...string) *exec.Cmd {
+	if r.Dir != "" {
+		args = append([]string{"-C", r.Dir}, args...)
+	}
+	cmd := exec.Command("git", args...)
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		if !slices.Contains(repoEnvVars, name) {
+			cmd.Env = append(cmd.Env, kv)
+		}
+	}
+	return cmd
+}
+
+// git runs a git command and returns trimmed stdout. Stderr is included in
+// the error so failures are actionable.
+func (r Repo) git(stdin []byte, args ...string) (string, error) {
+	cmd := r.command(args...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("git %s: %s", args[0], msg)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// ResolveRef returns the commit a ref points to, or "" if it doesn't exist.
+func (r Repo) ResolveRef(ref string) string {
+	sha, err := r.git(nil, "rev-parse", "-q", "--verify", ref+"^{commit}")
+	if err != nil {
+		return ""
+	}
+	return sha
+}
+
+// ReadSnapshot loads the records stored in commit. An empty commit yields an
+// empty snapshot; missing files are treated as empty.
+func (r Repo) ReadSnapshot(commit string) (*Snapshot, error) {
+	snap := &Snapshot{}
+	if commit == "" {
+		return snap, nil
+	}
+
+	read := func(name string) ([]byte, bool, error) {
+		if _, err := r.git(nil, "cat-file", "-e", commit+":"+name); err != nil {
+			return nil, false, nil
+		}
+		out, err := r.gitRaw("cat-file", "blob", commit+":"+name)
+		return out, true, err
+	}
+
+	if data, ok, err := read(insightsFile); err != nil {
+		return nil, err
+	} else if ok {
+		if snap.Insights, err = jsonl.DecodeInsights(bytes.NewReader(data)); err != nil {
+			return nil, err
+		}
+	}
+	if data, ok, err := read(threadsFile); err != nil {
+		return nil, err
+	} else if ok {
+		if snap.Threads, err = jsonl.DecodeThreads(bytes.NewReader(data)); err != nil {
+			return nil, err
+		}
+	}
+	if data, ok, err := read(depsFile); err != nil {
+		return nil, err
+	} else if ok {
+		if snap.Deps, err = jsonl.DecodeDependencies(bytes.NewReader(data)); err != nil {
+			return nil, err
+		}
+	}
+	return snap, nil
+}
+
+// gitRaw is git without trimming, for blob contents.
+func (r Repo) gitRaw(args ...string) ([]byte, error) {
+	out, err := r.command(args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return out, nil
+}
+
+// WriteTree stores a snapshot's files as blobs and returns the tree SHA.
+// Records are sorted by key so identical data always yields the same tree.
+func (r Repo) WriteTree(snap *Snapshot) (string, error) {
+	snap.sort()
+
+	var insights, threads, deps bytes.Buffer
+	if err := jsonl.EncodeInsights(&insights, snap.Insights); err != nil {
+		return "", err
+	}
+	if err := jsonl.EncodeThreads(&threads, snap.Threads); err != nil {
+		return "", err
+	}
+	if err := jsonl.EncodeDependencies(&deps, snap.Deps); err != nil {
+		return "", err
+	}
+
+	var entries strings.Builder
+	for _, f := range []struct {
+		name string
+		data []byte
+	}{
+		{depsFile, deps.Bytes()},
+		{insightsFile, insights.Bytes()},
+		{threadsFile, threads.Bytes()},
+	} {
+		blob, err := r.git(f.data, "hash-object", "-w", "--stdin")
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&entries, "100644 blob %s\t%s\n", blob, f.name)
+	}
+	return r.git([]byte(entries.String()), "mktree")
+}
+
+// TreeOf returns the tree SHA of a commit, or "" for an empty commit.
+func (r Repo) TreeOf(commit string) string {
+	if commit == "" {
+		return ""
+	}
+	tree, err := r.git(nil, "rev-parse", commit+"^{tree}")
+	if err != nil {
+		return ""
+	}
+	return tree
+}
+
+// CommitTree creates a commit for tree with the given (non-empty) parents.
+func (r Repo) CommitTree(tree, message string, parents ...string) (string, error) {
+	args := []string{"commit-tree", tree, "-m", message}
+	seen := make(map[string]bool)
+	for _, p := range parents {
+		if p != "" && !seen[p] {
+			seen[p] = true
+			args = append(args, "-p", p)
+		}
+	}
+	return r.git(nil, args...)
+}
+
+// UpdateRef moves ref to newSHA, failing if it no longer points at oldSHA
+// ("" means the ref must not exist yet).
+func (r Repo) UpdateRef(ref, newSHA, oldSHA string) error {
+	if oldSHA == "" {
+		oldSHA = strings.Repeat("0", 40)
+	}
+	_, err := r.git(nil, "update-ref", "-m", "bdc sync", ref, newSHA, oldSHA)
+	return err
+}
+
+// IsAncestor reports whether commit a is an ancestor of (or equal to) b.
+func (r Repo) IsAncestor(a, b string) bool {
+	_, err := r.git(nil, "merge-base", "--is-ancestor", a, b)
+	return err == nil
+}
+
+// HasRemote reports whether a remote with this name is configured.
+func (r Repo) HasRemote(remote string) bool {
+	_, err := r.git(nil, "remote", "get-url", remote)
+	return err == nil
+}
+
+// Fetch fetches ref from remote into the local tracking ref into and returns
+// the fetched commit, or "" if the remote doesn't have the ref yet.
+func (r Repo) Fetch(remote, ref, into string) (string, error) {
+	out, err := r.git(nil, "ls-remote", remote, ref)
+	if err != nil {
+		return "", err
+	}
+	if out == "" {
+		return "", nil
+	}
+	if _, err := r.git(nil, "fetch", "--quiet", "--no-tags", remote, "+"+ref+":"+into); err != nil {
+		return "", err
+	}
+	return r.ResolveRef(into), nil
+}
+
+// Push pushes the local ref to the same name on remote. It is a plain
+// (non-forced) push, so it fails if someone else pushed since the fetch.
+func (r Repo) Push(remote, ref string) error {
+	_, err := r.git(nil, "push", "--quiet", remote, ref+":"+ref)
+	return err
+}
+
+// sort orders records by ID (dependencies by from, to, type).
+func (s *Snapshot) sort() {
+	sort.Slice(s.Insights, func(i, j int) bool { return s.Insights[i].ID < s.Insights[j].ID })
+	sort.Slice(s.Threads, func(i, j int) bool { return s.Threads[i].ID < s.Threads[j].ID })
+	sort.Slice(s.Deps, func(i, j int) bool { return depKey(s.Deps[i]) < depKey(s.Deps[j]) })
+}
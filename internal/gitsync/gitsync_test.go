@@ -0,0 +1,170 @@
+package gitsync
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+)
+
+func insight(id, content string) *types.Insight {
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	return &types.Insight{ID: id, Content: content, Type: types.InsightDiscovery, Timestamp: ts, CreatedAt: ts}
+}
+
+func TestMerge(t *testing.T) {
+	base := &Snapshot{Insights: []*types.Insight{
+		insight("ins-a", "a"),
+		insight("ins-b", "b"),
+		insight("ins-c", "c"),
+	}}
+	ours := &Snapshot{Insights: []*types.Insight{
+		insight("ins-a", "a"),         // unchanged, they edited
+		insight("ins-b", "b ours"),    // we edited
+		insight("ins-c", "c ours"),    // both edited
+		insight("ins-d", "only ours"), // new locally
+	}}
+	theirs := &Snapshot{
+		Insights: []*types.Insight{
+			insight("ins-a", "a theirs"),
+			insight("ins-b", "b"),
+			insight("ins-c", "c theirs"),
+			insight("ins-e", "only theirs"),
+		},
+		Deps: []*types.Dependency{{From: "ins-e", To: "ins-a", Type: types.DepBuildsOn}},
+	}
+
+	res := Merge(base, ours, theirs)
+
+	want := map[string]string{
+		"ins-a": "a theirs",
+		"ins-b": "b ours",
+		"ins-c": "c ours",
+		"ins-d": "only ours",
+		"ins-e": "only theirs",
+	}
+	if len(res.Merged.Insights) != len(want) {
+		t.Fatalf("got %d insights, want %d", len(res.Merged.Insights), len(want))
+	}
+	for i, ins := range res.Merged.Insights {
+		if want[ins.ID] != ins.Content {
+			t.Errorf("%s: content %q, want %q", ins.ID, ins.Content, want[ins.ID])
+		}
+		if i > 0 && res.Merged.Insights[i-1].ID > ins.ID {
+			t.Errorf("insights not sorted by ID")
+		}
+	}
+	if len(res.Conflicts) != 1 || res.Conflicts[0] != "ins-c" {
+		t.Errorf("conflicts = %v, want [ins-c]", res.Conflicts)
+	}
+	if len(res.Merged.Deps) != 1 {
+		t.Errorf("got %d deps, want 1", len(res.Merged.Deps))
+	}
+}
+
+func TestMergeDeletions(t *testing.T) {
+	dep := &types.Dependency{From: "ins-b", To: "ins-a", Type: types.DepBuildsOn}
+	thread := &types.InsightThread{ID: "thr-a", Title: "a"}
+	base := &Snapshot{
+		Insights: []*types.Insight{insight("ins-a", "a"), insight("ins-b", "b"), insight("ins-c", "c"), insight("ins-d", "d")},
+		Threads:  []*types.InsightThread{thread},
+		Deps:     []*types.Dependency{dep},
+	}
+	ours := &Snapshot{Insights: []*types.Insight{
+		insight("ins-b", "b"),      // they deleted it: gone
+		insight("ins-c", "c ours"), // they deleted it, we edited: kept
+		insight("ins-d", "d"),      // unchanged
+	}} // we deleted ins-a, the dep and the thread
+	theirs := &Snapshot{
+		Insights: []*types.Insight{insight("ins-a", "a"), insight("ins-d", "d")},
+		Threads:  []*types.InsightThread{thread},
+		Deps:     []*types.Dependency{dep},
+	}
+
+	res := Merge(base, ours, theirs)
+
+	var ids []string
+	for _, ins := range res.Merged.Insights {
+		ids = append(ids, ins.ID)
+	}
+	if got := strings.Join(ids, " "); got != "ins-c ins-d" {
+		t.Errorf("merged insights = %q, want %q", got, "ins-c ins-d")
+	}
+	if len(res.Merged.Deps) != 0 {
+		t.Errorf("deleted dependency came back: %+v", res.Merged.Deps)
+	}
+	if len(res.Merged.Threads) != 1 {
+		t.Errorf("threads should never be deleted, got %+v", res.Merged.Threads)
+	}
+	if len(res.Conflicts) != 0 {
+		t.Errorf("conflicts = %v, want none", res.Conflicts)
+	}
+
+	deleted := Deleted(ours, res.Merged)
+	if len(deleted.Insights) != 1 || deleted.Insights[0].ID != "ins-b" {
+		t.Errorf("Deleted = %+v, want ins-b", deleted.Insights)
+	}
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	if out, err := exec.Command("git", "init", "-q", dir).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v: %s", err, out)
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	repo := Repo{Dir: dir}
+	if sha := repo.ResolveRef(DefaultRef); sha != "" {
+		t.Fatalf("ResolveRef on missing ref = %q", sha)
+	}
+
+	snap := &Snapshot{
+		Insights: []*types.Insight{insight("ins-b", "b"), insight("ins-a", "a")},
+		Threads:  []*types.InsightThread{{ID: "thr-1", Title: "T", Status: types.ThreadActive}},
+	}
+	tree, err := repo.WriteTree(snap)
+	if err != nil {
+		t.Fatalf("WriteTree: %v", err)
+	}
+	commit, err := repo.CommitTree(tree, "sync")
+	if err != nil {
+		t.Fatalf("CommitTree: %v", err)
+	}
+	if err := repo.UpdateRef(DefaultRef, commit, ""); err != nil {
+		t.Fatalf("UpdateRef: %v", err)
+	}
+	if got := repo.ResolveRef(DefaultRef); got != commit {
+		t.Fatalf("ResolveRef = %q, want %q", got, commit)
+	}
+	if repo.TreeOf(commit) != tree {
+		t.Errorf("TreeOf mismatch")
+	}
+
+	// Same records in a different order produce the same tree.
+	again, err := repo.WriteTree(&Snapshot{
+		Insights: []*types.Insight{insight("ins-a", "a"), insight("ins-b", "b")},
+		Threads:  snap.Threads,
+	})
+	if err != nil {
+		t.Fatalf("WriteTree: %v", err)
+	}
+	if again != tree {
+		t.Errorf("tree not stable: %s != %s", again, tree)
+	}
+
+	read, err := repo.ReadSnapshot(commit)
+	if err != nil {
+		t.Fatalf("ReadSnapshot: %v", err)
+	}
+	if len(read.Insights) != 2 || read.Insights[0].ID != "ins-a" || len(read.Threads) != 1 || len(read.Deps) != 0 {
+		t.Errorf("unexpected snapshot: %d insights, %d threads, %d deps", len(read.Insights), len(read.Threads), len(read.Deps))
+	}
+}
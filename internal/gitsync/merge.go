@@ -0,0 +1,158 @@
+package gitsync
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+)
+
+// MergeResult is the outcome of a three-way merge.
+type MergeResult struct {
+	Merged    *Snapshot
+	Conflicts []string // Keys changed differently on both sides (ours kept)
+}
+
+// Merge combines our records (the local database) with theirs (the remote
+// ref) by ID, using base (the last synced snapshot) to tell which side
+// changed a record:
+//
+//   - new on one side: kept
+//   - unchanged on our side since base: theirs wins
+//   - changed on both sides: ours wins and the key is reported as a conflict
+//   - deleted on one side and unchanged on the other: deleted
+//   - deleted on one side and changed on the other: the changed one is kept
+//
+// Threads can't be deleted, so a thread missing on one side is always kept.
+func Merge(base, ours, theirs *Snapshot) MergeResult {
+	var res MergeResult
+	insightID := func(i *types.Insight) string { return i.ID }
+	threadID := func(t *types.InsightThread) string { return t.ID }
+
+	res.Merged = &Snapshot{
+		Insights: mergeRecords(base.Insights, ours.Insights, theirs.Insights, insightID, true, &res.Conflicts),
+		Threads:  mergeRecords(base.Threads, ours.Threads, theirs.Threads, threadID, false, &res.Conflicts),
+		Deps:     mergeRecords(base.Deps, ours.Deps, theirs.Deps, depKey, true, &res.Conflicts),
+	}
+	res.Merged.sort()
+	sort.Strings(res.Conflicts)
+	return res
+}
+
+// mergeRecords merges one record kind by key. With deletes, a record in base
+// that one side removed and the other left alone is dropped.
+func mergeRecords[T any](base, ours, theirs []T, key func(T) string, deletes bool, conflicts *[]string) []T {
+	baseByKey := make(map[string]T, len(base))
+	for _, r := range base {
+		baseByKey[key(r)] = r
+	}
+	theirsByKey := make(map[string]T, len(theirs))
+	for _, r := range theirs {
+		theirsByKey[key(r)] = r
+	}
+
+	out := make([]T, 0, len(ours)+len(theirs))
+	seen := make(map[string]bool, len(ours))
+	for _, our := range ours {
+		k := key(our)
+		seen[k] = true
+		their, ok := theirsByKey[k]
+		b, inBase := baseByKey[k]
+		if !ok && deletes && inBase && sameRecord(our, b) {
+			continue // They deleted it
+		}
+		if !ok || sameRecord(our, their) {
+			out = append(out, our)
+			continue
+		}
+		switch {
+		case inBase && sameRecord(our, b):
+			out = append(out, their) // Only they changed it
+		case inBase && sameRecord(their, b):
+			out = append(out, our) // Only we changed it
+		default:
+			*conflicts = append(*conflicts, k)
+			out = append(out, our)
+		}
+	}
+	for _, their := range theirs {
+		k := key(their)
+		if seen[k] {
+			continue
+		}
+		if b, inBase := baseByKey[k]; deletes && inBase && sameRecord(their, b) {
+			continue // We deleted it
+		}
+		out = append(out, their)
+	}
+	return out
+}
+
+// sameRecord compares records by their JSON form, which is what gets stored.
+func sameRecord(a, b interface{}) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(ja) == string(jb)
+}
+
+// depKey identifies a dependency; there is at most one edge of each type
+// between two nodes.
+func depKey(d *types.Dependency) string {
+	return d.From + "|" + d.To + "|" + string(d.Type)
+}
+
+// Changed returns the records in to that are missing from, or different in,
+// from.
+func Changed(from, to *Snapshot) *Snapshot {
+	insightID := func(i *types.Insight) string { return i.ID }
+	threadID := func(t *types.InsightThread) string { return t.ID }
+	return &Snapshot{
+		Insights: changedRecords(from.Insights, to.Insights, insightID),
+		Threads:  changedRecords(from.Threads, to.Threads, threadID),
+		Deps:     changedRecords(from.Deps, to.Deps, depKey),
+	}
+}
+
+// Deleted returns the records in from that are missing from to.
+func Deleted(from, to *Snapshot) *Snapshot {
+	insightID := func(i *types.Insight) string { return i.ID }
+	threadID := func(t *types.InsightThread) string { return t.ID }
+	return &Snapshot{
+		Insights: deletedRecords(from.Insights, to.Insights, insightID),
+		Threads:  deletedRecords(from.Threads, to.Threads, threadID),
+		Deps:     deletedRecords(from.Deps, to.Deps, depKey),
+	}
+}
+
+// Len returns the total number of records in the snapshot.
+func (s *Snapshot) Len() int {
+	return len(s.Insights) + len(s.Threads) + len(s.Deps)
+}
+
+func changedRecords[T any](from, to []T, key func(T) string) []T {
+	fromByKey := make(map[string]T, len(from))
+	for _, r := range from {
+		fromByKey[key(r)] = r
+	}
+	var out []T
+	for _, r := range to {
+		if old, ok := fromByKey[key(r)]; !ok || !sameRecord(old, r) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func deletedRecords[T any](from, to []T, key func(T) string) []T {
+	kept := make(map[string]bool, len(to))
+	for _, r := range to {
+		kept[key(r)] = true
+	}
+	var out []T
+	for _, r := range from {
+		if !kept[key(r)] {
+			out = append(out, r)
+		}
+	}
+	return out
+}
@@ -0,0 +1,146 @@
+package gitsync
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/jsonl"
+)
+
+// emptyTree is the SHA of git's empty tree object.
+const emptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// CommonDir returns the absolute path of the repository's shared .git
+// directory (the same for every worktree).
+func (r Repo) CommonDir() (string, error) {
+	dir, err := r.git(nil, "rev-parse", "--git-common-dir")
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(r.Dir, dir)
+	}
+	return filepath.Abs(dir)
+}
+
+// MergeBase returns the best common ancestor of a and b, or "" if they have
+// none.
+func (r Repo) MergeBase(a, b string) string {
+	sha, err := r.git(nil, "merge-base", a, b)
+	if err != nil {
+		return ""
+	}
+	return sha
+}
+
+// AddWorktree checks out branch at path. A missing branch is created from
+// remote's copy when one has been fetched, otherwise as an orphan branch with
+// an empty root commit so it shares no history with the project's branches.
+func (r Repo) AddWorktree(path, branch, remote string) error {
+	if r.ResolveRef("refs/heads/"+branch) == "" {
+		start := r.ResolveRef("refs/remotes/" + remote + "/" + branch)
+		if start == "" {
+			root, err := r.CommitTree(emptyTree, "beadcrumbs: start sync branch")
+			if err != nil {
+				return err
+			}
+			start = root
+		}
+		if _, err := r.git(nil, "branch", "--no-track", branch, start); err != nil {
+			return err
+		}
+	}
+
+	// Forget worktrees whose directories were deleted by hand
+	r.git(nil, "worktree", "prune")
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	// Check out without running post-checkout hooks, which may call bdc
+	if _, err := r.git(nil, "worktree", "add", "--quiet", "--no-checkout", path, branch); err != nil {
+		return err
+	}
+	_, err := Repo{Dir: path}.git(nil, "reset", "--hard", "--quiet")
+	return err
+}
+
+// RemoveWorktree deletes the worktree at path. The branch is kept.
+func (r Repo) RemoveWorktree(path string) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		r.git(nil, "worktree", "prune")
+		return nil
+	}
+	_, err := r.git(nil, "worktree", "remove", "--force", path)
+	return err
+}
+
+// CommitAll stages every change in the worktree and commits it on top of
+// HEAD plus any extra parents (for merges). It returns the new commit, or ""
+// when there was nothing to commit.
+func (r Repo) CommitAll(message string, extraParents ...string) (string, error) {
+	if _, err := r.git(nil, "add", "-A"); err != nil {
+		return "", err
+	}
+	tree, err := r.git(nil, "write-tree")
+	if err != nil {
+		return "", err
+	}
+	head := r.ResolveRef("HEAD")
+	if len(extraParents) == 0 && head != "" && r.TreeOf(head) == tree {
+		return "", nil
+	}
+	commit, err := r.CommitTree(tree, message, append([]string{head}, extraParents...)...)
+	if err != nil {
+		return "", err
+	}
+	if _, err := r.git(nil, "update-ref", "-m", message, "HEAD", commit); err != nil {
+		return "", err
+	}
+	return commit, nil
+}
+
+// FastForward moves the worktree's branch (and files) forward to commit. It
+// uses plumbing so no post-merge hook runs.
+func (r Repo) FastForward(commit string) error {
+	if _, err := r.git(nil, "read-tree", "-u", "-m", "HEAD", commit); err != nil {
+		return err
+	}
+	_, err := r.git(nil, "update-ref", "-m", "fast-forward", "HEAD", commit)
+	return err
+}
+
+// FetchBranch fetches branch from remote into refs/remotes/<remote>/<branch>
+// and returns the fetched commit, or "" if the remote has no such branch.
+func (r Repo) FetchBranch(remote, branch string) (string, error) {
+	return r.Fetch(remote, "refs/heads/"+branch, "refs/remotes/"+remote+"/"+branch)
+}
+
+// PushBranch pushes the local branch to the same name on remote.
+func (r Repo) PushBranch(remote, branch string) error {
+	return r.Push(remote, "refs/heads/"+branch)
+}
+
+// WriteFiles writes the snapshot's JSONL files into dir, sorted the same way
+// as WriteTree.
+func (s *Snapshot) WriteFiles(dir string) error {
+	s.sort()
+	if err := jsonl.ExportInsights(s.Insights, filepath.Join(dir, insightsFile)); err != nil {
+		return err
+	}
+	if err := jsonl.ExportThreads(s.Threads, filepath.Join(dir, threadsFile)); err != nil {
+		return err
+	}
+	return jsonl.ExportDependencies(s.Deps, filepath.Join(dir, depsFile))
+}
+
+// IsBranchName reports whether name is a valid git branch name.
+func (r Repo) IsBranchName(name string) bool {
+	if name == "" || strings.HasPrefix(name, "-") {
+		return false
+	}
+	_, err := r.git(nil, "check-ref-format", "--branch", name)
+	return err == nil
+}
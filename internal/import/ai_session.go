@@ -6,16 +6,12 @@ import (
 	"strings"
 	"time"
 
+	"github.com/brianevanmiller/beadcrumbs/internal/classify"
 	"github.com/brianevanmiller/beadcrumbs/internal/types"
 )
 
-// Common patterns for insight type detection
-var (
-	discoveryPatterns = []string{"found:", "discovered:", "noticed:", "realized:", "identified:"}
-	decisionPatterns  = []string{"decision:", "decided:", "let's go with", "we'll use", "going with", "chose to", "will use"}
-	pivotPatterns     = []string{"actually", "wait,", "but actually", "turns out", "however,", "on second thought"}
-	questionPattern   = regexp.MustCompile(`\?[\s]*$`)
-)
+// minSegmentLength is the shortest text imported as an insight.
+const minSegmentLength = 10
 
 // ParseAISession parses an AI session transcript and extracts insights.
 // The content should be a plain text conversation between human and AI.
@@ -28,9 +24,10 @@ func ParseAISession(content string) ([]*types.Insight, error) {
 // If baseTimestamp is provided (non-zero), all insights will use that timestamp.
 // Otherwise, the current time is used.
 // This is useful for importing historical conversations.
+// The text is read by the default extractor (see DefaultExtractor), which
+// by default makes each markdown segment of 10+ characters an insight
+// whose confidence is the segment's score.
 func ParseAISessionWithTimestamp(content string, baseTimestamp time.Time) ([]*types.Insight, error) {
-	var insights []*types.Insight
-
 	// If no base timestamp provided, use current time
 	now := time.Now()
 	insightTimestamp := baseTimestamp
@@ -38,40 +35,15 @@ func ParseAISessionWithTimestamp(content string, baseTimestamp time.Time) ([]*ty
 		insightTimestamp = now
 	}
 
-	lines := strings.Split(content, "\n")
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-
-		// Skip very short lines (likely not substantive)
-		if len(line) < 10 {
-			continue
-		}
-
-		// Detect insight type based on content
-		insightType := DetectInsightType(line)
-
-		// Create insight
-		insight := &types.Insight{
-			ID:         types.GenerateID("ins"),
-			Timestamp:  insightTimestamp, // When the insight occurred (historical)
-			Content:    line,
-			Summary:    Truncate(line, 80),
-			Type:       insightType,
-			Confidence: 0.7, // Default confidence for auto-extracted
+	return extractInsights([]Passage{{Text: content}}, "ai-session", func(int) types.Insight {
+		return types.Insight{
+			Timestamp: insightTimestamp, // When the insight occurred (historical)
 			Source: types.InsightSource{
 				Type: "ai-session",
 			},
 			CreatedAt: now, // When we imported it (always now)
 		}
-
-		insights = append(insights, insight)
-	}
-
-	return insights, nil
+	})
 }
 
 // ParseConversation parses a conversation with explicit turn markers.
@@ -85,8 +57,6 @@ func ParseConversation(content string) ([]*types.Insight, error) {
 // and an optional base timestamp.
 // If baseTimestamp is provided (non-zero), all insights will use that timestamp.
 func ParseConversationWithTimestamp(content string, baseTimestamp time.Time) ([]*types.Insight, error) {
-	var insights []*types.Insight
-
 	// If no base timestamp provided, use current time
 	now := time.Now()
 	insightTimestamp := baseTimestamp
@@ -98,90 +68,61 @@ func ParseConversationWithTimestamp(content string, baseTimestamp time.Time) ([]
 	turnPattern := regexp.MustCompile(`(?i)^(human|user|ai|assistant|claude|gpt):\s*(.+)`)
 
 	lines := strings.Split(content, "\n")
-	var currentTurn strings.Builder
+	var passages []Passage
+	var participants []string
+	var currentTurn []string
 	var currentSpeaker string
 
 	flushTurn := func() {
-		if currentTurn.Len() > 0 && currentSpeaker != "" {
-			text := strings.TrimSpace(currentTurn.String())
-			if len(text) >= 10 {
-				insightType := DetectInsightType(text)
-
-				participant := currentSpeaker
-				if strings.EqualFold(participant, "human") || strings.EqualFold(participant, "user") {
-					participant = "human"
-				} else {
-					participant = "ai-agent"
-				}
-
-				insight := &types.Insight{
-					ID:         types.GenerateID("ins"),
-					Timestamp:  insightTimestamp, // When the insight occurred
-					Content:    text,
-					Summary:    Truncate(text, 80),
-					Type:       insightType,
-					Confidence: 0.7,
-					Source: types.InsightSource{
-						Type:         "ai-session",
-						Participants: []string{participant},
-					},
-					CreatedAt: now, // When we imported it
-				}
-
-				insights = append(insights, insight)
+		if len(currentTurn) > 0 && currentSpeaker != "" {
+			participant, role := "ai-agent", "assistant"
+			if strings.EqualFold(currentSpeaker, "human") || strings.EqualFold(currentSpeaker, "user") {
+				participant, role = "human", "user"
 			}
-			currentTurn.Reset()
+			passages = append(passages, Passage{Text: strings.Join(currentTurn, "\n"), Role: role})
+			participants = append(participants, participant)
 		}
+		currentTurn = nil
 	}
 
 	for _, line := range lines {
 		if matches := turnPattern.FindStringSubmatch(line); matches != nil {
 			flushTurn()
 			currentSpeaker = matches[1]
-			currentTurn.WriteString(matches[2])
+			currentTurn = append(currentTurn, matches[2])
 		} else if currentSpeaker != "" {
 			// Continuation of current turn
-			currentTurn.WriteString(" ")
-			currentTurn.WriteString(strings.TrimSpace(line))
+			currentTurn = append(currentTurn, line)
 		}
 	}
 	flushTurn()
 
-	return insights, nil
-}
-
-// DetectInsightType determines the type of insight based on content patterns.
-func DetectInsightType(text string) types.InsightType {
-	lower := strings.ToLower(text)
-
-	// Check for questions first
-	if questionPattern.MatchString(text) {
-		return types.InsightQuestion
+	if len(passages) == 0 {
+		return nil, nil
 	}
-
-	// Check for decisions
-	for _, pattern := range decisionPatterns {
-		if strings.Contains(lower, pattern) {
-			return types.InsightDecision
-		}
-	}
-
-	// Check for pivots
-	for _, pattern := range pivotPatterns {
-		if strings.Contains(lower, pattern) {
-			return types.InsightPivot
+	// Each insight found in a turn keeps the turn's speaker
+	return extractInsights(passages, "ai-session", func(i int) types.Insight {
+		return types.Insight{
+			Timestamp: insightTimestamp, // When the insight occurred
+			Source: types.InsightSource{
+				Type:         "ai-session",
+				Participants: []string{participants[i]},
+			},
+			CreatedAt: now, // When we imported it
 		}
-	}
+	})
+}
 
-	// Check for discoveries
-	for _, pattern := range discoveryPatterns {
-		if strings.Contains(lower, pattern) {
-			return types.InsightDiscovery
-		}
-	}
+// DetectInsightType determines the type of insight based on content
+// patterns, using the classifier registered with classify.SetDefault.
+func DetectInsightType(text string) types.InsightType {
+	return classify.Default().Classify(text, "").Type
+}
 
-	// Default to hypothesis
-	return types.InsightHypothesis
+// DetectInsightTypeFrom classifies text from the given source type (e.g.
+// "slack"), applying the classifier's overrides for that source.
+func DetectInsightTypeFrom(text, source string) types.InsightType {
+	return classify.Default().Classify(text, source).Type
 }
 
 // Truncate shortens a string to maxLen characters, adding "..." if truncated.
@@ -105,6 +105,20 @@ func TestDetectInsightType(t *testing.T) {
 	}
 }
 
+func TestDetectInsightType_CustomPhrases(t *testing.T) {
+	types.SetCustomInsightTypes([]types.CustomInsightType{
+		{Name: "risk", Symbol: "⚠", Plural: "risks", Detect: []string{"could break"}},
+	})
+	t.Cleanup(func() { types.SetCustomInsightTypes(nil) })
+
+	if got := DetectInsightType("We'll use Redis, but eviction could break sessions"); got != "risk" {
+		t.Errorf("custom phrase should win over built-in patterns, got %q", got)
+	}
+	if got := DetectInsightType("Could this break sessions?"); got != types.InsightQuestion {
+		t.Errorf("questions should still be detected first, got %q", got)
+	}
+}
+
 // ----------------------------------------------------------------------------
 // Truncate
 // ----------------------------------------------------------------------------
@@ -199,10 +213,11 @@ short
 		t.Fatalf("ParseAISession returned %d insights, want %d", len(insights), wantCount)
 	}
 
-	// Verify confidence and source type on every insight
+	// Verify confidence and source type on every insight. Confidence is the
+	// segment score; every line here is substantive.
 	for i, ins := range insights {
-		if ins.Confidence != 0.7 {
-			t.Errorf("insight[%d].Confidence = %v, want 0.7", i, ins.Confidence)
+		if ins.Confidence < DefaultMinConfidence || ins.Confidence > 1 {
+			t.Errorf("insight[%d].Confidence = %v, want between %v and 1", i, ins.Confidence, DefaultMinConfidence)
 		}
 		if ins.Source.Type != "ai-session" {
 			t.Errorf("insight[%d].Source.Type = %q, want \"ai-session\"", i, ins.Source.Type)
@@ -0,0 +1,113 @@
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// aiderSessionHeader starts each session appended to .aider.chat.history.md.
+var aiderSessionHeader = regexp.MustCompile(`^# aider chat started at (\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2})`)
+
+// aiderModelLine is the startup banner line naming the main model.
+var aiderModelLine = regexp.MustCompile(`^> (?:Main )?[Mm]odel: (\S+)`)
+
+// ParseAiderHistory reads an Aider chat history (.aider.chat.history.md).
+// User messages are the "#### " lines, tool output and commands are "> "
+// lines, and everything else is the assistant's reply. Aider only records
+// when each session started, so every turn gets its session's start time
+// (local time) and the origin "aider:<start time>".
+func ParseAiderHistory(r io.Reader) ([]Turn, error) {
+	var turns []Turn
+	var cur *Turn
+	var text []string
+	var started time.Time
+	var origin, model string
+	inFence := false // inside a fenced block of a reply, e.g. an edit
+
+	flush := func() {
+		if cur != nil {
+			cur.Text = strings.TrimSpace(strings.Join(text, "\n"))
+			if cur.Text != "" {
+				turns = append(turns, *cur)
+			}
+		}
+		cur, text, inFence = nil, nil, false
+	}
+	start := func(role string) {
+		flush()
+		cur = &Turn{Role: role, Timestamp: started, Origin: origin}
+		if role == "assistant" {
+			cur.Author = model
+		}
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		fence := cur != nil && cur.Role == "assistant" && strings.HasPrefix(strings.TrimSpace(line), "```")
+		if fence {
+			inFence = !inFence
+		}
+		switch {
+		case fence || inFence:
+			// Edit blocks hold ">>>>>>> REPLACE" lines that aren't tool output
+			text = append(text, line)
+		case aiderSessionHeader.MatchString(line):
+			flush()
+			started, origin, model = time.Time{}, "", ""
+			stamp := aiderSessionHeader.FindStringSubmatch(line)[1]
+			if t, err := time.ParseInLocation("2006-01-02 15:04:05", stamp, time.Local); err == nil {
+				started = t
+				origin = "aider:" + t.Format("2006-01-02T15:04:05")
+			}
+		case strings.HasPrefix(line, "#### "):
+			if cur == nil || cur.Role != "user" {
+				start("user")
+			}
+			text = append(text, strings.TrimPrefix(line, "#### "))
+		case line == "####":
+			// Blank line inside a user message
+			if cur != nil && cur.Role == "user" {
+				text = append(text, "")
+			}
+		case strings.HasPrefix(line, ">"):
+			if m := aiderModelLine.FindStringSubmatch(line); m != nil {
+				model = m[1]
+			}
+			// Tool output ends the reply; another may follow
+			if cur != nil && cur.Role == "assistant" {
+				flush()
+			}
+		case strings.TrimSpace(line) == "":
+			if cur != nil {
+				text = append(text, "")
+			}
+		default:
+			if cur == nil || cur.Role != "assistant" {
+				start("assistant")
+			}
+			text = append(text, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read aider history: %w", err)
+	}
+	flush()
+	return turns, nil
+}
+
+// LooksLikeAiderHistory reports whether text starts like an Aider chat
+// history.
+func LooksLikeAiderHistory(head []byte) bool {
+	for _, line := range strings.SplitN(string(head), "\n", 20) {
+		if aiderSessionHeader.MatchString(strings.TrimSpace(line)) {
+			return true
+		}
+	}
+	return false
+}
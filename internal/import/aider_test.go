@@ -0,0 +1,83 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const aiderHistory = `
+# aider chat started at 2024-05-01 10:22:33
+
+> Aider v0.50.1
+> Main model: gpt-4o with diff edit format
+> Added app.py to the chat.
+
+#### Why does the cache miss on every request?
+####
+#### It started after the upgrade.
+
+Found: the cache key includes the request ID.
+
+app.py
+` + "```python\n<<<<<<< SEARCH\nkey = req.id\n=======\nkey = req.path\n>>>>>>> REPLACE\n```" + `
+
+> Applied edit to app.py
+> Commit 1a2b3c4 fix: cache key
+
+#### thanks
+
+# aider chat started at 2024-05-02 09:00:00
+
+#### Should we add a TTL?
+`
+
+func TestParseAiderHistory(t *testing.T) {
+	turns, err := ParseAiderHistory(strings.NewReader(aiderHistory))
+	if err != nil {
+		t.Fatalf("ParseAiderHistory() error = %v", err)
+	}
+	if len(turns) != 4 {
+		t.Fatalf("got %d turns, want 4: %+v", len(turns), turns)
+	}
+
+	first := turns[0]
+	if first.Role != "user" || first.Text != "Why does the cache miss on every request?\n\nIt started after the upgrade." {
+		t.Errorf("user turn = %+v", first)
+	}
+	started := time.Date(2024, 5, 1, 10, 22, 33, 0, time.Local)
+	if !first.Timestamp.Equal(started) || first.Origin != "aider:2024-05-01T10:22:33" {
+		t.Errorf("turn should carry the session start, got %v %q", first.Timestamp, first.Origin)
+	}
+
+	reply := turns[1]
+	if reply.Role != "assistant" || reply.Author != "gpt-4o" || !strings.HasPrefix(reply.Text, "Found: the cache key") {
+		t.Errorf("assistant turn = %+v", reply)
+	}
+	if strings.Contains(reply.Text, "Applied edit") {
+		t.Error("tool output should not be part of the reply")
+	}
+
+	if turns[3].Origin != "aider:2024-05-02T09:00:00" || turns[3].Author != "" {
+		t.Errorf("second session should reset origin and model, got %+v", turns[3])
+	}
+
+	insights, err := TurnsToInsights(turns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ins := range insights {
+		if strings.Contains(ins.Content, "SEARCH") {
+			t.Errorf("edit blocks should not become insights: %q", ins.Content)
+		}
+	}
+}
+
+func TestLooksLikeAiderHistory(t *testing.T) {
+	if !LooksLikeAiderHistory([]byte(aiderHistory)) {
+		t.Error("aider history not recognized")
+	}
+	if LooksLikeAiderHistory([]byte("Human: hello\nAI: hi there\n")) {
+		t.Error("plain conversation taken for aider history")
+	}
+}
@@ -0,0 +1,191 @@
+package importer
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+)
+
+// builtinImporters returns the importers bdc ships with, in the order
+// detection ties are broken.
+func builtinImporters() []Importer {
+	return []Importer{
+		aiSessionImporter{},
+		transcriptImporter{
+			info:  Info{Name: "claude-code", Description: "Claude Code session transcript (~/.claude/projects/*/<session>.jsonl)", Timestamps: true, Scored: true},
+			sniff: LooksLikeClaudeTranscript,
+			parse: ParseClaudeTurns,
+		},
+		transcriptImporter{
+			info:  Info{Name: "aider", Description: "Aider chat history (.aider.chat.history.md)", Timestamps: true, Scored: true},
+			sniff: LooksLikeAiderHistory,
+			parse: ParseAiderHistory,
+		},
+		transcriptImporter{
+			info:  Info{Name: "codex", Description: "Codex CLI session log (~/.codex/sessions/.../rollout-*.jsonl)", Timestamps: true, Scored: true},
+			sniff: LooksLikeCodexSession,
+			parse: ParseCodexSession,
+		},
+		transcriptImporter{
+			info:  Info{Name: "cursor", Description: "Cursor chat export (markdown from \"Export Chat\")", Timestamps: true, Scored: true},
+			sniff: LooksLikeCursorExport,
+			parse: ParseCursorExport,
+		},
+		transcriptImporter{
+			info:  Info{Name: "chatgpt", Description: "ChatGPT data export (conversations.json)", Timestamps: true, Scored: true},
+			sniff: LooksLikeChatGPTExport,
+			parse: ParseChatGPTExport,
+		},
+		slackImporter{},
+		mappedImporter{
+			info:  Info{Name: "csv", Description: "CSV file, one insight per row", Options: mappedOptions("csv-import")},
+			ext:   ".csv",
+			parse: ParseCSVReader,
+		},
+		mappedImporter{
+			info:  Info{Name: "jsonl", Description: "JSONL file, one insight per object", Options: mappedOptions("jsonl-import")},
+			ext:   ".jsonl",
+			parse: ParseGenericJSONLReader,
+		},
+	}
+}
+
+// textExtensions are treated as prose unless a sniffer says otherwise.
+var textExtensions = map[string]bool{".txt": true, ".md": true, ".log": true}
+
+// aiSessionImporter reads plain text, preferring "Human:"/"AI:" turn
+// markers and falling back to one insight per line. It accepts any file
+// no other importer claims.
+type aiSessionImporter struct{}
+
+func (aiSessionImporter) Info() Info {
+	return Info{Name: "ai-session", Description: "Plain-text AI session, with or without \"Human:\"/\"AI:\" markers", Scored: true}
+}
+
+func (aiSessionImporter) Detect(s Sample) int {
+	if s.Dir {
+		return 0
+	}
+	return scoreFallback
+}
+
+func (aiSessionImporter) Parse(r io.Reader, _ Options) ([]*types.Insight, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	insights, err := ParseConversation(string(content))
+	if err != nil || len(insights) == 0 {
+		insights, err = ParseAISession(string(content))
+	}
+	return insights, err
+}
+
+// transcriptImporter reads an agent conversation format into turns.
+type transcriptImporter struct {
+	info  Info
+	sniff func(head []byte) bool
+	parse func(io.Reader) ([]Turn, error)
+}
+
+func (t transcriptImporter) Info() Info { return t.info }
+
+func (t transcriptImporter) Detect(s Sample) int {
+	if !s.Dir && t.sniff(s.Head) {
+		return scoreContent
+	}
+	return 0
+}
+
+func (t transcriptImporter) Parse(r io.Reader, _ Options) ([]*types.Insight, error) {
+	turns, err := t.parse(r)
+	if err != nil {
+		return nil, err
+	}
+	return TurnsToInsights(turns)
+}
+
+// slackImporter reads a Slack export directory or a single channel file.
+type slackImporter struct{}
+
+func (slackImporter) Info() Info {
+	return Info{Name: "slack", Description: "Slack export (directory of channel JSON files, or one file)", Timestamps: true}
+}
+
+func (slackImporter) Detect(s Sample) int {
+	switch {
+	case s.Dir:
+		return scoreExtension
+	case s.Ext == ".json":
+		return scoreGuess
+	case s.Ext == ".csv" || s.Ext == ".jsonl" || textExtensions[s.Ext]:
+		return 0
+	}
+	trimmed := bytes.TrimSpace(s.Head)
+	if bytes.HasPrefix(trimmed, []byte("[")) || bytes.HasPrefix(trimmed, []byte("{")) {
+		return scoreGuess
+	}
+	return 0
+}
+
+func (slackImporter) Parse(r io.Reader, _ Options) ([]*types.Insight, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	return ParseSlackJSON(content)
+}
+
+func (slackImporter) ParseDir(dir string, _ Options) ([]*types.Insight, error) {
+	return ParseSlackExport(dir)
+}
+
+// mappedImporter reads tabular formats whose columns are mapped to insight
+// fields through options.
+type mappedImporter struct {
+	info  Info
+	ext   string
+	parse func(io.Reader, ColumnMapping, string) ([]*types.Insight, error)
+}
+
+func (m mappedImporter) Info() Info { return m.info }
+
+func (m mappedImporter) Detect(s Sample) int {
+	if !s.Dir && s.Ext == m.ext {
+		return scoreExtension
+	}
+	return 0
+}
+
+func (m mappedImporter) Parse(r io.Reader, opts Options) ([]*types.Insight, error) {
+	mapping := ColumnMapping{
+		Content:   opts["map-content"],
+		Type:      opts["map-type"],
+		Timestamp: opts["map-timestamp"],
+		Author:    opts["map-author"],
+		Summary:   opts["map-summary"],
+		SourceRef: opts["map-source-ref"],
+		Tags:      opts["map-tags"],
+	}
+	sourceType := opts["source-type"]
+	if sourceType == "" {
+		sourceType = m.info.Name + "-import"
+	}
+	return m.parse(r, mapping, sourceType)
+}
+
+// mappedOptions is the options schema of the CSV and JSONL importers.
+func mappedOptions(defaultSource string) []Option {
+	return []Option{
+		{Name: "map-content", Description: "column holding the content (default content)"},
+		{Name: "map-type", Description: "column holding the insight type (default type)"},
+		{Name: "map-timestamp", Description: "column holding the timestamp (default timestamp)"},
+		{Name: "map-author", Description: "column holding the author (default author)"},
+		{Name: "map-summary", Description: "column holding the summary (default summary)"},
+		{Name: "map-source-ref", Description: "column holding the source reference (default source_ref)"},
+		{Name: "map-tags", Description: "column holding comma-separated tags (default tags)"},
+		{Name: "source-type", Description: "source type to record (default " + defaultSource + ")"},
+	}
+}
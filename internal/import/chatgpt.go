@@ -0,0 +1,136 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// chatGPTConversation is one conversation of a ChatGPT data export
+// (conversations.json). Messages form a tree in Mapping; the branch shown
+// to the user ends at CurrentNode.
+type chatGPTConversation struct {
+	ID             string                 `json:"id"`
+	ConversationID string                 `json:"conversation_id"`
+	Title          string                 `json:"title"`
+	CreateTime     float64                `json:"create_time"`
+	CurrentNode    string                 `json:"current_node"`
+	Mapping        map[string]chatGPTNode `json:"mapping"`
+}
+
+type chatGPTNode struct {
+	Parent  string `json:"parent"`
+	Message *struct {
+		Author struct {
+			Role string `json:"role"`
+		} `json:"author"`
+		CreateTime *float64 `json:"create_time"`
+		Content    struct {
+			ContentType string            `json:"content_type"`
+			Parts       []json.RawMessage `json:"parts"`
+		} `json:"content"`
+		Metadata struct {
+			ModelSlug string `json:"model_slug"`
+			Hidden    bool   `json:"is_visually_hidden_from_conversation"`
+		} `json:"metadata"`
+	} `json:"message"`
+}
+
+// ParseChatGPTExport reads conversations.json from a ChatGPT data export,
+// one conversation at a time. For each conversation only the branch that
+// was last shown is read; system, tool and hidden messages are skipped.
+// Turns carry their message's time, the origin "chatgpt:<conversation id>"
+// and, for assistant turns, the model.
+func ParseChatGPTExport(r io.Reader) ([]Turn, error) {
+	dec := json.NewDecoder(r)
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+		return nil, fmt.Errorf("failed to parse ChatGPT export: expected a JSON array")
+	}
+	var turns []Turn
+	for dec.More() {
+		var conv chatGPTConversation
+		if err := dec.Decode(&conv); err != nil {
+			return nil, fmt.Errorf("failed to parse ChatGPT export: %w", err)
+		}
+		turns = append(turns, conv.turns()...)
+	}
+	return turns, nil
+}
+
+func (c *chatGPTConversation) turns() []Turn {
+	id := c.ConversationID
+	if id == "" {
+		id = c.ID
+	}
+
+	// Walk up from the current node, then reverse into reading order
+	var path []chatGPTNode
+	seen := make(map[string]bool)
+	for node := c.CurrentNode; node != "" && !seen[node]; {
+		seen[node] = true
+		n, ok := c.Mapping[node]
+		if !ok {
+			break
+		}
+		path = append(path, n)
+		node = n.Parent
+	}
+
+	var turns []Turn
+	for i := len(path) - 1; i >= 0; i-- {
+		msg := path[i].Message
+		if msg == nil || msg.Metadata.Hidden || msg.Content.ContentType != "text" {
+			continue
+		}
+		role := msg.Author.Role
+		if role != "user" && role != "assistant" {
+			continue
+		}
+		var parts []string
+		for _, raw := range msg.Content.Parts {
+			var s string
+			if json.Unmarshal(raw, &s) == nil && strings.TrimSpace(s) != "" {
+				parts = append(parts, s)
+			}
+		}
+		if len(parts) == 0 {
+			continue
+		}
+
+		created := c.CreateTime
+		if msg.CreateTime != nil {
+			created = *msg.CreateTime
+		}
+		turn := Turn{Role: role, Text: strings.Join(parts, "\n\n"), Timestamp: unixFloat(created)}
+		if id != "" {
+			turn.Origin = "chatgpt:" + id
+		}
+		if role == "assistant" {
+			turn.Author = msg.Metadata.ModelSlug
+		}
+		turns = append(turns, turn)
+	}
+	return turns
+}
+
+// unixFloat converts fractional Unix seconds; 0 gives the zero time.
+func unixFloat(secs float64) time.Time {
+	if secs <= 0 {
+		return time.Time{}
+	}
+	whole, frac := math.Modf(secs)
+	return time.Unix(int64(whole), int64(frac*1e9)).UTC()
+}
+
+// LooksLikeChatGPTExport reports whether text starts like a ChatGPT
+// conversations.json: an array of objects with a message "mapping".
+func LooksLikeChatGPTExport(head []byte) bool {
+	trimmed := bytes.TrimSpace(head)
+	return bytes.HasPrefix(trimmed, []byte("[")) &&
+		bytes.Contains(head, []byte(`"mapping"`)) &&
+		(bytes.Contains(head, []byte(`"current_node"`)) || bytes.Contains(head, []byte(`"create_time"`)))
+}
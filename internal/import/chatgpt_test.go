@@ -0,0 +1,53 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const chatGPTExport = `[{
+  "title": "Queue design",
+  "create_time": 1714550000.5,
+  "conversation_id": "c0ffee-01",
+  "current_node": "n4",
+  "mapping": {
+    "root": {"id": "root", "message": null, "parent": null, "children": ["n1"]},
+    "n1": {"id": "n1", "parent": "root", "children": ["n2"], "message": {"author": {"role": "system"}, "content": {"content_type": "text", "parts": ["You are ChatGPT"]}, "metadata": {"is_visually_hidden_from_conversation": true}}},
+    "n2": {"id": "n2", "parent": "n1", "children": ["n3", "n3b"], "message": {"author": {"role": "user"}, "create_time": 1714550010, "content": {"content_type": "text", "parts": ["Should we use SQS or Kafka?"]}, "metadata": {}}},
+    "n3b": {"id": "n3b", "parent": "n2", "children": [], "message": {"author": {"role": "assistant"}, "create_time": 1714550015, "content": {"content_type": "text", "parts": ["An abandoned regenerated answer"]}, "metadata": {"model_slug": "gpt-4o"}}},
+    "n3": {"id": "n3", "parent": "n2", "children": ["n4"], "message": {"author": {"role": "tool"}, "create_time": 1714550016, "content": {"content_type": "text", "parts": ["search results"]}, "metadata": {}}},
+    "n4": {"id": "n4", "parent": "n3", "children": [], "message": {"author": {"role": "assistant"}, "create_time": 1714550020, "content": {"content_type": "text", "parts": ["Decision: going with SQS for now."]}, "metadata": {"model_slug": "gpt-4o"}}}
+  }
+}]`
+
+func TestParseChatGPTExport(t *testing.T) {
+	turns, err := ParseChatGPTExport(strings.NewReader(chatGPTExport))
+	if err != nil {
+		t.Fatalf("ParseChatGPTExport() error = %v", err)
+	}
+	if len(turns) != 2 {
+		t.Fatalf("got %d turns, want 2 (current branch, no system/tool): %+v", len(turns), turns)
+	}
+	if turns[0].Role != "user" || turns[0].Text != "Should we use SQS or Kafka?" || !turns[0].Timestamp.Equal(time.Unix(1714550010, 0)) {
+		t.Errorf("user turn = %+v", turns[0])
+	}
+	if turns[1].Author != "gpt-4o" || turns[1].Origin != "chatgpt:c0ffee-01" || strings.Contains(turns[1].Text, "abandoned") {
+		t.Errorf("assistant turn = %+v", turns[1])
+	}
+}
+
+func TestParseChatGPTExport_NotAnArray(t *testing.T) {
+	if _, err := ParseChatGPTExport(strings.NewReader(`{"mapping":{}}`)); err == nil {
+		t.Error("expected an error for a non-array export")
+	}
+}
+
+func TestLooksLikeChatGPTExport(t *testing.T) {
+	if !LooksLikeChatGPTExport([]byte(chatGPTExport)) {
+		t.Error("chatgpt export not recognized")
+	}
+	if LooksLikeChatGPTExport([]byte(`[{"type":"message","user":"U1","text":"hi","ts":"1.0"}]`)) {
+		t.Error("slack export taken for chatgpt")
+	}
+}
@@ -0,0 +1,179 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+)
+
+// ClaudeMessage is one user or assistant message of a Claude Code session
+// transcript (~/.claude/projects/<project>/<session>.jsonl), reduced to its
+// prose.
+type ClaudeMessage struct {
+	UUID      string
+	SessionID string
+	Role      string // "user" or "assistant"
+	Model     string // Assistant messages only, e.g. "claude-sonnet-4-5"
+	Text      string
+	Timestamp time.Time
+}
+
+// claudeEntry is a transcript line. Besides messages, transcripts hold
+// summaries, attachments, mode changes and other bookkeeping entries.
+type claudeEntry struct {
+	Type                      string    `json:"type"`
+	UUID                      string    `json:"uuid"`
+	SessionID                 string    `json:"sessionId"`
+	Timestamp                 time.Time `json:"timestamp"`
+	IsSidechain               bool      `json:"isSidechain"`
+	IsMeta                    bool      `json:"isMeta"`
+	IsCompactSummary          bool      `json:"isCompactSummary"`
+	IsVisibleInTranscriptOnly bool      `json:"isVisibleInTranscriptOnly"`
+	Message                   *struct {
+		Role    string          `json:"role"`
+		Model   string          `json:"model"`
+		Content json.RawMessage `json:"content"`
+	} `json:"message"`
+}
+
+// claudeNoisePrefixes mark text that Claude Code injects into user messages
+// rather than anything the user wrote.
+var claudeNoisePrefixes = []string{
+	"<command-", "<local-command-", "<system-reminder>", "<user-prompt-submit-hook>",
+	"<bash-", "Caveat:", "[Request interrupted",
+}
+
+// ParseClaudeTranscript reads a Claude Code JSONL transcript and returns its
+// user and assistant messages in order. Tool calls and results, thinking,
+// subagent (sidechain) messages, compaction summaries and injected meta
+// messages are skipped, as are lines that aren't valid JSON.
+func ParseClaudeTranscript(r io.Reader) ([]ClaudeMessage, error) {
+	var messages []ClaudeMessage
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(strings.TrimSpace(string(line))) > 0 {
+			if msg, ok := parseClaudeLine(line); ok {
+				messages = append(messages, msg)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read transcript: %w", err)
+		}
+	}
+	return messages, nil
+}
+
+func parseClaudeLine(line []byte) (ClaudeMessage, bool) {
+	var e claudeEntry
+	if err := json.Unmarshal(line, &e); err != nil {
+		return ClaudeMessage{}, false
+	}
+	if (e.Type != "user" && e.Type != "assistant") || e.Message == nil {
+		return ClaudeMessage{}, false
+	}
+	if e.IsSidechain || e.IsMeta || e.IsCompactSummary || e.IsVisibleInTranscriptOnly {
+		return ClaudeMessage{}, false
+	}
+
+	var parts []string
+	var s string
+	if err := json.Unmarshal(e.Message.Content, &s); err == nil {
+		parts = append(parts, s)
+	} else {
+		var blocks []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		}
+		if err := json.Unmarshal(e.Message.Content, &blocks); err != nil {
+			return ClaudeMessage{}, false
+		}
+		for _, b := range blocks {
+			if b.Type == "text" {
+				parts = append(parts, b.Text)
+			}
+		}
+	}
+
+	var kept []string
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if p != "" && !hasAnyPrefix(p, claudeNoisePrefixes) {
+			kept = append(kept, p)
+		}
+	}
+	if len(kept) == 0 {
+		return ClaudeMessage{}, false
+	}
+
+	return ClaudeMessage{
+		UUID:      e.UUID,
+		SessionID: e.SessionID,
+		Role:      e.Message.Role,
+		Model:     e.Message.Model,
+		Text:      strings.Join(kept, "\n\n"),
+		Timestamp: e.Timestamp,
+	}, true
+}
+
+// LooksLikeClaudeTranscript reports whether the first lines of a JSONL file
+// are Claude Code transcript entries: objects with a "type" and a
+// "sessionId".
+func LooksLikeClaudeTranscript(head []byte) bool {
+	for _, line := range strings.Split(string(head), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		var e struct {
+			Type      string `json:"type"`
+			SessionID string `json:"sessionId"`
+		}
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			continue // possibly cut off at the end of head
+		}
+		if e.Type != "" && e.SessionID != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// ClaudeMessagesToInsights extracts insights from the messages, each with
+// its message's timestamp (see TurnsToInsights). Source.Ref is
+// "claude:<sessionId>" and assistant insights are authored by the model.
+func ClaudeMessagesToInsights(messages []ClaudeMessage) ([]*types.Insight, error) {
+	return TurnsToInsights(claudeTurns(messages))
+}
+
+// ParseClaudeTurns reads a Claude Code transcript as conversation turns.
+func ParseClaudeTurns(r io.Reader) ([]Turn, error) {
+	messages, err := ParseClaudeTranscript(r)
+	if err != nil {
+		return nil, err
+	}
+	return claudeTurns(messages), nil
+}
+
+func claudeTurns(messages []ClaudeMessage) []Turn {
+	turns := make([]Turn, 0, len(messages))
+	for _, msg := range messages {
+		turn := Turn{Role: msg.Role, Text: msg.Text, Timestamp: msg.Timestamp}
+		if msg.Role == "assistant" {
+			turn.Author = msg.Model
+		}
+		if msg.SessionID != "" {
+			turn.Origin = "claude:" + msg.SessionID
+		}
+		turns = append(turns, turn)
+	}
+	return turns
+}
@@ -0,0 +1,95 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+)
+
+const claudeTranscript = `{"type":"summary","summary":"Auth work","leafUuid":"u0"}
+{"type":"user","sessionId":"sess-1","uuid":"u1","timestamp":"2025-06-01T10:00:00Z","message":{"role":"user","content":"Why do tokens expire early?"}}
+{"type":"user","sessionId":"sess-1","uuid":"u1m","isMeta":true,"timestamp":"2025-06-01T10:00:00Z","message":{"role":"user","content":"Caveat: injected by the CLI"}}
+{"type":"assistant","sessionId":"sess-1","uuid":"a1","timestamp":"2025-06-01T10:00:05Z","message":{"role":"assistant","model":"claude-sonnet-4-5","content":[{"type":"thinking","thinking":"hmm"},{"type":"text","text":"Found: the exp check compares seconds to milliseconds.\n\n` + "```go\\nif exp < now {\\n}\\n```" + `\n\nDecision: normalize both to seconds."},{"type":"tool_use","id":"t1","name":"Edit","input":{}}]}}
+{"type":"user","sessionId":"sess-1","uuid":"u2","timestamp":"2025-06-01T10:00:09Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"ok"}]}}
+{"type":"assistant","sessionId":"sess-1","uuid":"a2","isSidechain":true,"timestamp":"2025-06-01T10:00:10Z","message":{"role":"assistant","model":"claude-haiku","content":[{"type":"text","text":"Subagent chatter that should be skipped"}]}}
+not json
+{"type":"user","sessionId":"sess-1","uuid":"u3","timestamp":"2025-06-01T10:01:00Z","message":{"role":"user","content":"<command-name>/clear</command-name>"}}
+`
+
+func TestParseClaudeTranscript(t *testing.T) {
+	msgs, err := ParseClaudeTranscript(strings.NewReader(claudeTranscript))
+	if err != nil {
+		t.Fatalf("ParseClaudeTranscript() error = %v", err)
+	}
+	if len(msgs) != 2 {
+		t.Fatalf("got %d messages, want 2 (user question, assistant text): %+v", len(msgs), msgs)
+	}
+	if msgs[0].Role != "user" || msgs[0].Text != "Why do tokens expire early?" {
+		t.Errorf("first message = %+v", msgs[0])
+	}
+	a := msgs[1]
+	if a.Role != "assistant" || a.Model != "claude-sonnet-4-5" || a.SessionID != "sess-1" {
+		t.Errorf("assistant message = %+v", a)
+	}
+	if !a.Timestamp.Equal(time.Date(2025, 6, 1, 10, 0, 5, 0, time.UTC)) {
+		t.Errorf("timestamp = %v", a.Timestamp)
+	}
+	if strings.Contains(a.Text, "hmm") {
+		t.Error("thinking blocks should be skipped")
+	}
+}
+
+func TestClaudeMessagesToInsights(t *testing.T) {
+	msgs, _ := ParseClaudeTranscript(strings.NewReader(claudeTranscript))
+	insights, err := ClaudeMessagesToInsights(msgs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(insights) != 3 {
+		for _, ins := range insights {
+			t.Logf("%s: %s", ins.Type, ins.Content)
+		}
+		t.Fatalf("got %d insights, want 3 (code block dropped)", len(insights))
+	}
+
+	want := []struct {
+		typ     types.InsightType
+		content string
+		author  string
+	}{
+		{types.InsightQuestion, "Why do tokens expire early?", ""},
+		{types.InsightDiscovery, "Found: the exp check compares seconds to milliseconds.", "claude-sonnet-4-5"},
+		{types.InsightDecision, "Decision: normalize both to seconds.", "claude-sonnet-4-5"},
+	}
+	for i, w := range want {
+		ins := insights[i]
+		if ins.Type != w.typ || ins.Content != w.content || ins.AuthorID != w.author {
+			t.Errorf("insight %d = %s %q by %q, want %s %q by %q", i, ins.Type, ins.Content, ins.AuthorID, w.typ, w.content, w.author)
+		}
+		if ins.Source.Ref != "claude:sess-1" {
+			t.Errorf("insight %d ref = %q", i, ins.Source.Ref)
+		}
+	}
+	if !insights[0].Timestamp.Equal(time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("insights should keep their message's timestamp, got %v", insights[0].Timestamp)
+	}
+	if insights[0].Source.Participants[0] != "human" || insights[1].Source.Participants[0] != "ai-agent" {
+		t.Error("participants should follow the message role")
+	}
+}
+
+func TestLooksLikeClaudeTranscript(t *testing.T) {
+	if !LooksLikeClaudeTranscript([]byte(claudeTranscript)) {
+		t.Error("a Claude Code transcript should be recognized")
+	}
+	generic := `{"content":"JSONL test insight","type":"hypothesis"}` + "\n"
+	if LooksLikeClaudeTranscript([]byte(generic)) {
+		t.Error("generic JSONL should not be taken for a Claude transcript")
+	}
+	// A head cut off mid-line still matches on its complete lines
+	if !LooksLikeClaudeTranscript([]byte(claudeTranscript[:len(claudeTranscript)-20])) {
+		t.Error("a truncated head should still be recognized")
+	}
+}
@@ -0,0 +1,156 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// codexLine is one line of a Codex CLI session log
+// (~/.codex/sessions/YYYY/MM/DD/rollout-*.jsonl). Current logs wrap each
+// item as {"timestamp", "type", "payload"}; older ones start with a
+// {"id", "timestamp", "instructions"} header followed by bare items.
+type codexLine struct {
+	Timestamp string          `json:"timestamp"`
+	Type      string          `json:"type"`
+	Payload   json.RawMessage `json:"payload"`
+	ID        string          `json:"id"`
+}
+
+// codexItem is a response item: a message, function call or reasoning.
+type codexItem struct {
+	Type    string `json:"type"`
+	Role    string `json:"role"`
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+}
+
+// codexNoisePrefixes mark user messages Codex injects for context.
+var codexNoisePrefixes = []string{"<environment_context>", "<user_instructions>", "# AGENTS.md instructions"}
+
+// ParseCodexSession reads a Codex CLI session log. Function calls, their
+// output and reasoning are skipped. Turns carry their line's timestamp
+// (the session's for older logs), the origin "codex:<session id>" and, for
+// assistant turns, the model from the latest turn context.
+func ParseCodexSession(r io.Reader) ([]Turn, error) {
+	var turns []Turn
+	var origin, model string
+	var sessionTime time.Time
+
+	br := bufio.NewReader(r)
+	for {
+		raw, readErr := br.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return nil, fmt.Errorf("failed to read codex session: %w", readErr)
+		}
+		var line codexLine
+		if json.Unmarshal(raw, &line) == nil {
+			ts := parseCodexTime(line.Timestamp)
+			switch line.Type {
+			case "session_meta":
+				var meta struct {
+					ID        string `json:"id"`
+					Timestamp string `json:"timestamp"`
+				}
+				if json.Unmarshal(line.Payload, &meta) == nil {
+					origin = "codex:" + meta.ID
+					sessionTime = parseCodexTime(meta.Timestamp)
+				}
+			case "turn_context":
+				var tc struct {
+					Model string `json:"model"`
+				}
+				if json.Unmarshal(line.Payload, &tc) == nil && tc.Model != "" {
+					model = tc.Model
+				}
+			case "response_item":
+				if turn, ok := codexTurn(line.Payload); ok {
+					turns = append(turns, codexWithContext(turn, ts, origin, model))
+				}
+			case "message":
+				// Older logs: bare items after the header
+				if turn, ok := codexTurn(raw); ok {
+					turns = append(turns, codexWithContext(turn, sessionTime, origin, model))
+				}
+			case "":
+				if line.ID != "" && origin == "" {
+					origin = "codex:" + line.ID
+					sessionTime = ts
+				}
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+	}
+	return turns, nil
+}
+
+func codexWithContext(turn Turn, ts time.Time, origin, model string) Turn {
+	turn.Timestamp = ts
+	turn.Origin = origin
+	if turn.Role == "assistant" {
+		turn.Author = model
+	}
+	return turn
+}
+
+// codexTurn extracts the text of a user or assistant message item.
+func codexTurn(data []byte) (Turn, bool) {
+	var item codexItem
+	if err := json.Unmarshal(data, &item); err != nil || item.Type != "message" {
+		return Turn{}, false
+	}
+	if item.Role != "user" && item.Role != "assistant" {
+		return Turn{}, false
+	}
+	var parts []string
+	for _, c := range item.Content {
+		text := strings.TrimSpace(c.Text)
+		if text == "" || hasAnyPrefix(text, codexNoisePrefixes) {
+			continue
+		}
+		parts = append(parts, text)
+	}
+	if len(parts) == 0 {
+		return Turn{}, false
+	}
+	return Turn{Role: item.Role, Text: strings.Join(parts, "\n\n")}, true
+}
+
+func parseCodexTime(s string) time.Time {
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// LooksLikeCodexSession reports whether the first line of a JSONL file is a
+// Codex CLI session header.
+func LooksLikeCodexSession(head []byte) bool {
+	first, _, _ := strings.Cut(strings.TrimSpace(string(head)), "\n")
+	var line struct {
+		Type         string          `json:"type"`
+		ID           string          `json:"id"`
+		Instructions json.RawMessage `json:"instructions"`
+	}
+	if err := json.Unmarshal([]byte(first), &line); err != nil {
+		return false
+	}
+	return line.Type == "session_meta" || (line.Type == "" && line.ID != "" && line.Instructions != nil)
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
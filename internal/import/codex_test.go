@@ -0,0 +1,60 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const codexSession = `{"timestamp":"2025-09-01T10:00:00.000Z","type":"session_meta","payload":{"id":"5973b6c0-94b8","timestamp":"2025-09-01T10:00:00.000Z","cwd":"/src/app"}}
+{"timestamp":"2025-09-01T10:00:01.000Z","type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"<environment_context>\n<cwd>/src/app</cwd>\n</environment_context>"}]}}
+{"timestamp":"2025-09-01T10:00:02.000Z","type":"turn_context","payload":{"cwd":"/src/app","model":"gpt-5-codex"}}
+{"timestamp":"2025-09-01T10:00:03.000Z","type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"Why is the build slow?"}]}}
+{"timestamp":"2025-09-01T10:00:03.500Z","type":"event_msg","payload":{"type":"user_message","message":"Why is the build slow?"}}
+{"timestamp":"2025-09-01T10:00:04.000Z","type":"response_item","payload":{"type":"function_call","name":"shell","arguments":"{}"}}
+{"timestamp":"2025-09-01T10:00:09.000Z","type":"response_item","payload":{"type":"message","role":"assistant","content":[{"type":"output_text","text":"Found: the linker runs twice."}]}}
+`
+
+func TestParseCodexSession(t *testing.T) {
+	turns, err := ParseCodexSession(strings.NewReader(codexSession))
+	if err != nil {
+		t.Fatalf("ParseCodexSession() error = %v", err)
+	}
+	if len(turns) != 2 {
+		t.Fatalf("got %d turns, want 2 (context and tool calls skipped): %+v", len(turns), turns)
+	}
+	if turns[0].Role != "user" || turns[0].Text != "Why is the build slow?" || turns[0].Origin != "codex:5973b6c0-94b8" {
+		t.Errorf("user turn = %+v", turns[0])
+	}
+	a := turns[1]
+	if a.Author != "gpt-5-codex" || !a.Timestamp.Equal(time.Date(2025, 9, 1, 10, 0, 9, 0, time.UTC)) {
+		t.Errorf("assistant turn = %+v", a)
+	}
+}
+
+func TestParseCodexSession_LegacyFormat(t *testing.T) {
+	legacy := `{"id":"abc-123","timestamp":"2025-04-20T08:00:00Z","instructions":""}
+{"type":"message","role":"user","content":[{"type":"input_text","text":"Should we cache builds?"}]}
+{"record_type":"state"}
+{"type":"message","role":"assistant","content":[{"type":"output_text","text":"Decision: cache per commit."}]}
+`
+	if !LooksLikeCodexSession([]byte(legacy)) {
+		t.Error("legacy session header not recognized")
+	}
+	turns, err := ParseCodexSession(strings.NewReader(legacy))
+	if err != nil || len(turns) != 2 {
+		t.Fatalf("got %d turns, err %v", len(turns), err)
+	}
+	if turns[1].Origin != "codex:abc-123" || !turns[1].Timestamp.Equal(time.Date(2025, 4, 20, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("legacy turns should use the session header, got %+v", turns[1])
+	}
+}
+
+func TestLooksLikeCodexSession(t *testing.T) {
+	if !LooksLikeCodexSession([]byte(codexSession)) {
+		t.Error("codex session not recognized")
+	}
+	if LooksLikeCodexSession([]byte(claudeTranscript)) {
+		t.Error("claude transcript taken for a codex session")
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/brianevanmiller/beadcrumbs/internal/classify"
 	"github.com/brianevanmiller/beadcrumbs/internal/types"
 )
 
@@ -104,12 +105,13 @@ func ParseCSVReader(r io.Reader, mapping ColumnMapping, sourceType string) ([]*t
 			continue
 		}
 
-		// Determine insight type
-		insightType := DetectInsightType(content)
+		// Determine insight type; a type given in the file is not a guess
+		res := classify.Default().Classify(content, sourceType)
+		insightType, confidence := res.Type, res.Confidence
 		if typeIdx >= 0 && typeIdx < len(record) {
 			candidate := types.InsightType(strings.ToLower(strings.TrimSpace(record[typeIdx])))
 			if candidate.IsValid() {
-				insightType = candidate
+				insightType, confidence = candidate, 1.0
 			}
 		}
 
@@ -160,7 +162,7 @@ func ParseCSVReader(r io.Reader, mapping ColumnMapping, sourceType string) ([]*t
 			Content:    content,
 			Summary:    summary,
 			Type:       insightType,
-			Confidence: 0.7,
+			Confidence: float32(confidence),
 			Source: types.InsightSource{
 				Type: sourceType,
 				Ref:  sourceRef,
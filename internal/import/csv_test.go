@@ -3,6 +3,8 @@ package importer
 import (
 	"strings"
 	"testing"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/classify"
 )
 
 func TestParseCSVReader_BasicMapping(t *testing.T) {
@@ -34,6 +36,26 @@ func TestParseCSVReader_BasicMapping(t *testing.T) {
 	}
 }
 
+func TestParseCSVReader_Confidence(t *testing.T) {
+	csv := `content,type
+"We decided to use Redis",decision
+"Notes from the sync",
+`
+	insights, err := ParseCSVReader(strings.NewReader(csv), ColumnMapping{}, "test")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(insights) != 2 {
+		t.Fatalf("expected 2 insights, got %d", len(insights))
+	}
+	if insights[0].Confidence != 1 {
+		t.Errorf("a type given in the file should have confidence 1, got %v", insights[0].Confidence)
+	}
+	if want := float32(classify.Default().Classify("Notes from the sync", "test").Confidence); insights[1].Confidence != want {
+		t.Errorf("a detected type should have the classifier's confidence %v, got %v", want, insights[1].Confidence)
+	}
+}
+
 func TestParseCSVReader_CustomMapping(t *testing.T) {
 	csv := `body,category,created_at
 "Important discovery about caching",discovery,2024-03-01
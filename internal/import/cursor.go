@@ -0,0 +1,102 @@
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cursorExportLine is the byline of a Cursor "Export Chat" markdown file:
+// _Exported on 5/1/2025 at 10:22:33 GMT+2 from Cursor (0.50.5)_
+var cursorExportLine = regexp.MustCompile(`^_Exported on (\d{1,2}/\d{1,2}/\d{4}) at (\d{1,2}:\d{2}:\d{2})(?: (GMT|UTC)([+-]\d{1,2})?(?::?(\d{2}))?)? from Cursor`)
+
+// cursorSpeakers maps the bold speaker lines of an export to roles.
+var cursorSpeakers = map[string]string{"**User**": "user", "**Cursor**": "assistant"}
+
+// ParseCursorExport reads a chat exported from Cursor as markdown. Turns
+// start at a "**User**" or "**Cursor**" line and are separated by "---".
+// Exports only record when they were made, so every turn gets the export
+// time and the origin "cursor:<export time>".
+func ParseCursorExport(r io.Reader) ([]Turn, error) {
+	var turns []Turn
+	var cur *Turn
+	var text []string
+	var exported time.Time
+	var origin string
+
+	flush := func() {
+		if cur != nil {
+			cur.Text = strings.TrimSpace(strings.Join(text, "\n"))
+			if cur.Text != "" {
+				turns = append(turns, *cur)
+			}
+		}
+		cur, text = nil, nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if m := cursorExportLine.FindStringSubmatch(trimmed); m != nil && cur == nil {
+			exported = parseCursorExportTime(m)
+			if !exported.IsZero() {
+				origin = "cursor:" + exported.UTC().Format("2006-01-02T15:04:05Z")
+			}
+			continue
+		}
+		if role, ok := cursorSpeakers[trimmed]; ok {
+			flush()
+			cur = &Turn{Role: role, Timestamp: exported, Origin: origin}
+			continue
+		}
+		if cur == nil {
+			continue // title and byline
+		}
+		if trimmed == "---" {
+			flush()
+			continue
+		}
+		text = append(text, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read cursor export: %w", err)
+	}
+	flush()
+	return turns, nil
+}
+
+func parseCursorExportTime(m []string) time.Time {
+	loc := time.Local
+	if m[3] != "" {
+		hours, _ := strconv.Atoi(m[4])
+		mins, _ := strconv.Atoi(m[5])
+		offset := hours*3600 + mins*60
+		if strings.HasPrefix(m[4], "-") {
+			offset = hours*3600 - mins*60
+		}
+		loc = time.FixedZone(m[3]+m[4], offset)
+	}
+	t, err := time.ParseInLocation("1/2/2006 15:04:05", m[1]+" "+m[2], loc)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// LooksLikeCursorExport reports whether text starts like a Cursor chat
+// export.
+func LooksLikeCursorExport(head []byte) bool {
+	for _, line := range strings.SplitN(string(head), "\n", 20) {
+		line = strings.TrimSpace(line)
+		if cursorExportLine.MatchString(line) || line == "**Cursor**" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const cursorExport = `# Fix flaky login test
+_Exported on 5/1/2025 at 10:22:33 GMT+2 from Cursor (0.50.5)_
+
+---
+
+**User**
+
+Why does the login test fail on CI only?
+
+---
+
+**Cursor**
+
+Turns out CI runs in UTC, so the session cookie expires early.
+
+` + "```ts\nexpect(cookie).toBeDefined()\n```" + `
+
+---
+
+**User**
+
+Let's go with freezing the clock in the test.
+`
+
+func TestParseCursorExport(t *testing.T) {
+	turns, err := ParseCursorExport(strings.NewReader(cursorExport))
+	if err != nil {
+		t.Fatalf("ParseCursorExport() error = %v", err)
+	}
+	if len(turns) != 3 {
+		t.Fatalf("got %d turns, want 3: %+v", len(turns), turns)
+	}
+	roles := turns[0].Role + "," + turns[1].Role + "," + turns[2].Role
+	if roles != "user,assistant,user" {
+		t.Errorf("roles = %s", roles)
+	}
+	exported := time.Date(2025, 5, 1, 8, 22, 33, 0, time.UTC)
+	if !turns[1].Timestamp.Equal(exported) || turns[1].Origin != "cursor:2025-05-01T08:22:33Z" {
+		t.Errorf("turn should carry the export time, got %v %q", turns[1].Timestamp, turns[1].Origin)
+	}
+	if strings.Contains(turns[0].Text, "Exported on") {
+		t.Error("the byline is not part of a turn")
+	}
+}
+
+func TestLooksLikeCursorExport(t *testing.T) {
+	if !LooksLikeCursorExport([]byte(cursorExport)) {
+		t.Error("cursor export not recognized")
+	}
+	if LooksLikeCursorExport([]byte(aiderHistory)) {
+		t.Error("aider history taken for a cursor export")
+	}
+}
@@ -0,0 +1,213 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+	"gopkg.in/yaml.v3"
+)
+
+// ExternalConfigFileName is the external importers file inside .beadcrumbs/.
+const ExternalConfigFileName = "importers.yaml"
+
+// ExternalCommandPrefix names importer executables found on PATH:
+// bdc-import-<format>.
+const ExternalCommandPrefix = "bdc-import-"
+
+// ExternalConfig is the parsed form of .beadcrumbs/importers.yaml.
+//
+//	version: 1
+//	importers:
+//	  - name: jira
+//	    description: Jira issue export
+//	    command: ./scripts/jira-to-bdc
+//	    args: ["--comments"]
+//	    extensions: [".xml"]
+//	    options:
+//	      - name: project
+//	        description: only import this project
+type ExternalConfig struct {
+	Version   int                `yaml:"version"`
+	Importers []ExternalImporter `yaml:"importers"`
+}
+
+var formatNamePattern = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
+// ExternalImporter runs an executable that speaks the external importer
+// protocol. The executable is run as
+//
+//	<command> <args...> [--<option>=<value>...] [<directory>]
+//
+// with a file's content on stdin, or with the directory as the last
+// argument. It writes one JSON object per line to stdout, using the keys
+// of the jsonl format (content, type, timestamp, author, summary,
+// source_ref, tags); objects without content are ignored. Anything it
+// writes to stderr is passed through, and a non-zero exit fails the
+// import.
+type ExternalImporter struct {
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description"`
+	Command     string   `yaml:"command"`
+	Args        []string `yaml:"args"`
+	Extensions  []string `yaml:"extensions"`
+	Options     []Option `yaml:"options"`
+
+	// Dir is where the command runs and relative commands resolve from.
+	Dir string `yaml:"-"`
+
+	// Trusted lets Detect claim files by extension. importers.yaml comes
+	// with the repository, so until the user trusts it locally its
+	// importers only run when chosen with --format.
+	Trusted bool `yaml:"-"`
+}
+
+// LoadExternal reads external importers from an importers.yaml file.
+// Relative commands are resolved against root, the directory holding
+// .beadcrumbs. A missing file is not an error.
+func LoadExternal(path, root string) ([]*ExternalImporter, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read importers config: %w", err)
+	}
+	cfg := &ExternalConfig{}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse importers config: %w", err)
+	}
+	if cfg.Version != 1 {
+		return nil, fmt.Errorf("importers config must have \"version: 1\" (got %d)", cfg.Version)
+	}
+
+	var importers []*ExternalImporter
+	for i := range cfg.Importers {
+		e := &cfg.Importers[i]
+		if !formatNamePattern.MatchString(e.Name) {
+			return nil, fmt.Errorf("importers config: invalid name %q (use lowercase letters, digits and dashes)", e.Name)
+		}
+		if e.Command == "" {
+			return nil, fmt.Errorf("importers config: %s has no command", e.Name)
+		}
+		for j, ext := range e.Extensions {
+			ext = strings.ToLower(ext)
+			if !strings.HasPrefix(ext, ".") {
+				ext = "." + ext
+			}
+			e.Extensions[j] = ext
+		}
+		if strings.ContainsRune(e.Command, filepath.Separator) && !filepath.IsAbs(e.Command) {
+			e.Command = filepath.Join(root, e.Command)
+		}
+		e.Dir = root
+		importers = append(importers, e)
+	}
+	return importers, nil
+}
+
+// FindExternalCommands returns importers for the bdc-import-<format>
+// executables on PATH, sorted by name. The first one found for a format
+// wins, as with the shell.
+func FindExternalCommands(path string) []*ExternalImporter {
+	seen := make(map[string]bool)
+	var importers []*ExternalImporter
+	for _, dir := range filepath.SplitList(path) {
+		matches, _ := filepath.Glob(filepath.Join(dir, ExternalCommandPrefix+"*"))
+		for _, m := range matches {
+			name := strings.TrimPrefix(filepath.Base(m), ExternalCommandPrefix)
+			name = strings.TrimSuffix(name, filepath.Ext(name))
+			if seen[name] || !formatNamePattern.MatchString(name) {
+				continue
+			}
+			info, err := os.Stat(m)
+			if err != nil || info.IsDir() || info.Mode()&0111 == 0 {
+				continue
+			}
+			seen[name] = true
+			importers = append(importers, &ExternalImporter{
+				Name:        name,
+				Description: "external importer " + filepath.Base(m),
+				Command:     m,
+			})
+		}
+	}
+	sort.Slice(importers, func(i, j int) bool { return importers[i].Name < importers[j].Name })
+	return importers
+}
+
+// Info describes the importer.
+func (e *ExternalImporter) Info() Info {
+	return Info{Name: e.Name, Description: e.Description, Options: e.Options, Command: e.Command}
+}
+
+// Detect claims files with one of the configured extensions, once the
+// importer is trusted.
+func (e *ExternalImporter) Detect(s Sample) int {
+	if s.Dir || !e.Trusted {
+		return 0
+	}
+	for _, ext := range e.Extensions {
+		if s.Ext == ext {
+			return scoreExtension
+		}
+	}
+	return 0
+}
+
+// Parse runs the command with r as stdin.
+func (e *ExternalImporter) Parse(r io.Reader, opts Options) ([]*types.Insight, error) {
+	return e.run(r, opts, "")
+}
+
+// ParseDir runs the command with the directory as its last argument.
+func (e *ExternalImporter) ParseDir(dir string, opts Options) ([]*types.Insight, error) {
+	return e.run(nil, opts, dir)
+}
+
+func (e *ExternalImporter) run(stdin io.Reader, opts Options, dir string) ([]*types.Insight, error) {
+	args := append([]string(nil), e.Args...)
+	names := make([]string, 0, len(opts))
+	for name := range opts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		args = append(args, "--"+name+"="+opts[name])
+	}
+	if dir != "" {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", dir, err)
+		}
+		args = append(args, abs)
+	}
+
+	cmd := exec.Command(e.Command, args...)
+	cmd.Dir = e.Dir
+	cmd.Stdin = stdin
+	cmd.Stderr = os.Stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run importer %s: %w", e.Name, err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to run importer %s: %w", e.Name, err)
+	}
+	insights, parseErr := ParseGenericJSONLReader(stdout, ColumnMapping{}, e.Name)
+	// Drain whatever the parser left so the command can exit
+	io.Copy(io.Discard, stdout)
+	if err := cmd.Wait(); err != nil {
+		return nil, fmt.Errorf("importer %s failed: %w", e.Name, err)
+	}
+	if parseErr != nil {
+		return nil, fmt.Errorf("importer %s: %w", e.Name, parseErr)
+	}
+	return insights, nil
+}
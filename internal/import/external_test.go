@@ -0,0 +1,118 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeScript writes an executable shell script.
+func writeScript(t *testing.T, path, body string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadExternal(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, ExternalConfigFileName)
+
+	if importers, err := LoadExternal(path, root); err != nil || importers != nil {
+		t.Fatalf("missing file should give no importers, got %v, %v", importers, err)
+	}
+
+	cfg := `version: 1
+importers:
+  - name: jira
+    description: Jira issue export
+    command: ./scripts/jira
+    extensions: [XML]
+    options:
+      - name: project
+        description: only this project
+  - name: notes
+    command: notes-to-bdc
+`
+	if err := os.WriteFile(path, []byte(cfg), 0644); err != nil {
+		t.Fatal(err)
+	}
+	importers, err := LoadExternal(path, root)
+	if err != nil {
+		t.Fatalf("LoadExternal() error = %v", err)
+	}
+	if len(importers) != 2 {
+		t.Fatalf("got %d importers, want 2", len(importers))
+	}
+	jira := importers[0]
+	if jira.Command != filepath.Join(root, "scripts", "jira") || jira.Extensions[0] != ".xml" || jira.Info().Options[0].Name != "project" {
+		t.Errorf("jira = %+v", jira)
+	}
+	if importers[1].Command != "notes-to-bdc" {
+		t.Errorf("bare commands should be looked up on PATH, got %q", importers[1].Command)
+	}
+
+	for _, bad := range []string{
+		"importers: []\n",
+		"version: 1\nimporters:\n  - name: Bad Name\n    command: x\n",
+		"version: 1\nimporters:\n  - name: jira\n",
+	} {
+		os.WriteFile(path, []byte(bad), 0644)
+		if _, err := LoadExternal(path, root); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+func TestExternalImporterParse(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "importer")
+	// Echo stdin as insights, with the arguments as the author
+	writeScript(t, script, `args="$*"
+while IFS= read -r line; do
+  printf '{"content":"%s","author":"%s","timestamp":"2024-01-15T10:00:00Z"}\n' "$line" "$args"
+done
+`)
+	e := &ExternalImporter{Name: "lines", Command: script, Args: []string{"-v"}, Dir: dir}
+	insights, err := e.Parse(strings.NewReader("Decision: ship on Friday\n"), Options{"team": "core"})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(insights) != 1 {
+		t.Fatalf("got %d insights, want 1", len(insights))
+	}
+	ins := insights[0]
+	if ins.Content != "Decision: ship on Friday" || ins.AuthorID != "-v --team=core" || ins.Source.Type != "lines" {
+		t.Errorf("insight = %+v", ins)
+	}
+	if ins.Timestamp.Year() != 2024 {
+		t.Errorf("timestamp not read: %v", ins.Timestamp)
+	}
+
+	failing := filepath.Join(dir, "failing")
+	writeScript(t, failing, "echo boom >&2\nexit 3\n")
+	e = &ExternalImporter{Name: "failing", Command: failing, Dir: dir}
+	if _, err := e.Parse(strings.NewReader(""), nil); err == nil || !strings.Contains(err.Error(), "importer failing failed") {
+		t.Errorf("expected the exit status to fail the import, got %v", err)
+	}
+}
+
+func TestFindExternalCommands(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+	writeScript(t, filepath.Join(first, "bdc-import-jira"), "exit 0\n")
+	writeScript(t, filepath.Join(second, "bdc-import-jira"), "exit 0\n")
+	writeScript(t, filepath.Join(second, "bdc-import-notes"), "exit 0\n")
+	os.WriteFile(filepath.Join(second, "bdc-import-readme"), []byte("not executable"), 0644)
+
+	found := FindExternalCommands(first + string(os.PathListSeparator) + second)
+	if len(found) != 2 {
+		t.Fatalf("got %d importers, want 2: %+v", len(found), found)
+	}
+	if found[0].Name != "jira" || filepath.Dir(found[0].Command) != first {
+		t.Errorf("the first jira on PATH should win, got %+v", found[0])
+	}
+	if found[1].Name != "notes" {
+		t.Errorf("found[1] = %+v", found[1])
+	}
+}
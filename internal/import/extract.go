@@ -0,0 +1,136 @@
+package importer
+
+import (
+	"sync"
+	"time"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/classify"
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+)
+
+// Passage is a span of text an extractor reads: a conversation turn or a
+// whole document.
+type Passage struct {
+	Text string
+	Role string // "user", "assistant" or "" when unknown
+}
+
+// Extracted is an insight an extractor found in a passage.
+type Extracted struct {
+	Passage    int // Index of the passage it came from
+	Content    string
+	Summary    string
+	Type       types.InsightType
+	Confidence float32
+	Links      []SuggestedLink
+
+	// Typed is set when Type has evidence behind it: a classifier rule
+	// fired for the text, or the extractor typed it itself. It is unset
+	// when the classifier fell back to its default type or only the
+	// trained model picked the type.
+	Typed bool
+}
+
+// SuggestedLink relates an extracted insight to an earlier one from the
+// same Extract call.
+type SuggestedLink struct {
+	To   int // Index of the earlier insight in the results
+	Type types.DependencyType
+}
+
+// Extractor finds insights in passages of imported text. source is the
+// source type the insights are imported as, e.g. "ai-session".
+type Extractor interface {
+	Name() string
+	Extract(passages []Passage, source string) ([]Extracted, error)
+}
+
+// HeuristicExtractor splits passages into segments (see SegmentText),
+// types them with the classifier and uses each segment's score as its
+// confidence. It suggests no links.
+type HeuristicExtractor struct{}
+
+// Name returns "heuristic".
+func (HeuristicExtractor) Name() string { return "heuristic" }
+
+// Extract returns the segments of 10+ characters.
+func (HeuristicExtractor) Extract(passages []Passage, source string) ([]Extracted, error) {
+	var found []Extracted
+	for i, p := range passages {
+		for _, seg := range SegmentText(p.Text) {
+			if len(seg.Text) < minSegmentLength {
+				continue
+			}
+			res := classify.Default().Classify(seg.Text, source)
+			found = append(found, Extracted{
+				Passage:    i,
+				Content:    seg.Text,
+				Summary:    Truncate(seg.Text, 80),
+				Type:       res.Type,
+				Confidence: seg.Score,
+				Typed:      res.Matched() && !res.Fallback,
+			})
+		}
+	}
+	return found, nil
+}
+
+var (
+	extractorMu sync.RWMutex
+	extractor   Extractor = HeuristicExtractor{}
+)
+
+// DefaultExtractor returns the extractor the prose importers use.
+func DefaultExtractor() Extractor {
+	extractorMu.RLock()
+	defer extractorMu.RUnlock()
+	return extractor
+}
+
+// SetExtractor registers the extractor used by DefaultExtractor; nil
+// restores the heuristic extractor.
+func SetExtractor(e Extractor) {
+	if e == nil {
+		e = HeuristicExtractor{}
+	}
+	extractorMu.Lock()
+	defer extractorMu.Unlock()
+	extractor = e
+}
+
+// extractInsights runs the default extractor and builds an insight for
+// each result from base, given the passage it came from. Suggested links
+// become the insights' Links.
+func extractInsights(passages []Passage, source string, base func(passage int) types.Insight) ([]*types.Insight, error) {
+	found, err := DefaultExtractor().Extract(passages, source)
+	if err != nil {
+		return nil, err
+	}
+
+	insights := make([]*types.Insight, len(found))
+	for i, ex := range found {
+		ins := base(ex.Passage)
+		ins.ID = types.GenerateID("ins")
+		ins.Content = ex.Content
+		ins.Summary = ex.Summary
+		ins.Type = ex.Type
+		ins.Confidence = ex.Confidence
+		ins.Typed = ex.Typed
+		insights[i] = &ins
+	}
+	now := time.Now()
+	for i, ex := range found {
+		for _, l := range ex.Links {
+			if l.To < 0 || l.To >= i {
+				continue
+			}
+			insights[i].Links = append(insights[i].Links, types.Dependency{
+				From:      insights[i].ID,
+				To:        insights[l.To].ID,
+				Type:      l.Type,
+				CreatedAt: now,
+			})
+		}
+	}
+	return insights, nil
+}
@@ -0,0 +1,38 @@
+package importer
+
+import (
+	"testing"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/classify"
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+)
+
+func TestHeuristicExtractor_Typed(t *testing.T) {
+	// A model trained on plain statements types chatter too
+	cfg := classify.DefaultConfig()
+	cfg.Model = classify.Train([]classify.Example{
+		{Text: "i will run the test suite and check the output", Type: types.InsightDiscovery},
+		{Text: "the test suite passes and the output looks right", Type: types.InsightDiscovery},
+		{Text: "we will keep postgres for sessions", Type: types.InsightDecision},
+		{Text: "we will use redis for the cache", Type: types.InsightDecision},
+	})
+	classify.SetDefault(cfg)
+	t.Cleanup(func() { classify.SetDefault(nil) })
+
+	found, err := HeuristicExtractor{}.Extract([]Passage{
+		{Text: "I'll run the test suite now and check the output."},
+		{Text: "Decision: normalize token expiry checks to seconds everywhere."},
+	}, "ai-session")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 2 {
+		t.Fatalf("expected 2 segments, got %+v", found)
+	}
+	if found[0].Typed {
+		t.Errorf("chatter typed only by the model should not be Typed, got %+v", found[0])
+	}
+	if !found[1].Typed || found[1].Type != types.InsightDecision {
+		t.Errorf("a rule-typed decision should be Typed, got %+v", found[1])
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/brianevanmiller/beadcrumbs/internal/classify"
 	"github.com/brianevanmiller/beadcrumbs/internal/types"
 )
 
@@ -84,12 +85,13 @@ func ParseGenericJSONLReader(r io.Reader, mapping ColumnMapping, sourceType stri
 			continue
 		}
 
-		// Determine insight type
-		insightType := DetectInsightType(content)
+		// Determine insight type; a type given in the file is not a guess
+		res := classify.Default().Classify(content, sourceType)
+		insightType, confidence := res.Type, res.Confidence
 		if typeStr := getStringField(obj, typeKey); typeStr != "" {
 			candidate := types.InsightType(strings.ToLower(typeStr))
 			if candidate.IsValid() {
-				insightType = candidate
+				insightType, confidence = candidate, 1.0
 			}
 		}
 
@@ -151,7 +153,7 @@ func ParseGenericJSONLReader(r io.Reader, mapping ColumnMapping, sourceType stri
 			Content:    content,
 			Summary:    summary,
 			Type:       insightType,
-			Confidence: 0.7,
+			Confidence: float32(confidence),
 			Source: types.InsightSource{
 				Type: sourceType,
 				Ref:  sourceRef,
@@ -0,0 +1,344 @@
+package importer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/classify"
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+)
+
+// ExtractCacheDirName is the LLM response cache inside .beadcrumbs/.
+const ExtractCacheDirName = "extract-cache"
+
+const (
+	defaultLLMTimeout  = 120 * time.Second
+	defaultLLMMaxChars = 16000
+	// defaultLLMConfidence is used when the model gives no confidence.
+	defaultLLMConfidence = 0.7
+)
+
+// LLMConfig configures an LLMExtractor.
+type LLMConfig struct {
+	Endpoint string        // Base URL of an OpenAI-compatible API, e.g. http://localhost:11434/v1
+	Model    string        // Model name, e.g. llama3.1
+	APIKey   string        // Sent as a bearer token if set
+	Timeout  time.Duration // Per request; default 120s
+	CacheDir string        // Where responses are cached; "" disables the cache
+	MaxChars int           // Passage text per request; default 16000
+}
+
+// LLMExtractor asks a chat model behind an OpenAI-compatible
+// /chat/completions endpoint (OpenAI, Ollama, llama.cpp, vLLM, ...) for
+// the insights in the passages. Passages are sent in batches of about
+// MaxChars characters at temperature 0, and each response is cached under
+// the SHA-256 of its request so re-importing the same text gives the same
+// insights without calling the model again.
+type LLMExtractor struct {
+	cfg        LLMConfig
+	url        string
+	httpClient *http.Client
+}
+
+// NewLLMExtractor checks cfg and returns an extractor for it.
+func NewLLMExtractor(cfg LLMConfig) (*LLMExtractor, error) {
+	if cfg.Endpoint == "" {
+		return nil, fmt.Errorf("LLM extractor needs an endpoint (bdc config extract.endpoint <url>)")
+	}
+	if cfg.Model == "" {
+		return nil, fmt.Errorf("LLM extractor needs a model (bdc config extract.model <name>)")
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultLLMTimeout
+	}
+	if cfg.MaxChars <= 0 {
+		cfg.MaxChars = defaultLLMMaxChars
+	}
+	url := strings.TrimRight(cfg.Endpoint, "/")
+	if !strings.HasSuffix(url, "/chat/completions") {
+		url += "/chat/completions"
+	}
+	return &LLMExtractor{cfg: cfg, url: url, httpClient: &http.Client{Timeout: cfg.Timeout}}, nil
+}
+
+// Name returns "llm".
+func (e *LLMExtractor) Name() string { return "llm" }
+
+// Extract sends the passages in batches and collects the insights of each
+// response. Links only relate insights of the same batch.
+func (e *LLMExtractor) Extract(passages []Passage, source string) ([]Extracted, error) {
+	var found []Extracted
+	for start := 0; start < len(passages); {
+		end, size := start, 0
+		for end < len(passages) && (end == start || size+len(passages[end].Text) <= e.cfg.MaxChars) {
+			size += len(passages[end].Text)
+			end++
+		}
+		batch, err := e.extractBatch(passages[start:end], source)
+		if err != nil {
+			return nil, err
+		}
+		offset := len(found)
+		for _, ex := range batch {
+			ex.Passage += start
+			for j := range ex.Links {
+				ex.Links[j].To += offset
+			}
+			found = append(found, ex)
+		}
+		start = end
+	}
+	return found, nil
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model       string        `json:"model"`
+	Messages    []chatMessage `json:"messages"`
+	Temperature float64       `json:"temperature"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+// llmInsight is one insight as the model reports it.
+type llmInsight struct {
+	Passage    int      `json:"passage"`
+	Type       string   `json:"type"`
+	Content    string   `json:"content"`
+	Summary    string   `json:"summary"`
+	Confidence *float64 `json:"confidence"`
+	Links      []struct {
+		To   int    `json:"to"`
+		Type string `json:"type"`
+	} `json:"links"`
+}
+
+func (e *LLMExtractor) extractBatch(passages []Passage, source string) ([]Extracted, error) {
+	var user strings.Builder
+	fmt.Fprintf(&user, "Source: %s\n", source)
+	for i, p := range passages {
+		role := p.Role
+		if role == "" {
+			role = "text"
+		}
+		fmt.Fprintf(&user, "\n[%d] %s:\n%s\n", i, role, strings.TrimSpace(p.Text))
+	}
+	req := chatRequest{
+		Model: e.cfg.Model,
+		Messages: []chatMessage{
+			{Role: "system", Content: llmSystemPrompt()},
+			{Role: "user", Content: user.String()},
+		},
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal LLM request: %w", err)
+	}
+
+	sum := sha256.Sum256(body)
+	key := hex.EncodeToString(sum[:])
+	reply, cached := e.readCache(key)
+	if !cached {
+		if reply, err = e.complete(body); err != nil {
+			return nil, err
+		}
+	}
+
+	found, err := parseLLMReply(reply, len(passages), source)
+	if err != nil {
+		return nil, err
+	}
+	if !cached {
+		e.writeCache(key, reply)
+	}
+	return found, nil
+}
+
+// complete posts a chat request and returns the reply's text.
+func (e *LLMExtractor) complete(body []byte) (string, error) {
+	req, err := http.NewRequest(http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create LLM request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.cfg.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.cfg.APIKey)
+	}
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("LLM request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read LLM response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("LLM endpoint returned %s: %s", resp.Status, Truncate(strings.TrimSpace(string(data)), 200))
+	}
+
+	var chat chatResponse
+	if err := json.Unmarshal(data, &chat); err != nil {
+		return "", fmt.Errorf("failed to parse LLM response: %w", err)
+	}
+	if len(chat.Choices) == 0 {
+		return "", fmt.Errorf("LLM response has no choices")
+	}
+	return chat.Choices[0].Message.Content, nil
+}
+
+// cacheEntry is a cached reply, stored as <key>.json.
+type cacheEntry struct {
+	Key   string `json:"key"`
+	Reply string `json:"reply"`
+}
+
+func (e *LLMExtractor) readCache(key string) (string, bool) {
+	if e.cfg.CacheDir == "" {
+		return "", false
+	}
+	data, err := os.ReadFile(filepath.Join(e.cfg.CacheDir, key+".json"))
+	if err != nil {
+		return "", false
+	}
+	var entry cacheEntry
+	if json.Unmarshal(data, &entry) != nil || entry.Key != key {
+		return "", false
+	}
+	return entry.Reply, true
+}
+
+// writeCache stores a reply. A cache that can't be written only costs a
+// repeated request next time, so errors are ignored. Replies quote the
+// transcripts, so the directory gets a .gitignore that ignores everything
+// in it, whatever the repository's own .gitignore says.
+func (e *LLMExtractor) writeCache(key, reply string) {
+	if e.cfg.CacheDir == "" {
+		return
+	}
+	data, err := json.Marshal(cacheEntry{Key: key, Reply: reply})
+	if err != nil {
+		return
+	}
+	if os.MkdirAll(e.cfg.CacheDir, 0755) != nil {
+		return
+	}
+	ignore := filepath.Join(e.cfg.CacheDir, ".gitignore")
+	if _, err := os.Stat(ignore); os.IsNotExist(err) {
+		if os.WriteFile(ignore, []byte("*\n"), 0644) != nil {
+			return
+		}
+	}
+	os.WriteFile(filepath.Join(e.cfg.CacheDir, key+".json"), data, 0644)
+}
+
+// parseLLMReply reads the insights from a reply. Code fences and text
+// around the JSON object are ignored. Insights without content or with a
+// passage out of range are dropped, unknown types are replaced with the
+// classifier's guess, and links that don't point to an earlier kept
+// insight with an insight-to-insight type are dropped.
+func parseLLMReply(reply string, passages int, source string) ([]Extracted, error) {
+	start, end := strings.Index(reply, "{"), strings.LastIndex(reply, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("LLM reply is not JSON: %s", Truncate(strings.TrimSpace(reply), 200))
+	}
+	var parsed struct {
+		Insights []llmInsight `json:"insights"`
+	}
+	if err := json.Unmarshal([]byte(reply[start:end+1]), &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse LLM reply: %w", err)
+	}
+
+	var found []Extracted
+	kept := make(map[int]int) // reply index -> index in found
+	for i, in := range parsed.Insights {
+		content := strings.TrimSpace(in.Content)
+		if content == "" || in.Passage < 0 || in.Passage >= passages {
+			continue
+		}
+		t := types.InsightType(strings.ToLower(strings.TrimSpace(in.Type)))
+		typed := t.IsValid()
+		if !typed {
+			res := classify.Default().Classify(content, source)
+			t, typed = res.Type, res.Matched() && !res.Fallback
+		}
+		summary := strings.TrimSpace(in.Summary)
+		if summary == "" {
+			summary = content
+		}
+		conf := defaultLLMConfidence
+		if in.Confidence != nil {
+			conf = max(0, min(1, *in.Confidence))
+		}
+
+		ex := Extracted{
+			Passage:    in.Passage,
+			Content:    content,
+			Summary:    Truncate(summary, 80),
+			Type:       t,
+			Confidence: float32(conf),
+			Typed:      typed,
+		}
+		for _, l := range in.Links {
+			to, ok := kept[l.To]
+			dep := types.DependencyType(l.Type)
+			if !ok || l.To >= i || !insightLinkTypes[dep] {
+				continue
+			}
+			ex.Links = append(ex.Links, SuggestedLink{To: to, Type: dep})
+		}
+		kept[i] = len(found)
+		found = append(found, ex)
+	}
+	return found, nil
+}
+
+// insightLinkTypes are the dependency types between two insights.
+var insightLinkTypes = map[types.DependencyType]bool{
+	types.DepBuildsOn:    true,
+	types.DepSupersedes:  true,
+	types.DepContradicts: true,
+}
+
+// llmSystemPrompt tells the model what to extract and how to answer.
+func llmSystemPrompt() string {
+	names := make([]string, 0, len(types.ValidInsightTypes()))
+	for _, t := range types.ValidInsightTypes() {
+		names = append(names, string(t))
+	}
+	return `You extract insights from conversations and notes for a project's knowledge log.
+An insight is a self-contained statement of something learned, suspected, asked, decided
+or changed. Skip greetings, acknowledgements, step-by-step plans, code and tool output.
+
+The text is split into numbered passages. Reply with JSON only, in this shape:
+{"insights": [{"passage": 0, "type": "decision", "content": "...", "summary": "...",
+  "confidence": 0.8, "links": [{"to": 0, "type": "builds-on"}]}]}
+
+- passage: the number of the passage the insight comes from
+- type: one of ` + strings.Join(names, ", ") + `
+- content: the insight in one to three sentences that make sense on their own
+- summary: at most 80 characters
+- confidence: 0 to 1, how sure you are that this is a real insight of that type
+- links: earlier insights in your list that this one builds-on, supersedes or
+  contradicts; "to" is the earlier insight's position in the list, from 0
+
+Reply {"insights": []} if there are none.`
+}
@@ -0,0 +1,159 @@
+package importer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+)
+
+// stubLLM serves /v1/chat/completions with a fixed reply and counts calls.
+func stubLLM(t *testing.T, reply string) (*httptest.Server, *int32) {
+	t.Helper()
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if r.URL.Path != "/v1/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer sk-test" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var req chatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Model != "test-model" || len(req.Messages) != 2 {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": map[string]string{"role": "assistant", "content": reply}}},
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+const stubReply = "```json\n" + `{"insights": [
+  {"passage": 0, "type": "question", "content": "Should sessions live in Redis?", "summary": "Redis for sessions?", "confidence": 0.9},
+  {"passage": 1, "type": "decision", "content": "Sessions stay in Postgres because Redis adds an ops burden.", "confidence": 0.8,
+   "links": [{"to": 0, "type": "builds-on"}, {"to": 1, "type": "builds-on"}, {"to": 0, "type": "spawns"}]},
+  {"passage": 1, "type": "musing", "content": "Going with one database.", "summary": ""},
+  {"passage": 7, "type": "decision", "content": "Out of range"},
+  {"passage": 0, "type": "discovery", "content": "  "}
+]}` + "\n```"
+
+func TestLLMExtractor(t *testing.T) {
+	srv, calls := stubLLM(t, stubReply)
+	cache := filepath.Join(t.TempDir(), "extract-cache")
+	e, err := NewLLMExtractor(LLMConfig{Endpoint: srv.URL + "/v1/", Model: "test-model", APIKey: "sk-test", CacheDir: cache})
+	if err != nil {
+		t.Fatal(err)
+	}
+	passages := []Passage{
+		{Text: "Should we move sessions to Redis?", Role: "user"},
+		{Text: "Let's keep them in Postgres; Redis is one more thing to run.", Role: "assistant"},
+	}
+
+	found, err := e.Extract(passages, "ai-session")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 3 {
+		t.Fatalf("expected 3 insights, got %d: %+v", len(found), found)
+	}
+	if found[0].Type != types.InsightQuestion || found[0].Summary != "Redis for sessions?" || found[0].Confidence != 0.9 {
+		t.Errorf("unexpected first insight: %+v", found[0])
+	}
+	if found[1].Passage != 1 || len(found[1].Links) != 1 || found[1].Links[0] != (SuggestedLink{To: 0, Type: types.DepBuildsOn}) {
+		t.Errorf("expected one builds-on link to the question, got %+v", found[1])
+	}
+	if found[2].Type != types.InsightDecision || found[2].Confidence != defaultLLMConfidence || found[2].Summary == "" {
+		t.Errorf("unknown type should be classified and defaults filled, got %+v", found[2])
+	}
+
+	// The same text is served from the cache
+	again, err := e.Extract(passages, "ai-session")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(calls); n != 1 {
+		t.Errorf("expected 1 request with a warm cache, got %d", n)
+	}
+	if len(again) != len(found) || again[1].Content != found[1].Content {
+		t.Errorf("cached extraction differs: %+v", again)
+	}
+	if data, err := os.ReadFile(filepath.Join(cache, ".gitignore")); err != nil || string(data) != "*\n" {
+		t.Errorf("the cache should ignore itself in git, got %q, %v", data, err)
+	}
+}
+
+func TestLLMExtractor_Batches(t *testing.T) {
+	srv, calls := stubLLM(t, `{"insights": [{"passage": 0, "type": "discovery", "content": "first of the batch"},
+		{"passage": 0, "type": "discovery", "content": "builds on it", "links": [{"to": 0, "type": "builds-on"}]}]}`)
+	e, err := NewLLMExtractor(LLMConfig{Endpoint: srv.URL + "/v1", Model: "test-model", APIKey: "sk-test", MaxChars: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	found, err := e.Extract([]Passage{{Text: "a passage too long to share"}, {Text: "another long passage"}}, "ai-session")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(calls); n != 2 {
+		t.Errorf("expected one request per passage, got %d", n)
+	}
+	if len(found) != 4 || found[2].Passage != 1 || found[3].Links[0].To != 2 {
+		t.Errorf("second batch should be offset, got %+v", found)
+	}
+}
+
+func TestLLMExtractor_Errors(t *testing.T) {
+	if _, err := NewLLMExtractor(LLMConfig{Model: "m"}); err == nil {
+		t.Error("expected an error without an endpoint")
+	}
+
+	srv, _ := stubLLM(t, "I can't help with that.")
+	e, _ := NewLLMExtractor(LLMConfig{Endpoint: srv.URL + "/v1", Model: "test-model", APIKey: "sk-test"})
+	if _, err := e.Extract([]Passage{{Text: "text"}}, "ai-session"); err == nil || !strings.Contains(err.Error(), "not JSON") {
+		t.Errorf("expected a non-JSON reply to fail, got %v", err)
+	}
+
+	e, _ = NewLLMExtractor(LLMConfig{Endpoint: srv.URL + "/v1", Model: "test-model", APIKey: "wrong"})
+	if _, err := e.Extract([]Passage{{Text: "text"}}, "ai-session"); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("expected the HTTP status in the error, got %v", err)
+	}
+}
+
+func TestTurnsToInsights_Extractor(t *testing.T) {
+	srv, _ := stubLLM(t, stubReply)
+	e, err := NewLLMExtractor(LLMConfig{Endpoint: srv.URL + "/v1", Model: "test-model", APIKey: "sk-test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetExtractor(e)
+	defer SetExtractor(nil)
+
+	insights, err := TurnsToInsights([]Turn{
+		{Role: "user", Text: "Should we move sessions to Redis?", Origin: "codex:1"},
+		{Role: "assistant", Text: "Let's keep them in Postgres.", Author: "gpt-5", Origin: "codex:1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(insights) != 3 {
+		t.Fatalf("expected 3 insights, got %d", len(insights))
+	}
+	dec := insights[1]
+	if dec.AuthorID != "gpt-5" || dec.Source.Participants[0] != "ai-agent" || dec.Source.Ref != "codex:1" {
+		t.Errorf("insight should keep its turn's metadata, got %+v", dec)
+	}
+	if len(dec.Links) != 1 || dec.Links[0].From != dec.ID || dec.Links[0].To != insights[0].ID {
+		t.Errorf("expected a link to the question, got %+v", dec.Links)
+	}
+}
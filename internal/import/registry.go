@@ -0,0 +1,163 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+)
+
+// Importer reads one source format into insights.
+type Importer interface {
+	// Info describes the importer for --list-formats and option checking.
+	Info() Info
+
+	// Detect scores how likely it is that the sample is in this format: 0
+	// means no, 100 means certain. Content sniffers score higher than
+	// guesses from the extension alone.
+	Detect(s Sample) int
+
+	// Parse reads the format from r.
+	Parse(r io.Reader, opts Options) ([]*types.Insight, error)
+}
+
+// DirImporter is an Importer that can also read a directory, such as a
+// Slack export.
+type DirImporter interface {
+	Importer
+	ParseDir(dir string, opts Options) ([]*types.Insight, error)
+}
+
+// Info describes an importer.
+type Info struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Options     []Option `json:"options,omitempty"`
+
+	// Timestamps is set when insights keep the times recorded in the source
+	// rather than the time of the import.
+	Timestamps bool `json:"timestamps,omitempty"`
+
+	// Scored is set when the importer splits prose into segments and scores
+	// each for how likely it is an insight; --min-confidence filters those.
+	// Rows of structured formats are imported as given.
+	Scored bool `json:"scored,omitempty"`
+
+	// Command is the executable of an external importer.
+	Command string `json:"command,omitempty"`
+}
+
+// Option is a setting an importer accepts, passed as --option name=value.
+type Option struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Options holds option values by name.
+type Options map[string]string
+
+// Sample is what Detect sees of the input: the path, whether it is a
+// directory and, for files, the first bytes.
+type Sample struct {
+	Path string
+	Ext  string // Lower-cased extension including the dot
+	Dir  bool
+	Head []byte
+}
+
+// Detection scores used by the built-in importers.
+const (
+	scoreContent   = 90 // Content matches a distinctive format
+	scoreExtension = 50 // Extension names the format
+	scoreGuess     = 10 // Extension or content merely fits
+	scoreFallback  = 1  // Anything that is text
+)
+
+// Registry holds the importers bdc import can use, in priority order.
+type Registry struct {
+	importers []Importer
+}
+
+// NewRegistry returns a registry holding the built-in importers.
+func NewRegistry() *Registry {
+	return &Registry{importers: builtinImporters()}
+}
+
+// Register adds an importer. Names must be unique.
+func (r *Registry) Register(imp Importer) error {
+	name := imp.Info().Name
+	if name == "" {
+		return fmt.Errorf("importer has no name")
+	}
+	if r.Lookup(name) != nil {
+		return fmt.Errorf("importer %q is already registered", name)
+	}
+	r.importers = append(r.importers, imp)
+	return nil
+}
+
+// Lookup returns the importer with the given name, or nil.
+func (r *Registry) Lookup(name string) Importer {
+	for _, imp := range r.importers {
+		if imp.Info().Name == name {
+			return imp
+		}
+	}
+	return nil
+}
+
+// All returns the registered importers in registration order.
+func (r *Registry) All() []Importer {
+	return append([]Importer(nil), r.importers...)
+}
+
+// Names returns the registered importer names, sorted.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.importers))
+	for _, imp := range r.importers {
+		names = append(names, imp.Info().Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Detect returns the importer scoring highest for the sample, or nil if
+// none recognizes it. Ties go to the earlier registration.
+func (r *Registry) Detect(s Sample) Importer {
+	var best Importer
+	bestScore := 0
+	for _, imp := range r.importers {
+		if score := imp.Detect(s); score > bestScore {
+			best, bestScore = imp, score
+		}
+	}
+	return best
+}
+
+// CheckOptions rejects options the importer does not declare. Importers
+// that declare no options accept none.
+func CheckOptions(imp Importer, opts Options) error {
+	info := imp.Info()
+	for name := range opts {
+		known := false
+		for _, o := range info.Options {
+			if o.Name == name {
+				known = true
+				break
+			}
+		}
+		if !known {
+			var names []string
+			for _, o := range info.Options {
+				names = append(names, o.Name)
+			}
+			if len(names) == 0 {
+				return fmt.Errorf("format %s takes no options (got %q)", info.Name, name)
+			}
+			return fmt.Errorf("format %s has no option %q (options: %s)", info.Name, name, strings.Join(names, ", "))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegistryDetect(t *testing.T) {
+	reg := NewRegistry()
+	tests := []struct {
+		name   string
+		sample Sample
+		want   string
+	}{
+		{"directory", Sample{Path: "export", Dir: true}, "slack"},
+		{"csv", Sample{Path: "a.csv", Ext: ".csv", Head: []byte("content,type\n")}, "csv"},
+		{"jsonl", Sample{Path: "a.jsonl", Ext: ".jsonl", Head: []byte(`{"content":"x"}`)}, "jsonl"},
+		{"claude jsonl", Sample{Path: "s.jsonl", Ext: ".jsonl", Head: []byte(claudeTranscript)}, "claude-code"},
+		{"codex jsonl", Sample{Path: "r.jsonl", Ext: ".jsonl", Head: []byte(codexSession)}, "codex"},
+		{"slack json", Sample{Path: "c.json", Ext: ".json", Head: []byte(`[{"type":"message"}]`)}, "slack"},
+		{"chatgpt json", Sample{Path: "conversations.json", Ext: ".json", Head: []byte(chatGPTExport)}, "chatgpt"},
+		{"aider md", Sample{Path: "h.md", Ext: ".md", Head: []byte(aiderHistory)}, "aider"},
+		{"cursor md", Sample{Path: "c.md", Ext: ".md", Head: []byte(cursorExport)}, "cursor"},
+		{"plain text", Sample{Path: "s.txt", Ext: ".txt", Head: []byte("Human: hi\nAI: hello\n")}, "ai-session"},
+		{"json-looking text", Sample{Path: "s.txt", Ext: ".txt", Head: []byte("{not really json")}, "ai-session"},
+		{"no extension json", Sample{Path: "dump", Head: []byte(`[{"ts":"1"}]`)}, "slack"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			imp := reg.Detect(tt.sample)
+			if imp == nil || imp.Info().Name != tt.want {
+				got := "<nil>"
+				if imp != nil {
+					got = imp.Info().Name
+				}
+				t.Errorf("Detect() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistryRegister(t *testing.T) {
+	reg := NewRegistry()
+	if err := reg.Register(&ExternalImporter{Name: "csv", Command: "x"}); err == nil {
+		t.Error("expected an error registering a duplicate name")
+	}
+	jira := &ExternalImporter{Name: "jira", Command: "x", Extensions: []string{".xml"}}
+	if err := reg.Register(jira); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if reg.Lookup("jira") == nil {
+		t.Error("registered importer not found")
+	}
+	if imp := reg.Detect(Sample{Path: "i.xml", Ext: ".xml"}); imp != nil && imp.Info().Name == "jira" {
+		t.Error("untrusted external importer should not claim its extension")
+	}
+	jira.Trusted = true
+	if imp := reg.Detect(Sample{Path: "i.xml", Ext: ".xml"}); imp == nil || imp.Info().Name != "jira" {
+		t.Error("external importer should claim its extension")
+	}
+	names := strings.Join(reg.Names(), ",")
+	if !strings.Contains(names, "jira") || !strings.HasPrefix(names, "ai-session,aider,") {
+		t.Errorf("Names() = %s, want sorted names including jira", names)
+	}
+}
+
+func TestCheckOptions(t *testing.T) {
+	reg := NewRegistry()
+	if err := CheckOptions(reg.Lookup("csv"), Options{"map-content": "body"}); err != nil {
+		t.Errorf("declared option rejected: %v", err)
+	}
+	if err := CheckOptions(reg.Lookup("csv"), Options{"bogus": "1"}); err == nil || !strings.Contains(err.Error(), "map-content") {
+		t.Errorf("expected an error listing the options, got %v", err)
+	}
+	if err := CheckOptions(reg.Lookup("aider"), Options{"map-content": "body"}); err == nil {
+		t.Error("expected an error for a format without options")
+	}
+}
+
+func TestMappedImporterOptions(t *testing.T) {
+	imp := NewRegistry().Lookup("csv")
+	insights, err := imp.Parse(strings.NewReader("body,kind\nThe cache is per tenant,decision\n"),
+		Options{"map-content": "body", "map-type": "kind", "source-type": "notion"})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(insights) != 1 || insights[0].Type != "decision" || insights[0].Source.Type != "notion" {
+		t.Errorf("options not applied: %+v", insights)
+	}
+}
@@ -0,0 +1,295 @@
+package importer
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/classify"
+)
+
+// DefaultMinConfidence is the segment score below which bdc import leaves
+// a segment out unless --min-confidence says otherwise.
+const DefaultMinConfidence = 0.4
+
+// Segment is a span of prose that may hold an insight: a paragraph, a list
+// item, a quote or, when a paragraph makes several claims, one of them.
+type Segment struct {
+	Text  string
+	Score float32 // How likely the segment is worth keeping, 0-1 (see ScoreSegment)
+}
+
+var (
+	listItemPattern  = regexp.MustCompile(`^(?:[-*+]|\d{1,3}[.)])\s+(?:\[[ xX]\]\s+)?`)
+	headingPattern   = regexp.MustCompile(`^#{1,6}(?:\s|$)`)
+	rulePattern      = regexp.MustCompile(`^(?:-{3,}|\*{3,}|_{3,})$`)
+	toolCallPattern  = regexp.MustCompile(`^[⏺●] ?[A-Z][A-Za-z]*\(`)
+	emphasisPattern  = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	linkPattern      = regexp.MustCompile(`\[([^\]]+)\]\([^)]+\)`)
+	claimLeadPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z ]{0,20}:\s`)
+)
+
+// toolOutputPrefixes start lines of shell sessions and agent tool output.
+var toolOutputPrefixes = []string{"$ ", "> $ ", "⎿", "│", "└", "├"}
+
+// SegmentText splits markdown into scored segments. Fenced and indented
+// code, tool output, tables, headings and HTML are left out. Wrapped lines
+// are joined into paragraphs, each list item and block quote stands alone,
+// and paragraphs that make more than one claim are split between the
+// claims (see splitClaims).
+func SegmentText(text string) []Segment {
+	var blocks []string
+	var cur []string
+	inFence := false
+	fence := ""
+	inTool := false
+	inList := false
+
+	flush := func() {
+		if len(cur) > 0 {
+			blocks = append(blocks, strings.Join(cur, "\n"))
+			cur = nil
+		}
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		// Code fences end only at a matching fence
+		if inFence {
+			if strings.HasPrefix(trimmed, fence) {
+				inFence = false
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			flush()
+			inFence, fence = true, trimmed[:3]
+			continue
+		}
+
+		if trimmed == "" {
+			flush()
+			inTool, inList = false, false
+			continue
+		}
+
+		// Tool output runs until the next blank line
+		if inTool || hasAnyPrefix(trimmed, toolOutputPrefixes) || toolCallPattern.MatchString(trimmed) {
+			flush()
+			inTool = true
+			continue
+		}
+
+		indented := strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t")
+		switch {
+		case indented && len(cur) == 0 && !inList:
+			// Indented code block
+			continue
+		case headingPattern.MatchString(trimmed), rulePattern.MatchString(trimmed):
+			flush()
+			inList = false
+		case strings.HasPrefix(trimmed, "|"), strings.HasPrefix(trimmed, "<"):
+			// Tables and HTML
+			flush()
+		case listItemPattern.MatchString(trimmed):
+			flush()
+			inList = true
+			cur = append(cur, listItemPattern.ReplaceAllString(trimmed, ""))
+		case strings.HasPrefix(trimmed, ">"):
+			quoted := strings.TrimSpace(strings.TrimLeft(trimmed, ">"))
+			if !inQuote(cur) {
+				flush()
+			}
+			cur = append(cur, "\x00"+quoted)
+		default:
+			if inQuote(cur) {
+				flush()
+			}
+			cur = append(cur, trimmed)
+		}
+	}
+	flush()
+
+	var segments []Segment
+	for _, block := range blocks {
+		block = strings.ReplaceAll(block, "\x00", "")
+		for _, claim := range splitClaims(cleanInline(block)) {
+			segments = append(segments, Segment{Text: claim, Score: ScoreSegment(claim)})
+		}
+	}
+	return segments
+}
+
+// inQuote reports whether the block being built is a block quote.
+func inQuote(lines []string) bool {
+	return len(lines) > 0 && strings.HasPrefix(lines[0], "\x00")
+}
+
+// cleanInline joins a block's lines and drops emphasis markers and link
+// targets, keeping inline code as written.
+func cleanInline(block string) string {
+	text := strings.Join(strings.Fields(block), " ")
+	text = emphasisPattern.ReplaceAllString(text, "$1$2")
+	return linkPattern.ReplaceAllString(text, "$1")
+}
+
+// splitClaims splits a paragraph into sentences when two or more of them
+// carry a claim marker (a question, or a decision, pivot, discovery or
+// custom-type phrase). Unmarked sentences stay with the claim before them,
+// or the first claim if they lead. Other paragraphs are returned whole.
+func splitClaims(para string) []string {
+	sentences := splitSentences(para)
+	marked := 0
+	for _, s := range sentences {
+		if hasClaimMarker(s) {
+			marked++
+		}
+	}
+	if marked < 2 {
+		return []string{para}
+	}
+
+	var claims []string
+	var cur []string
+	seenClaim := false
+	for _, s := range sentences {
+		if hasClaimMarker(s) {
+			if seenClaim {
+				claims = append(claims, strings.Join(cur, " "))
+				cur = nil
+			}
+			seenClaim = true
+		}
+		cur = append(cur, s)
+	}
+	return append(claims, strings.Join(cur, " "))
+}
+
+// abbreviations don't end a sentence.
+var abbreviations = map[string]bool{
+	"e.g.": true, "i.e.": true, "etc.": true, "vs.": true, "cf.": true,
+	"mr.": true, "mrs.": true, "dr.": true, "approx.": true, "no.": true,
+}
+
+// splitSentences splits at ., ! or ? followed by a space and a word that
+// doesn't start in lower case, outside inline code.
+func splitSentences(text string) []string {
+	var sentences []string
+	runes := []rune(text)
+	start := 0
+	inCode := false
+	for i, r := range runes {
+		if r == '`' {
+			inCode = !inCode
+		}
+		if inCode || (r != '.' && r != '!' && r != '?') {
+			continue
+		}
+		if i+2 >= len(runes) || runes[i+1] != ' ' || unicode.IsLower(runes[i+2]) {
+			continue
+		}
+		fields := strings.Fields(string(runes[start : i+1]))
+		if r == '.' && len(fields) > 0 && abbreviations[strings.ToLower(fields[len(fields)-1])] {
+			continue
+		}
+		sentences = append(sentences, strings.TrimSpace(string(runes[start:i+1])))
+		start = i + 2
+	}
+	if rest := strings.TrimSpace(string(runes[start:])); rest != "" {
+		sentences = append(sentences, rest)
+	}
+	return sentences
+}
+
+// hasClaimMarker reports whether a classifier rule fires for text, such
+// as a question mark or a decision phrase.
+func hasClaimMarker(text string) bool {
+	return classify.Default().Classify(text, "").Matched()
+}
+
+// reasoningWords suggest a segment explains why, not just what.
+var reasoningWords = []string{
+	"because", "since ", "so that", "instead of", "rather than", "root cause",
+	"the problem", "the issue", "trade-off", "tradeoff", "which means",
+}
+
+// fillerPrefixes start short segments that carry no content.
+var fillerPrefixes = []string{
+	"ok", "okay", "sure", "thanks", "thank you", "great", "perfect", "done",
+	"got it", "sounds good", "let me", "i'll", "i will", "now i", "here's", "here is",
+	"looks good", "yes", "no problem",
+}
+
+// ScoreSegment scores how likely a segment is to be an insight worth
+// keeping. A plain statement of a few words or more scores 0.5. Claims the
+// classifier types confidently and reasoning raise the score; fragments,
+// filler, code-like text and very long segments lower it. Scores are
+// rounded to two places and kept within 0.05-0.95.
+func ScoreSegment(text string) float32 {
+	lower := strings.ToLower(text)
+	words := len(strings.Fields(text))
+	score := 0.5
+
+	if res := classify.Default().Classify(text, ""); res.Matched() && !res.Fallback {
+		score += 0.35 * res.Confidence
+	}
+	for _, w := range reasoningWords {
+		if strings.Contains(lower, w) {
+			score += 0.1
+			break
+		}
+	}
+	if claimLeadPattern.MatchString(text) && words > 3 {
+		// "Decision: ...", "Root cause: ..."
+		score += 0.05
+	}
+
+	switch {
+	case words < 3:
+		score -= 0.4
+	case words < 5:
+		score -= 0.2
+	case words > 120:
+		score -= 0.2
+	case words > 60:
+		score -= 0.1
+	}
+	if words <= 6 {
+		for _, p := range fillerPrefixes {
+			if strings.HasPrefix(lower, p) {
+				score -= 0.3
+				break
+			}
+		}
+	}
+	if symbolRatio(text) > 0.15 {
+		score -= 0.3
+	}
+	if strings.HasSuffix(strings.TrimSpace(text), ":") {
+		// Introduces a list or code that was left out
+		score -= 0.2
+	}
+
+	score = math.Max(0.05, math.Min(0.95, score))
+	return float32(math.Round(score*100) / 100)
+}
+
+// symbolRatio is the share of characters typical of code rather than prose.
+func symbolRatio(text string) float64 {
+	var symbols, total int
+	for _, r := range text {
+		if unicode.IsSpace(r) {
+			continue
+		}
+		total++
+		if strings.ContainsRune("{}()[];=<>$&|\\/_`", r) {
+			symbols++
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(symbols) / float64(total)
+}
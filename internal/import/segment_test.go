@@ -0,0 +1,99 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+)
+
+func segmentTexts(segs []Segment) []string {
+	var texts []string
+	for _, s := range segs {
+		texts = append(texts, s.Text)
+	}
+	return texts
+}
+
+func TestSegmentText_Markdown(t *testing.T) {
+	text := "## Findings\n" +
+		"\n" +
+		"The cache misses on every request because the key\n" +
+		"includes the **request ID**, see [the handler](http://x/y).\n" +
+		"\n" +
+		"- Decision: key on the path instead\n" +
+		"- Add a test that\n" +
+		"  covers two requests\n" +
+		"\n" +
+		"```go\n" +
+		"key := req.ID // found: not prose\n" +
+		"```\n" +
+		"\n" +
+		"    indented := code()\n" +
+		"\n" +
+		"| option | cost |\n" +
+		"|--------|------|\n" +
+		"| redis  | high |\n" +
+		"\n" +
+		"$ go test ./...\n" +
+		"ok  \tcache\t0.01s\n" +
+		"\n" +
+		"> Turns out the old cache had the same bug.\n"
+
+	got := segmentTexts(SegmentText(text))
+	want := []string{
+		"The cache misses on every request because the key includes the request ID, see the handler.",
+		"Decision: key on the path instead",
+		"Add a test that covers two requests",
+		"Turns out the old cache had the same bug.",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("SegmentText() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestSegmentText_SplitsClaims(t *testing.T) {
+	para := "Found: the pool is exhausted under load. It holds 10 connections. " +
+		"Decision: raise it to 50 for now. Should we make it configurable?"
+	got := segmentTexts(SegmentText(para))
+	want := []string{
+		"Found: the pool is exhausted under load. It holds 10 connections.",
+		"Decision: raise it to 50 for now.",
+		"Should we make it configurable?",
+	}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// One claim with supporting sentences stays whole
+	single := "I recommend Postgres because of JSONB. It also has good tooling, e.g. pgx."
+	if got := SegmentText(single); len(got) != 1 {
+		t.Errorf("single-claim paragraph split into %q", segmentTexts(got))
+	}
+}
+
+func TestSplitSentences(t *testing.T) {
+	got := splitSentences("Use `cfg.Load()` first. Then call e.g. Start! Is it v1.2? yes it is.")
+	want := []string{"Use `cfg.Load()` first.", "Then call e.g. Start!", "Is it v1.2? yes it is."}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("splitSentences() = %q, want %q", got, want)
+	}
+}
+
+func TestScoreSegment(t *testing.T) {
+	decision := ScoreSegment("Decision: we'll use Redis because it supports TTL natively")
+	plain := ScoreSegment("The service reads its settings from the environment")
+	filler := ScoreSegment("Sure, let me check that")
+	code := ScoreSegment("if (x) { return y[0]; } else { z = f(); }")
+	intro := ScoreSegment("Here are the changes I made:")
+
+	if !(decision > plain && plain >= DefaultMinConfidence) {
+		t.Errorf("decision %v should beat plain %v, which should be kept", decision, plain)
+	}
+	for name, score := range map[string]float32{"filler": filler, "code": code, "intro": intro} {
+		if score >= DefaultMinConfidence {
+			t.Errorf("%s scored %v, want below %v", name, score, DefaultMinConfidence)
+		}
+	}
+	if decision > 0.95 || ScoreSegment("") < 0.05 {
+		t.Error("scores should stay within 0.05-0.95")
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/brianevanmiller/beadcrumbs/internal/classify"
 	"github.com/brianevanmiller/beadcrumbs/internal/types"
 )
 
@@ -93,15 +94,15 @@ func messagesToInsights(messages []SlackMessage) ([]*types.Insight, error) {
 		ts := ParseSlackTimestamp(msg.Timestamp)
 
 		// Detect insight type
-		insightType := DetectInsightType(text)
+		res := classify.Default().Classify(text, "slack")
 
 		insight := &types.Insight{
 			ID:         types.GenerateID("ins"),
 			Timestamp:  ts,
 			Content:    text,
 			Summary:    Truncate(text, 80),
-			Type:       insightType,
-			Confidence: 0.6, // Lower confidence for Slack (more noise)
+			Type:       res.Type,
+			Confidence: float32(res.Confidence),
 			Source: types.InsightSource{
 				Type:         "slack",
 				Ref:          msg.Timestamp, // Slack timestamp as reference
@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/brianevanmiller/beadcrumbs/internal/classify"
 	"github.com/brianevanmiller/beadcrumbs/internal/types"
 )
 
@@ -133,10 +134,13 @@ func TestParseSlackJSON_Valid(t *testing.T) {
 		t.Fatalf("ParseSlackJSON returned %d insights, want %d", len(insights), wantCount)
 	}
 
-	// Verify all insights have correct confidence and source type
+	// Verify all insights have the classifier's confidence and source type
 	for i, ins := range insights {
-		if ins.Confidence != 0.6 {
-			t.Errorf("insight[%d].Confidence = %v, want 0.6", i, ins.Confidence)
+		if want := float32(classify.Default().Classify(ins.Content, "slack").Confidence); ins.Confidence != want {
+			t.Errorf("insight[%d].Confidence = %v, want %v", i, ins.Confidence, want)
+		}
+		if i == 2 && ins.Confidence >= insights[0].Confidence {
+			t.Errorf("the unmarked message should score below the decision, got %v >= %v", ins.Confidence, insights[0].Confidence)
 		}
 		if ins.Source.Type != "slack" {
 			t.Errorf("insight[%d].Source.Type = %q, want \"slack\"", i, ins.Source.Type)
@@ -0,0 +1,55 @@
+package importer
+
+import (
+	"time"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+)
+
+// Turn is one message of a conversation with an AI agent, as read from a
+// transcript or chat export.
+type Turn struct {
+	Role      string    // "user" or "assistant"
+	Author    string    // Model that wrote an assistant turn, if known
+	Text      string    // Markdown
+	Timestamp time.Time // Zero if the source doesn't record it
+	Origin    string    // Session identifier, e.g. "codex:<session-id>"
+}
+
+// TurnsToInsights reads the turns with the default extractor (see
+// DefaultExtractor) and gives each insight found the turn's timestamp,
+// speaker, author and origin. By default each segment of 10+ characters
+// (see SegmentText) becomes an insight with the segment's score as its
+// confidence.
+func TurnsToInsights(turns []Turn) ([]*types.Insight, error) {
+	if len(turns) == 0 {
+		return nil, nil
+	}
+	now := time.Now()
+	passages := make([]Passage, len(turns))
+	for i, turn := range turns {
+		passages[i] = Passage{Text: turn.Text, Role: turn.Role}
+	}
+
+	return extractInsights(passages, "ai-session", func(i int) types.Insight {
+		turn := turns[i]
+		participant := "human"
+		if turn.Role == "assistant" {
+			participant = "ai-agent"
+		}
+		ts := turn.Timestamp
+		if ts.IsZero() {
+			ts = now
+		}
+		return types.Insight{
+			Timestamp: ts,
+			Source: types.InsightSource{
+				Type:         "ai-session",
+				Ref:          turn.Origin,
+				Participants: []string{participant},
+			},
+			AuthorID:  turn.Author,
+			CreatedAt: now,
+		}
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"bufio"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/brianevanmiller/beadcrumbs/internal/types"
@@ -73,6 +74,60 @@ func ImportDependencies(filePath string) ([]*types.Dependency, error) {
 	return deps, nil
 }
 
+// EncodeInsights writes insights to w as JSONL.
+func EncodeInsights(w io.Writer, insights []*types.Insight) error {
+	return encodeJSONL(w, insights)
+}
+
+// EncodeThreads writes insight threads to w as JSONL.
+func EncodeThreads(w io.Writer, threads []*types.InsightThread) error {
+	return encodeJSONL(w, threads)
+}
+
+// EncodeDependencies writes dependencies to w as JSONL.
+func EncodeDependencies(w io.Writer, deps []*types.Dependency) error {
+	return encodeJSONL(w, deps)
+}
+
+// DecodeInsights reads JSONL insights from r.
+func DecodeInsights(r io.Reader) ([]*types.Insight, error) {
+	items, err := decodeJSONL(r, func() interface{} { return &types.Insight{} })
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode insights: %w", err)
+	}
+	insights := make([]*types.Insight, len(items))
+	for i, item := range items {
+		insights[i] = item.(*types.Insight)
+	}
+	return insights, nil
+}
+
+// DecodeThreads reads JSONL insight threads from r.
+func DecodeThreads(r io.Reader) ([]*types.InsightThread, error) {
+	items, err := decodeJSONL(r, func() interface{} { return &types.InsightThread{} })
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode threads: %w", err)
+	}
+	threads := make([]*types.InsightThread, len(items))
+	for i, item := range items {
+		threads[i] = item.(*types.InsightThread)
+	}
+	return threads, nil
+}
+
+// DecodeDependencies reads JSONL dependencies from r.
+func DecodeDependencies(r io.Reader) ([]*types.Dependency, error) {
+	items, err := decodeJSONL(r, func() interface{} { return &types.Dependency{} })
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode dependencies: %w", err)
+	}
+	deps := make([]*types.Dependency, len(items))
+	for i, item := range items {
+		deps[i] = item.(*types.Dependency)
+	}
+	return deps, nil
+}
+
 // writeJSONL is a generic JSONL writer that writes a slice of items to a file,
 // with one JSON object per line.
 func writeJSONL(data interface{}, filePath string) error {
@@ -82,9 +137,12 @@ func writeJSONL(data interface{}, filePath string) error {
 	}
 	defer file.Close()
 
-	writer := bufio.NewWriter(file)
-	defer writer.Flush()
+	return encodeJSONL(file, data)
+}
 
+// encodeJSONL writes a slice of items to w, one JSON object per line.
+func encodeJSONL(w io.Writer, data interface{}) error {
+	writer := bufio.NewWriter(w)
 	encoder := json.NewEncoder(writer)
 
 	// Handle slice types by using reflection-free type assertion patterns
@@ -127,8 +185,15 @@ func readJSONL(filePath string, factory func() interface{}) ([]interface{}, erro
 	}
 	defer file.Close()
 
+	return decodeJSONL(file, factory)
+}
+
+// decodeJSONL reads r line by line, decoding each non-empty line with a
+// fresh value from factory.
+func decodeJSONL(r io.Reader, factory func() interface{}) ([]interface{}, error) {
 	var items []interface{}
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
 	lineNum := 0
 
 	for scanner.Scan() {
@@ -148,7 +213,7 @@ func readJSONL(filePath string, factory func() interface{}) ([]interface{}, erro
 	}
 
 	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading file %s: %w", filePath, err)
+		return nil, fmt.Errorf("error reading JSONL: %w", err)
 	}
 
 	return items, nil
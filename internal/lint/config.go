@@ -0,0 +1,115 @@
+package lint
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileName is the lint configuration file inside .beadcrumbs/.
+const ConfigFileName = "lint.yaml"
+
+// Config is the parsed form of .beadcrumbs/lint.yaml.
+//
+//	version: 1
+//	rules:
+//	  concluded-without-decision:
+//	    severity: error
+//	  duplicate-content:
+//	    severity: off
+//	  orphan-insight:
+//	    ignore: [ins-7f2a]
+type Config struct {
+	Version int                   `yaml:"version"`
+	Rules   map[string]RuleConfig `yaml:"rules"`
+}
+
+// RuleConfig overrides a single rule's behaviour.
+type RuleConfig struct {
+	Severity Severity `yaml:"severity,omitempty"`
+	Ignore   []string `yaml:"ignore,omitempty"` // Finding subjects to suppress
+}
+
+// DefaultConfig returns a config that runs every rule at its default severity.
+func DefaultConfig() *Config {
+	return &Config{Version: 1, Rules: map[string]RuleConfig{}}
+}
+
+// LoadConfig reads and validates a lint config file.
+// A missing file is not an error; the default config is returned instead.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return DefaultConfig(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lint config: %w", err)
+	}
+	return ParseConfig(data)
+}
+
+// ParseConfig parses and validates lint config YAML.
+func ParseConfig(data []byte) (*Config, error) {
+	cfg := DefaultConfig()
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse lint config: %w", err)
+	}
+	if cfg.Rules == nil {
+		cfg.Rules = map[string]RuleConfig{}
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate rejects unknown rule IDs, unknown severities, and future versions.
+func (c *Config) Validate() error {
+	if c.Version > 1 {
+		return fmt.Errorf("unsupported lint config version %d (this bdc understands version 1)", c.Version)
+	}
+
+	known := make(map[string]bool)
+	var ids []string
+	for _, r := range Rules() {
+		known[r.ID] = true
+		ids = append(ids, r.ID)
+	}
+
+	var unknown []string
+	for id, rc := range c.Rules {
+		if !known[id] {
+			unknown = append(unknown, id)
+			continue
+		}
+		if rc.Severity != "" && !rc.Severity.IsValid() {
+			return fmt.Errorf("rule %s: invalid severity %q (use error, warning, or off)", id, rc.Severity)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown lint rule(s): %s. Known rules: %s",
+			strings.Join(unknown, ", "), strings.Join(ids, ", "))
+	}
+	return nil
+}
+
+// severityFor returns the configured severity for a rule, or its default.
+func (c *Config) severityFor(rule Rule) Severity {
+	if rc, ok := c.Rules[rule.ID]; ok && rc.Severity != "" {
+		return rc.Severity
+	}
+	return rule.DefaultSeverity
+}
+
+// ignoredFor returns the set of subjects suppressed for a rule.
+func (c *Config) ignoredFor(ruleID string) map[string]bool {
+	ignored := make(map[string]bool)
+	for _, subject := range c.Rules[ruleID].Ignore {
+		ignored[subject] = true
+	}
+	return ignored
+}
@@ -0,0 +1,214 @@
+// Package lint checks the insight graph against configurable quality rules.
+//
+// Rules run over an in-memory snapshot of threads, insights, and dependencies
+// (see Graph) and report Findings. Some findings carry a mechanical Fix that
+// callers can apply through the store; the lint package itself never writes.
+package lint
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+)
+
+// Severity controls how a finding is reported and whether it fails the run.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"   // Fails the run (non-zero exit)
+	SeverityWarning Severity = "warning" // Reported, fails only with --strict
+	SeverityOff     Severity = "off"     // Rule disabled
+)
+
+// IsValid checks if the severity is one of the known levels.
+func (s Severity) IsValid() bool {
+	switch s {
+	case SeverityError, SeverityWarning, SeverityOff:
+		return true
+	default:
+		return false
+	}
+}
+
+// Fix describes a mechanical repair for a finding. At most one field group
+// is set per fix: a dependency to add, a dependency to remove, or a thread
+// assignment for an insight.
+type Fix struct {
+	Description string            `json:"description"`
+	AddDep      *types.Dependency `json:"add_dependency,omitempty"`
+	RemoveDep   *types.Dependency `json:"remove_dependency,omitempty"`
+	InsightID   string            `json:"insight_id,omitempty"`
+	ThreadID    string            `json:"thread_id,omitempty"`
+}
+
+// Finding is a single rule violation.
+type Finding struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Subject  string   `json:"subject"` // ins-xxx, thr-xxx, or "from -> to" for edges
+	Message  string   `json:"message"`
+	Fix      *Fix     `json:"fix,omitempty"`
+	Fixed    bool     `json:"fixed,omitempty"`
+}
+
+// Rule is a named check over the insight graph.
+type Rule struct {
+	ID              string
+	Description     string
+	DefaultSeverity Severity
+	Check           func(g *Graph) []Finding
+}
+
+// Graph is an indexed snapshot of the data a rule can inspect.
+type Graph struct {
+	Threads  []*types.InsightThread
+	Insights []*types.Insight
+	Deps     []*types.Dependency
+
+	ordered     []*types.Insight // all insights, oldest first
+	insightByID map[string]*types.Insight
+	byThread    map[string][]*types.Insight // oldest first
+	depsFrom    map[string][]*types.Dependency
+	depsTo      map[string][]*types.Dependency
+}
+
+// NewGraph indexes threads, insights, and dependencies for rule evaluation.
+func NewGraph(threads []*types.InsightThread, insights []*types.Insight, deps []*types.Dependency) *Graph {
+	g := &Graph{
+		Threads:     threads,
+		Insights:    insights,
+		Deps:        deps,
+		insightByID: make(map[string]*types.Insight),
+		byThread:    make(map[string][]*types.Insight),
+		depsFrom:    make(map[string][]*types.Dependency),
+		depsTo:      make(map[string][]*types.Dependency),
+	}
+	sorted := make([]*types.Insight, len(insights))
+	copy(sorted, insights)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+	g.ordered = sorted
+	for _, ins := range sorted {
+		g.insightByID[ins.ID] = ins
+		if ins.ThreadID != "" {
+			g.byThread[ins.ThreadID] = append(g.byThread[ins.ThreadID], ins)
+		}
+	}
+
+	for _, d := range deps {
+		g.depsFrom[d.From] = append(g.depsFrom[d.From], d)
+		g.depsTo[d.To] = append(g.depsTo[d.To], d)
+	}
+	return g
+}
+
+// Rules returns all built-in rules in reporting order.
+func Rules() []Rule {
+	return []Rule{
+		{
+			ID:              "concluded-without-decision",
+			Description:     "A concluded thread should record at least one decision",
+			DefaultSeverity: SeverityWarning,
+			Check:           checkConcludedWithoutDecision,
+		},
+		{
+			ID:              "decision-without-evidence",
+			Description:     "A decision should follow a discovery or feedback in its thread",
+			DefaultSeverity: SeverityWarning,
+			Check:           checkDecisionWithoutEvidence,
+		},
+		{
+			ID:              "pivot-without-supersedes",
+			Description:     "A pivot should supersede the insight it changes direction from",
+			DefaultSeverity: SeverityWarning,
+			Check:           checkPivotWithoutSupersedes,
+		},
+		{
+			ID:              "orphan-insight",
+			Description:     "Every insight should belong to a thread",
+			DefaultSeverity: SeverityWarning,
+			Check:           checkOrphanInsight,
+		},
+		{
+			ID:              "duplicate-content",
+			Description:     "The same content should not be captured in several threads",
+			DefaultSeverity: SeverityWarning,
+			Check:           checkDuplicateContent,
+		},
+		{
+			ID:              "placeholder-spawn",
+			Description:     "spawns edges should point at real beads, not bdc spawn placeholders",
+			DefaultSeverity: SeverityWarning,
+			Check:           checkPlaceholderSpawn,
+		},
+		{
+			ID:              "dangling-dependency",
+			Description:     "Dependencies should not reference insights that no longer exist",
+			DefaultSeverity: SeverityError,
+			Check:           checkDanglingDependency,
+		},
+	}
+}
+
+// Run evaluates every enabled rule against g and returns the findings,
+// sorted by severity (errors first), then rule order, then subject.
+func Run(g *Graph, cfg *Config) []Finding {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+
+	var findings []Finding
+	ruleOrder := make(map[string]int)
+	for i, rule := range Rules() {
+		ruleOrder[rule.ID] = i
+		severity := cfg.severityFor(rule)
+		if severity == SeverityOff {
+			continue
+		}
+		ignored := cfg.ignoredFor(rule.ID)
+		for _, f := range rule.Check(g) {
+			if ignored[f.Subject] {
+				continue
+			}
+			f.Rule = rule.ID
+			f.Severity = severity
+			findings = append(findings, f)
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Severity != b.Severity {
+			return a.Severity == SeverityError
+		}
+		if a.Rule != b.Rule {
+			return ruleOrder[a.Rule] < ruleOrder[b.Rule]
+		}
+		return a.Subject < b.Subject
+	})
+	return findings
+}
+
+// Counts tallies unfixed findings by severity.
+func Counts(findings []Finding) (errors, warnings int) {
+	for _, f := range findings {
+		if f.Fixed {
+			continue
+		}
+		switch f.Severity {
+		case SeverityError:
+			errors++
+		case SeverityWarning:
+			warnings++
+		}
+	}
+	return errors, warnings
+}
+
+// normalizeContent lowercases and collapses whitespace so trivially
+// reformatted copies compare equal.
+func normalizeContent(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
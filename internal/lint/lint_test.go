@@ -0,0 +1,241 @@
+package lint
+
+import (
+	"testing"
+	"time"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+)
+
+// at returns an insight in a thread with a fixed offset timestamp.
+func at(id string, typ types.InsightType, threadID string, minute int) *types.Insight {
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	return &types.Insight{
+		ID:        id,
+		Content:   "content of " + id,
+		Type:      typ,
+		ThreadID:  threadID,
+		Timestamp: base.Add(time.Duration(minute) * time.Minute),
+	}
+}
+
+func findingsFor(findings []Finding, rule string) []Finding {
+	var out []Finding
+	for _, f := range findings {
+		if f.Rule == rule {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func TestConcludedWithoutDecision(t *testing.T) {
+	concluded := &types.InsightThread{ID: "thr-0001", Title: "Done", Status: types.ThreadConcluded}
+	decided := &types.InsightThread{ID: "thr-0002", Title: "Decided", Status: types.ThreadConcluded}
+	active := &types.InsightThread{ID: "thr-0003", Title: "Open", Status: types.ThreadActive}
+
+	g := NewGraph(
+		[]*types.InsightThread{concluded, decided, active},
+		[]*types.Insight{
+			at("ins-0001", types.InsightDiscovery, "thr-0001", 0),
+			at("ins-0002", types.InsightDecision, "thr-0002", 0),
+		},
+		nil,
+	)
+
+	got := findingsFor(Run(g, nil), "concluded-without-decision")
+	if len(got) != 1 || got[0].Subject != "thr-0001" {
+		t.Fatalf("expected one finding for thr-0001, got %+v", got)
+	}
+}
+
+func TestDecisionWithoutEvidence(t *testing.T) {
+	g := NewGraph(nil, []*types.Insight{
+		// Supported by an earlier discovery in the same thread.
+		at("ins-0001", types.InsightDiscovery, "thr-a", 0),
+		at("ins-0002", types.InsightDecision, "thr-a", 1),
+		// Discovery comes after the decision: unsupported.
+		at("ins-0003", types.InsightDecision, "thr-b", 0),
+		at("ins-0004", types.InsightDiscovery, "thr-b", 1),
+		// Supported by an explicit builds-on edge to feedback elsewhere.
+		at("ins-0005", types.InsightDecision, "thr-c", 0),
+		at("ins-0006", types.InsightFeedback, "thr-d", 0),
+	}, []*types.Dependency{
+		types.NewDependency("ins-0005", "ins-0006", types.DepBuildsOn),
+	})
+
+	got := findingsFor(Run(g, nil), "decision-without-evidence")
+	if len(got) != 1 || got[0].Subject != "ins-0003" {
+		t.Fatalf("expected one finding for ins-0003, got %+v", got)
+	}
+}
+
+func TestPivotWithoutSupersedes_Fix(t *testing.T) {
+	g := NewGraph(nil, []*types.Insight{
+		at("ins-0001", types.InsightHypothesis, "thr-a", 0),
+		at("ins-0002", types.InsightDiscovery, "thr-a", 1),
+		at("ins-0003", types.InsightPivot, "thr-a", 2),
+		// Already supersedes something: no finding.
+		at("ins-0004", types.InsightPivot, "thr-a", 3),
+	}, []*types.Dependency{
+		types.NewDependency("ins-0004", "ins-0002", types.DepSupersedes),
+	})
+
+	got := findingsFor(Run(g, nil), "pivot-without-supersedes")
+	if len(got) != 1 || got[0].Subject != "ins-0003" {
+		t.Fatalf("expected one finding for ins-0003, got %+v", got)
+	}
+	fix := got[0].Fix
+	if fix == nil || fix.AddDep == nil {
+		t.Fatal("expected a supersedes fix")
+	}
+	// Discoveries are skipped; the hypothesis is the direction being abandoned.
+	if fix.AddDep.From != "ins-0003" || fix.AddDep.To != "ins-0001" || fix.AddDep.Type != types.DepSupersedes {
+		t.Errorf("unexpected fix edge: %+v", fix.AddDep)
+	}
+}
+
+func TestOrphanInsight_FixFromOrigin(t *testing.T) {
+	threaded := at("ins-0001", types.InsightDiscovery, "thr-a", 0)
+	threaded.Source.Ref = "claude:sess_1"
+	orphan := at("ins-0002", types.InsightDiscovery, "", 1)
+	orphan.Source.Ref = "claude:sess_1"
+	loner := at("ins-0003", types.InsightDiscovery, "", 2)
+
+	g := NewGraph(nil, []*types.Insight{threaded, orphan, loner}, nil)
+
+	got := findingsFor(Run(g, nil), "orphan-insight")
+	if len(got) != 2 {
+		t.Fatalf("expected 2 orphan findings, got %d", len(got))
+	}
+	for _, f := range got {
+		switch f.Subject {
+		case "ins-0002":
+			if f.Fix == nil || f.Fix.ThreadID != "thr-a" {
+				t.Errorf("expected fix assigning thr-a, got %+v", f.Fix)
+			}
+		case "ins-0003":
+			if f.Fix != nil {
+				t.Errorf("insight without origin should have no fix, got %+v", f.Fix)
+			}
+		}
+	}
+}
+
+func TestDuplicateContent(t *testing.T) {
+	a := at("ins-0001", types.InsightDiscovery, "thr-a", 0)
+	a.Content = "Redis evicts keys   under pressure"
+	b := at("ins-0002", types.InsightDiscovery, "thr-b", 1)
+	b.Content = "redis evicts keys under pressure"
+	sameThread := at("ins-0003", types.InsightDiscovery, "thr-a", 2)
+	sameThread.Content = "Redis evicts keys under pressure"
+
+	g := NewGraph(nil, []*types.Insight{b, sameThread, a}, nil)
+
+	got := findingsFor(Run(g, nil), "duplicate-content")
+	if len(got) != 1 || got[0].Subject != "ins-0002" {
+		t.Fatalf("expected one finding for ins-0002, got %+v", got)
+	}
+
+	// Merged by bdc dedupe: nothing left to report
+	g = NewGraph(nil, []*types.Insight{b, sameThread, a}, []*types.Dependency{
+		types.NewDependency("ins-0002", "ins-0001", types.DepDuplicates),
+	})
+	if got := findingsFor(Run(g, nil), "duplicate-content"); len(got) != 0 {
+		t.Fatalf("merged duplicates should be skipped, got %+v", got)
+	}
+}
+
+func TestPlaceholderSpawn(t *testing.T) {
+	g := NewGraph(nil, []*types.Insight{at("ins-0001", types.InsightDecision, "thr-a", 0)}, []*types.Dependency{
+		types.NewDependency("ins-0001", "bead-3", types.DepSpawns),
+		types.NewDependency("ins-0001", "bd-a1b2", types.DepSpawns),
+		types.NewDependency("ins-0001", "bead-a1b2", types.DepSpawns),
+	})
+
+	got := findingsFor(Run(g, nil), "placeholder-spawn")
+	if len(got) != 1 || got[0].Subject != "ins-0001 -> bead-3" {
+		t.Fatalf("expected one placeholder finding, got %+v", got)
+	}
+}
+
+func TestDanglingDependency(t *testing.T) {
+	g := NewGraph(nil, []*types.Insight{at("ins-0001", types.InsightDecision, "thr-a", 0)}, []*types.Dependency{
+		types.NewDependency("ins-0001", "ins-dead", types.DepBuildsOn),
+		types.NewDependency("ins-0001", "bd-a1b2", types.DepSpawns),
+	})
+
+	got := findingsFor(Run(g, nil), "dangling-dependency")
+	if len(got) != 1 {
+		t.Fatalf("expected one dangling finding, got %+v", got)
+	}
+	if got[0].Severity != SeverityError {
+		t.Errorf("severity = %s, want error", got[0].Severity)
+	}
+	if got[0].Fix == nil || got[0].Fix.RemoveDep == nil || got[0].Fix.RemoveDep.To != "ins-dead" {
+		t.Errorf("expected removal fix, got %+v", got[0].Fix)
+	}
+}
+
+func TestRun_ConfigSeverityAndIgnore(t *testing.T) {
+	g := NewGraph(nil, []*types.Insight{
+		at("ins-0001", types.InsightDiscovery, "", 0),
+		at("ins-0002", types.InsightDiscovery, "", 1),
+		at("ins-0003", types.InsightPivot, "thr-a", 2),
+	}, nil)
+
+	cfg, err := ParseConfig([]byte(`
+version: 1
+rules:
+  orphan-insight:
+    severity: error
+    ignore: [ins-0002]
+  pivot-without-supersedes:
+    severity: off
+`))
+	if err != nil {
+		t.Fatalf("ParseConfig: %v", err)
+	}
+
+	findings := Run(g, cfg)
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %+v", findings)
+	}
+	if findings[0].Subject != "ins-0001" || findings[0].Severity != SeverityError {
+		t.Errorf("unexpected finding: %+v", findings[0])
+	}
+
+	errs, warns := Counts(findings)
+	if errs != 1 || warns != 0 {
+		t.Errorf("Counts = (%d, %d), want (1, 0)", errs, warns)
+	}
+}
+
+func TestParseConfig_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+	}{
+		{"unknown rule", "rules:\n  no-such-rule:\n    severity: error\n"},
+		{"bad severity", "rules:\n  orphan-insight:\n    severity: fatal\n"},
+		{"future version", "version: 2\n"},
+		{"malformed", "rules: [\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseConfig([]byte(tt.yaml)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestLoadConfig_Missing(t *testing.T) {
+	cfg, err := LoadConfig(t.TempDir() + "/lint.yaml")
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if len(cfg.Rules) != 0 {
+		t.Errorf("expected default config, got %+v", cfg)
+	}
+}
@@ -0,0 +1,294 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+)
+
+// placeholderBeadPattern matches the IDs `bdc spawn` invents when no beads
+// database is present: "bead-" plus the last hex digit of a generated ID.
+// Real bead IDs with a bead- prefix have longer suffixes.
+var placeholderBeadPattern = regexp.MustCompile(`^bead-[0-9a-f]$`)
+
+// IsPlaceholderBeadID reports whether id looks like a bdc spawn placeholder.
+func IsPlaceholderBeadID(id string) bool {
+	return placeholderBeadPattern.MatchString(id)
+}
+
+func checkConcludedWithoutDecision(g *Graph) []Finding {
+	var findings []Finding
+	for _, t := range g.Threads {
+		if t.Status != types.ThreadConcluded {
+			continue
+		}
+		hasDecision := false
+		for _, ins := range g.byThread[t.ID] {
+			if ins.Type == types.InsightDecision {
+				hasDecision = true
+				break
+			}
+		}
+		if !hasDecision {
+			findings = append(findings, Finding{
+				Subject: t.ID,
+				Message: fmt.Sprintf("thread %q was concluded without any decision", t.Title),
+			})
+		}
+	}
+	return findings
+}
+
+func checkDecisionWithoutEvidence(g *Graph) []Finding {
+	isEvidence := func(id string) bool {
+		ins := g.insightByID[id]
+		return ins != nil && (ins.Type == types.InsightDiscovery || ins.Type == types.InsightFeedback)
+	}
+
+	var findings []Finding
+	for _, ins := range g.Insights {
+		if ins.Type != types.InsightDecision {
+			continue
+		}
+
+		supported := false
+		for _, prior := range g.byThread[ins.ThreadID] {
+			if !prior.Timestamp.Before(ins.Timestamp) {
+				break
+			}
+			if isEvidence(prior.ID) {
+				supported = true
+				break
+			}
+		}
+		// An explicit builds-on edge to evidence counts regardless of thread.
+		if !supported {
+			for _, d := range g.depsFrom[ins.ID] {
+				if d.Type == types.DepBuildsOn && isEvidence(d.To) {
+					supported = true
+					break
+				}
+			}
+		}
+		if !supported {
+			for _, d := range g.depsTo[ins.ID] {
+				if d.Type == types.DepBuildsOn && isEvidence(d.From) {
+					supported = true
+					break
+				}
+			}
+		}
+
+		if !supported {
+			findings = append(findings, Finding{
+				Subject: ins.ID,
+				Message: fmt.Sprintf("decision %q has no preceding discovery or feedback", preview(ins)),
+			})
+		}
+	}
+	return findings
+}
+
+func checkPivotWithoutSupersedes(g *Graph) []Finding {
+	var findings []Finding
+	for _, ins := range g.Insights {
+		if ins.Type != types.InsightPivot {
+			continue
+		}
+
+		supersedes := false
+		for _, d := range g.depsFrom[ins.ID] {
+			if d.Type == types.DepSupersedes {
+				supersedes = true
+				break
+			}
+		}
+		if supersedes {
+			continue
+		}
+
+		f := Finding{
+			Subject: ins.ID,
+			Message: fmt.Sprintf("pivot %q does not supersede anything", preview(ins)),
+		}
+		if target := PivotTarget(g, ins); target != nil {
+			f.Fix = &Fix{
+				Description: fmt.Sprintf("link %s --supersedes=%s", ins.ID, target.ID),
+				AddDep:      types.NewDependency(ins.ID, target.ID, types.DepSupersedes),
+			}
+		}
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+// PivotTarget picks the most recent earlier hypothesis, decision, or pivot in
+// the pivot's thread that has not already been superseded. Those are the
+// insight types that express a direction a pivot can turn away from.
+func PivotTarget(g *Graph, pivot *types.Insight) *types.Insight {
+	if pivot.ThreadID == "" {
+		return nil
+	}
+	thread := g.byThread[pivot.ThreadID]
+	for i := len(thread) - 1; i >= 0; i-- {
+		prior := thread[i]
+		if prior.ID == pivot.ID || !prior.Timestamp.Before(pivot.Timestamp) {
+			continue
+		}
+		switch prior.Type {
+		case types.InsightHypothesis, types.InsightDecision, types.InsightPivot:
+		default:
+			continue
+		}
+		superseded := false
+		for _, d := range g.depsTo[prior.ID] {
+			if d.Type == types.DepSupersedes {
+				superseded = true
+				break
+			}
+		}
+		if !superseded {
+			return prior
+		}
+	}
+	return nil
+}
+
+func checkOrphanInsight(g *Graph) []Finding {
+	// Map each origin to the set of threads its threaded insights belong to.
+	originThreads := make(map[string]map[string]bool)
+	for _, ins := range g.Insights {
+		if ins.ThreadID == "" || ins.Source.Ref == "" {
+			continue
+		}
+		if originThreads[ins.Source.Ref] == nil {
+			originThreads[ins.Source.Ref] = make(map[string]bool)
+		}
+		originThreads[ins.Source.Ref][ins.ThreadID] = true
+	}
+
+	var findings []Finding
+	for _, ins := range g.Insights {
+		if ins.ThreadID != "" {
+			continue
+		}
+		f := Finding{
+			Subject: ins.ID,
+			Message: fmt.Sprintf("insight %q has no thread", preview(ins)),
+		}
+		// If every other insight from the same origin went to one thread,
+		// this one almost certainly belongs there too.
+		if threads := originThreads[ins.Source.Ref]; len(threads) == 1 {
+			for threadID := range threads {
+				f.Fix = &Fix{
+					Description: fmt.Sprintf("assign to %s (only thread used by origin %s)", threadID, ins.Source.Ref),
+					InsightID:   ins.ID,
+					ThreadID:    threadID,
+				}
+			}
+		}
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+func checkDuplicateContent(g *Graph) []Finding {
+	// Walk insights oldest first so the earliest copy is the original.
+	type original struct {
+		id       string
+		threadID string
+	}
+	seen := make(map[string]original)
+
+	var findings []Finding
+	for _, ins := range g.ordered {
+		// Insights merged by bdc dedupe are already resolved.
+		if ins.ThreadID == "" || g.mergedDuplicate(ins.ID) {
+			continue
+		}
+		key := normalizeContent(ins.Content)
+		if key == "" {
+			continue
+		}
+		orig, ok := seen[key]
+		if !ok {
+			seen[key] = original{id: ins.ID, threadID: ins.ThreadID}
+			continue
+		}
+		if orig.threadID == ins.ThreadID {
+			continue
+		}
+		findings = append(findings, Finding{
+			Subject: ins.ID,
+			Message: fmt.Sprintf("content duplicates %s in thread %s", orig.id, orig.threadID),
+		})
+	}
+	return findings
+}
+
+// mergedDuplicate reports whether id has a duplicates link to the insight
+// it was merged into.
+func (g *Graph) mergedDuplicate(id string) bool {
+	for _, d := range g.depsFrom[id] {
+		if d.Type == types.DepDuplicates {
+			return true
+		}
+	}
+	return false
+}
+
+func checkPlaceholderSpawn(g *Graph) []Finding {
+	var findings []Finding
+	for _, d := range g.Deps {
+		if d.Type != types.DepSpawns || !IsPlaceholderBeadID(d.To) {
+			continue
+		}
+		findings = append(findings, Finding{
+			Subject: d.From + " -> " + d.To,
+			Message: fmt.Sprintf("%s spawns placeholder %s; relink with bdc link %s --spawns=<bead-id>", d.From, d.To, d.From),
+		})
+	}
+	return findings
+}
+
+func checkDanglingDependency(g *Graph) []Finding {
+	missing := func(id string) bool {
+		return strings.HasPrefix(id, "ins-") && g.insightByID[id] == nil
+	}
+
+	var findings []Finding
+	for _, d := range g.Deps {
+		var gone string
+		switch {
+		case missing(d.From):
+			gone = d.From
+		case missing(d.To):
+			gone = d.To
+		default:
+			continue
+		}
+		findings = append(findings, Finding{
+			Subject: d.From + " -> " + d.To,
+			Message: fmt.Sprintf("%s edge references missing insight %s", d.Type, gone),
+			Fix: &Fix{
+				Description: fmt.Sprintf("remove %s -> %s [%s]", d.From, d.To, d.Type),
+				RemoveDep:   d,
+			},
+		})
+	}
+	return findings
+}
+
+// preview returns a short single-line excerpt of an insight for messages.
+func preview(ins *types.Insight) string {
+	text := ins.Summary
+	if text == "" {
+		text = strings.Join(strings.Fields(ins.Content), " ")
+	}
+	if len(text) > 50 {
+		return text[:47] + "..."
+	}
+	return text
+}
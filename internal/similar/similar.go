@@ -0,0 +1,280 @@
+// Package similar compares texts. Near-duplicates are found by the Jaccard
+// similarity of sets of normalized words; MinHash signatures with
+// locality-sensitive hashing narrow the candidates so large collections
+// don't need every pair compared, unless the threshold is too low for them.
+package similar
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// DefaultThreshold is the similarity at or above which two texts count as
+// near-duplicates.
+const DefaultThreshold = 0.8
+
+const (
+	numHashes = 64
+	// bands of rows: texts sharing all rows of any band become candidates.
+	// A pair of similarity s is found with probability 1-(1-s^2)^32, which
+	// is 99.99% at 0.5 and higher above it.
+	bands = 32
+	rows  = numHashes / bands
+
+	// lshFloor is the lowest threshold the bands reliably find pairs for.
+	// Below it every text is compared with every other of the same key.
+	lshFloor = 0.5
+)
+
+// stopWords are left out so short texts don't match on filler alone.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "the": true, "and": true, "or": true, "of": true,
+	"to": true, "in": true, "on": true, "for": true, "is": true, "are": true,
+	"was": true, "be": true, "it": true, "this": true, "that": true, "with": true,
+	"as": true, "at": true, "by": true, "we": true, "i": true,
+}
+
+// Set is a text's normalized words, hashed.
+type Set map[uint64]struct{}
+
+// contractions are expanded so "we'll" and "we will" match.
+var contractions = strings.NewReplacer(
+	"won't", "will not", "can't", "cannot", "n't", " not", "'ll", " will",
+	"'re", " are", "'ve", " have", "'m", " am", "'d", " would",
+)
+
+// Words returns the lower-cased words of text without punctuation and
+// stop words, with contractions expanded.
+func Words(text string) []string {
+	text = contractions.Replace(strings.ReplaceAll(strings.ToLower(text), "’", "'"))
+	fields := strings.FieldsFunc(strings.ReplaceAll(text, "'", ""), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	words := fields[:0]
+	for _, w := range fields {
+		if !stopWords[w] {
+			words = append(words, w)
+		}
+	}
+	return words
+}
+
+// NewSet returns the word set of text.
+func NewSet(text string) Set {
+	s := make(Set)
+	for _, w := range Words(text) {
+		h := fnv.New64a()
+		h.Write([]byte(w))
+		s[h.Sum64()] = struct{}{}
+	}
+	return s
+}
+
+// Jaccard is the size of the intersection of a and b over the size of
+// their union. Two empty sets have similarity 0.
+func Jaccard(a, b Set) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	shared := 0
+	for h := range a {
+		if _, ok := b[h]; ok {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// Similarity is the Jaccard similarity of the word sets of a and b.
+func Similarity(a, b string) float64 {
+	return Jaccard(NewSet(a), NewSet(b))
+}
+
+// Signature is a MinHash signature: for each of numHashes hash functions,
+// the smallest hash of the set's members.
+type Signature [numHashes]uint64
+
+// MinHash returns s's signature. The share of positions where two
+// signatures agree estimates the sets' Jaccard similarity.
+func MinHash(s Set) Signature {
+	var sig Signature
+	for i := range sig {
+		sig[i] = ^uint64(0)
+	}
+	for h := range s {
+		for i := range sig {
+			if v := mix(h ^ seeds[i]); v < sig[i] {
+				sig[i] = v
+			}
+		}
+	}
+	return sig
+}
+
+// Estimate returns the share of positions where sig and other agree.
+func (sig Signature) Estimate(other Signature) float64 {
+	same := 0
+	for i := range sig {
+		if sig[i] == other[i] {
+			same++
+		}
+	}
+	return float64(same) / numHashes
+}
+
+var seeds = func() [numHashes]uint64 {
+	var s [numHashes]uint64
+	x := uint64(0x9e3779b97f4a7c15)
+	for i := range s {
+		x = mix(x + uint64(i))
+		s[i] = x
+	}
+	return s
+}()
+
+// mix is the splitmix64 finalizer.
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// Match is an indexed text similar to a query.
+type Match struct {
+	ID         string  `json:"id"`
+	Similarity float64 `json:"similarity"`
+}
+
+// Pair is two indexed texts similar to each other. A was added before B.
+type Pair struct {
+	A          string  `json:"a"`
+	B          string  `json:"b"`
+	Similarity float64 `json:"similarity"`
+}
+
+type entry struct {
+	id    string
+	key   string
+	set   Set
+	bands []bucketKey
+}
+
+// Index holds texts for near-duplicate lookups. Texts are only compared
+// with texts of the same key (e.g. the same insight type).
+type Index struct {
+	entries []entry
+	buckets map[bucketKey][]int
+}
+
+type bucketKey struct {
+	band int
+	key  string
+	hash uint64
+}
+
+// NewIndex returns an empty index.
+func NewIndex() *Index {
+	return &Index{buckets: make(map[bucketKey][]int)}
+}
+
+// Len returns the number of texts added.
+func (ix *Index) Len() int { return len(ix.entries) }
+
+// Add indexes text under id and key. Texts without words are ignored.
+func (ix *Index) Add(id, key, text string) {
+	set := NewSet(text)
+	if len(set) == 0 {
+		return
+	}
+	n := len(ix.entries)
+	e := entry{id: id, key: key, set: set, bands: bandKeys(key, set)}
+	ix.entries = append(ix.entries, e)
+	for _, bk := range e.bands {
+		ix.buckets[bk] = append(ix.buckets[bk], n)
+	}
+}
+
+// Similar returns the indexed texts with key whose similarity to text is
+// at least threshold, most similar first.
+func (ix *Index) Similar(key, text string, threshold float64) []Match {
+	set := NewSet(text)
+	if len(set) == 0 {
+		return nil
+	}
+	var matches []Match
+	for _, i := range ix.candidates(key, bandKeys(key, set), threshold) {
+		if sim := Jaccard(set, ix.entries[i].set); sim >= threshold {
+			matches = append(matches, Match{ID: ix.entries[i].id, Similarity: sim})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Similarity > matches[j].Similarity })
+	return matches
+}
+
+// Pairs returns every pair of indexed texts with the same key and a
+// similarity of at least threshold, ordered by when B was added.
+func (ix *Index) Pairs(threshold float64) []Pair {
+	var pairs []Pair
+	for j, e := range ix.entries {
+		for _, i := range ix.candidates(e.key, e.bands, threshold) {
+			if i >= j {
+				continue
+			}
+			if sim := Jaccard(ix.entries[i].set, e.set); sim >= threshold {
+				pairs = append(pairs, Pair{A: ix.entries[i].id, B: e.id, Similarity: sim})
+			}
+		}
+	}
+	return pairs
+}
+
+// candidates returns the entries sharing one of the bands, in order, or
+// every entry with key when threshold is too low for the bands to find.
+func (ix *Index) candidates(key string, keys []bucketKey, threshold float64) []int {
+	if threshold < lshFloor {
+		var out []int
+		for i, e := range ix.entries {
+			if e.key == key {
+				out = append(out, i)
+			}
+		}
+		return out
+	}
+	seen := make(map[int]bool)
+	var out []int
+	for _, bk := range keys {
+		for _, i := range ix.buckets[bk] {
+			if !seen[i] {
+				seen[i] = true
+				out = append(out, i)
+			}
+		}
+	}
+	sort.Ints(out)
+	return out
+}
+
+// bandKeys hashes each band of set's signature, with key.
+func bandKeys(key string, set Set) []bucketKey {
+	sig := MinHash(set)
+	keys := make([]bucketKey, bands)
+	buf := make([]byte, 8*rows)
+	for b := 0; b < bands; b++ {
+		for r := 0; r < rows; r++ {
+			binary.LittleEndian.PutUint64(buf[8*r:], sig[b*rows+r])
+		}
+		h := fnv.New64a()
+		h.Write(buf)
+		keys[b] = bucketKey{band: b, key: key, hash: h.Sum64()}
+	}
+	return keys
+}
@@ -0,0 +1,104 @@
+package similar
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		min, max float64
+	}{
+		{"The cache is keyed per tenant.", "the cache is keyed per-tenant", 1, 1},
+		{"We'll use Redis for sessions", "We will use Redis for the sessions!", 1, 1},
+		{"It doesn't stream", "It does not stream", 1, 1},
+		{"Use Redis for sessions", "Use Redis for sessions and caching", 0.6, 0.8},
+		{"Sessions stay in Postgres", "The export job leaks memory", 0, 0},
+		{"", "anything", 0, 0},
+	}
+	for _, tt := range tests {
+		if got := Similarity(tt.a, tt.b); got < tt.min || got > tt.max {
+			t.Errorf("Similarity(%q, %q) = %.2f, want %.2f-%.2f", tt.a, tt.b, got, tt.min, tt.max)
+		}
+	}
+}
+
+func TestMinHashEstimate(t *testing.T) {
+	var a, b string
+	for i := 0; i < 100; i++ {
+		a += fmt.Sprintf(" word%d", i)
+		b += fmt.Sprintf(" word%d", i+25)
+	}
+	exact := Similarity(a, b) // 75 shared of 125
+	est := MinHash(NewSet(a)).Estimate(MinHash(NewSet(b)))
+	if math.Abs(est-exact) > 0.2 {
+		t.Errorf("MinHash estimate %.2f too far from %.2f", est, exact)
+	}
+	if MinHash(NewSet(a)) != MinHash(NewSet(a)) {
+		t.Error("signatures should be deterministic")
+	}
+}
+
+func TestIndex(t *testing.T) {
+	ix := NewIndex()
+	ix.Add("ins-1", "decision", "We will keep sessions in Postgres for now")
+	ix.Add("ins-2", "discovery", "We will keep sessions in Postgres for now")
+	ix.Add("ins-3", "decision", "The export job leaks memory under load")
+	ix.Add("ins-4", "decision", "we will keep the sessions in postgres for now.")
+	ix.Add("ins-5", "decision", "...")
+
+	if ix.Len() != 4 {
+		t.Errorf("texts without words should be ignored, got %d entries", ix.Len())
+	}
+
+	matches := ix.Similar("decision", "Keep sessions in Postgres for now", 0.8)
+	if len(matches) != 2 || matches[0].ID != "ins-1" {
+		t.Errorf("expected ins-1 and ins-4, got %+v", matches)
+	}
+	if m := ix.Similar("question", "Keep sessions in Postgres for now", 0.5); len(m) != 0 {
+		t.Errorf("other keys should not match, got %+v", m)
+	}
+
+	pairs := ix.Pairs(DefaultThreshold)
+	if len(pairs) != 1 || pairs[0].A != "ins-1" || pairs[0].B != "ins-4" || pairs[0].Similarity != 1 {
+		t.Errorf("expected one pair ins-1/ins-4, got %+v", pairs)
+	}
+}
+
+func TestIndexBelowLSHFloor(t *testing.T) {
+	ix := NewIndex()
+	ix.Add("ins-1", "decision", "alpha beta gamma delta epsilon zeta")
+	ix.Add("ins-2", "decision", "alpha beta gamma theta iota kappa")
+	ix.Add("ins-3", "discovery", "alpha beta gamma delta epsilon zeta")
+
+	pairs := ix.Pairs(0.3)
+	if len(pairs) != 1 || pairs[0].A != "ins-1" || pairs[0].B != "ins-2" {
+		t.Errorf("expected the 0.33 pair ins-1/ins-2, got %+v", pairs)
+	}
+	if m := ix.Similar("decision", "alpha beta gamma theta iota kappa", 0.3); len(m) != 2 || m[0].ID != "ins-2" {
+		t.Errorf("expected ins-2 then ins-1, got %+v", m)
+	}
+}
+
+func TestIndexRecallAtDedupeThreshold(t *testing.T) {
+	// 100 pairs sharing 6 of 10 words: each has a similarity of exactly 0.6
+	ix := NewIndex()
+	for i := 0; i < 100; i++ {
+		var a, b string
+		for w := 0; w < 8; w++ {
+			a += fmt.Sprintf(" p%dw%d", i, w)
+		}
+		for w := 0; w < 6; w++ {
+			b += fmt.Sprintf(" p%dw%d", i, w)
+		}
+		b += fmt.Sprintf(" p%dx0 p%dx1", i, i)
+		ix.Add(fmt.Sprintf("ins-%da", i), "decision", a)
+		ix.Add(fmt.Sprintf("ins-%db", i), "decision", b)
+	}
+
+	if pairs := ix.Pairs(0.6); len(pairs) != 100 {
+		t.Errorf("expected all 100 pairs at threshold 0.6, got %d", len(pairs))
+	}
+}
@@ -0,0 +1,28 @@
+package similar
+
+import "strings"
+
+// Terms returns how often each of text's words occurs. Plurals are folded
+// into the singular, so "caches" and "cache" are the same term.
+func Terms(text string) map[string]int {
+	terms := make(map[string]int)
+	for _, w := range Words(text) {
+		terms[stem(w)]++
+	}
+	return terms
+}
+
+// stem strips common plural endings.
+func stem(w string) string {
+	switch {
+	case len(w) <= 3:
+		return w
+	case strings.HasSuffix(w, "ies") && len(w) > 4:
+		return w[:len(w)-3] + "y"
+	case strings.HasSuffix(w, "ss"), strings.HasSuffix(w, "us"), strings.HasSuffix(w, "is"):
+		return w
+	case strings.HasSuffix(w, "s"):
+		return w[:len(w)-1]
+	}
+	return w
+}
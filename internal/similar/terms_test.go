@@ -0,0 +1,16 @@
+package similar
+
+import "testing"
+
+func TestTerms(t *testing.T) {
+	got := Terms("The caches and the cache; retries, retry. Redis is fast")
+	want := map[string]int{"cache": 2, "retry": 2, "redis": 1, "fast": 1}
+	if len(got) != len(want) {
+		t.Fatalf("Terms = %v, want %v", got, want)
+	}
+	for term, n := range want {
+		if got[term] != n {
+			t.Errorf("Terms[%q] = %d, want %d", term, got[term], n)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/brianevanmiller/beadcrumbs/internal/classify"
 	importer "github.com/brianevanmiller/beadcrumbs/internal/import"
 	"github.com/brianevanmiller/beadcrumbs/internal/types"
 )
@@ -49,7 +50,7 @@ func ConvertMessages(messages []Message, opts ConvertOptions) []*types.Insight {
 		ts := importer.ParseSlackTimestamp(msg.Timestamp)
 
 		// Detect insight type
-		insightType := importer.DetectInsightType(text)
+		res := classify.Default().Classify(text, "slack")
 
 		// Resolve user name
 		var authorID string
@@ -74,8 +75,8 @@ func ConvertMessages(messages []Message, opts ConvertOptions) []*types.Insight {
 			Timestamp:  ts,
 			Content:    text,
 			Summary:    importer.Truncate(text, 80),
-			Type:       insightType,
-			Confidence: 0.6, // Lower confidence for Slack messages
+			Type:       res.Type,
+			Confidence: float32(res.Confidence),
 			Source: types.InsightSource{
 				Type:         sourceType,
 				Ref:          sourceRef,
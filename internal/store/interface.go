@@ -23,23 +23,32 @@ type Storage interface {
 	DeleteInsight(id string) error
 	ListInsights(threadID string, insightType types.InsightType, since time.Time, sourceRef string) ([]*types.Insight, error)
 	SearchInsights(query string) ([]*types.Insight, error)
+	SearchInsightsRanked(query, threadID string, limit int) ([]*ScoredInsight, error)
 	ListInsightsByAuthor(authorID string) ([]*types.Insight, error)
 	UpsertInsight(insight *types.Insight) error
+	MoveInsights(insightIDs []string, threadID string) error
+	MergeInsights(dupID, keepID string) error
 
 	// Thread operations
 	CreateThread(thread *types.InsightThread) error
 	GetThread(id string) (*types.InsightThread, error)
 	UpdateThread(thread *types.InsightThread) error
 	ListThreads(status types.ThreadStatus) ([]*types.InsightThread, error)
+	ListChildThreads(parentID string) ([]*types.InsightThread, error)
+	MergeThreads(srcID, dstID string) error
 	UpsertThread(thread *types.InsightThread) error
 
 	// Dependency operations
 	AddDependency(dep *types.Dependency) error
+	RemoveDependency(fromID, toID string, depType types.DependencyType) error
 	GetDependencies(fromID string) ([]*types.Dependency, error)
 	GetDependents(toID string) ([]*types.Dependency, error)
 	ListAllDependencies() ([]*types.Dependency, error)
 	UpsertDependency(dep *types.Dependency) error
 
+	// Bulk operations
+	ApplyRecords(remove, upsert Records) error
+
 	// Config operations
 	GetConfig(key string) (string, error)
 	SetConfig(key, value string) error
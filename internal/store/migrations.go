@@ -25,6 +25,11 @@ var migrationsList = []Migration{
 	{"006_migrate_bead_thread_ids", migrateBeadThreadIDs},
 	{"007_insights_source_ref_index", migrateInsightsSourceRefIndex},
 	{"008_insights_content_hash", migrateInsightsContentHash},
+	{"009_threads_parent_id", migrateThreadsParentID},
+	{"010_threads_merged_into", migrateThreadsMergedInto},
+	{"011_threads_understanding_history", migrateThreadsUnderstandingHistory},
+	{"012_insights_anchors", migrateInsightsAnchors},
+	{"013_insights_source_git", migrateInsightsSourceGit},
 }
 
 // RunMigrations runs all database migrations.
@@ -373,3 +378,124 @@ func migrateInsightsContentHash(db *sql.DB) error {
 
 	return nil
 }
+
+// migrateThreadsParentID adds the parent_id column to the threads table so
+// threads can be nested as sub-investigations. No foreign key is declared:
+// JSONL import may load a child before its parent.
+func migrateThreadsParentID(db *sql.DB) error {
+	var count int
+	err := db.QueryRow(`
+		SELECT COUNT(*) FROM pragma_table_info('threads')
+		WHERE name = 'parent_id'
+	`).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("failed to check for parent_id column: %w", err)
+	}
+	if count > 0 {
+		return nil // Already migrated.
+	}
+
+	_, err = db.Exec(`ALTER TABLE threads ADD COLUMN parent_id TEXT`)
+	if err != nil {
+		return fmt.Errorf("failed to add parent_id column: %w", err)
+	}
+
+	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_threads_parent_id ON threads(parent_id)`)
+	if err != nil {
+		return fmt.Errorf("failed to create parent_id index: %w", err)
+	}
+
+	return nil
+}
+
+// migrateThreadsMergedInto adds the merged_into column to the threads table.
+// A merged thread is kept as a redirect so its old ID keeps resolving.
+func migrateThreadsMergedInto(db *sql.DB) error {
+	var count int
+	err := db.QueryRow(`
+		SELECT COUNT(*) FROM pragma_table_info('threads')
+		WHERE name = 'merged_into'
+	`).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("failed to check for merged_into column: %w", err)
+	}
+	if count > 0 {
+		return nil // Already migrated.
+	}
+
+	_, err = db.Exec(`ALTER TABLE threads ADD COLUMN merged_into TEXT`)
+	if err != nil {
+		return fmt.Errorf("failed to add merged_into column: %w", err)
+	}
+
+	return nil
+}
+
+// migrateThreadsUnderstandingHistory adds the understanding_history column
+// (a JSON array of revisions) to the threads table.
+func migrateThreadsUnderstandingHistory(db *sql.DB) error {
+	var count int
+	err := db.QueryRow(`
+		SELECT COUNT(*) FROM pragma_table_info('threads')
+		WHERE name = 'understanding_history'
+	`).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("failed to check for understanding_history column: %w", err)
+	}
+	if count > 0 {
+		return nil // Already migrated.
+	}
+
+	_, err = db.Exec(`ALTER TABLE threads ADD COLUMN understanding_history TEXT`)
+	if err != nil {
+		return fmt.Errorf("failed to add understanding_history column: %w", err)
+	}
+
+	return nil
+}
+
+// migrateInsightsAnchors adds the anchors column (JSON list of code anchors)
+// to the insights table.
+func migrateInsightsAnchors(db *sql.DB) error {
+	var count int
+	err := db.QueryRow(`
+		SELECT COUNT(*) FROM pragma_table_info('insights')
+		WHERE name = 'anchors'
+	`).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("failed to check for anchors column: %w", err)
+	}
+	if count > 0 {
+		return nil // Already migrated.
+	}
+
+	_, err = db.Exec(`ALTER TABLE insights ADD COLUMN anchors TEXT`)
+	if err != nil {
+		return fmt.Errorf("failed to add anchors column: %w", err)
+	}
+
+	return nil
+}
+
+// migrateInsightsSourceGit adds the source_git column (JSON git context
+// recorded at capture time) to the insights table.
+func migrateInsightsSourceGit(db *sql.DB) error {
+	var count int
+	err := db.QueryRow(`
+		SELECT COUNT(*) FROM pragma_table_info('insights')
+		WHERE name = 'source_git'
+	`).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("failed to check for source_git column: %w", err)
+	}
+	if count > 0 {
+		return nil // Already migrated.
+	}
+
+	_, err = db.Exec(`ALTER TABLE insights ADD COLUMN source_git TEXT`)
+	if err != nil {
+		return fmt.Errorf("failed to add source_git column: %w", err)
+	}
+
+	return nil
+}
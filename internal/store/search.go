@@ -0,0 +1,120 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/similar"
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+)
+
+// ScoredInsight is an insight with its BM25 relevance to a query.
+type ScoredInsight struct {
+	*types.Insight
+	Score float64 `json:"score"`
+}
+
+// MatchAny returns an FTS5 query for the insights containing any word of
+// text. Words match as prefixes, in their plural-folded form as well, so
+// "policies" finds "policy" and "cache" finds "caches".
+func MatchAny(text string) string {
+	seen := make(map[string]bool)
+	var parts []string
+	for _, w := range similar.Words(text) {
+		forms := []string{w}
+		for t := range similar.Terms(w) {
+			forms = append(forms, t)
+		}
+		for _, f := range forms {
+			if !seen[f] {
+				seen[f] = true
+				parts = append(parts, `"`+f+`"*`)
+			}
+		}
+	}
+	return strings.Join(parts, " OR ")
+}
+
+// rankExpr scores a row of insights_fts by BM25 over content and summary
+// (the id column is given no weight). FTS5 returns lower values for better
+// matches.
+const rankExpr = `bm25(insights_fts, 0.0, 1.0, 1.0)`
+
+// SearchInsights performs a full-text search across insight content and summaries.
+func (s *Store) SearchInsights(query string) ([]*types.Insight, error) {
+	// Use FTS5 for full-text search
+	rows, err := s.db.Query(`
+		SELECT i.id, i.timestamp, i.content, i.summary, i.type, i.confidence,
+		       i.source_type, i.source_ref, i.source_participants,
+		       i.thread_id, i.author_id, i.endorsed_by, i.tags, i.created_by, i.created_at,
+		       i.content_hash, i.anchors, i.source_git
+		FROM insights i
+		JOIN insights_fts fts ON i.rowid = fts.rowid
+		WHERE insights_fts MATCH ?
+		ORDER BY rank
+	`, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search insights: %w", err)
+	}
+	defer rows.Close()
+
+	var insights []*types.Insight
+	for rows.Next() {
+		insight, err := scanInsight(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan insight: %w", err)
+		}
+		insights = append(insights, insight)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating search results: %w", err)
+	}
+
+	return insights, nil
+}
+
+// SearchInsightsRanked performs a full-text search like SearchInsights and
+// returns the matches most relevant first, each with its BM25 score. Pass a
+// threadID to search only that thread's insights, and a limit of 0 for
+// every match.
+func (s *Store) SearchInsightsRanked(query, threadID string, limit int) ([]*ScoredInsight, error) {
+	sqlQuery := `
+		SELECT i.id, i.timestamp, i.content, i.summary, i.type, i.confidence,
+		       i.source_type, i.source_ref, i.source_participants,
+		       i.thread_id, i.author_id, i.endorsed_by, i.tags, i.created_by, i.created_at,
+		       i.content_hash, i.anchors, i.source_git, -` + rankExpr + `
+		FROM insights_fts
+		JOIN insights i ON i.rowid = insights_fts.rowid
+		WHERE insights_fts MATCH ?`
+	args := []interface{}{query}
+	if threadID != "" {
+		sqlQuery += ` AND i.thread_id = ?`
+		args = append(args, threadID)
+	}
+	sqlQuery += ` ORDER BY ` + rankExpr + `, i.id`
+	if limit > 0 {
+		sqlQuery += ` LIMIT ?`
+		args = append(args, limit)
+	}
+
+	rows, err := s.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search insights: %w", err)
+	}
+	defer rows.Close()
+
+	var ranked []*ScoredInsight
+	for rows.Next() {
+		var score float64
+		insight, err := scanInsight(rows, &score)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %w", err)
+		}
+		ranked = append(ranked, &ScoredInsight{Insight: insight, Score: score})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating search results: %w", err)
+	}
+	return ranked, nil
+}
@@ -3,6 +3,7 @@ package store
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -11,6 +12,15 @@ import (
 	_ "modernc.org/sqlite"
 )
 
+// ErrDuplicateInsight is returned by CreateInsight when an insight with
+// identical content already exists.
+var ErrDuplicateInsight = errors.New("duplicate insight")
+
+// execer is satisfied by *sql.DB and *sql.Tx.
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
 // Store provides SQLite persistence for insights, threads, and dependencies.
 type Store struct {
 	db *sql.DB
@@ -85,7 +95,7 @@ func (s *Store) CreateInsight(insight *types.Insight) error {
 	var existingID string
 	err := s.db.QueryRow(`SELECT id FROM insights WHERE content_hash = ? LIMIT 1`, hash).Scan(&existingID)
 	if err == nil {
-		return fmt.Errorf("duplicate insight: identical content already captured as %s", existingID)
+		return fmt.Errorf("%w: identical content already captured as %s", ErrDuplicateInsight, existingID)
 	}
 	if err != sql.ErrNoRows {
 		return fmt.Errorf("failed to check for duplicate insight: %w", err)
@@ -107,6 +117,16 @@ func (s *Store) CreateInsight(insight *types.Insight) error {
 		return fmt.Errorf("failed to marshal endorsed_by: %w", err)
 	}
 
+	anchors, err := marshalAnchors(insight)
+	if err != nil {
+		return err
+	}
+
+	sourceGit, err := marshalSourceGit(insight)
+	if err != nil {
+		return err
+	}
+
 	// Handle nullable fields - convert empty strings to sql.NullString
 	var threadID interface{}
 	if insight.ThreadID == "" {
@@ -126,8 +146,9 @@ func (s *Store) CreateInsight(insight *types.Insight) error {
 		INSERT INTO insights (
 			id, timestamp, content, summary, type, confidence,
 			source_type, source_ref, source_participants,
-			thread_id, author_id, endorsed_by, tags, created_by, created_at, content_hash
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			thread_id, author_id, endorsed_by, tags, created_by, created_at, content_hash,
+			anchors, source_git
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`,
 		insight.ID,
 		insight.Timestamp,
@@ -145,6 +166,8 @@ func (s *Store) CreateInsight(insight *types.Insight) error {
 		insight.CreatedBy,
 		insight.CreatedAt,
 		insight.ContentHash,
+		anchors,
+		sourceGit,
 	)
 
 	if err != nil {
@@ -156,75 +179,22 @@ func (s *Store) CreateInsight(insight *types.Insight) error {
 
 // GetInsight retrieves an insight by ID.
 func (s *Store) GetInsight(id string) (*types.Insight, error) {
-	var insight types.Insight
-	var sourceParticipantsJSON, tagsJSON, endorsedByJSON sql.NullString
-	var authorID, threadID, contentHash sql.NullString
-
-	err := s.db.QueryRow(`
+	insight, err := scanInsight(s.db.QueryRow(`
 		SELECT
 			id, timestamp, content, summary, type, confidence,
 			source_type, source_ref, source_participants,
 			thread_id, author_id, endorsed_by, tags, created_by, created_at,
-			content_hash
+			content_hash, anchors, source_git
 		FROM insights
 		WHERE id = ?
-	`, id).Scan(
-		&insight.ID,
-		&insight.Timestamp,
-		&insight.Content,
-		&insight.Summary,
-		&insight.Type,
-		&insight.Confidence,
-		&insight.Source.Type,
-		&insight.Source.Ref,
-		&sourceParticipantsJSON,
-		&threadID,
-		&authorID,
-		&endorsedByJSON,
-		&tagsJSON,
-		&insight.CreatedBy,
-		&insight.CreatedAt,
-		&contentHash,
-	)
-
+	`, id))
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("insight not found: %s", id)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to query insight: %w", err)
 	}
-
-	// Handle nullable fields
-	if authorID.Valid {
-		insight.AuthorID = authorID.String
-	}
-	if threadID.Valid {
-		insight.ThreadID = threadID.String
-	}
-	if contentHash.Valid {
-		insight.ContentHash = contentHash.String
-	}
-
-	// Deserialize complex fields
-	if sourceParticipantsJSON.Valid && sourceParticipantsJSON.String != "" {
-		if err := json.Unmarshal([]byte(sourceParticipantsJSON.String), &insight.Source.Participants); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal source participants: %w", err)
-		}
-	}
-
-	if tagsJSON.Valid && tagsJSON.String != "" {
-		if err := json.Unmarshal([]byte(tagsJSON.String), &insight.Tags); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal tags: %w", err)
-		}
-	}
-
-	if endorsedByJSON.Valid && endorsedByJSON.String != "" {
-		if err := json.Unmarshal([]byte(endorsedByJSON.String), &insight.EndorsedBy); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal endorsed_by: %w", err)
-		}
-	}
-
-	return &insight, nil
+	return insight, nil
 }
 
 // UpdateInsight updates an existing insight.
@@ -245,6 +215,16 @@ func (s *Store) UpdateInsight(insight *types.Insight) error {
 		return fmt.Errorf("failed to marshal endorsed_by: %w", err)
 	}
 
+	anchors, err := marshalAnchors(insight)
+	if err != nil {
+		return err
+	}
+
+	sourceGit, err := marshalSourceGit(insight)
+	if err != nil {
+		return err
+	}
+
 	// Handle nullable fields - convert empty strings to nil
 	var threadID interface{}
 	if insight.ThreadID == "" {
@@ -260,6 +240,9 @@ func (s *Store) UpdateInsight(insight *types.Insight) error {
 		authorID = insight.AuthorID
 	}
 
+	// Thread and content changes alter the dedup hash, so keep it current.
+	insight.ContentHash = insight.ComputeContentHash()
+
 	result, err := s.db.Exec(`
 		UPDATE insights SET
 			timestamp = ?,
@@ -275,7 +258,10 @@ func (s *Store) UpdateInsight(insight *types.Insight) error {
 			endorsed_by = ?,
 			tags = ?,
 			created_by = ?,
-			created_at = ?
+			created_at = ?,
+			content_hash = ?,
+			anchors = ?,
+			source_git = ?
 		WHERE id = ?
 	`,
 		insight.Timestamp,
@@ -292,6 +278,9 @@ func (s *Store) UpdateInsight(insight *types.Insight) error {
 		string(tags),
 		insight.CreatedBy,
 		insight.CreatedAt,
+		insight.ContentHash,
+		anchors,
+		sourceGit,
 		insight.ID,
 	)
 
@@ -313,7 +302,11 @@ func (s *Store) UpdateInsight(insight *types.Insight) error {
 
 // DeleteInsight removes an insight from the database.
 func (s *Store) DeleteInsight(id string) error {
-	result, err := s.db.Exec("DELETE FROM insights WHERE id = ?", id)
+	return deleteInsight(s.db, id)
+}
+
+func deleteInsight(ex execer, id string) error {
+	result, err := ex.Exec("DELETE FROM insights WHERE id = ?", id)
 	if err != nil {
 		return fmt.Errorf("failed to delete insight: %w", err)
 	}
@@ -330,11 +323,114 @@ func (s *Store) DeleteInsight(id string) error {
 	return nil
 }
 
+// MergeInsights merges the near-duplicate dupID into keepID: keepID takes
+// over dupID's links, labels and endorsements, and dupID gets a duplicates
+// link to keepID. Both insights are kept, so dupID still resolves.
+func (s *Store) MergeInsights(dupID, keepID string) error {
+	if dupID == keepID {
+		return fmt.Errorf("cannot merge insight %s into itself", dupID)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var tags, endorsed [2][]string
+	for i, id := range []string{keepID, dupID} {
+		var tagsJSON, endorsedJSON sql.NullString
+		err := tx.QueryRow(`SELECT tags, endorsed_by FROM insights WHERE id = ?`, id).Scan(&tagsJSON, &endorsedJSON)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("insight not found: %s", id)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to query insight: %w", err)
+		}
+		if tagsJSON.Valid && tagsJSON.String != "" {
+			if err := json.Unmarshal([]byte(tagsJSON.String), &tags[i]); err != nil {
+				return fmt.Errorf("failed to unmarshal tags: %w", err)
+			}
+		}
+		if endorsedJSON.Valid && endorsedJSON.String != "" {
+			if err := json.Unmarshal([]byte(endorsedJSON.String), &endorsed[i]); err != nil {
+				return fmt.Errorf("failed to unmarshal endorsed_by: %w", err)
+			}
+		}
+	}
+
+	mergedTags := appendMissing(tags[0], tags[1])
+	mergedEndorsed := appendMissing(endorsed[0], endorsed[1])
+	if len(mergedTags) != len(tags[0]) || len(mergedEndorsed) != len(endorsed[0]) {
+		tagsJSON, err := json.Marshal(mergedTags)
+		if err != nil {
+			return fmt.Errorf("failed to marshal tags: %w", err)
+		}
+		endorsedJSON, err := json.Marshal(mergedEndorsed)
+		if err != nil {
+			return fmt.Errorf("failed to marshal endorsed_by: %w", err)
+		}
+		if _, err := tx.Exec(`UPDATE insights SET tags = ?, endorsed_by = ? WHERE id = ?`, string(tagsJSON), string(endorsedJSON), keepID); err != nil {
+			return fmt.Errorf("failed to update insight: %w", err)
+		}
+	}
+
+	// Move dupID's links, except its own duplicates links, to keepID.
+	if _, err := tx.Exec(`
+		INSERT INTO dependencies (from_id, to_id, type, created_at)
+		SELECT ?, to_id, type, created_at FROM dependencies
+		WHERE from_id = ? AND type != ? AND to_id != ?
+		ON CONFLICT(from_id, to_id, type) DO NOTHING
+	`, keepID, dupID, types.DepDuplicates, keepID); err != nil {
+		return fmt.Errorf("failed to move links: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM dependencies WHERE from_id = ? AND type != ?`, dupID, types.DepDuplicates); err != nil {
+		return fmt.Errorf("failed to move links: %w", err)
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO dependencies (from_id, to_id, type, created_at)
+		SELECT from_id, ?, type, created_at FROM dependencies
+		WHERE to_id = ? AND from_id != ?
+		ON CONFLICT(from_id, to_id, type) DO NOTHING
+	`, keepID, dupID, keepID); err != nil {
+		return fmt.Errorf("failed to move links: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM dependencies WHERE to_id = ?`, dupID); err != nil {
+		return fmt.Errorf("failed to move links: %w", err)
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO dependencies (from_id, to_id, type, created_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(from_id, to_id, type) DO NOTHING
+	`, dupID, keepID, types.DepDuplicates, time.Now()); err != nil {
+		return fmt.Errorf("failed to link %s to %s: %w", dupID, keepID, err)
+	}
+
+	return tx.Commit()
+}
+
+// appendMissing appends the values of b missing from a.
+func appendMissing(a, b []string) []string {
+	seen := make(map[string]bool, len(a))
+	for _, v := range a {
+		seen[v] = true
+	}
+	out := a
+	for _, v := range b {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
 // ListInsights retrieves insights based on filters.
 // Pass empty string for threadID, insightType, or sourceRef to skip that filter.
 // Pass zero time for since to skip time filter.
 func (s *Store) ListInsights(threadID string, insightType types.InsightType, since time.Time, sourceRef string) ([]*types.Insight, error) {
-	query := "SELECT id, timestamp, content, summary, type, confidence, source_type, source_ref, source_participants, thread_id, author_id, endorsed_by, tags, created_by, created_at, content_hash FROM insights WHERE 1=1"
+	query := "SELECT id, timestamp, content, summary, type, confidence, source_type, source_ref, source_participants, thread_id, author_id, endorsed_by, tags, created_by, created_at, content_hash, anchors, source_git FROM insights WHERE 1=1"
 	args := []interface{}{}
 
 	if threadID != "" {
@@ -367,64 +463,11 @@ func (s *Store) ListInsights(threadID string, insightType types.InsightType, sin
 
 	var insights []*types.Insight
 	for rows.Next() {
-		var insight types.Insight
-		var sourceParticipantsJSON, tagsJSON sql.NullString
-		var endorsedByJSON sql.NullString
-		var authorID, threadID, contentHash sql.NullString
-
-		err := rows.Scan(
-			&insight.ID,
-			&insight.Timestamp,
-			&insight.Content,
-			&insight.Summary,
-			&insight.Type,
-			&insight.Confidence,
-			&insight.Source.Type,
-			&insight.Source.Ref,
-			&sourceParticipantsJSON,
-			&threadID,
-			&authorID,
-			&endorsedByJSON,
-			&tagsJSON,
-			&insight.CreatedBy,
-			&insight.CreatedAt,
-			&contentHash,
-		)
+		insight, err := scanInsight(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan insight: %w", err)
 		}
-
-		// Handle nullable fields
-		if authorID.Valid {
-			insight.AuthorID = authorID.String
-		}
-		if threadID.Valid {
-			insight.ThreadID = threadID.String
-		}
-		if contentHash.Valid {
-			insight.ContentHash = contentHash.String
-		}
-
-		// Deserialize complex fields
-		if sourceParticipantsJSON.Valid && sourceParticipantsJSON.String != "" {
-			if err := json.Unmarshal([]byte(sourceParticipantsJSON.String), &insight.Source.Participants); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal source participants: %w", err)
-			}
-		}
-
-		if tagsJSON.Valid && tagsJSON.String != "" {
-			if err := json.Unmarshal([]byte(tagsJSON.String), &insight.Tags); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal tags: %w", err)
-			}
-		}
-
-		if endorsedByJSON.Valid && endorsedByJSON.String != "" {
-			if err := json.Unmarshal([]byte(endorsedByJSON.String), &insight.EndorsedBy); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal endorsed_by: %w", err)
-			}
-		}
-
-		insights = append(insights, &insight)
+		insights = append(insights, insight)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -434,102 +477,24 @@ func (s *Store) ListInsights(threadID string, insightType types.InsightType, sin
 	return insights, nil
 }
 
-// SearchInsights performs a full-text search across insight content and summaries.
-func (s *Store) SearchInsights(query string) ([]*types.Insight, error) {
-	// Use FTS5 for full-text search
-	rows, err := s.db.Query(`
-		SELECT i.id, i.timestamp, i.content, i.summary, i.type, i.confidence,
-		       i.source_type, i.source_ref, i.source_participants,
-		       i.thread_id, i.author_id, i.endorsed_by, i.tags, i.created_by, i.created_at,
-		       i.content_hash
-		FROM insights i
-		JOIN insights_fts fts ON i.rowid = fts.rowid
-		WHERE insights_fts MATCH ?
-		ORDER BY rank
-	`, query)
+// CreateThread inserts a new thread into the database.
+func (s *Store) CreateThread(thread *types.InsightThread) error {
+	history, err := marshalUnderstandingHistory(thread)
 	if err != nil {
-		return nil, fmt.Errorf("failed to search insights: %w", err)
-	}
-	defer rows.Close()
-
-	var insights []*types.Insight
-	for rows.Next() {
-		var insight types.Insight
-		var sourceParticipantsJSON, tagsJSON, endorsedByJSON sql.NullString
-		var authorID, threadID, contentHash sql.NullString
-
-		err := rows.Scan(
-			&insight.ID,
-			&insight.Timestamp,
-			&insight.Content,
-			&insight.Summary,
-			&insight.Type,
-			&insight.Confidence,
-			&insight.Source.Type,
-			&insight.Source.Ref,
-			&sourceParticipantsJSON,
-			&threadID,
-			&authorID,
-			&endorsedByJSON,
-			&tagsJSON,
-			&insight.CreatedBy,
-			&insight.CreatedAt,
-			&contentHash,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan insight: %w", err)
-		}
-
-		// Handle nullable fields
-		if authorID.Valid {
-			insight.AuthorID = authorID.String
-		}
-		if threadID.Valid {
-			insight.ThreadID = threadID.String
-		}
-		if contentHash.Valid {
-			insight.ContentHash = contentHash.String
-		}
-
-		// Deserialize complex fields
-		if sourceParticipantsJSON.Valid && sourceParticipantsJSON.String != "" {
-			if err := json.Unmarshal([]byte(sourceParticipantsJSON.String), &insight.Source.Participants); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal source participants: %w", err)
-			}
-		}
-
-		if tagsJSON.Valid && tagsJSON.String != "" {
-			if err := json.Unmarshal([]byte(tagsJSON.String), &insight.Tags); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal tags: %w", err)
-			}
-		}
-
-		if endorsedByJSON.Valid && endorsedByJSON.String != "" {
-			if err := json.Unmarshal([]byte(endorsedByJSON.String), &insight.EndorsedBy); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal endorsed_by: %w", err)
-			}
-		}
-
-		insights = append(insights, &insight)
+		return err
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating search results: %w", err)
-	}
-
-	return insights, nil
-}
-
-// CreateThread inserts a new thread into the database.
-func (s *Store) CreateThread(thread *types.InsightThread) error {
-	_, err := s.db.Exec(`
-		INSERT INTO threads (id, title, status, current_understanding, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?)
+	_, err = s.db.Exec(`
+		INSERT INTO threads (id, title, status, parent_id, merged_into, current_understanding, understanding_history, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`,
 		thread.ID,
 		thread.Title,
 		thread.Status,
+		nullableString(thread.ParentID),
+		nullableString(thread.MergedInto),
 		thread.CurrentUnderstanding,
+		history,
 		thread.CreatedAt,
 		thread.UpdatedAt,
 	)
@@ -544,16 +509,20 @@ func (s *Store) CreateThread(thread *types.InsightThread) error {
 // GetThread retrieves a thread by ID.
 func (s *Store) GetThread(id string) (*types.InsightThread, error) {
 	var thread types.InsightThread
+	var historyJSON sql.NullString
 
 	err := s.db.QueryRow(`
-		SELECT id, title, status, current_understanding, created_at, updated_at
+		SELECT id, title, status, COALESCE(parent_id, ''), COALESCE(merged_into, ''), current_understanding, understanding_history, created_at, updated_at
 		FROM threads
 		WHERE id = ?
 	`, id).Scan(
 		&thread.ID,
 		&thread.Title,
 		&thread.Status,
+		&thread.ParentID,
+		&thread.MergedInto,
 		&thread.CurrentUnderstanding,
+		&historyJSON,
 		&thread.CreatedAt,
 		&thread.UpdatedAt,
 	)
@@ -565,22 +534,37 @@ func (s *Store) GetThread(id string) (*types.InsightThread, error) {
 		return nil, fmt.Errorf("failed to query thread: %w", err)
 	}
 
+	if err := unmarshalUnderstandingHistory(historyJSON, &thread); err != nil {
+		return nil, err
+	}
+
 	return &thread, nil
 }
 
 // UpdateThread updates an existing thread.
 func (s *Store) UpdateThread(thread *types.InsightThread) error {
+	history, err := marshalUnderstandingHistory(thread)
+	if err != nil {
+		return err
+	}
+
 	result, err := s.db.Exec(`
 		UPDATE threads SET
 			title = ?,
 			status = ?,
+			parent_id = ?,
+			merged_into = ?,
 			current_understanding = ?,
+			understanding_history = ?,
 			updated_at = ?
 		WHERE id = ?
 	`,
 		thread.Title,
 		thread.Status,
+		nullableString(thread.ParentID),
+		nullableString(thread.MergedInto),
 		thread.CurrentUnderstanding,
+		history,
 		thread.UpdatedAt,
 		thread.ID,
 	)
@@ -604,7 +588,7 @@ func (s *Store) UpdateThread(thread *types.InsightThread) error {
 // ListThreads retrieves threads, optionally filtered by status.
 // Pass empty string for status to retrieve all threads.
 func (s *Store) ListThreads(status types.ThreadStatus) ([]*types.InsightThread, error) {
-	query := "SELECT id, title, status, current_understanding, created_at, updated_at FROM threads"
+	query := "SELECT id, title, status, COALESCE(parent_id, ''), COALESCE(merged_into, ''), current_understanding, understanding_history, created_at, updated_at FROM threads"
 	args := []interface{}{}
 
 	if status != "" {
@@ -623,17 +607,24 @@ func (s *Store) ListThreads(status types.ThreadStatus) ([]*types.InsightThread,
 	var threads []*types.InsightThread
 	for rows.Next() {
 		var thread types.InsightThread
+		var historyJSON sql.NullString
 		err := rows.Scan(
 			&thread.ID,
 			&thread.Title,
 			&thread.Status,
+			&thread.ParentID,
+			&thread.MergedInto,
 			&thread.CurrentUnderstanding,
+			&historyJSON,
 			&thread.CreatedAt,
 			&thread.UpdatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan thread: %w", err)
 		}
+		if err := unmarshalUnderstandingHistory(historyJSON, &thread); err != nil {
+			return nil, err
+		}
 		threads = append(threads, &thread)
 	}
 
@@ -644,6 +635,161 @@ func (s *Store) ListThreads(status types.ThreadStatus) ([]*types.InsightThread,
 	return threads, nil
 }
 
+// ListChildThreads retrieves the direct children of a thread, oldest first.
+func (s *Store) ListChildThreads(parentID string) ([]*types.InsightThread, error) {
+	rows, err := s.db.Query(`
+		SELECT id, title, status, COALESCE(parent_id, ''), COALESCE(merged_into, ''), current_understanding, understanding_history, created_at, updated_at
+		FROM threads
+		WHERE parent_id = ?
+		ORDER BY created_at ASC
+	`, parentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query child threads: %w", err)
+	}
+	defer rows.Close()
+
+	var threads []*types.InsightThread
+	for rows.Next() {
+		var thread types.InsightThread
+		var historyJSON sql.NullString
+		err := rows.Scan(
+			&thread.ID,
+			&thread.Title,
+			&thread.Status,
+			&thread.ParentID,
+			&thread.MergedInto,
+			&thread.CurrentUnderstanding,
+			&historyJSON,
+			&thread.CreatedAt,
+			&thread.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan thread: %w", err)
+		}
+		if err := unmarshalUnderstandingHistory(historyJSON, &thread); err != nil {
+			return nil, err
+		}
+		threads = append(threads, &thread)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating child threads: %w", err)
+	}
+
+	return threads, nil
+}
+
+// MoveInsights reassigns insights to a thread. Content hashes include the
+// thread ID, so they are recomputed. Insight IDs (and therefore dependency
+// edges) are unchanged.
+func (s *Store) MoveInsights(insightIDs []string, threadID string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, id := range insightIDs {
+		if err := moveInsightTx(tx, id, threadID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// MergeThreads moves everything from srcID into dstID: insights, external ref
+// mappings, and child threads. The source thread is kept with status merged
+// and merged_into set, so its ID still resolves.
+func (s *Store) MergeThreads(srcID, dstID string) error {
+	if srcID == dstID {
+		return fmt.Errorf("cannot merge thread %s into itself", srcID)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, id := range []string{srcID, dstID} {
+		var count int
+		if err := tx.QueryRow(`SELECT COUNT(*) FROM threads WHERE id = ?`, id).Scan(&count); err != nil {
+			return fmt.Errorf("failed to query thread: %w", err)
+		}
+		if count == 0 {
+			return fmt.Errorf("thread not found: %s", id)
+		}
+	}
+
+	rows, err := tx.Query(`SELECT id FROM insights WHERE thread_id = ?`, srcID)
+	if err != nil {
+		return fmt.Errorf("failed to query insights: %w", err)
+	}
+	var insightIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan insight ID: %w", err)
+		}
+		insightIDs = append(insightIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating insights: %w", err)
+	}
+
+	for _, id := range insightIDs {
+		if err := moveInsightTx(tx, id, dstID); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	if _, err := tx.Exec(`UPDATE external_ref_mappings SET thread_id = ?, updated_at = ? WHERE thread_id = ?`, dstID, now, srcID); err != nil {
+		return fmt.Errorf("failed to remap external refs: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE threads SET parent_id = ?, updated_at = ? WHERE parent_id = ? AND id != ?`, dstID, now, srcID, dstID); err != nil {
+		return fmt.Errorf("failed to reparent child threads: %w", err)
+	}
+	// Re-point older redirects so chains stay one hop long.
+	if _, err := tx.Exec(`UPDATE threads SET merged_into = ? WHERE merged_into = ?`, dstID, srcID); err != nil {
+		return fmt.Errorf("failed to update redirects: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE threads SET status = ?, merged_into = ?, updated_at = ? WHERE id = ?`, types.ThreadMerged, dstID, now, srcID); err != nil {
+		return fmt.Errorf("failed to mark thread merged: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE threads SET updated_at = ? WHERE id = ?`, now, dstID); err != nil {
+		return fmt.Errorf("failed to update thread: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// moveInsightTx sets one insight's thread and recomputes its content hash.
+func moveInsightTx(tx *sql.Tx, insightID, threadID string) error {
+	var ins types.Insight
+	var authorID sql.NullString
+	err := tx.QueryRow(`SELECT content, type, author_id FROM insights WHERE id = ?`, insightID).
+		Scan(&ins.Content, &ins.Type, &authorID)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("insight not found: %s", insightID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to query insight: %w", err)
+	}
+	ins.AuthorID = authorID.String
+	ins.ThreadID = threadID
+
+	_, err = tx.Exec(`UPDATE insights SET thread_id = ?, content_hash = ? WHERE id = ?`,
+		nullableString(threadID), ins.ComputeContentHash(), insightID)
+	if err != nil {
+		return fmt.Errorf("failed to move insight %s: %w", insightID, err)
+	}
+	return nil
+}
+
 // AddDependency creates a new dependency relationship.
 func (s *Store) AddDependency(dep *types.Dependency) error {
 	_, err := s.db.Exec(`
@@ -667,6 +813,31 @@ func (s *Store) AddDependency(dep *types.Dependency) error {
 	return nil
 }
 
+// RemoveDependency deletes a single dependency relationship.
+func (s *Store) RemoveDependency(fromID, toID string, depType types.DependencyType) error {
+	return removeDependency(s.db, fromID, toID, depType)
+}
+
+func removeDependency(ex execer, fromID, toID string, depType types.DependencyType) error {
+	result, err := ex.Exec(`
+		DELETE FROM dependencies WHERE from_id = ? AND to_id = ? AND type = ?
+	`, fromID, toID, depType)
+	if err != nil {
+		return fmt.Errorf("failed to delete dependency: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("dependency not found: %s -> %s (%s)", fromID, toID, depType)
+	}
+
+	return nil
+}
+
 // GetDependencies retrieves all dependencies where fromID is the source.
 func (s *Store) GetDependencies(fromID string) ([]*types.Dependency, error) {
 	rows, err := s.db.Query(`
@@ -761,7 +932,8 @@ func (s *Store) ListInsightsByAuthor(authorID string) ([]*types.Insight, error)
 	query := `
 		SELECT id, timestamp, content, summary, type, confidence,
 		       source_type, source_ref, source_participants,
-		       thread_id, author_id, endorsed_by, tags, created_by, created_at
+		       thread_id, author_id, endorsed_by, tags, created_by, created_at,
+		       anchors, source_git
 		FROM insights
 		WHERE author_id = ?
 		ORDER BY timestamp DESC
@@ -777,7 +949,7 @@ func (s *Store) ListInsightsByAuthor(authorID string) ([]*types.Insight, error)
 	for rows.Next() {
 		var insight types.Insight
 		var sourceParticipantsJSON, tagsJSON, endorsedByJSON sql.NullString
-		var authorID, threadID sql.NullString
+		var authorID, threadID, anchorsJSON, sourceGitJSON sql.NullString
 
 		err := rows.Scan(
 			&insight.ID,
@@ -795,6 +967,8 @@ func (s *Store) ListInsightsByAuthor(authorID string) ([]*types.Insight, error)
 			&tagsJSON,
 			&insight.CreatedBy,
 			&insight.CreatedAt,
+			&anchorsJSON,
+			&sourceGitJSON,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan insight: %w", err)
@@ -827,6 +1001,14 @@ func (s *Store) ListInsightsByAuthor(authorID string) ([]*types.Insight, error)
 			}
 		}
 
+		if err := unmarshalAnchors(anchorsJSON, &insight); err != nil {
+			return nil, err
+		}
+
+		if err := unmarshalSourceGit(sourceGitJSON, &insight); err != nil {
+			return nil, err
+		}
+
 		insights = append(insights, &insight)
 	}
 
@@ -994,6 +1176,10 @@ func (s *Store) ListOrigins() ([]*OriginSummary, error) {
 
 // UpsertInsight inserts or updates an insight by ID (for JSONL import).
 func (s *Store) UpsertInsight(insight *types.Insight) error {
+	return upsertInsight(s.db, insight)
+}
+
+func upsertInsight(ex execer, insight *types.Insight) error {
 	// Ensure content hash is set (may already be populated from JSONL).
 	if insight.ContentHash == "" {
 		insight.ContentHash = insight.ComputeContentHash()
@@ -1014,6 +1200,16 @@ func (s *Store) UpsertInsight(insight *types.Insight) error {
 		return fmt.Errorf("failed to marshal endorsed_by: %w", err)
 	}
 
+	anchors, err := marshalAnchors(insight)
+	if err != nil {
+		return err
+	}
+
+	sourceGit, err := marshalSourceGit(insight)
+	if err != nil {
+		return err
+	}
+
 	var threadID interface{}
 	if insight.ThreadID == "" {
 		threadID = nil
@@ -1028,12 +1224,13 @@ func (s *Store) UpsertInsight(insight *types.Insight) error {
 		authorID = insight.AuthorID
 	}
 
-	_, err = s.db.Exec(`
+	_, err = ex.Exec(`
 		INSERT INTO insights (
 			id, timestamp, content, summary, type, confidence,
 			source_type, source_ref, source_participants,
-			thread_id, author_id, endorsed_by, tags, created_by, created_at, content_hash
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			thread_id, author_id, endorsed_by, tags, created_by, created_at, content_hash,
+			anchors, source_git
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(id) DO UPDATE SET
 			timestamp = excluded.timestamp,
 			content = excluded.content,
@@ -1049,7 +1246,9 @@ func (s *Store) UpsertInsight(insight *types.Insight) error {
 			tags = excluded.tags,
 			created_by = excluded.created_by,
 			created_at = excluded.created_at,
-			content_hash = excluded.content_hash
+			content_hash = excluded.content_hash,
+			anchors = excluded.anchors,
+			source_git = excluded.source_git
 	`,
 		insight.ID,
 		insight.Timestamp,
@@ -1067,6 +1266,8 @@ func (s *Store) UpsertInsight(insight *types.Insight) error {
 		insight.CreatedBy,
 		insight.CreatedAt,
 		insight.ContentHash,
+		anchors,
+		sourceGit,
 	)
 
 	if err != nil {
@@ -1078,19 +1279,34 @@ func (s *Store) UpsertInsight(insight *types.Insight) error {
 
 // UpsertThread inserts or updates a thread by ID (for JSONL import).
 func (s *Store) UpsertThread(thread *types.InsightThread) error {
-	_, err := s.db.Exec(`
-		INSERT INTO threads (id, title, status, current_understanding, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?)
+	return upsertThread(s.db, thread)
+}
+
+func upsertThread(ex execer, thread *types.InsightThread) error {
+	history, err := marshalUnderstandingHistory(thread)
+	if err != nil {
+		return err
+	}
+
+	_, err = ex.Exec(`
+		INSERT INTO threads (id, title, status, parent_id, merged_into, current_understanding, understanding_history, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(id) DO UPDATE SET
 			title = excluded.title,
 			status = excluded.status,
+			parent_id = excluded.parent_id,
+			merged_into = excluded.merged_into,
 			current_understanding = excluded.current_understanding,
+			understanding_history = excluded.understanding_history,
 			updated_at = excluded.updated_at
 	`,
 		thread.ID,
 		thread.Title,
 		thread.Status,
+		nullableString(thread.ParentID),
+		nullableString(thread.MergedInto),
 		thread.CurrentUnderstanding,
+		history,
 		thread.CreatedAt,
 		thread.UpdatedAt,
 	)
@@ -1104,7 +1320,11 @@ func (s *Store) UpsertThread(thread *types.InsightThread) error {
 
 // UpsertDependency inserts a dependency, ignoring conflicts (for JSONL import).
 func (s *Store) UpsertDependency(dep *types.Dependency) error {
-	_, err := s.db.Exec(`
+	return upsertDependency(s.db, dep)
+}
+
+func upsertDependency(ex execer, dep *types.Dependency) error {
+	_, err := ex.Exec(`
 		INSERT INTO dependencies (from_id, to_id, type, created_at)
 		VALUES (?, ?, ?, ?)
 		ON CONFLICT(from_id, to_id, type) DO NOTHING
@@ -1122,6 +1342,52 @@ func (s *Store) UpsertDependency(dep *types.Dependency) error {
 	return nil
 }
 
+// Records is a set of insights, threads and dependencies.
+type Records struct {
+	Insights []*types.Insight
+	Threads  []*types.InsightThread
+	Deps     []*types.Dependency
+}
+
+// ApplyRecords deletes the insights and dependencies of remove, then
+// upserts the records of upsert, in one transaction: if any write fails,
+// none is kept. Threads are upserted first so insights can reference them.
+// The threads of remove are ignored.
+func (s *Store) ApplyRecords(remove, upsert Records) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, d := range remove.Deps {
+		if err := removeDependency(tx, d.From, d.To, d.Type); err != nil {
+			return err
+		}
+	}
+	for _, i := range remove.Insights {
+		if err := deleteInsight(tx, i.ID); err != nil {
+			return err
+		}
+	}
+	for _, t := range upsert.Threads {
+		if err := upsertThread(tx, t); err != nil {
+			return fmt.Errorf("thread %s: %w", t.ID, err)
+		}
+	}
+	for _, i := range upsert.Insights {
+		if err := upsertInsight(tx, i); err != nil {
+			return fmt.Errorf("insight %s: %w", i.ID, err)
+		}
+	}
+	for _, d := range upsert.Deps {
+		if err := upsertDependency(tx, d); err != nil {
+			return fmt.Errorf("dependency %s -> %s: %w", d.From, d.To, err)
+		}
+	}
+	return tx.Commit()
+}
+
 // Verify checks the database integrity.
 func (s *Store) Verify() error {
 	// Run integrity check
@@ -1149,3 +1415,160 @@ func (s *Store) Verify() error {
 
 	return nil
 }
+
+// marshalUnderstandingHistory encodes a thread's understanding history as a
+// JSON column value, or NULL when there is none.
+func marshalUnderstandingHistory(thread *types.InsightThread) (interface{}, error) {
+	if len(thread.UnderstandingHistory) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(thread.UnderstandingHistory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal understanding history: %w", err)
+	}
+	return string(data), nil
+}
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanInsight reads an insight from a row with the columns GetInsight
+// selects, in order, followed by any extra columns into extra. Errors from
+// the scan itself, such as sql.ErrNoRows, are returned unwrapped.
+func scanInsight(row rowScanner, extra ...interface{}) (*types.Insight, error) {
+	var insight types.Insight
+	var sourceParticipantsJSON, tagsJSON, endorsedByJSON sql.NullString
+	var authorID, threadID, contentHash, anchorsJSON, sourceGitJSON sql.NullString
+
+	dest := []interface{}{
+		&insight.ID,
+		&insight.Timestamp,
+		&insight.Content,
+		&insight.Summary,
+		&insight.Type,
+		&insight.Confidence,
+		&insight.Source.Type,
+		&insight.Source.Ref,
+		&sourceParticipantsJSON,
+		&threadID,
+		&authorID,
+		&endorsedByJSON,
+		&tagsJSON,
+		&insight.CreatedBy,
+		&insight.CreatedAt,
+		&contentHash,
+		&anchorsJSON,
+		&sourceGitJSON,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	// Handle nullable fields
+	if authorID.Valid {
+		insight.AuthorID = authorID.String
+	}
+	if threadID.Valid {
+		insight.ThreadID = threadID.String
+	}
+	if contentHash.Valid {
+		insight.ContentHash = contentHash.String
+	}
+
+	// Deserialize complex fields
+	if sourceParticipantsJSON.Valid && sourceParticipantsJSON.String != "" {
+		if err := json.Unmarshal([]byte(sourceParticipantsJSON.String), &insight.Source.Participants); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal source participants: %w", err)
+		}
+	}
+
+	if tagsJSON.Valid && tagsJSON.String != "" {
+		if err := json.Unmarshal([]byte(tagsJSON.String), &insight.Tags); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal tags: %w", err)
+		}
+	}
+
+	if endorsedByJSON.Valid && endorsedByJSON.String != "" {
+		if err := json.Unmarshal([]byte(endorsedByJSON.String), &insight.EndorsedBy); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal endorsed_by: %w", err)
+		}
+	}
+
+	if err := unmarshalAnchors(anchorsJSON, &insight); err != nil {
+		return nil, err
+	}
+
+	if err := unmarshalSourceGit(sourceGitJSON, &insight); err != nil {
+		return nil, err
+	}
+
+	return &insight, nil
+}
+
+// unmarshalUnderstandingHistory decodes the understanding_history column.
+func unmarshalUnderstandingHistory(historyJSON sql.NullString, thread *types.InsightThread) error {
+	if !historyJSON.Valid || historyJSON.String == "" {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(historyJSON.String), &thread.UnderstandingHistory); err != nil {
+		return fmt.Errorf("failed to unmarshal understanding history: %w", err)
+	}
+	return nil
+}
+
+// marshalAnchors encodes an insight's code anchors for the anchors column.
+func marshalAnchors(insight *types.Insight) (interface{}, error) {
+	if len(insight.Anchors) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(insight.Anchors)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal anchors: %w", err)
+	}
+	return string(data), nil
+}
+
+// unmarshalAnchors decodes the anchors column.
+func unmarshalAnchors(anchorsJSON sql.NullString, insight *types.Insight) error {
+	if !anchorsJSON.Valid || anchorsJSON.String == "" {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(anchorsJSON.String), &insight.Anchors); err != nil {
+		return fmt.Errorf("failed to unmarshal anchors: %w", err)
+	}
+	return nil
+}
+
+// marshalSourceGit encodes an insight's git context for the source_git column.
+func marshalSourceGit(insight *types.Insight) (interface{}, error) {
+	if insight.Source.Git == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(insight.Source.Git)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal git context: %w", err)
+	}
+	return string(data), nil
+}
+
+// unmarshalSourceGit decodes the source_git column.
+func unmarshalSourceGit(sourceGitJSON sql.NullString, insight *types.Insight) error {
+	if !sourceGitJSON.Valid || sourceGitJSON.String == "" {
+		return nil
+	}
+	insight.Source.Git = &types.GitContext{}
+	if err := json.Unmarshal([]byte(sourceGitJSON.String), insight.Source.Git); err != nil {
+		return fmt.Errorf("failed to unmarshal git context: %w", err)
+	}
+	return nil
+}
+
+// nullableString maps an empty string to SQL NULL.
+func nullableString(v string) interface{} {
+	if v == "" {
+		return nil
+	}
+	return v
+}
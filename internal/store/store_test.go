@@ -1,7 +1,10 @@
 package store
 
 import (
+	"errors"
 	"os"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
@@ -819,6 +822,167 @@ func TestUpdateThread_NotFound(t *testing.T) {
 	}
 }
 
+func TestThreadUnderstandingHistory(t *testing.T) {
+	s := newTestStore(t)
+
+	thread := types.NewThread("Understood")
+	if err := s.CreateThread(thread); err != nil {
+		t.Fatal(err)
+	}
+
+	at := time.Now().Truncate(time.Second)
+	thread.SetUnderstanding("first", "alice", at)
+	thread.SetUnderstanding("second", "bob", at.Add(time.Minute))
+	if err := s.UpdateThread(thread); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := s.GetThread(thread.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.CurrentUnderstanding != "second" {
+		t.Errorf("CurrentUnderstanding = %q, want %q", got.CurrentUnderstanding, "second")
+	}
+	if len(got.UnderstandingHistory) != 2 || got.UnderstandingHistory[0].AuthorID != "alice" {
+		t.Errorf("unexpected history: %+v", got.UnderstandingHistory)
+	}
+
+	// History survives the JSONL import path
+	if err := s.UpsertThread(got); err != nil {
+		t.Fatal(err)
+	}
+	threads, err := s.ListThreads("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(threads) != 1 || len(threads[0].UnderstandingHistory) != 2 {
+		t.Errorf("expected history in ListThreads, got %+v", threads)
+	}
+}
+
+func TestInsightAnchors(t *testing.T) {
+	s := newTestStore(t)
+
+	insight := types.NewInsight("The bug is in JWT validation", types.InsightDiscovery)
+	insight.Anchors = []types.CodeAnchor{
+		{Path: "src/auth/jwt.go", StartLine: 40, EndLine: 72, Commit: "abc123"},
+		{Path: "README.md"},
+	}
+	if err := s.CreateInsight(insight); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := s.GetInsight(insight.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Anchors) != 2 || got.Anchors[0] != insight.Anchors[0] || got.Anchors[1].Path != "README.md" {
+		t.Errorf("anchors did not round-trip: %+v", got.Anchors)
+	}
+
+	// Anchors survive updates and the JSONL import path
+	got.Anchors = got.Anchors[:1]
+	if err := s.UpsertInsight(got); err != nil {
+		t.Fatal(err)
+	}
+	list, err := s.ListInsights("", "", time.Time{}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || len(list[0].Anchors) != 1 {
+		t.Errorf("expected one anchor after upsert, got %+v", list)
+	}
+
+	plain := types.NewInsight("No code here", types.InsightQuestion)
+	if err := s.CreateInsight(plain); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.GetInsight(plain.ID); got.Anchors != nil {
+		t.Errorf("expected no anchors, got %+v", got.Anchors)
+	}
+}
+
+func TestInsightSourceGit(t *testing.T) {
+	s := newTestStore(t)
+
+	insight := types.NewInsight("Found the race", types.InsightDiscovery)
+	insight.Source.Git = &types.GitContext{Branch: "feature/auth", Commit: "0123abcd", Dirty: true, Worktree: "/src/app"}
+	if err := s.CreateInsight(insight); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := s.GetInsight(insight.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Source.Git == nil || *got.Source.Git != *insight.Source.Git {
+		t.Errorf("git context did not round-trip: %+v", got.Source.Git)
+	}
+
+	list, err := s.SearchInsights("race")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].Source.Git == nil || list[0].Source.Git.Branch != "feature/auth" {
+		t.Errorf("expected git context in search results, got %+v", list)
+	}
+}
+
+func TestThreadParentID(t *testing.T) {
+	s := newTestStore(t)
+
+	parent := types.NewThread("Parent")
+	child := types.NewThread("Child")
+	child.ParentID = parent.ID
+	grandchild := types.NewThread("Grandchild")
+	grandchild.ParentID = child.ID
+
+	for _, th := range []*types.InsightThread{parent, child, grandchild} {
+		if err := s.CreateThread(th); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := s.GetThread(child.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ParentID != parent.ID {
+		t.Errorf("ParentID = %q, want %q", got.ParentID, parent.ID)
+	}
+
+	got, err = s.GetThread(parent.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ParentID != "" {
+		t.Errorf("root thread ParentID = %q, want empty", got.ParentID)
+	}
+
+	// Only direct children are returned
+	children, err := s.ListChildThreads(parent.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(children) != 1 || children[0].ID != child.ID {
+		t.Errorf("expected [%s], got %v", child.ID, children)
+	}
+
+	// Clearing the parent via update detaches the thread
+	child.ParentID = ""
+	if err := s.UpdateThread(child); err != nil {
+		t.Fatal(err)
+	}
+	children, err = s.ListChildThreads(parent.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(children) != 0 {
+		t.Errorf("expected no children after detaching, got %d", len(children))
+	}
+}
+
 // ============================================================================
 // Dependency Operations
 // ============================================================================
@@ -931,6 +1095,179 @@ func TestDuplicateDependency(t *testing.T) {
 	}
 }
 
+func TestRemoveDependency(t *testing.T) {
+	s := newTestStore(t)
+
+	insA := types.NewInsight("A", types.InsightPivot)
+	insB := types.NewInsight("B", types.InsightHypothesis)
+	s.CreateInsight(insA)
+	s.CreateInsight(insB)
+
+	s.AddDependency(types.NewDependency(insA.ID, insB.ID, types.DepSupersedes))
+	s.AddDependency(types.NewDependency(insA.ID, insB.ID, types.DepBuildsOn))
+
+	if err := s.RemoveDependency(insA.ID, insB.ID, types.DepSupersedes); err != nil {
+		t.Fatalf("RemoveDependency: %v", err)
+	}
+
+	deps, err := s.GetDependencies(insA.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deps) != 1 || deps[0].Type != types.DepBuildsOn {
+		t.Errorf("expected only the builds-on edge to remain, got %+v", deps)
+	}
+
+	// Removing again should report not found
+	if err := s.RemoveDependency(insA.ID, insB.ID, types.DepSupersedes); err == nil {
+		t.Error("expected error removing a missing dependency")
+	}
+}
+
+func TestUpdateInsight_RecomputesContentHash(t *testing.T) {
+	s := newTestStore(t)
+
+	thread := types.NewThread("Hash Test")
+	s.CreateThread(thread)
+
+	ins := types.NewInsight("Moved insight", types.InsightDiscovery)
+	if err := s.CreateInsight(ins); err != nil {
+		t.Fatal(err)
+	}
+	before := ins.ContentHash
+
+	ins.ThreadID = thread.ID
+	if err := s.UpdateInsight(ins); err != nil {
+		t.Fatalf("UpdateInsight: %v", err)
+	}
+
+	got, err := s.GetInsight(ins.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ContentHash == before {
+		t.Error("content hash should change when the thread changes")
+	}
+	if got.ContentHash != got.ComputeContentHash() {
+		t.Error("stored content hash does not match recomputed hash")
+	}
+}
+
+func TestMoveInsights(t *testing.T) {
+	s := newTestStore(t)
+
+	src := types.NewThread("Source")
+	dst := types.NewThread("Destination")
+	for _, th := range []*types.InsightThread{src, dst} {
+		if err := s.CreateThread(th); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a := types.NewInsight("first", types.InsightDiscovery)
+	a.ThreadID = src.ID
+	b := types.NewInsight("second", types.InsightDecision)
+	b.ThreadID = src.ID
+	for _, ins := range []*types.Insight{a, b} {
+		if err := s.CreateInsight(ins); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.AddDependency(types.NewDependency(b.ID, a.ID, types.DepBuildsOn)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.MoveInsights([]string{a.ID}, dst.ID); err != nil {
+		t.Fatalf("MoveInsights: %v", err)
+	}
+
+	got, err := s.GetInsight(a.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ThreadID != dst.ID {
+		t.Errorf("ThreadID = %q, want %q", got.ThreadID, dst.ID)
+	}
+	if got.ContentHash != got.ComputeContentHash() {
+		t.Error("content hash not recomputed after move")
+	}
+
+	deps, err := s.GetDependencies(b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deps) != 1 || deps[0].To != a.ID {
+		t.Errorf("dependency should survive move, got %v", deps)
+	}
+
+	if err := s.MoveInsights([]string{"ins-none"}, dst.ID); err == nil {
+		t.Error("expected error moving non-existent insight")
+	}
+}
+
+func TestMergeThreads(t *testing.T) {
+	s := newTestStore(t)
+
+	src := types.NewThread("Source")
+	dst := types.NewThread("Destination")
+	child := types.NewThread("Child of source")
+	child.ParentID = src.ID
+	for _, th := range []*types.InsightThread{src, dst, child} {
+		if err := s.CreateThread(th); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ins := types.NewInsight("moved by merge", types.InsightDiscovery)
+	ins.ThreadID = src.ID
+	if err := s.CreateInsight(ins); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	if err := s.CreateExternalRefMapping(&ExternalRefMapping{
+		ExternalRef: "bead:abc1", ThreadID: src.ID, System: "bead", ExternalID: "abc1",
+		Metadata: "{}", CreatedAt: now, UpdatedAt: now,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.MergeThreads(src.ID, dst.ID); err != nil {
+		t.Fatalf("MergeThreads: %v", err)
+	}
+
+	got, _ := s.GetInsight(ins.ID)
+	if got.ThreadID != dst.ID {
+		t.Errorf("insight ThreadID = %q, want %q", got.ThreadID, dst.ID)
+	}
+
+	m, _ := s.GetExternalRefMappingByRef("bead:abc1")
+	if m == nil || m.ThreadID != dst.ID {
+		t.Errorf("external ref should be remapped to %s, got %+v", dst.ID, m)
+	}
+
+	gotChild, _ := s.GetThread(child.ID)
+	if gotChild.ParentID != dst.ID {
+		t.Errorf("child ParentID = %q, want %q", gotChild.ParentID, dst.ID)
+	}
+
+	// Source is kept as a redirect
+	gotSrc, err := s.GetThread(src.ID)
+	if err != nil {
+		t.Fatalf("merged thread should still exist: %v", err)
+	}
+	if gotSrc.Status != types.ThreadMerged || gotSrc.MergedInto != dst.ID {
+		t.Errorf("expected merged redirect to %s, got status=%s merged_into=%s", dst.ID, gotSrc.Status, gotSrc.MergedInto)
+	}
+
+	if err := s.MergeThreads(dst.ID, dst.ID); err == nil {
+		t.Error("expected error merging a thread into itself")
+	}
+	if err := s.MergeThreads("thr-none", dst.ID); err == nil {
+		t.Error("expected error merging unknown thread")
+	}
+}
+
 // ============================================================================
 // Verify
 // ============================================================================
@@ -942,3 +1279,198 @@ func TestVerify(t *testing.T) {
 		t.Fatalf("Verify failed on fresh store: %v", err)
 	}
 }
+
+func TestSearchInsightsRanked(t *testing.T) {
+	s := newTestStore(t)
+
+	thread := types.NewThread("Rank Test")
+	s.CreateThread(thread)
+
+	redis := types.NewInsight("Redis connections time out under load", types.InsightDiscovery)
+	redis.ThreadID = thread.ID
+	backoff := types.NewInsight("Retry Redis calls with exponential backoff", types.InsightDecision)
+	other := types.NewInsight("The export job leaks memory", types.InsightDiscovery)
+	for _, ins := range []*types.Insight{redis, backoff, other} {
+		if err := s.CreateInsight(ins); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ranked, err := s.SearchInsightsRanked(MatchAny("redis connection timeouts"), "", 0)
+	if err != nil {
+		t.Fatalf("SearchInsightsRanked failed: %v", err)
+	}
+	if len(ranked) != 2 || ranked[0].ID != redis.ID || ranked[1].ID != backoff.ID {
+		t.Fatalf("expected %s then %s, got %+v", redis.ID, backoff.ID, ranked)
+	}
+	if ranked[0].Score <= ranked[1].Score {
+		t.Errorf("scores should descend: %.2f, %.2f", ranked[0].Score, ranked[1].Score)
+	}
+
+	ranked, err = s.SearchInsightsRanked(MatchAny("redis"), thread.ID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ranked) != 1 || ranked[0].ID != redis.ID {
+		t.Errorf("thread filter: expected only %s, got %+v", redis.ID, ranked)
+	}
+
+	// Updates and deletes keep the index current.
+	other.Content = "Redis memory grows without eviction"
+	if err := s.UpdateInsight(other); err != nil {
+		t.Fatalf("UpdateInsight failed: %v", err)
+	}
+	if err := s.DeleteInsight(backoff.ID); err != nil {
+		t.Fatalf("DeleteInsight failed: %v", err)
+	}
+	ranked, err = s.SearchInsightsRanked(MatchAny("redis memory"), "", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ranked) != 1 || ranked[0].ID != other.ID {
+		t.Errorf("expected updated %s first, got %+v", other.ID, ranked)
+	}
+	ranked, _ = s.SearchInsightsRanked(MatchAny("exponential backoff"), "", 0)
+	if len(ranked) != 0 {
+		t.Errorf("deleted insight still ranked: %+v", ranked)
+	}
+}
+
+func TestCreateInsight_Duplicate(t *testing.T) {
+	s := newTestStore(t)
+	if err := s.CreateInsight(types.NewInsight("Redis connections time out", types.InsightDiscovery)); err != nil {
+		t.Fatal(err)
+	}
+	err := s.CreateInsight(types.NewInsight("Redis connections time out", types.InsightDiscovery))
+	if !errors.Is(err, ErrDuplicateInsight) {
+		t.Errorf("expected ErrDuplicateInsight, got %v", err)
+	}
+}
+
+func TestMatchAny(t *testing.T) {
+	s := newTestStore(t)
+	policy := types.NewInsight("The retry policy caps attempts", types.InsightDecision)
+	caches := types.NewInsight("Tenant caches are warmed at boot", types.InsightDiscovery)
+	for _, ins := range []*types.Insight{policy, caches} {
+		if err := s.CreateInsight(ins); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := MatchAny("retry policies"); got != `"retry"* OR "policies"* OR "policy"*` {
+		t.Errorf("MatchAny = %q", got)
+	}
+	found, err := s.SearchInsights(MatchAny("policies and cache"))
+	if err != nil {
+		t.Fatalf("SearchInsights failed: %v", err)
+	}
+	if len(found) != 2 {
+		t.Errorf("expected both insights, got %d", len(found))
+	}
+}
+
+func TestApplyRecords_RollsBack(t *testing.T) {
+	s := newTestStore(t)
+
+	a := types.NewInsight("Sessions live in Postgres", types.InsightDecision)
+	b := types.NewInsight("Add an index on session expiry", types.InsightDecision)
+	for _, ins := range []*types.Insight{a, b} {
+		if err := s.CreateInsight(ins); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dep := types.NewDependency(b.ID, a.ID, types.DepBuildsOn)
+	if err := s.AddDependency(dep); err != nil {
+		t.Fatal(err)
+	}
+
+	// The insight's thread doesn't exist, so the upsert fails after the delete
+	orphan := types.NewInsight("Expire sessions after a day", types.InsightDecision)
+	orphan.ThreadID = "thr-missing"
+	err := s.ApplyRecords(
+		Records{Deps: []*types.Dependency{dep}},
+		Records{Insights: []*types.Insight{orphan}},
+	)
+	if err == nil {
+		t.Fatal("expected ApplyRecords to fail on the missing thread")
+	}
+	if deps, _ := s.GetDependencies(b.ID); len(deps) != 1 {
+		t.Errorf("the delete should be rolled back, got %d dependencies", len(deps))
+	}
+	if _, err := s.GetInsight(orphan.ID); err == nil {
+		t.Error("no insight should be saved when the transaction fails")
+	}
+
+	thread := types.NewThread("Sessions")
+	orphan.ThreadID = thread.ID
+	err = s.ApplyRecords(
+		Records{Deps: []*types.Dependency{dep}},
+		Records{Insights: []*types.Insight{orphan}, Threads: []*types.InsightThread{thread}},
+	)
+	if err != nil {
+		t.Fatalf("ApplyRecords failed: %v", err)
+	}
+	if deps, _ := s.GetDependencies(b.ID); len(deps) != 0 {
+		t.Errorf("dependency should be deleted, got %d", len(deps))
+	}
+	if got, err := s.GetInsight(orphan.ID); err != nil || got.ThreadID != thread.ID {
+		t.Errorf("insight should be saved in its thread, got %+v, %v", got, err)
+	}
+}
+
+func TestMergeInsights(t *testing.T) {
+	s := newTestStore(t)
+
+	keep := types.NewInsight("Keep sessions in Postgres", types.InsightDecision)
+	keep.Tags = []string{"db"}
+	dup := types.NewInsight("We keep the sessions in Postgres", types.InsightDecision)
+	dup.Tags = []string{"db", "sessions"}
+	dup.EndorsedBy = []string{"alice"}
+	q := types.NewInsight("Where do sessions live?", types.InsightQuestion)
+	follow := types.NewInsight("Add an index on session expiry", types.InsightDecision)
+	for _, ins := range []*types.Insight{keep, dup, q, follow} {
+		if err := s.CreateInsight(ins); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, d := range []*types.Dependency{
+		types.NewDependency(dup.ID, q.ID, types.DepBuildsOn),
+		types.NewDependency(follow.ID, dup.ID, types.DepBuildsOn),
+		types.NewDependency(keep.ID, dup.ID, types.DepBuildsOn),
+	} {
+		if err := s.AddDependency(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := s.MergeInsights(dup.ID, keep.ID); err != nil {
+		t.Fatalf("MergeInsights failed: %v", err)
+	}
+
+	got, _ := s.GetInsight(keep.ID)
+	if strings.Join(got.Tags, ",") != "db,sessions" || strings.Join(got.EndorsedBy, ",") != "alice" {
+		t.Errorf("expected merged labels and endorsements, got %v / %v", got.Tags, got.EndorsedBy)
+	}
+	deps, _ := s.ListAllDependencies()
+	var edges []string
+	for _, d := range deps {
+		edges = append(edges, d.From+">"+d.To+":"+string(d.Type))
+	}
+	sort.Strings(edges)
+	want := []string{
+		dup.ID + ">" + keep.ID + ":duplicates",
+		follow.ID + ">" + keep.ID + ":builds-on",
+		keep.ID + ">" + q.ID + ":builds-on",
+	}
+	sort.Strings(want)
+	if strings.Join(edges, " ") != strings.Join(want, " ") {
+		t.Errorf("links = %v, want %v", edges, want)
+	}
+
+	if err := s.MergeInsights(dup.ID, "ins-missing"); err == nil {
+		t.Error("expected an error for a missing insight")
+	}
+	if deps, _ := s.ListAllDependencies(); len(deps) != len(want) {
+		t.Errorf("a failed merge should change nothing, got %d links", len(deps))
+	}
+}
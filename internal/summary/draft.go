@@ -0,0 +1,68 @@
+package summary
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+)
+
+// Draft limits: how many of the most recent decisions and pivots to include.
+const (
+	draftMaxDecisions = 3
+	draftMaxPivots    = 2
+)
+
+// DraftUnderstanding builds a "current understanding" paragraph from the
+// latest decisions and pivots among a thread's insights (oldest first). The
+// output depends only on its input, so the same thread always produces the
+// same draft. Returns "" when there is nothing to draft from.
+func DraftUnderstanding(insights []*types.Insight) string {
+	var decisions, pivots []*types.Insight
+	for i := len(insights) - 1; i >= 0; i-- {
+		switch insights[i].Type {
+		case types.InsightDecision:
+			if len(decisions) < draftMaxDecisions {
+				decisions = append(decisions, insights[i])
+			}
+		case types.InsightPivot:
+			if len(pivots) < draftMaxPivots {
+				pivots = append(pivots, insights[i])
+			}
+		}
+	}
+	if len(decisions) == 0 && len(pivots) == 0 {
+		return ""
+	}
+
+	var lines []string
+	if len(decisions) > 0 {
+		lines = append(lines, fmt.Sprintf("Current direction: %s.", draftText(decisions[0])))
+		if len(decisions) > 1 {
+			lines = append(lines, fmt.Sprintf("Also decided: %s.", joinDraftTexts(decisions[1:])))
+		}
+	}
+	if len(pivots) > 0 {
+		lines = append(lines, fmt.Sprintf("Changed course: %s.", joinDraftTexts(pivots)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// draftText returns an insight's summary (or content) as a single line
+// without trailing punctuation.
+func draftText(ins *types.Insight) string {
+	text := ins.Summary
+	if text == "" {
+		text = ins.Content
+	}
+	text = strings.Join(strings.Fields(text), " ")
+	return strings.TrimRight(text, ".;")
+}
+
+func joinDraftTexts(insights []*types.Insight) string {
+	texts := make([]string, len(insights))
+	for i, ins := range insights {
+		texts[i] = draftText(ins)
+	}
+	return strings.Join(texts, "; ")
+}
@@ -0,0 +1,50 @@
+package summary
+
+import (
+	"testing"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+)
+
+func TestDraftUnderstanding(t *testing.T) {
+	insights := []*types.Insight{
+		makeInsight("Use memcached", types.InsightDecision),
+		makeInsight("Memcached lacks persistence", types.InsightDiscovery),
+		makeInsight("Dropping memcached.", types.InsightPivot),
+		makeInsight("Use Redis", types.InsightDecision),
+		makeInsight("Set a 5  minute\nTTL", types.InsightDecision),
+	}
+
+	got := DraftUnderstanding(insights)
+	want := "Current direction: Set a 5 minute TTL.\n" +
+		"Also decided: Use Redis; Use memcached.\n" +
+		"Changed course: Dropping memcached."
+	if got != want {
+		t.Errorf("DraftUnderstanding =\n%s\nwant\n%s", got, want)
+	}
+
+	// Deterministic: same input, same output
+	if again := DraftUnderstanding(insights); again != got {
+		t.Error("draft should be deterministic")
+	}
+}
+
+func TestDraftUnderstanding_Limits(t *testing.T) {
+	var insights []*types.Insight
+	for _, c := range []string{"d1", "d2", "d3", "d4"} {
+		insights = append(insights, makeInsight(c, types.InsightDecision))
+	}
+
+	got := DraftUnderstanding(insights)
+	want := "Current direction: d4.\nAlso decided: d3; d2."
+	if got != want {
+		t.Errorf("DraftUnderstanding = %q, want %q", got, want)
+	}
+}
+
+func TestDraftUnderstanding_Empty(t *testing.T) {
+	insights := []*types.Insight{makeInsight("Just a finding", types.InsightDiscovery)}
+	if got := DraftUnderstanding(insights); got != "" {
+		t.Errorf("expected empty draft, got %q", got)
+	}
+}
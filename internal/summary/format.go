@@ -9,9 +9,21 @@ import (
 	"github.com/brianevanmiller/beadcrumbs/internal/types"
 )
 
+// ChildThread pairs a sub-thread with its insights for rollup summaries.
+type ChildThread struct {
+	Thread   *types.InsightThread
+	Insights []*types.Insight
+}
+
 // FormatSummary formats thread insights as a markdown summary comment.
 // This is the canonical format used by all integrations (Linear, GitHub PR, etc.).
 func FormatSummary(thread *types.InsightThread, insights []*types.Insight) string {
+	return FormatRollupSummary(thread, insights, nil)
+}
+
+// FormatRollupSummary is FormatSummary plus a section listing the decisions
+// made in each sub-thread. Sub-threads without decisions are omitted.
+func FormatRollupSummary(thread *types.InsightThread, insights []*types.Insight, children []ChildThread) string {
 	var sb strings.Builder
 
 	sb.WriteString(fmt.Sprintf("## Beadcrumbs Summary \u2014 Thread `%s`\n\n", thread.ID))
@@ -42,6 +54,27 @@ func FormatSummary(thread *types.InsightThread, insights []*types.Insight) strin
 		sb.WriteString("\n")
 	}
 
+	var childSections []string
+	for _, child := range children {
+		var lines []string
+		for _, ins := range child.Insights {
+			if ins.Type == types.InsightDecision {
+				lines = append(lines, fmt.Sprintf("  - **%s**\n", ins.Content))
+			}
+		}
+		if len(lines) > 0 {
+			childSections = append(childSections,
+				fmt.Sprintf("- `%s` %s\n%s", child.Thread.ID, child.Thread.Title, strings.Join(lines, "")))
+		}
+	}
+	if len(childSections) > 0 {
+		sb.WriteString("### Sub-thread Decisions\n\n")
+		for _, section := range childSections {
+			sb.WriteString(section)
+		}
+		sb.WriteString("\n")
+	}
+
 	if len(discoveries) > 0 {
 		sb.WriteString("### Discoveries\n\n")
 		for _, d := range discoveries {
@@ -66,9 +99,26 @@ func FormatSummary(thread *types.InsightThread, insights []*types.Insight) strin
 		sb.WriteString("\n")
 	}
 
+	// Project-defined types get their own sections, in config order.
+	for _, def := range types.CustomInsightTypes() {
+		if typeCounts[def.Name] == 0 {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("### %s\n\n", def.Section))
+		for _, ins := range insights {
+			if ins.Type == def.Name {
+				sb.WriteString(fmt.Sprintf("- %s\n", ins.Content))
+			}
+		}
+		sb.WriteString("\n")
+	}
+
 	if thread.CurrentUnderstanding != "" {
 		sb.WriteString("### Summary\n\n")
 		sb.WriteString(thread.CurrentUnderstanding + "\n\n")
+		if rev := thread.LatestUnderstanding(); rev != nil && rev.AuthorID != "" {
+			sb.WriteString(fmt.Sprintf("*Updated %s by %s*\n\n", rev.UpdatedAt.Format("2006-01-02"), rev.AuthorID))
+		}
 	}
 
 	// Footer: divider + per-type breakdown + attribution
@@ -92,12 +142,19 @@ func FormatSummary(thread *types.InsightThread, insights []*types.Insight) strin
 		types.InsightQuestion:   "questions",
 		types.InsightFeedback:   "feedback",
 	}
+	for _, def := range types.CustomInsightTypes() {
+		typeOrder = append(typeOrder, def.Name)
+		pluralLabels[def.Name] = def.Plural
+	}
 	for _, t := range typeOrder {
 		if c, ok := typeCounts[t]; ok && c > 0 {
 			label := pluralLabels[t]
 			parts = append(parts, fmt.Sprintf("%d %s", c, label))
 		}
 	}
+	if len(children) > 0 {
+		parts = append(parts, fmt.Sprintf("%d sub-threads", len(children)))
+	}
 	sb.WriteString(fmt.Sprintf("*%s*\n", strings.Join(parts, " \u00b7 ")))
 	sb.WriteString("*Tracked by [beadcrumbs](https://github.com/brianevanmiller/beadcrumbs)*\n")
 
@@ -168,3 +168,60 @@ func TestFormatSummary_Empty(t *testing.T) {
 		t.Error("missing attribution even with no insights")
 	}
 }
+
+func TestFormatRollupSummary_ChildDecisions(t *testing.T) {
+	parent := &types.InsightThread{ID: "thr-0001", Title: "Auth investigation"}
+	children := []ChildThread{
+		{
+			Thread:   &types.InsightThread{ID: "thr-0002", Title: "Token refresh"},
+			Insights: []*types.Insight{makeInsight("Refresh tokens on 401", types.InsightDecision)},
+		},
+		{
+			// No decisions: omitted from the section but still counted.
+			Thread:   &types.InsightThread{ID: "thr-0003", Title: "Session storage"},
+			Insights: []*types.Insight{makeInsight("Cookies are httpOnly", types.InsightDiscovery)},
+		},
+	}
+
+	result := FormatRollupSummary(parent, []*types.Insight{makeInsight("Split auth work", types.InsightDecision)}, children)
+
+	if !strings.Contains(result, "### Sub-thread Decisions") {
+		t.Errorf("missing sub-thread section, got:\n%s", result)
+	}
+	if !strings.Contains(result, "- `thr-0002` Token refresh\n  - **Refresh tokens on 401**") {
+		t.Errorf("missing child decision, got:\n%s", result)
+	}
+	if strings.Contains(result, "thr-0003") {
+		t.Error("child thread without decisions should be omitted")
+	}
+	if !strings.Contains(result, "2 sub-threads") {
+		t.Error("footer should count sub-threads")
+	}
+}
+
+func TestFormatSummary_NoSubThreadSection(t *testing.T) {
+	thread := &types.InsightThread{ID: "thr-0001", Title: "Flat"}
+	result := FormatSummary(thread, []*types.Insight{makeInsight("Decided", types.InsightDecision)})
+	if strings.Contains(result, "Sub-thread") || strings.Contains(result, "sub-threads") {
+		t.Errorf("flat summary should not mention sub-threads, got:\n%s", result)
+	}
+}
+
+func TestFormatSummary_CustomTypeSection(t *testing.T) {
+	types.SetCustomInsightTypes([]types.CustomInsightType{
+		{Name: "risk", Symbol: "⚠", Plural: "risks", Section: "Open Risks"},
+	})
+	t.Cleanup(func() { types.SetCustomInsightTypes(nil) })
+
+	thread := &types.InsightThread{ID: "thr-0001", Title: "Custom"}
+	result := FormatSummary(thread, []*types.Insight{
+		makeInsight("Cache stampede on deploy", "risk"),
+		makeInsight("Use Redis", types.InsightDecision),
+	})
+	if !strings.Contains(result, "### Open Risks\n\n- Cache stampede on deploy") {
+		t.Errorf("expected custom section, got:\n%s", result)
+	}
+	if !strings.Contains(result, "1 risks") {
+		t.Errorf("footer should count custom types with their plural, got:\n%s", result)
+	}
+}
@@ -0,0 +1,149 @@
+// Package typeconfig loads project-defined insight types from
+// .beadcrumbs/types.yaml and registers them with the types package.
+package typeconfig
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileName is the custom types file inside .beadcrumbs/.
+const ConfigFileName = "types.yaml"
+
+// CurrentVersion is the newest config version this bdc understands.
+const CurrentVersion = 1
+
+// Config is the parsed form of .beadcrumbs/types.yaml.
+//
+//	version: 1
+//	types:
+//	  - name: risk
+//	    symbol: "⚠"
+//	    plural: risks
+//	    section: Open Risks
+//	    flag: risk
+//	    detect: ["risk is", "could break"]
+type Config struct {
+	Version int                       `yaml:"version"`
+	Types   []types.CustomInsightType `yaml:"types"`
+}
+
+var namePattern = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
+// reservedFlags are capture flags a custom type may not claim. bdc also
+// checks types against capture's actual flags when it loads them.
+var reservedFlags = map[string]bool{
+	"type": true, "thread": true, "timestamp": true, "author": true,
+	"endorsed-by": true, "origin": true, "help": true, "json": true, "db": true,
+	"at": true, "no-git-context": true, "label": true, "suggest-links": true,
+}
+
+// Load reads and validates a types config file.
+// A missing file is not an error; an empty config is returned instead.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Config{Version: CurrentVersion}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read types config: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse parses, validates and fills defaults for types config YAML.
+func Parse(data []byte) (*Config, error) {
+	cfg := &Config{}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse types config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate rejects missing or future versions, invalid or duplicate names,
+// names that shadow built-in types, and colliding capture flags. Optional
+// fields are filled with defaults: plural = name+"s", section = the
+// capitalized plural, flag = name.
+func (c *Config) Validate() error {
+	if c.Version == 0 {
+		return fmt.Errorf("types config is missing \"version: %d\"", CurrentVersion)
+	}
+	if c.Version > CurrentVersion {
+		return fmt.Errorf("unsupported types config version %d (this bdc understands version %d)", c.Version, CurrentVersion)
+	}
+
+	names := make(map[types.InsightType]bool)
+	flags := make(map[string]types.InsightType)
+	for i := range c.Types {
+		def := &c.Types[i]
+		if !namePattern.MatchString(string(def.Name)) {
+			return fmt.Errorf("type %q: name must be lowercase letters, digits and dashes", def.Name)
+		}
+		if def.Name.IsBuiltin() {
+			return fmt.Errorf("type %q: shadows a built-in type", def.Name)
+		}
+		if names[def.Name] {
+			return fmt.Errorf("type %q: defined more than once", def.Name)
+		}
+		names[def.Name] = true
+
+		if strings.TrimSpace(def.Symbol) == "" {
+			return fmt.Errorf("type %q: symbol is required", def.Name)
+		}
+		if def.Plural == "" {
+			def.Plural = string(def.Name) + "s"
+		}
+		if def.Section == "" {
+			def.Section = strings.ToUpper(def.Plural[:1]) + def.Plural[1:]
+		}
+
+		if def.Flag == "" {
+			def.Flag = string(def.Name)
+		}
+		if !namePattern.MatchString(def.Flag) {
+			return fmt.Errorf("type %q: flag %q must be lowercase letters, digits and dashes", def.Name, def.Flag)
+		}
+		if reservedFlags[def.Flag] || types.InsightType(def.Flag).IsBuiltin() {
+			return fmt.Errorf("type %q: flag --%s is reserved", def.Name, def.Flag)
+		}
+		if other, ok := flags[def.Flag]; ok {
+			return fmt.Errorf("type %q: flag --%s already used by %q", def.Name, def.Flag, other)
+		}
+		flags[def.Flag] = def.Name
+
+		var detect []string
+		for _, phrase := range def.Detect {
+			phrase = strings.ToLower(strings.TrimSpace(phrase))
+			if phrase != "" {
+				detect = append(detect, phrase)
+			}
+		}
+		def.Detect = detect
+	}
+	return nil
+}
+
+// Apply registers the config's types, replacing any previously registered.
+func (c *Config) Apply() {
+	types.SetCustomInsightTypes(c.Types)
+}
+
+// LoadAndApply loads path and registers its types. On error the registry is
+// left empty so a broken config never leaves stale types behind.
+func LoadAndApply(path string) error {
+	cfg, err := Load(path)
+	if err != nil {
+		types.SetCustomInsightTypes(nil)
+		return err
+	}
+	cfg.Apply()
+	return nil
+}
@@ -0,0 +1,114 @@
+package typeconfig
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/brianevanmiller/beadcrumbs/internal/types"
+)
+
+func TestParse_Defaults(t *testing.T) {
+	cfg, err := Parse([]byte(`
+version: 1
+types:
+  - name: risk
+    symbol: "⚠"
+    detect: ["  Could Break ", ""]
+  - name: assumption
+    symbol: "≈"
+    plural: assumptions
+    section: Working Assumptions
+    flag: assume
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(cfg.Types) != 2 {
+		t.Fatalf("expected 2 types, got %d", len(cfg.Types))
+	}
+
+	risk := cfg.Types[0]
+	if risk.Plural != "risks" || risk.Section != "Risks" || risk.Flag != "risk" {
+		t.Errorf("risk defaults = plural %q, section %q, flag %q", risk.Plural, risk.Section, risk.Flag)
+	}
+	if len(risk.Detect) != 1 || risk.Detect[0] != "could break" {
+		t.Errorf("detect phrases should be trimmed and lowercased, got %q", risk.Detect)
+	}
+
+	assumption := cfg.Types[1]
+	if assumption.Section != "Working Assumptions" || assumption.Flag != "assume" {
+		t.Errorf("explicit fields overwritten: %+v", assumption)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want string
+	}{
+		{"missing version", "types: []", "missing"},
+		{"future version", "version: 2", "unsupported"},
+		{"bad name", "version: 1\ntypes: [{name: Risk, symbol: x}]", "lowercase"},
+		{"builtin", "version: 1\ntypes: [{name: decision, symbol: x}]", "built-in"},
+		{"duplicate", "version: 1\ntypes: [{name: risk, symbol: x}, {name: risk, symbol: y}]", "more than once"},
+		{"no symbol", "version: 1\ntypes: [{name: risk}]", "symbol"},
+		{"reserved flag", "version: 1\ntypes: [{name: risk, symbol: x, flag: thread}]", "reserved"},
+		{"builtin flag", "version: 1\ntypes: [{name: risk, symbol: x, flag: pivot}]", "reserved"},
+		{"flag clash", "version: 1\ntypes: [{name: risk, symbol: x}, {name: hazard, symbol: y, flag: risk}]", "already used"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.yaml))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse error = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadAndApply(t *testing.T) {
+	t.Cleanup(func() { types.SetCustomInsightTypes(nil) })
+	dir := t.TempDir()
+	path := filepath.Join(dir, ConfigFileName)
+
+	// Missing file: nothing registered.
+	if err := LoadAndApply(path); err != nil {
+		t.Fatalf("missing file should not error: %v", err)
+	}
+	if types.InsightType("risk").IsValid() {
+		t.Fatal("risk should not be valid without config")
+	}
+
+	if err := os.WriteFile(path, []byte("version: 1\ntypes: [{name: risk, symbol: \"⚠\"}]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadAndApply(path); err != nil {
+		t.Fatalf("LoadAndApply: %v", err)
+	}
+	if !types.InsightType("risk").IsValid() {
+		t.Error("risk should be valid after applying config")
+	}
+	found := false
+	for _, it := range types.ValidInsightTypes() {
+		if it == "risk" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("ValidInsightTypes should include risk")
+	}
+
+	// A broken config clears the registry.
+	if err := os.WriteFile(path, []byte("version: 9\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadAndApply(path); err == nil {
+		t.Fatal("expected error for unsupported version")
+	}
+	if types.InsightType("risk").IsValid() {
+		t.Error("registry should be cleared after a failed load")
+	}
+}
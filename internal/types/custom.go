@@ -0,0 +1,58 @@
+package types
+
+import "sync"
+
+// CustomInsightType is a project-defined insight type (e.g. risk, assumption)
+// loaded from .beadcrumbs/types.yaml. The built-in six are not represented
+// here; they are always valid.
+type CustomInsightType struct {
+	Name        InsightType `yaml:"name" json:"name"`
+	Description string      `yaml:"description,omitempty" json:"description,omitempty"`
+	Symbol      string      `yaml:"symbol" json:"symbol"`                       // Timeline symbol, e.g. "⚠"
+	Plural      string      `yaml:"plural,omitempty" json:"plural"`             // Footer label, e.g. "risks"
+	Section     string      `yaml:"section,omitempty" json:"section,omitempty"` // Summary heading, e.g. "Risks"
+	Flag        string      `yaml:"flag,omitempty" json:"flag,omitempty"`       // capture --<flag> alias
+	Detect      []string    `yaml:"detect,omitempty" json:"detect,omitempty"`   // Lowercase trigger phrases for import
+}
+
+var (
+	customMu    sync.RWMutex
+	customTypes []CustomInsightType
+)
+
+// SetCustomInsightTypes replaces the registered custom types. Definitions
+// are expected to be validated already (see internal/typeconfig).
+func SetCustomInsightTypes(defs []CustomInsightType) {
+	customMu.Lock()
+	defer customMu.Unlock()
+	customTypes = append([]CustomInsightType(nil), defs...)
+}
+
+// CustomInsightTypes returns the registered custom types in config order.
+func CustomInsightTypes() []CustomInsightType {
+	customMu.RLock()
+	defer customMu.RUnlock()
+	return append([]CustomInsightType(nil), customTypes...)
+}
+
+// LookupCustomInsightType returns the custom definition for t, if any.
+func LookupCustomInsightType(t InsightType) (CustomInsightType, bool) {
+	customMu.RLock()
+	defer customMu.RUnlock()
+	for _, def := range customTypes {
+		if def.Name == t {
+			return def, true
+		}
+	}
+	return CustomInsightType{}, false
+}
+
+// IsBuiltin reports whether t is one of the six built-in insight types.
+func (t InsightType) IsBuiltin() bool {
+	switch t {
+	case InsightHypothesis, InsightDiscovery, InsightQuestion, InsightFeedback, InsightPivot, InsightDecision:
+		return true
+	default:
+		return false
+	}
+}
@@ -21,9 +21,10 @@ const (
 	InsightDecision   InsightType = "decision"   // "We'll do..." — committed to approach
 )
 
-// ValidInsightTypes returns all valid insight types.
+// ValidInsightTypes returns all valid insight types: the built-in six
+// followed by any registered custom types.
 func ValidInsightTypes() []InsightType {
-	return []InsightType{
+	valid := []InsightType{
 		InsightHypothesis,
 		InsightDiscovery,
 		InsightQuestion,
@@ -31,23 +32,35 @@ func ValidInsightTypes() []InsightType {
 		InsightPivot,
 		InsightDecision,
 	}
+	for _, def := range CustomInsightTypes() {
+		valid = append(valid, def.Name)
+	}
+	return valid
 }
 
-// IsValid checks if the insight type is valid.
+// IsValid checks if the insight type is built in or registered as custom.
 func (t InsightType) IsValid() bool {
-	switch t {
-	case InsightHypothesis, InsightDiscovery, InsightQuestion, InsightFeedback, InsightPivot, InsightDecision:
+	if t.IsBuiltin() {
 		return true
-	default:
-		return false
 	}
+	_, ok := LookupCustomInsightType(t)
+	return ok
 }
 
 // InsightSource represents the source of an insight.
 type InsightSource struct {
-	Type         string   `json:"type"`                    // ai-session|slack|git|human
-	Ref          string   `json:"ref,omitempty"`           // Optional external reference
-	Participants []string `json:"participants,omitempty"`  // Who was involved
+	Type         string      `json:"type"`                   // ai-session|slack|git|human
+	Ref          string      `json:"ref,omitempty"`          // Optional external reference
+	Participants []string    `json:"participants,omitempty"` // Who was involved
+	Git          *GitContext `json:"git,omitempty"`          // Repository state at capture time
+}
+
+// GitContext records where in the repository an insight was captured.
+type GitContext struct {
+	Branch   string `json:"branch,omitempty"`   // "" when HEAD is detached
+	Commit   string `json:"commit,omitempty"`   // HEAD SHA ("" before the first commit)
+	Dirty    bool   `json:"dirty,omitempty"`    // Uncommitted changes to tracked files
+	Worktree string `json:"worktree,omitempty"` // Absolute path of the worktree root
 }
 
 // Insight is the atomic unit - a moment of understanding.
@@ -73,6 +86,19 @@ type Insight struct {
 	AuthorID   string   `json:"author_id,omitempty"`   // Who captured/recorded this insight
 	EndorsedBy []string `json:"endorsed_by,omitempty"` // Who endorsed this insight
 
+	// Code the insight is about
+	Anchors []CodeAnchor `json:"anchors,omitempty"`
+
+	// Links an import extractor suggested to other insights of the same
+	// import. They are not stored or exported with the insight; bdc import
+	// saves them as dependencies.
+	Links []Dependency `json:"-"`
+
+	// Typed is set by an import extractor when the insight's type has
+	// evidence behind it rather than being a fallback or a guess of the
+	// trained model. It is not stored.
+	Typed bool `json:"-"`
+
 	// Metadata
 	Tags        []string `json:"labels,omitempty"`
 	CreatedBy   string   `json:"created_by,omitempty"` // Legacy field for backwards compatibility
@@ -80,6 +106,15 @@ type Insight struct {
 	ContentHash string   `json:"content_hash,omitempty"` // SHA256 of substantive fields for dedup
 }
 
+// CodeAnchor points an insight at a line range of a file as of a commit.
+// Lines are 1-based and inclusive; zero lines anchor the whole file.
+type CodeAnchor struct {
+	Path      string `json:"path"`                 // Repo-relative, slash-separated
+	StartLine int    `json:"start_line,omitempty"` // First line
+	EndLine   int    `json:"end_line,omitempty"`   // Last line
+	Commit    string `json:"commit,omitempty"`     // Commit the lines refer to ("" if untracked)
+}
+
 // ThreadStatus represents the status of an insight thread.
 type ThreadStatus string
 
@@ -87,6 +122,7 @@ const (
 	ThreadActive    ThreadStatus = "active"
 	ThreadConcluded ThreadStatus = "concluded"
 	ThreadAbandoned ThreadStatus = "abandoned"
+	ThreadMerged    ThreadStatus = "merged" // Redirect left behind by thread merge
 )
 
 // InsightThread groups related insights into a narrative journey.
@@ -95,13 +131,50 @@ type InsightThread struct {
 	Title  string       `json:"title"`  // "Understanding the auth bug"
 	Status ThreadStatus `json:"status"` // active|concluded|abandoned
 
-	// AI-generated summary of current understanding
+	// Parent thread when this thread is a sub-investigation
+	ParentID string `json:"parent_id,omitempty"`
+
+	// Destination thread when Status is merged
+	MergedInto string `json:"merged_into,omitempty"`
+
+	// Summary of current understanding, set via bdc thread understand
 	CurrentUnderstanding string `json:"current_understanding,omitempty"`
 
+	// Every revision of CurrentUnderstanding, oldest first; the last entry is current
+	UnderstandingHistory []UnderstandingRevision `json:"understanding_history,omitempty"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// UnderstandingRevision records one edit of a thread's current understanding.
+type UnderstandingRevision struct {
+	Content   string    `json:"content"`
+	AuthorID  string    `json:"author_id,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SetUnderstanding replaces the thread's current understanding and appends
+// the revision to its history.
+func (t *InsightThread) SetUnderstanding(content, authorID string, at time.Time) {
+	t.CurrentUnderstanding = content
+	t.UnderstandingHistory = append(t.UnderstandingHistory, UnderstandingRevision{
+		Content:   content,
+		AuthorID:  authorID,
+		UpdatedAt: at,
+	})
+	t.UpdatedAt = at
+}
+
+// LatestUnderstanding returns the most recent revision, or nil if the
+// understanding has never been set through SetUnderstanding.
+func (t *InsightThread) LatestUnderstanding() *UnderstandingRevision {
+	if len(t.UnderstandingHistory) == 0 {
+		return nil
+	}
+	return &t.UnderstandingHistory[len(t.UnderstandingHistory)-1]
+}
+
 // DependencyType represents the type of relationship between insights/beads.
 type DependencyType string
 
@@ -110,6 +183,7 @@ const (
 	DepBuildsOn    DependencyType = "builds-on"    // Extends understanding
 	DepSupersedes  DependencyType = "supersedes"   // Replaces/corrects
 	DepContradicts DependencyType = "contradicts"  // Unresolved tension
+	DepDuplicates  DependencyType = "duplicates"   // Near-duplicate merged into the target
 
 	// Insight → Bead relationships (when beads present)
 	DepSpawns DependencyType = "spawns" // Led to task creation
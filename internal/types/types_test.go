@@ -243,3 +243,30 @@ func TestValidInsightTypes_ContainsAllTypes(t *testing.T) {
 		}
 	}
 }
+
+// TestSetUnderstanding verifies revisions accumulate and the latest is current.
+func TestSetUnderstanding(t *testing.T) {
+	thread := NewThread("Understanding")
+	if thread.LatestUnderstanding() != nil {
+		t.Fatal("expected no revision on a new thread")
+	}
+
+	t1 := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+	thread.SetUnderstanding("first take", "alice", t1)
+	thread.SetUnderstanding("second take", "bob", t2)
+
+	if thread.CurrentUnderstanding != "second take" {
+		t.Errorf("CurrentUnderstanding = %q, want %q", thread.CurrentUnderstanding, "second take")
+	}
+	if len(thread.UnderstandingHistory) != 2 {
+		t.Fatalf("expected 2 revisions, got %d", len(thread.UnderstandingHistory))
+	}
+	latest := thread.LatestUnderstanding()
+	if latest.AuthorID != "bob" || !latest.UpdatedAt.Equal(t2) {
+		t.Errorf("unexpected latest revision: %+v", latest)
+	}
+	if !thread.UpdatedAt.Equal(t2) {
+		t.Errorf("UpdatedAt = %v, want %v", thread.UpdatedAt, t2)
+	}
+}